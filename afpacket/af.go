@@ -5,6 +5,8 @@ import (
 	"net"
 	"runtime"
 	"time"
+
+	"github.com/sebnyberg/net/bpf"
 )
 
 // errUnimplemented is returned by all functions on non-Linux platforms.
@@ -40,6 +42,13 @@ func (c *Conn) ReadFrom(p []byte) (n int, addr net.Addr, err error) {
 	return c.readFrom(p)
 }
 
+// SetBPF attaches a classic BPF program to the socket, so that packets which
+// are rejected by the program never leave the kernel. Programs can be compiled
+// from filter expressions with filter.Filter.Compile.
+func (c *Conn) SetBPF(prog []bpf.Instruction) error {
+	return c.setBPF(prog)
+}
+
 func (c *Conn) WriteTo(p []byte, addr net.Addr) (n int, err error) {
 	return 0, errUnimplemented
 }
@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/sebnyberg/net/bpf"
 	"golang.org/x/sys/unix"
 )
 
@@ -46,6 +47,21 @@ func (c *Conn) readFrom(b []byte) (int, net.Addr, error) {
 	return n, addr, nil
 }
 
+func (c *Conn) setBPF(prog []bpf.Instruction) error {
+	if err := bpf.Validate(prog); err != nil {
+		return err
+	}
+	filter := make([]unix.SockFilter, len(prog))
+	for i, ins := range prog {
+		filter[i] = unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K}
+	}
+	fprog := unix.SockFprog{
+		Len:    uint16(len(filter)),
+		Filter: &filter[0],
+	}
+	return unix.SetsockoptSockFprog(c.sockfd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER, &fprog)
+}
+
 func (c *Conn) writeTo(_ []byte, _ net.Addr) (int, error) { return 0, errUnimplemented }
 func (c *Conn) close() error                              { return errUnimplemented }
 func (c *Conn) localAddr() net.Addr                       { return nil }
@@ -9,12 +9,15 @@ import (
 	"runtime"
 	"syscall"
 	"time"
+
+	"github.com/sebnyberg/net/bpf"
 )
 
 var errUnimplemented = fmt.Errorf("not implemented for %v", runtime.GOOS)
 
 func listen(_ net.Interface) (*Conn, error)               { return nil, errUnimplemented }
 func (c *Conn) readFrom(_ []byte) (int, net.Addr, error)  { return 0, nil, errUnimplemented }
+func (c *Conn) setBPF(_ []bpf.Instruction) error          { return errUnimplemented }
 func (c *Conn) writeTo(_ []byte, _ net.Addr) (int, error) { return 0, errUnimplemented }
 func (c *Conn) close() error                              { return errUnimplemented }
 func (c *Conn) localAddr() net.Addr                       { return nil }
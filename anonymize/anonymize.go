@@ -0,0 +1,258 @@
+// Package anonymize sanitizes captured packets before they are shared.
+//
+// IPv4 and IPv6 addresses are rewritten with Crypto-PAn, which is prefix
+// preserving: two addresses which share a k-bit prefix are mapped to
+// addresses which share a k-bit prefix, so subnets remain recognizable.
+// Unicast MAC addresses are replaced with a keyed hash. All mappings are
+// derived from a secret, so the same secret gives the same mappings across
+// files and runs.
+//
+// Addresses are rewritten in the Ethernet, MACsec, ARP and IP headers,
+// including the inner headers of IP in IP tunnels, and in the packets quoted
+// by ICMP errors. Addresses carried in other protocols, such as DNS answers or
+// neighbor discovery options, are only removed by truncating payloads.
+package anonymize
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/pcap"
+)
+
+// Anonymizer rewrites the addresses of packets. It is safe for concurrent
+// use.
+type Anonymizer struct {
+	// TruncatePayload removes all bytes after the transport header of
+	// packets. Checksums are updated before truncation, so they remain valid
+	// for the original payload.
+	TruncatePayload bool
+
+	block  cipher.Block
+	pad    [16]byte
+	macKey []byte
+}
+
+// New returns an anonymizer whose mappings are derived from secret.
+func New(secret []byte) *Anonymizer {
+	derive := func(label string) []byte {
+		h := hmac.New(sha256.New, secret)
+		h.Write([]byte(label))
+		return h.Sum(nil)
+	}
+	key := derive("crypto-pan")
+	a := &Anonymizer{macKey: derive("mac")}
+	a.block, _ = aes.NewCipher(key[:16])
+	a.block.Encrypt(a.pad[:], key[16:32])
+	return a
+}
+
+// Addr returns the anonymized address of addr. The zone of IPv6 addresses is
+// dropped. The mapping is computed for each call rather than cached, so memory
+// use does not grow with the number of addresses seen.
+func (a *Anonymizer) Addr(addr netip.Addr) netip.Addr {
+	res, _ := netip.AddrFromSlice(a.cryptoPAn(addr.AsSlice()))
+	return res
+}
+
+// cryptoPAn anonymizes an address of 4 or 16 bytes. Bit i of the result is
+// bit i of the address XORed with the first bit of the encryption of the
+// preceding i bits, padded with the secret pad.
+func (a *Anonymizer) cryptoPAn(addr []byte) []byte {
+	res := make([]byte, len(addr))
+	copy(res, addr)
+	var in, out [16]byte
+	for i := 0; i < len(addr)*8; i++ {
+		for j := range in {
+			switch {
+			case j >= len(addr) || j*8 >= i:
+				in[j] = a.pad[j]
+			case (j+1)*8 <= i:
+				in[j] = addr[j]
+			default:
+				mask := byte(0xFF) << (8 - (i - j*8))
+				in[j] = addr[j]&mask | a.pad[j]&^mask
+			}
+		}
+		a.block.Encrypt(out[:], in[:])
+		res[i/8] ^= (out[0] >> 7) << (7 - i%8)
+	}
+	return res
+}
+
+// MAC returns the anonymized MAC address of mac. Group addresses, which
+// include broadcast and multicast addresses, identify protocols rather than
+// hosts and are returned unchanged. Unicast addresses are mapped to locally
+// administered addresses.
+func (a *Anonymizer) MAC(mac net.HardwareAddr) net.HardwareAddr {
+	if len(mac) != 6 || mac[0]&0x01 != 0 {
+		return mac
+	}
+	h := hmac.New(sha256.New, a.macKey)
+	h.Write(mac)
+	res := h.Sum(nil)[:6]
+	res[0] = res[0]&^0x01 | 0x02
+	return net.HardwareAddr(res)
+}
+
+// Packet anonymizes the addresses of p in place, and returns the bytes of the
+// frame. If TruncatePayload is set, the frame ends after the transport header,
+// or after the last decoded layer if there is no transport layer.
+func (a *Anonymizer) Packet(p *packet.Packet) []byte {
+	if p.Link == nil {
+		return nil
+	}
+	a.anonymize(p)
+	if !a.TruncatePayload {
+		return p.Link.Contents
+	}
+	return a.truncate(p)
+}
+
+func (a *Anonymizer) anonymize(p *packet.Packet) {
+	copy(p.Link.Destination, a.MAC(p.Link.Destination))
+	copy(p.Link.Source, a.MAC(p.Link.Source))
+	if m := p.MACsec; m != nil && m.HasSCI {
+		// The SCI starts with the address of the transmitting system
+		copy(m.Contents[6:12], a.MAC(m.Contents[6:12]))
+		m.SCI = binary.BigEndian.Uint64(m.Contents[6:14])
+	}
+	a.anonymizeNetwork(p)
+}
+
+func (a *Anonymizer) anonymizeNetwork(p *packet.Packet) {
+	switch n := p.Network.(type) {
+	case *packet.ARP:
+		copy(n.SourceHW, a.MAC(n.SourceHW))
+		copy(n.DestHW, a.MAC(n.DestHW))
+		if n.PType == packet.EthernetTypeIPv4 && n.PLen == 4 {
+			off := 8 + int(n.HLen)
+			n.SourceIP = a.Addr(n.SourceIP)
+			copy(n.Contents[off:off+4], n.SourceIP.AsSlice())
+			off += int(n.HLen) + 4
+			n.DestIP = a.Addr(n.DestIP)
+			copy(n.Contents[off:off+4], n.DestIP.AsSlice())
+		}
+	case *packet.IPv4:
+		// The setters update the checksums, and the quoted packet of ICMP
+		// errors, whose addresses are reversed
+		_ = p.SetSource(a.Addr(n.Source))
+		_ = p.SetDestination(a.Addr(n.Destination))
+	case *packet.IPv6:
+		_ = p.SetSource(a.Addr(n.Source))
+		_ = p.SetDestination(a.Addr(n.Destination))
+	}
+	if p.Inner != nil {
+		a.anonymizeNetwork(p.Inner)
+	}
+}
+
+// truncate returns the frame up to the end of the header of the innermost
+// layer.
+func (a *Anonymizer) truncate(p *packet.Packet) []byte {
+	frame := p.Link.Contents
+	var l packet.Layer = p.Link
+	if p.MACsec != nil && p.MACsec.Encrypted {
+		// The layers of decrypted frames are not part of the frame
+		return frame[:headerEnd(frame, p.MACsec)]
+	}
+	// Tunneled packets end after the inner headers, unless they are encrypted
+	for p.Inner != nil && p.ESP == nil {
+		p = p.Inner
+	}
+	switch {
+	case p.ESP != nil:
+		l = p.ESP
+	case p.Transport != nil:
+		l = p.Transport
+	case p.Network != nil:
+		l = p.Network
+	case p.PPP != nil:
+		l = p.PPP
+	case p.PPPoE != nil:
+		l = p.PPPoE
+	case p.SNAP != nil:
+		l = p.SNAP
+	case p.LLC != nil:
+		l = p.LLC
+	}
+	return frame[:headerEnd(frame, l)]
+}
+
+// headerEnd returns the offset in frame of the end of the header of l. All
+// layers reference the frame buffer, so the offset of a slice follows from its
+// capacity.
+func headerEnd(frame []byte, l packet.Layer) int {
+	c := l.GetContents()
+	off := cap(frame) - cap(c)
+	if _, ok := l.(*packet.SCTP); ok {
+		// The chunks of SCTP packets carry the payload
+		return off + 12
+	}
+	if _, ok := l.(*packet.ESP); ok {
+		// The payload of decrypted packets is not part of the frame
+		return off + 8
+	}
+	if m, ok := l.(*packet.MACsec); ok && m.Encrypted {
+		if m.HasSCI {
+			return off + 14
+		}
+		return off + 6
+	}
+	if pl := l.GetPayload(); pl != nil {
+		return cap(frame) - cap(pl)
+	}
+	return off + len(c)
+}
+
+// Pcap copies the packets of the capture file read from r to a capture file
+// written to w, anonymizing each packet. Only Ethernet captures are
+// supported.
+//
+// Packets which fail to decode are truncated after the last decoded layer
+// regardless of TruncatePayload, since their remaining bytes may contain
+// addresses which could not be rewritten. Packets without a complete
+// Ethernet header are dropped.
+func (a *Anonymizer) Pcap(w io.Writer, r io.Reader) error {
+	pr, err := pcap.NewReader(r)
+	if err != nil {
+		return err
+	}
+	if pr.LinkType() != pcap.LinkTypeEthernet {
+		return fmt.Errorf("unsupported link type %d", pr.LinkType())
+	}
+	pw, err := pcap.NewWriter(w, pr.LinkType(), pr.SnapLen())
+	if err != nil {
+		return err
+	}
+	for {
+		data, ci, err := pr.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p, err := packet.Decode(data)
+		if p.Link == nil {
+			continue
+		}
+		data = a.Packet(&p)
+		if err != nil && !a.TruncatePayload {
+			data = a.truncate(&p)
+		}
+		ci.CaptureLength = len(data)
+		if err := pw.WritePacket(ci, data); err != nil {
+			return err
+		}
+	}
+}
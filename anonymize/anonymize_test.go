@@ -0,0 +1,138 @@
+package anonymize_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/anonymize"
+	"github.com/sebnyberg/net/internal/packettest"
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/pcap"
+)
+
+func TestAddrPrefixPreserving(t *testing.T) {
+	a := anonymize.New([]byte("secret"))
+	for _, tc := range []struct {
+		x, y   string
+		prefix int
+	}{
+		{"192.168.1.10", "192.168.1.200", 24},
+		{"10.0.0.1", "10.128.0.1", 8},
+		{"2001:db8:1::1", "2001:db8:1:ffff::1", 48},
+	} {
+		x, y := netip.MustParseAddr(tc.x), netip.MustParseAddr(tc.y)
+		ax, ay := a.Addr(x), a.Addr(y)
+		if ax == x || ax.Is4() != x.Is4() {
+			t.Errorf("%v was not anonymized: %v", x, ax)
+		}
+		// The addresses must share exactly the same prefix length
+		for _, bits := range []int{tc.prefix, tc.prefix + 1} {
+			want := netip.PrefixFrom(x, bits).Masked() == netip.PrefixFrom(y, bits).Masked()
+			got := netip.PrefixFrom(ax, bits).Masked() == netip.PrefixFrom(ay, bits).Masked()
+			if got != want {
+				t.Errorf("%v and %v: /%d prefix equality %v, want %v", ax, ay, bits, got, want)
+			}
+		}
+	}
+	if b := anonymize.New([]byte("secret")); b.Addr(netip.MustParseAddr("10.0.0.1")) != a.Addr(netip.MustParseAddr("10.0.0.1")) {
+		t.Errorf("mapping is not stable for the same secret")
+	}
+}
+
+func TestPcap(t *testing.T) {
+	var (
+		src = netip.MustParseAddr("192.168.1.10")
+		dst = netip.MustParseAddr("192.168.1.1")
+	)
+	var in bytes.Buffer
+	w, err := pcap.NewWriter(&in, pcap.LinkTypeEthernet, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := packettest.UDPFrame(netip.AddrPortFrom(src, 5353), netip.AddrPortFrom(dst, 53), []byte("example.com"))
+	if err := w.WritePacket(pcap.CaptureInfo{Timestamp: time.Unix(1, 0)}, frame); err != nil {
+		t.Fatal(err)
+	}
+
+	a := anonymize.New([]byte("secret"))
+	a.TruncatePayload = true
+	var out bytes.Buffer
+	if err := a.Pcap(&out, &in); err != nil {
+		t.Fatal(err)
+	}
+	r, err := pcap.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ci, err := r.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 14+20+8 || ci.Length != len(frame) {
+		t.Fatalf("unexpected lengths %d/%d", len(data), ci.Length)
+	}
+
+	// The truncated frame decodes, with the anonymized addresses
+	p, err := packet.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(p.Link.Source, frame[6:12]) || p.Link.Source[0]&0x02 == 0 {
+		t.Errorf("mac addresses were not anonymized")
+	}
+	ip, ok := p.Network.(*packet.IPv4)
+	if !ok || !ip.Truncated || ip.Source != a.Addr(src) || ip.Destination != a.Addr(dst) {
+		t.Fatalf("unexpected network layer %+v", p.Network)
+	}
+	if packettest.OnesSum(ip.Contents[:ip.IHL*4], 0) != 0xFFFF {
+		t.Errorf("invalid ip header checksum")
+	}
+	udp, ok := p.Transport.(*packet.UDP)
+	if !ok || !udp.Truncated || udp.DestinationPort != 53 || len(udp.Payload) != 0 {
+		t.Fatalf("unexpected transport layer %+v", p.Transport)
+	}
+
+	// The UDP checksum remains valid for the original payload
+	b := append(append([]byte{}, udp.Contents...), "example.com"...)
+	if packettest.OnesSum(b, packettest.PseudoSum(ip.Source, ip.Destination, 17, len(b))) != 0xFFFF {
+		t.Errorf("invalid udp checksum")
+	}
+}
+
+func TestMACsecTruncate(t *testing.T) {
+	sa := packet.MACsecSA{SCI: 0x0066778899AA_0001, Key: bytes.Repeat([]byte{0x42}, 16)}
+	var d packet.Decoder
+	if err := d.AddMACsecSA(sa); err != nil {
+		t.Fatal(err)
+	}
+
+	// Encrypt the IP datagram of a UDP frame, with the SCI in the SecTAG
+	udp := packettest.UDPFrame(netip.MustParseAddrPort("192.168.1.10:5353"), netip.MustParseAddrPort("192.168.1.1:53"), []byte("example.com"))
+	frame := append(append([]byte{}, udp[:12]...), 0x88, 0xE5, 0x2C, 0, 0, 0, 0, 1)
+	frame = append(frame, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(frame[20:28], sa.SCI)
+	nonce := append(append([]byte{}, frame[20:28]...), frame[16:20]...)
+	block, _ := aes.NewCipher(sa.Key)
+	aead, _ := cipher.NewGCM(block)
+	frame = aead.Seal(frame, nonce, udp[12:], append([]byte{}, frame...))
+
+	p, err := d.Decode(frame)
+	if err != nil || p.MACsec == nil || !p.MACsec.Decrypted || p.Transport == nil {
+		t.Fatalf("frame was not decrypted, %v", err)
+	}
+	a := anonymize.New([]byte("secret"))
+	a.TruncatePayload = true
+	data := a.Packet(&p)
+	// The decrypted headers are not part of the frame
+	if len(data) != 14+14 {
+		t.Fatalf("got %d bytes, want the frame to end after the sectag", len(data))
+	}
+	if bytes.Equal(data[20:26], frame[20:26]) || !bytes.Equal(data[20:26], data[6:12]) {
+		t.Errorf("sci %x was not anonymized like the source address", data[20:28])
+	}
+}
@@ -0,0 +1,313 @@
+// Package ber decodes the Basic Encoding Rules of ASN.1 (X.690), as used by
+// SNMP, LDAP and other protocols.
+//
+// Unlike encoding/asn1, which unmarshals DER into Go values, the decoder
+// yields the tag-length-value elements of the encoding, and leaves their
+// interpretation to the caller. This suits protocols which use implicit
+// context-specific and application tags, and elements which are only decoded
+// when present. Elements reference the decoded data.
+package ber
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Class is the class of a tag.
+type Class uint8
+
+const (
+	ClassUniversal   Class = 0
+	ClassApplication Class = 1
+	ClassContext     Class = 2
+	ClassPrivate     Class = 3
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassUniversal:
+		return "universal"
+	case ClassApplication:
+		return "application"
+	case ClassContext:
+		return "context"
+	}
+	return "private"
+}
+
+// Tags of the universal class.
+const (
+	TagBoolean         = 1
+	TagInteger         = 2
+	TagBitString       = 3
+	TagOctetString     = 4
+	TagNull            = 5
+	TagOID             = 6
+	TagEnumerated      = 10
+	TagUTF8String      = 12
+	TagSequence        = 16
+	TagSet             = 17
+	TagPrintableString = 19
+	TagIA5String       = 22
+	TagUTCTime         = 23
+	TagGeneralizedTime = 24
+)
+
+// Element is a decoded tag-length-value element.
+type Element struct {
+	Class       Class
+	Constructed bool
+	Tag         int
+	// Value contains the contents octets. For constructed elements of
+	// indefinite length, it excludes the end-of-contents octets.
+	Value []byte
+	// Raw contains the entire encoding of the element.
+	Raw []byte
+}
+
+// Is reports whether the element has the class and tag.
+func (e Element) Is(c Class, tag int) bool {
+	return e.Class == c && e.Tag == tag
+}
+
+func (e Element) String() string {
+	return fmt.Sprintf("[%v %d] %d bytes", e.Class, e.Tag, len(e.Value))
+}
+
+// maxDepth limits the nesting of indefinite length elements.
+const maxDepth = 64
+
+// Parse decodes the element at the start of data, and returns the data which
+// follows it.
+func Parse(data []byte) (Element, []byte, error) {
+	return parse(data, 0)
+}
+
+func parse(data []byte, depth int) (Element, []byte, error) {
+	var e Element
+	if len(data) < 2 {
+		return e, nil, errors.New("ber element too small")
+	}
+	e.Class = Class(data[0] >> 6)
+	e.Constructed = data[0]&0x20 != 0
+	e.Tag = int(data[0] & 0x1F)
+	n := 1
+	if e.Tag == 0x1F {
+		// High tag number form, in base 128
+		e.Tag = 0
+		for {
+			if n >= len(data) {
+				return e, nil, errors.New("ber tag exceeds data")
+			}
+			if e.Tag > 1<<24 {
+				return e, nil, errors.New("ber tag too large")
+			}
+			e.Tag = e.Tag<<7 | int(data[n]&0x7F)
+			n++
+			if data[n-1]&0x80 == 0 {
+				break
+			}
+		}
+	}
+	if n >= len(data) {
+		return e, nil, errors.New("ber length exceeds data")
+	}
+	l := int(data[n])
+	n++
+	switch {
+	case l == 0x80:
+		// Indefinite length, terminated by end-of-contents octets
+		if !e.Constructed {
+			return e, nil, errors.New("ber primitive element has indefinite length")
+		}
+		if depth >= maxDepth {
+			return e, nil, errors.New("ber elements nested too deep")
+		}
+		end := n
+		for {
+			if end+2 <= len(data) && data[end] == 0 && data[end+1] == 0 {
+				break
+			}
+			_, rest, err := parse(data[end:], depth+1)
+			if err != nil {
+				return e, nil, err
+			}
+			end = len(data) - len(rest)
+		}
+		e.Value = data[n:end]
+		e.Raw = data[:end+2]
+		return e, data[end+2:], nil
+	case l > 0x80:
+		size := l & 0x7F
+		if size > 4 || n+size > len(data) {
+			return e, nil, errors.New("invalid ber length")
+		}
+		l = 0
+		for _, b := range data[n : n+size] {
+			l = l<<8 | int(b)
+		}
+		n += size
+	}
+	if l < 0 || l > len(data)-n {
+		return e, nil, errors.New("ber length exceeds data")
+	}
+	e.Value = data[n : n+l]
+	e.Raw = data[:n+l]
+	return e, data[n+l:], nil
+}
+
+// ParseAll decodes a sequence of elements which fills data.
+func ParseAll(data []byte) ([]Element, error) {
+	var elems []Element
+	for len(data) > 0 {
+		e, rest, err := Parse(data)
+		if err != nil {
+			return nil, err
+		}
+		elems = append(elems, e)
+		data = rest
+	}
+	return elems, nil
+}
+
+// Elements decodes the elements of a constructed element.
+func (e Element) Elements() ([]Element, error) {
+	if !e.Constructed {
+		return nil, fmt.Errorf("ber element %d is not constructed", e.Tag)
+	}
+	return ParseAll(e.Value)
+}
+
+// Int64 decodes the value as a two's complement integer, as used by INTEGER
+// and ENUMERATED elements.
+func (e Element) Int64() (int64, error) {
+	if len(e.Value) == 0 || len(e.Value) > 8 {
+		return 0, fmt.Errorf("invalid ber integer length %d", len(e.Value))
+	}
+	v := int64(int8(e.Value[0]))
+	for _, b := range e.Value[1:] {
+		v = v<<8 | int64(b)
+	}
+	return v, nil
+}
+
+// Uint64 decodes the value as an unsigned integer, which may have a leading
+// zero octet, as used by the counters of SNMP.
+func (e Element) Uint64() (uint64, error) {
+	b := e.Value
+	if len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("invalid ber unsigned integer length %d", len(e.Value))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// Bool decodes a BOOLEAN value.
+func (e Element) Bool() (bool, error) {
+	if len(e.Value) != 1 {
+		return false, fmt.Errorf("invalid ber boolean length %d", len(e.Value))
+	}
+	return e.Value[0] != 0, nil
+}
+
+// OID decodes an OBJECT IDENTIFIER value.
+func (e Element) OID() (OID, error) {
+	if len(e.Value) == 0 {
+		return nil, errors.New("empty ber object identifier")
+	}
+	var oid OID
+	var v uint64
+	for i, b := range e.Value {
+		if v == 0 && b == 0x80 {
+			return nil, errors.New("ber object identifier is not minimally encoded")
+		}
+		v = v<<7 | uint64(b&0x7F)
+		if v > 1<<32-1 {
+			return nil, errors.New("ber object identifier component too large")
+		}
+		if b&0x80 != 0 {
+			if i == len(e.Value)-1 {
+				return nil, errors.New("truncated ber object identifier")
+			}
+			continue
+		}
+		if len(oid) == 0 {
+			// The first two components are encoded as 40*x+y
+			switch {
+			case v < 40:
+				oid = append(oid, 0, uint32(v))
+			case v < 80:
+				oid = append(oid, 1, uint32(v-40))
+			default:
+				oid = append(oid, 2, uint32(v-80))
+			}
+		} else {
+			oid = append(oid, uint32(v))
+		}
+		v = 0
+	}
+	return oid, nil
+}
+
+// OID is an object identifier.
+type OID []uint32
+
+// ParseOID parses an object identifier in dotted notation, such as
+// "1.3.6.1.2.1.1.3.0". A leading dot is allowed.
+func ParseOID(s string) (OID, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "."), ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid object identifier %q", s)
+	}
+	oid := make(OID, len(parts))
+	for i, p := range parts {
+		v, err := strconv.ParseUint(p, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object identifier %q", s)
+		}
+		oid[i] = uint32(v)
+	}
+	return oid, nil
+}
+
+// Equal reports whether the identifiers are equal.
+func (o OID) Equal(other OID) bool {
+	if len(o) != len(other) {
+		return false
+	}
+	for i := range o {
+		if o[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPrefix reports whether the identifier is within the subtree of prefix.
+func (o OID) HasPrefix(prefix OID) bool {
+	return len(o) >= len(prefix) && o[:len(prefix)].Equal(prefix)
+}
+
+func (o OID) String() string {
+	var sb strings.Builder
+	for i, v := range o {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(strconv.FormatUint(uint64(v), 10))
+	}
+	return sb.String()
+}
+
+// MarshalText encodes the identifier in dotted notation.
+func (o OID) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
@@ -0,0 +1,107 @@
+package ber_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sebnyberg/net/ber"
+)
+
+func TestParse(t *testing.T) {
+	// SEQUENCE { INTEGER -129, OCTET STRING of 200 bytes, [APPLICATION 33] NULL }
+	str := bytes.Repeat([]byte{'x'}, 200)
+	data := []byte{0x30, 0x81, 0xD2, 0x02, 0x02, 0xFF, 0x7F, 0x04, 0x81, 0xC8}
+	data = append(data, str...)
+	data = append(data, 0x5F, 0x21, 0x00, 0xAA)
+
+	e, rest, err := ber.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Is(ber.ClassUniversal, ber.TagSequence) || !e.Constructed || len(rest) != 1 || rest[0] != 0xAA {
+		t.Fatalf("unexpected element %v, rest %x", e, rest)
+	}
+	elems, err := e.Elements()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(elems) != 3 {
+		t.Fatalf("got %d elements, want 3", len(elems))
+	}
+	if v, err := elems[0].Int64(); err != nil || v != -129 {
+		t.Errorf("integer is %v, %v", v, err)
+	}
+	if !bytes.Equal(elems[1].Value, str) {
+		t.Errorf("unexpected octet string %v", elems[1])
+	}
+	if !elems[2].Is(ber.ClassApplication, 33) || len(elems[2].Value) != 0 || len(elems[2].Raw) != 3 {
+		t.Errorf("unexpected high tag element %v", elems[2])
+	}
+
+	for _, bad := range [][]byte{
+		{0x04, 0x05, 'a'},
+		{0x04, 0x85, 0, 0, 0, 0, 1},
+		{0x1F, 0x81},
+		{0x04, 0x80, 0, 0},
+	} {
+		if _, _, err := ber.Parse(bad); err == nil {
+			t.Errorf("parse of %x succeeded", bad)
+		}
+	}
+}
+
+func TestIndefiniteLength(t *testing.T) {
+	data := []byte{0x30, 0x80, 0x02, 0x01, 0x05, 0x30, 0x80, 0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01}
+	e, rest, err := ber.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 1 || len(e.Raw) != len(data)-1 {
+		t.Fatalf("unexpected element %v, rest %x", e, rest)
+	}
+	elems, err := e.Elements()
+	if err != nil || len(elems) != 2 {
+		t.Fatalf("unexpected elements %v, %v", elems, err)
+	}
+	if inner, err := elems[1].Elements(); err != nil || len(inner) != 1 || inner[0].Tag != ber.TagNull {
+		t.Errorf("unexpected inner elements %v, %v", inner, err)
+	}
+}
+
+func TestOID(t *testing.T) {
+	e, _, err := ber.Parse([]byte{0x06, 0x09, 0x2B, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x15, 0x14})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oid, err := e.OID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ber.ParseOID(".1.3.6.1.4.1.311.21.20")
+	if !oid.Equal(want) || oid.String() != "1.3.6.1.4.1.311.21.20" {
+		t.Errorf("got %v, want %v", oid, want)
+	}
+	if prefix, _ := ber.ParseOID("1.3.6.1.4.1"); !oid.HasPrefix(prefix) {
+		t.Errorf("%v is not within %v", oid, prefix)
+	}
+
+	for _, bad := range [][]byte{{0x06, 0x00}, {0x06, 0x02, 0x2B, 0x86}, {0x06, 0x03, 0x2B, 0x80, 0x01}} {
+		e, _, _ := ber.Parse(bad)
+		if _, err := e.OID(); err == nil {
+			t.Errorf("oid %x decoded without error", bad)
+		}
+	}
+	if _, err := ber.ParseOID("1.x.3"); err == nil {
+		t.Error("invalid oid parsed without error")
+	}
+}
+
+func TestUint64(t *testing.T) {
+	e, _, _ := ber.Parse([]byte{0x46, 0x09, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	if v, err := e.Uint64(); err != nil || v != 1<<64-1 {
+		t.Errorf("got %v, %v", v, err)
+	}
+	if _, err := e.Int64(); err == nil {
+		t.Error("9 byte integer decoded without error")
+	}
+}
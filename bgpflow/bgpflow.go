@@ -0,0 +1,164 @@
+// Package bgpflow extracts BGP messages from reassembled TCP streams.
+//
+// An Analyzer is used as the stream factory of a reassembly.Assembler, so
+// messages which span several segments, and segments which carry several
+// messages, are decoded. The OPEN messages of both peers are tracked, so that
+// the AS numbers of UPDATE messages are decoded with the negotiated size.
+// After data is lost, the stream is resynchronized on the next message
+// marker.
+package bgpflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/reassembly"
+)
+
+// Message is a BGP message of a stream.
+type Message struct {
+	// Flow is the flow from the sender of the message to its peer.
+	Flow packet.Flow
+	// Time contains the capture time of the last byte of the message.
+	Time time.Time
+	BGP  *packet.BGP
+	// Err is set if the message has a valid header but its body could not be
+	// decoded.
+	Err error
+}
+
+// Analyzer decodes the BGP messages of TCP streams and passes them to a
+// handler. Streams are not filtered by port, so the assembler should only be
+// given BGP connections. It is not safe for concurrent use, which matches the
+// assembler.
+type Analyzer struct {
+	handler func(*Message)
+	conns   map[packet.Flow]*conn
+}
+
+// NewAnalyzer returns an analyzer which calls h for each message.
+func NewAnalyzer(h func(*Message)) *Analyzer {
+	return &Analyzer{
+		handler: h,
+		conns:   make(map[packet.Flow]*conn),
+	}
+}
+
+// Interface guard
+var _ reassembly.StreamFactory = new(Analyzer)
+
+// New implements reassembly.StreamFactory.
+func (a *Analyzer) New(flow packet.Flow) reassembly.Stream {
+	key := flow.Canonical()
+	c := a.conns[key]
+	if c == nil {
+		c = &conn{a: a, key: key, opens: make(map[packet.Flow]*packet.BGPOpen)}
+		a.conns[key] = c
+	}
+	c.open++
+	return &halfStream{c: c, flow: flow}
+}
+
+// conn contains the OPEN messages of a connection.
+type conn struct {
+	a     *Analyzer
+	key   packet.Flow
+	open  int
+	opens map[packet.Flow]*packet.BGPOpen
+}
+
+// fourOctetAS reports whether both peers advertised the four-octet AS
+// capability, and whether both OPEN messages were seen.
+func (c *conn) fourOctetAS() (fourOctet, known bool) {
+	if len(c.opens) < 2 {
+		return false, false
+	}
+	for _, o := range c.opens {
+		if _, ok := o.Capability(packet.BGPCapabilityCodeFourOctetAS); !ok {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+func (c *conn) end() {
+	c.open--
+	if c.open == 0 {
+		delete(c.a.conns, c.key)
+	}
+}
+
+// marker starts every BGP message.
+var marker = bytes.Repeat([]byte{0xFF}, 16)
+
+// halfStream decodes the messages of one direction of a connection.
+type halfStream struct {
+	c    *conn
+	flow packet.Flow
+	buf  []byte
+	// sync is set when data was lost, and the stream must be resynchronized
+	// on a marker.
+	sync bool
+}
+
+func (h *halfStream) Data(data []byte, ts time.Time) {
+	h.buf = append(h.buf, data...)
+	for h.next(ts) {
+	}
+	// Keep the buffer from growing while resynchronizing
+	if h.sync && len(h.buf) > len(marker) {
+		h.buf = append(h.buf[:0], h.buf[len(h.buf)-len(marker):]...)
+	}
+}
+
+// next decodes the message at the start of the buffer. It reports whether a
+// message was consumed.
+func (h *halfStream) next(ts time.Time) bool {
+	if h.sync {
+		i := bytes.Index(h.buf, marker)
+		if i < 0 {
+			return false
+		}
+		h.buf = h.buf[i:]
+		h.sync = false
+	}
+	if len(h.buf) < packet.BGPHeaderLen {
+		return false
+	}
+	n := int(binary.BigEndian.Uint16(h.buf[16:18]))
+	if !bytes.Equal(h.buf[:16], marker) || n < packet.BGPHeaderLen {
+		// Skip the bad marker
+		h.buf = h.buf[1:]
+		h.sync = true
+		return true
+	}
+	if len(h.buf) < n {
+		return false
+	}
+	data := make([]byte, n)
+	copy(data, h.buf)
+	h.buf = h.buf[n:]
+
+	m := &Message{Flow: h.flow, Time: ts, BGP: new(packet.BGP)}
+	if fourOctet, known := h.c.fourOctetAS(); known {
+		m.Err = m.BGP.UnmarshalAS(data, fourOctet)
+	} else {
+		m.Err = m.BGP.Unmarshal(data)
+	}
+	if m.BGP.Open != nil {
+		h.c.opens[h.flow] = m.BGP.Open
+	}
+	h.c.a.handler(m)
+	return true
+}
+
+func (h *halfStream) Gap(n int) {
+	h.buf = h.buf[:0]
+	h.sync = true
+}
+
+func (h *halfStream) End() {
+	h.c.end()
+}
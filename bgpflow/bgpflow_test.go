@@ -0,0 +1,131 @@
+package bgpflow_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/bgpflow"
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/reassembly"
+)
+
+type endpoint struct {
+	ip   [4]byte
+	port uint16
+	seq  uint32
+}
+
+// segment returns a decoded TCP segment from a to b, and advances the sequence
+// number of a.
+func segment(t *testing.T, a, b *endpoint, flags packet.TCPFlags, payload []byte) *packet.Packet {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], a.port)
+	binary.BigEndian.PutUint16(tcp[2:4], b.port)
+	binary.BigEndian.PutUint32(tcp[4:8], a.seq)
+	tcp[12] = 5 << 4
+	tcp[13] = uint8(flags)
+	tcp = append(tcp, payload...)
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(tcp)))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], a.ip[:])
+	copy(ip[16:20], b.ip[:])
+
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip...)
+	p, err := packet.Decode(append(frame, tcp...))
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	a.seq += uint32(len(payload))
+	if flags&(packet.TCPFlagSYN|packet.TCPFlagFIN) != 0 {
+		a.seq++
+	}
+	return &p
+}
+
+func message(typ packet.BGPMessageType, body []byte) []byte {
+	msg := make([]byte, 19, 19+len(body))
+	for i := 0; i < 16; i++ {
+		msg[i] = 0xFF
+	}
+	binary.BigEndian.PutUint16(msg[16:18], uint16(19+len(body)))
+	msg[18] = byte(typ)
+	return append(msg, body...)
+}
+
+func open(as uint16, fourOctetAS bool) []byte {
+	body := []byte{4, byte(as >> 8), byte(as), 0, 90, 192, 0, 2, 1, 0}
+	if fourOctetAS {
+		body[9] = 8
+		body = append(body, 2, 6, 65, 4, 0, 0, byte(as>>8), byte(as))
+	}
+	return message(packet.BGPMessageTypeOpen, body)
+}
+
+func TestAnalyzer(t *testing.T) {
+	var msgs []*bgpflow.Message
+	asm := reassembly.NewAssembler(bgpflow.NewAnalyzer(func(m *bgpflow.Message) {
+		msgs = append(msgs, m)
+	}))
+	ts := time.Unix(1700000000, 0)
+	assemble := func(p *packet.Packet) {
+		ts = ts.Add(time.Millisecond)
+		asm.Assemble(p, ts)
+	}
+
+	a := &endpoint{ip: [4]byte{10, 0, 0, 1}, port: 50000, seq: 1000}
+	b := &endpoint{ip: [4]byte{10, 0, 0, 2}, port: 179, seq: 5000}
+	assemble(segment(t, a, b, packet.TCPFlagSYN, nil))
+	assemble(segment(t, b, a, packet.TCPFlagSYN|packet.TCPFlagACK, nil))
+
+	// Only one peer supports 4-octet AS numbers, so the UPDATE uses 2 octets.
+	// Its AS path also parses with 4-octet AS numbers, as two segments.
+	assemble(segment(t, a, b, packet.TCPFlagACK, append(open(65001, true),
+		message(packet.BGPMessageTypeKeepalive, nil)...)))
+	assemble(segment(t, b, a, packet.TCPFlagACK, open(65002, false)))
+	update := message(packet.BGPMessageTypeUpdate, []byte{
+		0, 0, // withdrawn routes length
+		0, 19, // path attributes length
+		0x40, 1, 1, 0,
+		0x40, 2, 12, 2, 1, 0, 1, 2, 1, 2, 1, 2, 1, 0xFD, 0xE9,
+		24, 198, 51, 100,
+	})
+	assemble(segment(t, a, b, packet.TCPFlagACK, update[:10]))
+	assemble(segment(t, a, b, packet.TCPFlagACK, update[10:]))
+
+	// Lost data is skipped up to the next marker
+	b.seq += 7
+	assemble(segment(t, b, a, packet.TCPFlagACK, append([]byte{1, 2, 3},
+		message(packet.BGPMessageTypeNotification, []byte{6, 2})...)))
+	assemble(segment(t, a, b, packet.TCPFlagFIN|packet.TCPFlagACK, nil))
+	assemble(segment(t, b, a, packet.TCPFlagFIN|packet.TCPFlagACK, nil))
+	asm.FlushAll()
+
+	want := []packet.BGPMessageType{
+		packet.BGPMessageTypeOpen,
+		packet.BGPMessageTypeKeepalive,
+		packet.BGPMessageTypeOpen,
+		packet.BGPMessageTypeUpdate,
+		packet.BGPMessageTypeNotification,
+	}
+	if len(msgs) != len(want) {
+		t.Fatalf("expected %d messages, got %d", len(want), len(msgs))
+	}
+	for i, m := range msgs {
+		if m.Err != nil || m.BGP.MessageType != want[i] {
+			t.Errorf("message %d: unexpected %v (%v)", i, m.BGP.MessageType, m.Err)
+		}
+	}
+	u := msgs[3].BGP.Update
+	if msgs[3].Flow.Src.Port() != 50000 || u.FourOctetAS || len(u.ASPath) != 3 ||
+		u.ASPath[2].ASNs[0] != 65001 {
+		t.Errorf("unexpected update %+v", u)
+	}
+	if msgs[4].BGP.Notification.Code != packet.BGPErrorCodeCease {
+		t.Errorf("unexpected notification %+v", msgs[4].BGP.Notification)
+	}
+}
@@ -0,0 +1,100 @@
+// Package bpf contains classic BPF instructions and a virtual machine which
+// runs them in pure Go.
+//
+// Programs are laid out exactly like Linux' struct sock_filter, which means
+// that they can be attached to a socket as-is, while still being testable
+// without a kernel.
+package bpf
+
+// Instruction is a single classic BPF instruction.
+type Instruction struct {
+	Op uint16
+	Jt uint8
+	Jf uint8
+	K  uint32
+}
+
+// Instruction classes.
+const (
+	LD   = 0x00
+	LDX  = 0x01
+	ST   = 0x02
+	STX  = 0x03
+	ALU  = 0x04
+	JMP  = 0x05
+	RET  = 0x06
+	MISC = 0x07
+)
+
+// Load sizes.
+const (
+	W = 0x00
+	H = 0x08
+	B = 0x10
+)
+
+// Load modes.
+const (
+	IMM = 0x00
+	ABS = 0x20
+	IND = 0x40
+	MEM = 0x60
+	LEN = 0x80
+	MSH = 0xa0
+)
+
+// ALU operations.
+const (
+	ADD = 0x00
+	SUB = 0x10
+	MUL = 0x20
+	DIV = 0x30
+	OR  = 0x40
+	AND = 0x50
+	LSH = 0x60
+	RSH = 0x70
+	NEG = 0x80
+	MOD = 0x90
+	XOR = 0xa0
+)
+
+// Jump operations.
+const (
+	JA   = 0x00
+	JEQ  = 0x10
+	JGT  = 0x20
+	JGE  = 0x30
+	JSET = 0x40
+)
+
+// Operand sources.
+const (
+	K = 0x00
+	X = 0x08
+	A = 0x10
+)
+
+// Miscellaneous operations.
+const (
+	TAX = 0x00
+	TXA = 0x80
+)
+
+// MemWords is the number of words in the scratch memory store.
+const MemWords = 16
+
+// Stmt returns a non-jump instruction, like the BPF_STMT macro.
+func Stmt(op uint16, k uint32) Instruction {
+	return Instruction{Op: op, K: k}
+}
+
+// Jump returns a jump instruction, like the BPF_JUMP macro.
+func Jump(op uint16, k uint32, jt, jf uint8) Instruction {
+	return Instruction{Op: op, Jt: jt, Jf: jf, K: k}
+}
+
+func (ins Instruction) class() uint16 { return ins.Op & 0x07 }
+func (ins Instruction) size() uint16  { return ins.Op & 0x18 }
+func (ins Instruction) mode() uint16  { return ins.Op & 0xe0 }
+func (ins Instruction) aluOp() uint16 { return ins.Op & 0xf0 }
+func (ins Instruction) src() uint16   { return ins.Op & 0x08 }
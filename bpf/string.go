@@ -0,0 +1,82 @@
+package bpf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String returns the instruction in the assembler syntax used by tcpdump -d.
+// Jump offsets are printed relative to the instruction.
+func (ins Instruction) String() string {
+	sizes := map[uint16]string{W: "", H: "h", B: "b"}
+	switch ins.class() {
+	case LD, LDX:
+		op := "ld" + sizes[ins.size()]
+		if ins.class() == LDX {
+			op = "ldx" + sizes[ins.size()]
+		}
+		switch ins.mode() {
+		case IMM:
+			return fmt.Sprintf("%s #%#x", op, ins.K)
+		case ABS:
+			return fmt.Sprintf("%s [%d]", op, ins.K)
+		case IND:
+			return fmt.Sprintf("%s [x + %d]", op, ins.K)
+		case MEM:
+			return fmt.Sprintf("%s M[%d]", op, ins.K)
+		case LEN:
+			return fmt.Sprintf("%s #len", op)
+		case MSH:
+			return fmt.Sprintf("ldxb 4*([%d]&0xf)", ins.K)
+		}
+	case ST:
+		return fmt.Sprintf("st M[%d]", ins.K)
+	case STX:
+		return fmt.Sprintf("stx M[%d]", ins.K)
+	case ALU:
+		names := map[uint16]string{
+			ADD: "add", SUB: "sub", MUL: "mul", DIV: "div", MOD: "mod",
+			OR: "or", AND: "and", XOR: "xor", LSH: "lsh", RSH: "rsh",
+		}
+		if ins.aluOp() == NEG {
+			return "neg"
+		}
+		if ins.src() == X {
+			return fmt.Sprintf("%s x", names[ins.aluOp()])
+		}
+		return fmt.Sprintf("%s #%#x", names[ins.aluOp()], ins.K)
+	case JMP:
+		names := map[uint16]string{JEQ: "jeq", JGT: "jgt", JGE: "jge", JSET: "jset"}
+		if ins.aluOp() == JA {
+			return fmt.Sprintf("ja +%d", ins.K)
+		}
+		operand := fmt.Sprintf("#%#x", ins.K)
+		if ins.src() == X {
+			operand = "x"
+		}
+		return fmt.Sprintf("%s %s jt +%d jf +%d", names[ins.aluOp()], operand, ins.Jt, ins.Jf)
+	case RET:
+		switch ins.Op & 0x18 {
+		case A:
+			return "ret a"
+		case X:
+			return "ret x"
+		}
+		return fmt.Sprintf("ret #%d", ins.K)
+	case MISC:
+		if ins.Op&0xf8 == TXA {
+			return "txa"
+		}
+		return "tax"
+	}
+	return fmt.Sprintf("unknown op %#x", ins.Op)
+}
+
+// Disassemble returns a listing of the program, one instruction per line.
+func Disassemble(prog []Instruction) string {
+	var sb strings.Builder
+	for i, ins := range prog {
+		fmt.Fprintf(&sb, "(%03d) %s\n", i, ins)
+	}
+	return sb.String()
+}
@@ -0,0 +1,244 @@
+package bpf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// MaxInstructions is the maximum program length accepted by Linux.
+const MaxInstructions = 4096
+
+// Validate checks that the program would be accepted by the kernel. That is,
+// all opcodes are known, jumps stay within the program, scratch memory
+// accesses are within bounds, and the program ends with a return.
+func Validate(prog []Instruction) error {
+	if len(prog) == 0 {
+		return errors.New("bpf: empty program")
+	}
+	if len(prog) > MaxInstructions {
+		return fmt.Errorf("bpf: program too long, %d instructions", len(prog))
+	}
+	for i, ins := range prog {
+		switch ins.class() {
+		case LD, LDX:
+			switch ins.mode() {
+			case IMM, ABS, IND, LEN:
+			case MEM:
+				if ins.K >= MemWords {
+					return fmt.Errorf("bpf: invalid scratch index %d at %d", ins.K, i)
+				}
+			case MSH:
+				if ins.class() != LDX || ins.size() != B {
+					return fmt.Errorf("bpf: invalid msh load at %d", i)
+				}
+			default:
+				return fmt.Errorf("bpf: invalid load mode at %d", i)
+			}
+		case ST, STX:
+			if ins.K >= MemWords {
+				return fmt.Errorf("bpf: invalid scratch index %d at %d", ins.K, i)
+			}
+		case ALU:
+			switch ins.aluOp() {
+			case ADD, SUB, MUL, OR, AND, LSH, RSH, NEG, XOR:
+			case DIV, MOD:
+				if ins.src() == K && ins.K == 0 {
+					return fmt.Errorf("bpf: division by zero at %d", i)
+				}
+			default:
+				return fmt.Errorf("bpf: invalid alu op at %d", i)
+			}
+		case JMP:
+			var off int
+			switch ins.aluOp() {
+			case JA:
+				off = int(ins.K)
+			case JEQ, JGT, JGE, JSET:
+				off = int(ins.Jt)
+				if ins.Jf > ins.Jt {
+					off = int(ins.Jf)
+				}
+			default:
+				return fmt.Errorf("bpf: invalid jump op at %d", i)
+			}
+			if off < 0 || i+1+off >= len(prog) {
+				return fmt.Errorf("bpf: jump out of bounds at %d", i)
+			}
+		case RET, MISC:
+		}
+	}
+	if prog[len(prog)-1].class() != RET {
+		return errors.New("bpf: program does not end with a return")
+	}
+	return nil
+}
+
+// VM runs a validated program against packets.
+type VM struct {
+	prog []Instruction
+}
+
+// NewVM validates the program and returns a VM for running it.
+func NewVM(prog []Instruction) (*VM, error) {
+	if err := Validate(prog); err != nil {
+		return nil, err
+	}
+	return &VM{prog: prog}, nil
+}
+
+// Run runs the program against the packet and returns the number of bytes to
+// keep. Zero means that the packet was rejected. Like the kernel, loads
+// outside of the packet reject it.
+func (vm *VM) Run(pkt []byte) int {
+	var (
+		a, x uint32
+		mem  [MemWords]uint32
+	)
+	for pc := 0; pc < len(vm.prog); pc++ {
+		ins := vm.prog[pc]
+		switch ins.class() {
+		case LD:
+			v, ok := load(ins, pkt, x, &mem)
+			if !ok {
+				return 0
+			}
+			a = v
+		case LDX:
+			if ins.mode() == MSH {
+				if int(ins.K) >= len(pkt) {
+					return 0
+				}
+				x = uint32(pkt[ins.K]&0x0f) * 4
+				continue
+			}
+			v, ok := load(ins, pkt, x, &mem)
+			if !ok {
+				return 0
+			}
+			x = v
+		case ST:
+			mem[ins.K] = a
+		case STX:
+			mem[ins.K] = x
+		case ALU:
+			operand := ins.K
+			if ins.src() == X {
+				operand = x
+			}
+			switch ins.aluOp() {
+			case ADD:
+				a += operand
+			case SUB:
+				a -= operand
+			case MUL:
+				a *= operand
+			case DIV:
+				if operand == 0 {
+					return 0
+				}
+				a /= operand
+			case MOD:
+				if operand == 0 {
+					return 0
+				}
+				a %= operand
+			case OR:
+				a |= operand
+			case AND:
+				a &= operand
+			case XOR:
+				a ^= operand
+			case LSH:
+				a <<= operand
+			case RSH:
+				a >>= operand
+			case NEG:
+				a = -a
+			}
+		case JMP:
+			operand := ins.K
+			if ins.src() == X {
+				operand = x
+			}
+			var cond bool
+			switch ins.aluOp() {
+			case JA:
+				pc += int(ins.K)
+				continue
+			case JEQ:
+				cond = a == operand
+			case JGT:
+				cond = a > operand
+			case JGE:
+				cond = a >= operand
+			case JSET:
+				cond = a&operand != 0
+			}
+			if cond {
+				pc += int(ins.Jt)
+			} else {
+				pc += int(ins.Jf)
+			}
+		case RET:
+			var v uint32
+			switch ins.Op & 0x18 {
+			case A:
+				v = a
+			case X:
+				v = x
+			default:
+				v = ins.K
+			}
+			if int64(v) > int64(len(pkt)) {
+				return len(pkt)
+			}
+			return int(v)
+		case MISC:
+			if ins.Op&0xf8 == TXA {
+				a = x
+			} else {
+				x = a
+			}
+		}
+	}
+	return 0
+}
+
+// load returns the value referenced by a LD or LDX instruction.
+func load(ins Instruction, pkt []byte, x uint32, mem *[MemWords]uint32) (uint32, bool) {
+	switch ins.mode() {
+	case IMM:
+		return ins.K, true
+	case LEN:
+		return uint32(len(pkt)), true
+	case MEM:
+		return mem[ins.K], true
+	}
+	off := uint64(ins.K)
+	if ins.mode() == IND {
+		off = uint64(uint32(ins.K + x))
+	}
+	var n uint64
+	switch ins.size() {
+	case W:
+		n = 4
+	case H:
+		n = 2
+	case B:
+		n = 1
+	default:
+		return 0, false
+	}
+	if off+n > uint64(len(pkt)) {
+		return 0, false
+	}
+	switch n {
+	case 4:
+		return binary.BigEndian.Uint32(pkt[off:]), true
+	case 2:
+		return uint32(binary.BigEndian.Uint16(pkt[off:])), true
+	default:
+		return uint32(pkt[off]), true
+	}
+}
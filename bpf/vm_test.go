@@ -0,0 +1,169 @@
+package bpf_test
+
+import (
+	"testing"
+
+	"github.com/sebnyberg/net/bpf"
+)
+
+func TestValidate(t *testing.T) {
+	ret := bpf.Stmt(bpf.RET|bpf.K, 0)
+	for _, tc := range []struct {
+		name string
+		prog []bpf.Instruction
+	}{
+		{"empty", nil},
+		{"too long", make([]bpf.Instruction, bpf.MaxInstructions+1)},
+		{"no return", []bpf.Instruction{bpf.Stmt(bpf.LD|bpf.IMM, 1)}},
+		{"jump past end", []bpf.Instruction{bpf.Stmt(bpf.JMP|bpf.JA, 1), ret}},
+		{"jump to end", []bpf.Instruction{bpf.Stmt(bpf.JMP|bpf.JA, 0)}},
+		{"true branch out of bounds", []bpf.Instruction{bpf.Jump(bpf.JMP|bpf.JEQ|bpf.K, 0, 1, 0), ret}},
+		{"false branch out of bounds", []bpf.Instruction{bpf.Jump(bpf.JMP|bpf.JGT|bpf.K, 0, 0, 2), ret, ret}},
+		{"huge jump", []bpf.Instruction{bpf.Stmt(bpf.JMP|bpf.JA, 0xFFFFFFFF), ret}},
+		{"invalid jump op", []bpf.Instruction{bpf.Jump(bpf.JMP|0x50, 0, 0, 0), ret}},
+		{"division by zero", []bpf.Instruction{bpf.Stmt(bpf.ALU|bpf.DIV|bpf.K, 0), ret}},
+		{"modulo by zero", []bpf.Instruction{bpf.Stmt(bpf.ALU|bpf.MOD|bpf.K, 0), ret}},
+		{"invalid alu op", []bpf.Instruction{bpf.Stmt(bpf.ALU|0xb0, 0), ret}},
+		{"load scratch out of bounds", []bpf.Instruction{bpf.Stmt(bpf.LD|bpf.MEM, bpf.MemWords), ret}},
+		{"store scratch out of bounds", []bpf.Instruction{bpf.Stmt(bpf.ST, bpf.MemWords), ret}},
+		{"msh into a", []bpf.Instruction{bpf.Stmt(bpf.LD|bpf.B|bpf.MSH, 14), ret}},
+	} {
+		if err := bpf.Validate(tc.prog); err == nil {
+			t.Errorf("%s: expected error", tc.name)
+		}
+		if _, err := bpf.NewVM(tc.prog); err == nil {
+			t.Errorf("%s: expected NewVM to fail", tc.name)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	pkt := make([]byte, 32)
+	copy(pkt, []byte{0x45, 0x00, 0x00, 0x10, 0xAA, 0xBB, 0xCC, 0xDD})
+	for _, tc := range []struct {
+		name string
+		prog []bpf.Instruction
+		want int
+	}{
+		{
+			"accept",
+			[]bpf.Instruction{bpf.Stmt(bpf.RET|bpf.K, 0xFFFF)},
+			len(pkt),
+		},
+		{
+			"snap",
+			[]bpf.Instruction{bpf.Stmt(bpf.RET|bpf.K, 4)},
+			4,
+		},
+		{
+			"load word",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.W|bpf.ABS, 4),
+				bpf.Jump(bpf.JMP|bpf.JEQ|bpf.K, 0xAABBCCDD, 0, 1),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+				bpf.Stmt(bpf.RET|bpf.K, 0),
+			},
+			1,
+		},
+		{
+			"load past end",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.W|bpf.ABS, uint32(len(pkt))-3),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+			},
+			0,
+		},
+		{
+			"load byte past end",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.B|bpf.ABS, uint32(len(pkt))),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+			},
+			0,
+		},
+		{
+			// The offset wraps around to 3
+			"indirect offset overflow",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LDX|bpf.IMM, 4),
+				bpf.Stmt(bpf.LD|bpf.B|bpf.IND, 0xFFFFFFFF),
+				bpf.Stmt(bpf.RET|bpf.A, 0),
+			},
+			0x10,
+		},
+		{
+			"indirect load past end",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LDX|bpf.IMM, uint32(len(pkt))-1),
+				bpf.Stmt(bpf.LD|bpf.H|bpf.IND, 0),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+			},
+			0,
+		},
+		{
+			"msh past end",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LDX|bpf.B|bpf.MSH, uint32(len(pkt))),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+			},
+			0,
+		},
+		{
+			"msh",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LDX|bpf.B|bpf.MSH, 0),
+				bpf.Stmt(bpf.MISC|bpf.TXA, 0),
+				bpf.Stmt(bpf.RET|bpf.A, 0),
+			},
+			20,
+		},
+		{
+			"division by zero x",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.IMM, 10),
+				bpf.Stmt(bpf.ALU|bpf.DIV|bpf.X, 0),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+			},
+			0,
+		},
+		{
+			"modulo by zero x",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.IMM, 10),
+				bpf.Stmt(bpf.ALU|bpf.MOD|bpf.X, 0),
+				bpf.Stmt(bpf.RET|bpf.K, 1),
+			},
+			0,
+		},
+		{
+			"division",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.IMM, 10),
+				bpf.Stmt(bpf.LDX|bpf.IMM, 3),
+				bpf.Stmt(bpf.ALU|bpf.DIV|bpf.X, 0),
+				bpf.Stmt(bpf.RET|bpf.A, 0),
+			},
+			3,
+		},
+		{
+			"scratch memory",
+			[]bpf.Instruction{
+				bpf.Stmt(bpf.LD|bpf.LEN, 0),
+				bpf.Stmt(bpf.ST, bpf.MemWords-1),
+				bpf.Stmt(bpf.LD|bpf.IMM, 0),
+				bpf.Stmt(bpf.LDX|bpf.MEM, bpf.MemWords-1),
+				bpf.Stmt(bpf.RET|bpf.X, 0),
+			},
+			len(pkt),
+		},
+	} {
+		vm, err := bpf.NewVM(tc.prog)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if got := vm.Run(pkt); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
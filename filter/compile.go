@@ -0,0 +1,412 @@
+package filter
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/sebnyberg/net/bpf"
+	"github.com/sebnyberg/net/packet"
+)
+
+// SnapLen is the number of bytes kept from packets accepted by compiled
+// programs.
+const SnapLen = 262144
+
+// Compile compiles the filter to a classic BPF program for Ethernet frames.
+//
+// The program starts by storing the offset of the EtherType following any
+// VLAN tags in scratch memory, after which every primitive addresses the
+// network layer relative to that offset.
+func (f *Filter) Compile() ([]bpf.Instruction, error) {
+	if f.root == nil {
+		return []bpf.Instruction{bpf.Stmt(bpf.RET|bpf.K, SnapLen)}, nil
+	}
+	var c compiler
+	accept, reject := c.newLabel(), c.newLabel()
+	c.prologue()
+	if err := c.compile(f.root, accept, reject); err != nil {
+		return nil, err
+	}
+	c.place(accept)
+	c.stmt(bpf.RET|bpf.K, SnapLen)
+	c.place(reject)
+	c.stmt(bpf.RET|bpf.K, 0)
+	prog, err := c.assemble()
+	if err != nil {
+		return nil, err
+	}
+	if err := bpf.Validate(prog); err != nil {
+		return nil, err
+	}
+	return prog, nil
+}
+
+// Offsets of fields relative to the EtherType stored in scratch memory.
+const (
+	memEtherType = 0
+
+	offNet = 2
+
+	offIPv4Proto   = offNet + 9
+	offIPv4Frag    = offNet + 6
+	offIPv4Src     = offNet + 12
+	offIPv4Dst     = offNet + 16
+	offIPv6Next    = offNet + 6
+	offIPv6Src     = offNet + 8
+	offIPv6Dst     = offNet + 24
+	offIPv6Payload = offNet + 40
+	offARPSrc      = offNet + 14
+	offARPDst      = offNet + 24
+)
+
+// label is a jump target. Labels are placed at most once, and only after all
+// jumps to them have been emitted, which keeps jumps pointing forward.
+type label int
+
+// next is the label of the following instruction.
+const next label = -1
+
+type asmInsn struct {
+	ins    bpf.Instruction
+	jt, jf label
+	jump   bool
+}
+
+type compiler struct {
+	insns  []asmInsn
+	labels []int
+}
+
+func (c *compiler) newLabel() label {
+	c.labels = append(c.labels, -1)
+	return label(len(c.labels) - 1)
+}
+
+func (c *compiler) place(l label) {
+	c.labels[l] = len(c.insns)
+}
+
+func (c *compiler) stmt(op uint16, k uint32) {
+	c.insns = append(c.insns, asmInsn{ins: bpf.Stmt(op, k)})
+}
+
+func (c *compiler) jump(op uint16, k uint32, jt, jf label) {
+	c.insns = append(c.insns, asmInsn{ins: bpf.Jump(op, k, 0, 0), jt: jt, jf: jf, jump: true})
+}
+
+func (c *compiler) ja(l label) {
+	c.insns = append(c.insns, asmInsn{ins: bpf.Stmt(bpf.JMP|bpf.JA, 0), jt: l, jump: true})
+}
+
+// assemble resolves labels into relative jump offsets.
+func (c *compiler) assemble() ([]bpf.Instruction, error) {
+	prog := make([]bpf.Instruction, len(c.insns))
+	offset := func(pc int, l label) (int, error) {
+		if l == next {
+			return 0, nil
+		}
+		if c.labels[l] < 0 {
+			return 0, errors.New("filter: jump to unplaced label")
+		}
+		return c.labels[l] - pc - 1, nil
+	}
+	for pc, in := range c.insns {
+		prog[pc] = in.ins
+		if !in.jump {
+			continue
+		}
+		jt, err := offset(pc, in.jt)
+		if err != nil {
+			return nil, err
+		}
+		if in.ins.Op == bpf.JMP|bpf.JA {
+			prog[pc].K = uint32(jt)
+			continue
+		}
+		jf, err := offset(pc, in.jf)
+		if err != nil {
+			return nil, err
+		}
+		if jt > 255 || jf > 255 {
+			return nil, errors.New("filter: expression too large, conditional jump out of range")
+		}
+		prog[pc].Jt, prog[pc].Jf = uint8(jt), uint8(jf)
+	}
+	return prog, nil
+}
+
+// prologue stores the offset of the innermost EtherType in scratch memory,
+// skipping up to two VLAN tags.
+func (c *compiler) prologue() {
+	done := c.newLabel()
+	for _, off := range []uint32{12, 16} {
+		tagged := c.newLabel()
+		c.stmt(bpf.LDX|bpf.W|bpf.IMM, off)
+		c.stmt(bpf.LD|bpf.H|bpf.ABS, off)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeDot1Q), tagged, next)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeQinQ), tagged, done)
+		c.place(tagged)
+	}
+	c.stmt(bpf.LDX|bpf.W|bpf.IMM, 20)
+	c.place(done)
+	c.stmt(bpf.STX, memEtherType)
+}
+
+// compile emits code which jumps to t if the node matches, and to f otherwise.
+func (c *compiler) compile(n node, t, f label) error {
+	switch n := n.(type) {
+	case *andNode:
+		mid := c.newLabel()
+		if err := c.compile(n.left, mid, f); err != nil {
+			return err
+		}
+		c.place(mid)
+		return c.compile(n.right, t, f)
+	case *orNode:
+		mid := c.newLabel()
+		if err := c.compile(n.left, t, mid); err != nil {
+			return err
+		}
+		c.place(mid)
+		return c.compile(n.right, t, f)
+	case *notNode:
+		return c.compile(n.node, f, t)
+	case *protoNode:
+		c.protoNode(n, t, f)
+	case *etherHostNode:
+		c.dir(n.dir, t, f,
+			func(pass, fail label) { c.cmpBytes(6, n.addr, pass, fail) },
+			func(pass, fail label) { c.cmpBytes(0, n.addr, pass, fail) },
+		)
+	case *netNode:
+		c.netNode(n, t, f)
+	case *portNode:
+		c.portNode(n, t, f)
+	case *etherProtoNode:
+		c.loadEtherType()
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(n.etherType), t, f)
+	case *ipProtoNode:
+		c.requireEtherType(packet.EthernetTypeIPv4, f)
+		c.stmt(bpf.LD|bpf.B|bpf.IND, offIPv4Proto)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(n.proto), t, f)
+	case *lenNode:
+		c.stmt(bpf.LD|bpf.W|bpf.LEN, 0)
+		if n.greater {
+			c.jump(bpf.JMP|bpf.JGE|bpf.K, uint32(n.n), t, f)
+		} else {
+			c.jump(bpf.JMP|bpf.JGT|bpf.K, uint32(n.n), f, t)
+		}
+	case *vlanNode:
+		tagged := c.newLabel()
+		c.stmt(bpf.LD|bpf.H|bpf.ABS, 12)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeDot1Q), tagged, next)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeQinQ), tagged, f)
+		c.place(tagged)
+		if n.id < 0 {
+			c.ja(t)
+			return nil
+		}
+		c.stmt(bpf.LD|bpf.H|bpf.ABS, 14)
+		c.stmt(bpf.ALU|bpf.AND|bpf.K, 0x0FFF)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(n.id), t, f)
+	default:
+		return fmt.Errorf("filter: cannot compile %T", n)
+	}
+	return nil
+}
+
+// loadEtherType loads the offset of the EtherType into X, and the EtherType
+// itself into A.
+func (c *compiler) loadEtherType() {
+	c.stmt(bpf.LDX|bpf.W|bpf.MEM, memEtherType)
+	c.stmt(bpf.LD|bpf.H|bpf.IND, 0)
+}
+
+// requireEtherType jumps to f unless the frame carries the EtherType.
+func (c *compiler) requireEtherType(et packet.EtherType, f label) {
+	c.loadEtherType()
+	c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(et), next, f)
+}
+
+// dir combines tests of the source and destination according to d.
+func (c *compiler) dir(d dir, t, f label, src, dst func(pass, fail label)) {
+	switch d {
+	case dirSrc:
+		src(t, f)
+	case dirDst:
+		dst(t, f)
+	case dirSrcAndDst:
+		mid := c.newLabel()
+		src(mid, f)
+		c.place(mid)
+		dst(t, f)
+	default:
+		mid := c.newLabel()
+		src(t, mid)
+		c.place(mid)
+		dst(t, f)
+	}
+}
+
+// cmpBytes compares the packet at the absolute offset with b, which must have
+// an even length.
+func (c *compiler) cmpBytes(off uint32, b []byte, pass, fail label) {
+	for len(b) > 0 {
+		size, v, n := uint16(bpf.W), uint32(0), 4
+		if len(b) >= 4 {
+			v = binary.BigEndian.Uint32(b)
+		} else {
+			size, v, n = bpf.H, uint32(binary.BigEndian.Uint16(b)), 2
+		}
+		c.stmt(bpf.LD|size|bpf.ABS, off)
+		b, off = b[n:], off+uint32(n)
+		if len(b) == 0 {
+			c.jump(bpf.JMP|bpf.JEQ|bpf.K, v, pass, fail)
+		} else {
+			c.jump(bpf.JMP|bpf.JEQ|bpf.K, v, next, fail)
+		}
+	}
+}
+
+// cmpPrefix checks whether the address at the offset from X is within the
+// prefix.
+func (c *compiler) cmpPrefix(off uint32, prefix netip.Prefix, pass, fail label) {
+	addr := prefix.Addr().AsSlice()
+	bits := prefix.Bits()
+	if bits == 0 {
+		c.ja(pass)
+		return
+	}
+	for i := 0; i < len(addr); i += 4 {
+		word := binary.BigEndian.Uint32(addr[i:])
+		c.stmt(bpf.LD|bpf.W|bpf.IND, off+uint32(i))
+		n := bits - i*8
+		if n < 32 {
+			mask := ^uint32(0) << (32 - n)
+			c.stmt(bpf.ALU|bpf.AND|bpf.K, mask)
+			word &= mask
+		}
+		if n <= 32 {
+			c.jump(bpf.JMP|bpf.JEQ|bpf.K, word, pass, fail)
+			return
+		}
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, word, next, fail)
+	}
+}
+
+func (c *compiler) protoNode(n *protoNode, t, f label) {
+	switch n.proto {
+	case protoEther:
+		c.ja(t)
+	case protoARP:
+		c.loadEtherType()
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeARP), t, f)
+	case protoIP:
+		c.loadEtherType()
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeIPv4), t, f)
+	case protoIP6:
+		c.loadEtherType()
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeIPv6), t, f)
+	case protoTCP, protoUDP:
+		c.transport(n.proto, t, f, func(_ uint32) { c.ja(t) })
+	}
+}
+
+// transport checks that the packet carries the transport protocol (TCP, UDP
+// or either for protoNone), and calls emit with X and the offset from X of the
+// transport header. Like package packet, only the first fragment of IPv4
+// packets is considered to carry the transport header.
+func (c *compiler) transport(pr proto, t, f label, emit func(off uint32)) {
+	ip6 := c.newLabel()
+	c.loadEtherType()
+	c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeIPv4), next, ip6)
+
+	c.stmt(bpf.LD|bpf.B|bpf.IND, offIPv4Proto)
+	c.protoCheck(pr, f)
+	c.stmt(bpf.LD|bpf.H|bpf.IND, offIPv4Frag)
+	c.jump(bpf.JMP|bpf.JSET|bpf.K, 0x1FFF, f, next)
+	// X += 4 * IHL
+	c.stmt(bpf.LD|bpf.B|bpf.IND, offNet)
+	c.stmt(bpf.ALU|bpf.AND|bpf.K, 0x0F)
+	c.stmt(bpf.ALU|bpf.LSH|bpf.K, 2)
+	c.stmt(bpf.ALU|bpf.ADD|bpf.X, 0)
+	c.stmt(bpf.MISC|bpf.TAX, 0)
+	emit(offNet)
+
+	c.place(ip6)
+	c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.EthernetTypeIPv6), next, f)
+	c.stmt(bpf.LD|bpf.B|bpf.IND, offIPv6Next)
+	c.protoCheck(pr, f)
+	emit(offIPv6Payload)
+}
+
+// protoCheck jumps to f unless A holds the transport protocol.
+func (c *compiler) protoCheck(pr proto, f label) {
+	switch pr {
+	case protoTCP:
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.IPProtocolTCP), next, f)
+	case protoUDP:
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.IPProtocolUDP), next, f)
+	default:
+		ok := c.newLabel()
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.IPProtocolTCP), ok, next)
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(packet.IPProtocolUDP), ok, f)
+		c.place(ok)
+	}
+}
+
+func (c *compiler) portNode(n *portNode, t, f label) {
+	c.transport(n.proto, t, f, func(off uint32) {
+		port := func(off uint32) func(pass, fail label) {
+			return func(pass, fail label) {
+				c.stmt(bpf.LD|bpf.H|bpf.IND, off)
+				if n.lo == n.hi {
+					c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(n.lo), pass, fail)
+					return
+				}
+				c.jump(bpf.JMP|bpf.JGE|bpf.K, uint32(n.lo), next, fail)
+				c.jump(bpf.JMP|bpf.JGT|bpf.K, uint32(n.hi), fail, pass)
+			}
+		}
+		c.dir(n.dir, t, f, port(off), port(off+2))
+	})
+}
+
+func (c *compiler) netNode(n *netNode, t, f label) {
+	prefix := n.prefix
+	type family struct {
+		etherType packet.EtherType
+		src, dst  uint32
+	}
+	var families []family
+	switch {
+	case prefix.Addr().Is4() && (n.proto == protoNone || n.proto == protoIP):
+		families = append(families, family{packet.EthernetTypeIPv4, offIPv4Src, offIPv4Dst})
+	case prefix.Addr().Is6() && (n.proto == protoNone || n.proto == protoIP6):
+		families = append(families, family{packet.EthernetTypeIPv6, offIPv6Src, offIPv6Dst})
+	}
+	if prefix.Addr().Is4() && (n.proto == protoNone || n.proto == protoARP) {
+		families = append(families, family{packet.EthernetTypeARP, offARPSrc, offARPDst})
+	}
+	if len(families) == 0 {
+		c.ja(f)
+		return
+	}
+	c.loadEtherType()
+	for i, fam := range families {
+		other := f
+		if i < len(families)-1 {
+			other = c.newLabel()
+		}
+		c.jump(bpf.JMP|bpf.JEQ|bpf.K, uint32(fam.etherType), next, other)
+		c.dir(n.dir, t, f,
+			func(pass, fail label) { c.cmpPrefix(fam.src, prefix, pass, fail) },
+			func(pass, fail label) { c.cmpPrefix(fam.dst, prefix, pass, fail) },
+		)
+		if other != f {
+			c.place(other)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package filter_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/sebnyberg/net/bpf"
+	"github.com/sebnyberg/net/filter"
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/pcap"
+)
+
+// readFixture reads the packets from testdata/filter.pcap, which contains:
+//
+//	0: 10.1.2.3:50000 > 1.1.1.1:443, TCP SYN
+//	1: 192.168.0.2:40000 > 8.8.8.8:53, UDP
+//	2: ARP request, who-has 192.168.0.1 tell 192.168.0.2
+//	3: vlan 100, 10.0.0.5:1234 > 10.0.0.6:80, TCP
+//	4: [2001:db8::1]:5555 > [2001:db8::2]:443, TCP
+//	5: vlan 200, vlan 10, 172.16.0.1:5000 > 172.16.0.2:5001, UDP
+//	6: 192.168.0.2 > 8.8.8.8, UDP, non-first fragment
+func readFixture(t *testing.T) [][]byte {
+	f, err := os.Open("testdata/filter.pcap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := pcap.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.LinkType() != pcap.LinkTypeEthernet {
+		t.Fatalf("unexpected link type %v", r.LinkType())
+	}
+	var pkts [][]byte
+	for {
+		data, _, err := r.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return pkts
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkts = append(pkts, data)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	pkts := readFixture(t)
+	for _, tc := range []struct {
+		expr string
+		want []int
+	}{
+		{"", []int{0, 1, 2, 3, 4, 5, 6}},
+		{"ether", []int{0, 1, 2, 3, 4, 5, 6}},
+		{"arp", []int{2}},
+		{"ip", []int{0, 1, 3, 5, 6}},
+		{"ip6", []int{4}},
+		{"tcp", []int{0, 3, 4}},
+		{"udp", []int{1, 5}},
+		{"ip proto udp", []int{1, 5, 6}},
+		{"vlan", []int{3, 5}},
+		{"vlan 100", []int{3}},
+		{"vlan and udp", []int{5}},
+		{"port 443", []int{0, 4}},
+		{"tcp dst port 443", []int{0, 4}},
+		{"udp src port 5000", []int{5}},
+		{"portrange 5000-5555", []int{4, 5}},
+		{"src and dst portrange 5000-5555", []int{5}},
+		{"host 8.8.8.8", []int{1, 6}},
+		{"host 192.168.0.1", []int{2}},
+		{"net 192.168.0.0/16", []int{1, 2, 6}},
+		{"src net 10.0.0.0/8", []int{0, 3}},
+		{"dst net 10.0.0.0/30", []int{}},
+		{"dst net 10.0.0.4/30", []int{3}},
+		{"host 2001:db8::2", []int{4}},
+		{"ip6 src net 2001:db8::/32", []int{4}},
+		{"ip6 src host 2001:db8::2", []int{}},
+		{"ether src 02:00:00:00:00:0a", []int{0, 1, 2, 3, 4, 5, 6}},
+		{"ether dst 02:00:00:00:00:0a", []int{}},
+		{"ether proto arp", []int{2}},
+		{"arp or (ip src 10.0.0.0/8 and tcp dst port 443)", []int{0, 2}},
+		{"not ip and not arp", []int{4}},
+		{"greater 70", []int{4}},
+		{"less 60", []int{0, 1, 2, 3, 5, 6}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := filter.Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("parse failed, %v", err)
+			}
+			prog, err := f.Compile()
+			if err != nil {
+				t.Fatalf("compile failed, %v", err)
+			}
+			vm, err := bpf.NewVM(prog)
+			if err != nil {
+				t.Fatalf("invalid program, %v\n%s", err, bpf.Disassemble(prog))
+			}
+			got := []int{}
+			for i, data := range pkts {
+				if vm.Run(data) > 0 {
+					got = append(got, i)
+				}
+				// Decodable packets must yield the same result in Go
+				if p, err := packet.Decode(data); err == nil {
+					if f.Match(&p) != (vm.Run(data) > 0) {
+						t.Errorf("packet %d: Match and compiled program disagree", i)
+					}
+				}
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("matched %v, want %v\n%s", got, tc.want, bpf.Disassemble(prog))
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+// Package filter implements a small, tcpdump-like filter language which is
+// evaluated against decoded packets.
+//
+// An expression consists of primitives combined with and, or, not and
+// parentheses, e.g.
+//
+//	arp or (ip src 10.0.0.0/8 and tcp dst port 443)
+//
+// A primitive is an optional protocol qualifier (ether, arp, ip, ip6, tcp,
+// udp), an optional direction (src, dst, src or dst, src and dst), an optional
+// type (host, net, port, portrange, proto) and an id. A protocol qualifier may
+// also stand on its own, in which case it matches packets containing that
+// layer. Like tcpdump, an id without qualifiers reuses the qualifiers of the
+// preceding primitive, so "host 10.0.0.1 or 10.0.0.2" is short for
+// "host 10.0.0.1 or host 10.0.0.2".
+//
+// The primitives "less n" and "greater n" match on the length of the packet,
+// and "vlan [id]" matches 802.1Q tagged frames, optionally with the given ID
+// in the outermost tag. Other primitives look through VLAN tags, so "ip"
+// matches tagged and untagged IPv4 alike.
+//
+// Filters are evaluated against layers decoded by package packet, or compiled
+// to classic BPF for in-kernel filtering. Compiled programs handle up to two
+// VLAN tags.
+package filter
@@ -0,0 +1,49 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// Filter is a parsed filter expression.
+type Filter struct {
+	expr string
+	root node
+}
+
+// Parse parses a filter expression. An empty expression matches all packets.
+func Parse(expr string) (*Filter, error) {
+	f := &Filter{expr: expr}
+	if strings.TrimSpace(expr) == "" {
+		return f, nil
+	}
+	root, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	f.root = root
+	return f, nil
+}
+
+// MustParse is like Parse but panics if the expression cannot be parsed.
+func MustParse(expr string) *Filter {
+	f, err := Parse(expr)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Match reports whether the packet matches the filter.
+func (f *Filter) Match(p *packet.Packet) bool {
+	if f.root == nil {
+		return true
+	}
+	return f.root.match(p)
+}
+
+// String returns the source expression of the filter.
+func (f *Filter) String() string {
+	return f.expr
+}
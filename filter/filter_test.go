@@ -0,0 +1,140 @@
+package filter_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/sebnyberg/net/filter"
+	"github.com/sebnyberg/net/packet"
+)
+
+var (
+	macA = []byte{0x02, 0, 0, 0, 0, 0x0a}
+	macB = []byte{0x02, 0, 0, 0, 0, 0x0b}
+)
+
+func ethernet(etherType uint16, payload []byte) []byte {
+	b := append([]byte{}, macB...)
+	b = append(b, macA...)
+	b = binary.BigEndian.AppendUint16(b, etherType)
+	return append(b, payload...)
+}
+
+func ipv4(proto byte, src, dst [4]byte, payload []byte) []byte {
+	b := make([]byte, 20)
+	b[0] = 0x45
+	binary.BigEndian.PutUint16(b[2:4], uint16(20+len(payload)))
+	b[8] = 64
+	b[9] = proto
+	copy(b[12:16], src[:])
+	copy(b[16:20], dst[:])
+	return append(b, payload...)
+}
+
+func tcp(src, dst uint16) []byte {
+	b := make([]byte, 20)
+	binary.BigEndian.PutUint16(b[0:2], src)
+	binary.BigEndian.PutUint16(b[2:4], dst)
+	b[12] = 5 << 4
+	return b
+}
+
+func udp(src, dst uint16) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint16(b[0:2], src)
+	binary.BigEndian.PutUint16(b[2:4], dst)
+	binary.BigEndian.PutUint16(b[4:6], 8)
+	return b
+}
+
+func arp(src, dst [4]byte) []byte {
+	b := []byte{0, 1, 8, 0, 6, 4, 0, 1}
+	b = append(b, macA...)
+	b = append(b, src[:]...)
+	b = append(b, make([]byte, 6)...)
+	return append(b, dst[:]...)
+}
+
+func decode(t *testing.T, b []byte) *packet.Packet {
+	p, err := packet.Decode(b)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	return &p
+}
+
+func TestFilter(t *testing.T) {
+	var (
+		https = ethernet(0x0800, ipv4(6, [4]byte{10, 1, 2, 3}, [4]byte{1, 1, 1, 1}, tcp(50000, 443)))
+		dns   = ethernet(0x0800, ipv4(17, [4]byte{192, 168, 0, 2}, [4]byte{8, 8, 8, 8}, udp(40000, 53)))
+		req   = ethernet(0x0806, arp([4]byte{192, 168, 0, 2}, [4]byte{192, 168, 0, 1}))
+	)
+
+	for _, tc := range []struct {
+		expr string
+		pkt  []byte
+		want bool
+	}{
+		{"", https, true},
+		{"arp or (ip src 10.0.0.0/8 and tcp dst port 443)", https, true},
+		{"arp or (ip src 10.0.0.0/8 and tcp dst port 443)", dns, false},
+		{"arp or (ip src 10.0.0.0/8 and tcp dst port 443)", req, true},
+		{"tcp", https, true},
+		{"udp", https, false},
+		{"ip and not arp", https, true},
+		{"ip6", https, false},
+		{"ether", req, true},
+		{"host 1.1.1.1", https, true},
+		{"src host 1.1.1.1", https, false},
+		{"dst 1.1.1.1", https, true},
+		{"host 9.9.9.9 or 8.8.8.8", dns, true},
+		{"src or dst net 192.168.0.0/16", dns, true},
+		{"src and dst net 192.168.0.0/16", dns, false},
+		{"src and dst net 192.168.0.0/16", req, true},
+		{"arp host 192.168.0.1", req, true},
+		{"ip host 192.168.0.1", req, false},
+		{"port 53", dns, true},
+		{"tcp port 53", dns, false},
+		{"udp src port 53", dns, false},
+		{"portrange 440-450", https, true},
+		{"dst portrange 440-450 && ! udp", https, true},
+		{"ip proto tcp", https, true},
+		{"proto 17", dns, true},
+		{"ether proto arp", req, true},
+		{"ether src 02:00:00:00:00:0a", https, true},
+		{"ether dst 02:00:00:00:00:0a", https, false},
+		{"ether host 02:00:00:00:00:0b", https, true},
+		{"less 60", https, true},
+		{"greater 60", https, false},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			f, err := filter.Parse(tc.expr)
+			if err != nil {
+				t.Fatalf("parse failed, %v", err)
+			}
+			if got := f.Match(decode(t, tc.pkt)); got != tc.want {
+				t.Errorf("match = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, expr := range []string{
+		"(",
+		"tcp and",
+		"10.0.0.1",
+		"tcp host 10.0.0.1",
+		"ip port 80",
+		"port 70000",
+		"portrange 90-80",
+		"ether net 10.0.0.0/8",
+		"src proto tcp",
+		"host 1.1.1.1 )",
+		"port 80 & port 81",
+	} {
+		if _, err := filter.Parse(expr); err == nil {
+			t.Errorf("expected error parsing %q", expr)
+		}
+	}
+}
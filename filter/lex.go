@@ -0,0 +1,79 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind uint8
+
+const (
+	tokenEOF tokenKind = iota
+	tokenLParen
+	tokenRParen
+	tokenNot
+	tokenAnd
+	tokenOr
+	tokenWord
+)
+
+type token struct {
+	kind tokenKind
+	val  string
+	pos  int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// lex splits an expression into tokens. Keywords such as "and" are returned as
+// words, except for the boolean operators which get their own kind.
+func lex(expr string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			toks = append(toks, token{tokenLParen, "(", i})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokenRParen, ")", i})
+			i++
+		case c == '!':
+			toks = append(toks, token{tokenNot, "!", i})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"):
+			toks = append(toks, token{tokenAnd, "&&", i})
+			i += 2
+		case strings.HasPrefix(expr[i:], "||"):
+			toks = append(toks, token{tokenOr, "||", i})
+			i += 2
+		case c == '&' || c == '|':
+			return nil, fmt.Errorf("filter: unexpected %q at offset %d", c, i)
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\n\r()!&|", rune(expr[i])) {
+				i++
+			}
+			word := expr[start:i]
+			tok := token{tokenWord, word, start}
+			switch word {
+			case "and":
+				tok.kind = tokenAnd
+			case "or":
+				tok.kind = tokenOr
+			case "not":
+				tok.kind = tokenNot
+			}
+			toks = append(toks, tok)
+		}
+	}
+	toks = append(toks, token{tokenEOF, "", len(expr)})
+	return toks, nil
+}
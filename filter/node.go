@@ -0,0 +1,243 @@
+package filter
+
+import (
+	"bytes"
+	"net"
+	"net/netip"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// proto is a protocol qualifier, such as "ip" in "ip host 10.0.0.1".
+type proto uint8
+
+const (
+	protoNone proto = iota
+	protoEther
+	protoARP
+	protoIP
+	protoIP6
+	protoTCP
+	protoUDP
+)
+
+var protoNames = map[string]proto{
+	"ether": protoEther,
+	"arp":   protoARP,
+	"ip":    protoIP,
+	"ip6":   protoIP6,
+	"tcp":   protoTCP,
+	"udp":   protoUDP,
+}
+
+// dir is a direction qualifier, such as "src" in "src port 53".
+type dir uint8
+
+const (
+	dirSrcOrDst dir = iota
+	dirSrc
+	dirDst
+	dirSrcAndDst
+)
+
+// match reports whether the direction matches given whether the source and
+// destination matched on their own.
+func (d dir) match(src, dst bool) bool {
+	switch d {
+	case dirSrc:
+		return src
+	case dirDst:
+		return dst
+	case dirSrcAndDst:
+		return src && dst
+	default:
+		return src || dst
+	}
+}
+
+// node is a node in a parsed filter expression.
+type node interface {
+	match(p *packet.Packet) bool
+}
+
+type andNode struct {
+	left, right node
+}
+
+func (n *andNode) match(p *packet.Packet) bool {
+	return n.left.match(p) && n.right.match(p)
+}
+
+type orNode struct {
+	left, right node
+}
+
+func (n *orNode) match(p *packet.Packet) bool {
+	return n.left.match(p) || n.right.match(p)
+}
+
+type notNode struct {
+	node node
+}
+
+func (n *notNode) match(p *packet.Packet) bool {
+	return !n.node.match(p)
+}
+
+// protoNode matches packets containing a certain layer, e.g. "arp".
+type protoNode struct {
+	proto proto
+}
+
+func (n *protoNode) match(p *packet.Packet) bool {
+	switch n.proto {
+	case protoEther:
+		return p.Link != nil
+	case protoARP:
+		_, ok := p.Network.(*packet.ARP)
+		return ok
+	case protoIP:
+		_, ok := p.Network.(*packet.IPv4)
+		return ok
+	case protoIP6:
+		_, ok := p.Network.(*packet.IPv6)
+		return ok
+	case protoTCP:
+		_, ok := p.Transport.(*packet.TCP)
+		return ok
+	case protoUDP:
+		_, ok := p.Transport.(*packet.UDP)
+		return ok
+	}
+	return false
+}
+
+// etherHostNode matches the hardware addresses of the link layer.
+type etherHostNode struct {
+	dir  dir
+	addr net.HardwareAddr
+}
+
+func (n *etherHostNode) match(p *packet.Packet) bool {
+	if p.Link == nil {
+		return false
+	}
+	return n.dir.match(
+		bytes.Equal(p.Link.Source, n.addr),
+		bytes.Equal(p.Link.Destination, n.addr),
+	)
+}
+
+// netNode matches network-layer addresses against a prefix. Hosts are
+// represented as single-address prefixes.
+type netNode struct {
+	proto  proto
+	dir    dir
+	prefix netip.Prefix
+}
+
+func (n *netNode) match(p *packet.Packet) bool {
+	src, dst, ok := networkAddrs(p, n.proto)
+	if !ok {
+		return false
+	}
+	return n.dir.match(n.prefix.Contains(src), n.prefix.Contains(dst))
+}
+
+// networkAddrs returns the source and destination addresses of the network
+// layer of p, provided that it matches the protocol qualifier.
+func networkAddrs(p *packet.Packet, pr proto) (src, dst netip.Addr, ok bool) {
+	switch l := p.Network.(type) {
+	case *packet.IPv4:
+		if pr == protoNone || pr == protoIP {
+			return l.Source, l.Destination, true
+		}
+	case *packet.IPv6:
+		if pr == protoNone || pr == protoIP6 {
+			return l.Source, l.Destination, true
+		}
+	case *packet.ARP:
+		if pr == protoNone || pr == protoARP {
+			return l.SourceIP, l.DestIP, true
+		}
+	}
+	return src, dst, false
+}
+
+// portNode matches transport-layer ports within the range [lo, hi].
+type portNode struct {
+	proto  proto
+	dir    dir
+	lo, hi uint16
+}
+
+func (n *portNode) match(p *packet.Packet) bool {
+	var src, dst uint16
+	switch l := p.Transport.(type) {
+	case *packet.TCP:
+		if n.proto == protoUDP {
+			return false
+		}
+		src, dst = l.SourcePort, l.DestinationPort
+	case *packet.UDP:
+		if n.proto == protoTCP {
+			return false
+		}
+		src, dst = l.SourcePort, l.DestinationPort
+	default:
+		return false
+	}
+	return n.dir.match(
+		src >= n.lo && src <= n.hi,
+		dst >= n.lo && dst <= n.hi,
+	)
+}
+
+// etherProtoNode matches the EtherType of the link layer.
+type etherProtoNode struct {
+	etherType packet.EtherType
+}
+
+func (n *etherProtoNode) match(p *packet.Packet) bool {
+	return p.Link != nil && p.Link.EthernetType == n.etherType
+}
+
+// ipProtoNode matches the protocol field of the IPv4 header.
+type ipProtoNode struct {
+	proto packet.IPProtocol
+}
+
+func (n *ipProtoNode) match(p *packet.Packet) bool {
+	ip, ok := p.Network.(*packet.IPv4)
+	return ok && ip.Proto == n.proto
+}
+
+// lenNode matches the total length of the packet. If greater is set, the
+// length must be at least n, otherwise it must be at most n.
+type lenNode struct {
+	greater bool
+	n       int
+}
+
+func (n *lenNode) match(p *packet.Packet) bool {
+	if p.Link == nil {
+		return false
+	}
+	if n.greater {
+		return len(p.Link.Contents) >= n.n
+	}
+	return len(p.Link.Contents) <= n.n
+}
+
+// vlanNode matches frames with an 802.1Q tag. If id is non-negative, the
+// outermost tag must have that VLAN ID.
+type vlanNode struct {
+	id int
+}
+
+func (n *vlanNode) match(p *packet.Packet) bool {
+	if p.Link == nil || len(p.Link.VLANs) == 0 {
+		return false
+	}
+	return n.id < 0 || int(p.Link.VLANs[0].ID) == n.id
+}
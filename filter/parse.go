@@ -0,0 +1,365 @@
+package filter
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// typ is a type qualifier, such as "port" in "tcp port 80".
+type typ uint8
+
+const (
+	typNone typ = iota
+	typHost
+	typNet
+	typPort
+	typPortRange
+	typProto
+)
+
+var typNames = map[string]typ{
+	"host":      typHost,
+	"net":       typNet,
+	"port":      typPort,
+	"portrange": typPortRange,
+	"proto":     typProto,
+}
+
+// qualifiers contains the qualifiers of a primitive.
+type qualifiers struct {
+	proto proto
+	dir   dir
+	typ   typ
+}
+
+type parser struct {
+	toks []token
+	pos  int
+
+	// last holds the qualifiers of the previous primitive, which are reused
+	// when a primitive consists of a lone id.
+	last *qualifiers
+}
+
+func parse(expr string) (node, error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{toks: toks}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %v", tok)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) peekN(n int) token {
+	if p.pos+n >= len(p.toks) {
+		return p.toks[len(p.toks)-1]
+	}
+	return p.toks[p.pos+n]
+}
+
+func (p *parser) next() token {
+	tok := p.toks[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return fmt.Errorf("filter: %s at offset %d", fmt.Sprintf(format, args...), tok.pos)
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokenAnd {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.peek().kind == tokenNot {
+		p.next()
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{n}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokenLParen:
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != tokenRParen {
+			return nil, p.errorf(tok, "expected \")\", got %v", tok)
+		}
+		return n, nil
+	case tokenWord:
+		return p.parsePrimitive()
+	default:
+		return nil, p.errorf(tok, "unexpected %v", tok)
+	}
+}
+
+// isKeyword reports whether the word is a qualifier or a reserved word.
+func isKeyword(word string) bool {
+	_, isProto := protoNames[word]
+	_, isTyp := typNames[word]
+	switch word {
+	case "src", "dst", "less", "greater", "vlan":
+		return true
+	}
+	return isProto || isTyp
+}
+
+func (p *parser) parsePrimitive() (node, error) {
+	switch tok := p.peek(); tok.val {
+	case "less", "greater":
+		p.next()
+		id := p.next()
+		n, err := strconv.ParseUint(id.val, 0, 16)
+		if id.kind != tokenWord || err != nil {
+			return nil, p.errorf(id, "expected length, got %v", id)
+		}
+		return &lenNode{greater: tok.val == "greater", n: int(n)}, nil
+	case "vlan":
+		p.next()
+		n := &vlanNode{id: -1}
+		if id := p.peek(); id.kind == tokenWord && !isKeyword(id.val) {
+			p.next()
+			vid, err := strconv.ParseUint(id.val, 0, 12)
+			if err != nil {
+				return nil, p.errorf(id, "invalid vlan id %v", id)
+			}
+			n.id = int(vid)
+		}
+		return n, nil
+	}
+
+	var q qualifiers
+	var hasProto, hasDir, hasTyp bool
+	if pr, ok := protoNames[p.peek().val]; ok {
+		p.next()
+		q.proto, hasProto = pr, true
+	}
+	q.dir, hasDir = p.parseDir()
+	if t, ok := typNames[p.peek().val]; ok && p.peek().kind == tokenWord {
+		p.next()
+		q.typ, hasTyp = t, true
+	}
+
+	id := p.peek()
+	if id.kind != tokenWord || (isKeyword(id.val) && q.typ != typProto) {
+		// A lone protocol qualifier matches the layer itself
+		if hasProto && !hasDir && !hasTyp {
+			return &protoNode{q.proto}, nil
+		}
+		return nil, p.errorf(id, "expected id, got %v", id)
+	}
+	explicit := hasProto || hasDir || hasTyp
+	if !explicit {
+		if p.last == nil {
+			return nil, p.errorf(id, "missing qualifier before %v", id)
+		}
+		q = *p.last
+	}
+	p.next()
+	p.last = &q
+	return p.build(q, id)
+}
+
+// parseDir parses an optional direction qualifier.
+func (p *parser) parseDir() (dir, bool) {
+	first := p.peek()
+	if first.kind != tokenWord || (first.val != "src" && first.val != "dst") {
+		return dirSrcOrDst, false
+	}
+	p.next()
+	op, second := p.peek(), p.peekN(1)
+	if (op.kind == tokenOr || op.kind == tokenAnd) &&
+		(second.val == "src" || second.val == "dst") && second.val != first.val {
+		p.next()
+		p.next()
+		if op.kind == tokenAnd {
+			return dirSrcAndDst, true
+		}
+		return dirSrcOrDst, true
+	}
+	if first.val == "src" {
+		return dirSrc, true
+	}
+	return dirDst, true
+}
+
+// build creates the node for a primitive given its qualifiers and id.
+func (p *parser) build(q qualifiers, id token) (node, error) {
+	t := q.typ
+	if t == typNone {
+		t = typHost
+		if strings.Contains(id.val, "/") {
+			t = typNet
+		}
+	}
+
+	switch t {
+	case typHost, typNet:
+		switch q.proto {
+		case protoEther:
+			if t == typNet {
+				return nil, p.errorf(id, "\"ether\" modifier applied to net")
+			}
+			addr, err := net.ParseMAC(id.val)
+			if err != nil {
+				return nil, p.errorf(id, "invalid hardware address %v", id)
+			}
+			return &etherHostNode{dir: q.dir, addr: addr}, nil
+		case protoTCP, protoUDP:
+			return nil, p.errorf(id, "invalid protocol modifier for %v", id)
+		}
+		prefix, err := parsePrefix(id.val, t == typHost)
+		if err != nil {
+			return nil, p.errorf(id, "invalid address %v", id)
+		}
+		return &netNode{proto: q.proto, dir: q.dir, prefix: prefix}, nil
+
+	case typPort, typPortRange:
+		if q.proto != protoNone && q.proto != protoTCP && q.proto != protoUDP {
+			return nil, p.errorf(id, "invalid protocol modifier for port")
+		}
+		lo, hi, err := parsePortRange(id.val, t == typPortRange)
+		if err != nil {
+			return nil, p.errorf(id, "invalid port %v", id)
+		}
+		return &portNode{proto: q.proto, dir: q.dir, lo: lo, hi: hi}, nil
+
+	case typProto:
+		if q.dir != dirSrcOrDst {
+			return nil, p.errorf(id, "direction modifier applied to proto")
+		}
+		switch q.proto {
+		case protoEther:
+			et, ok := etherProtoNames[id.val]
+			if !ok {
+				n, err := strconv.ParseUint(id.val, 0, 16)
+				if err != nil {
+					return nil, p.errorf(id, "invalid ether proto %v", id)
+				}
+				et = packet.EtherType(n)
+			}
+			return &etherProtoNode{et}, nil
+		case protoNone, protoIP:
+			ipp, ok := ipProtoNames[id.val]
+			if !ok {
+				n, err := strconv.ParseUint(id.val, 0, 8)
+				if err != nil {
+					return nil, p.errorf(id, "invalid ip proto %v", id)
+				}
+				ipp = packet.IPProtocol(n)
+			}
+			return &ipProtoNode{ipp}, nil
+		}
+		return nil, p.errorf(id, "invalid protocol modifier for proto")
+	}
+	panic("unreachable")
+}
+
+var etherProtoNames = map[string]packet.EtherType{
+	"ip":  packet.EthernetTypeIPv4,
+	"arp": packet.EthernetTypeARP,
+	"ip6": packet.EthernetTypeIPv6,
+}
+
+var ipProtoNames = map[string]packet.IPProtocol{
+	"icmp": packet.IPProtocolICMP,
+	"igmp": packet.IPProtocolIGMP,
+	"tcp":  packet.IPProtocolTCP,
+	"udp":  packet.IPProtocolUDP,
+}
+
+// parsePrefix parses a prefix in CIDR notation. Plain addresses are accepted
+// as single-address prefixes.
+func parsePrefix(s string, host bool) (netip.Prefix, error) {
+	if !host && strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return prefix, err
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func parsePortRange(s string, isRange bool) (lo, hi uint16, err error) {
+	loStr, hiStr := s, s
+	if isRange {
+		var ok bool
+		loStr, hiStr, ok = strings.Cut(s, "-")
+		if !ok {
+			return 0, 0, fmt.Errorf("invalid port range %q", s)
+		}
+	}
+	l, err := strconv.ParseUint(loStr, 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	h, err := strconv.ParseUint(hiStr, 10, 16)
+	if err != nil {
+		return 0, 0, err
+	}
+	if l > h {
+		return 0, 0, fmt.Errorf("invalid port range %q", s)
+	}
+	return uint16(l), uint16(h), nil
+}
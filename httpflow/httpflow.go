@@ -0,0 +1,470 @@
+// Package httpflow extracts HTTP/1.x transactions from reassembled TCP
+// streams.
+//
+// An Analyzer is used as the stream factory of a reassembly.Assembler. The
+// direction of each connection which carries requests is determined from the
+// first bytes of the stream, so the ports of the server do not matter.
+// Responses are matched with requests in order, which supports pipelining.
+// Message bodies are delimited by Content-Length or chunked encoding, and only
+// their sizes are recorded.
+package httpflow
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/reassembly"
+)
+
+// Request is a parsed HTTP request.
+type Request struct {
+	Method   string
+	URL      string
+	Proto    string
+	Header   http.Header
+	BodySize int64
+	// Time contains the capture time of the first byte of the request.
+	Time time.Time
+}
+
+// Response is a parsed HTTP response.
+type Response struct {
+	Proto      string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	BodySize   int64
+	// Time contains the capture time of the first byte of the response.
+	Time time.Time
+}
+
+// Transaction is a request and its response. Either may be nil when the other
+// half was not captured.
+type Transaction struct {
+	// Flow is the flow from the client to the server.
+	Flow     packet.Flow
+	Request  *Request
+	Response *Response
+}
+
+// Analyzer extracts transactions from TCP streams and passes them to a
+// handler. It is not safe for concurrent use, which matches the assembler.
+type Analyzer struct {
+	handler func(*Transaction)
+	conns   map[packet.Flow]*conn
+}
+
+// NewAnalyzer returns an analyzer which calls h for each transaction. Requests
+// which never get a response are passed to h when their connection ends.
+func NewAnalyzer(h func(*Transaction)) *Analyzer {
+	return &Analyzer{
+		handler: h,
+		conns:   make(map[packet.Flow]*conn),
+	}
+}
+
+// Interface guard
+var _ reassembly.StreamFactory = new(Analyzer)
+
+// New implements reassembly.StreamFactory.
+func (a *Analyzer) New(flow packet.Flow) reassembly.Stream {
+	key := flow.Canonical()
+	c := a.conns[key]
+	if c == nil {
+		c = &conn{a: a, key: key}
+		a.conns[key] = c
+	}
+	c.open++
+	return &halfStream{c: c, flow: flow}
+}
+
+// conn correlates the requests and responses of a connection.
+type conn struct {
+	a       *Analyzer
+	key     packet.Flow
+	open    int
+	pending []*Transaction
+	// head is set for requests to HEAD, whose responses have no body.
+	head []bool
+}
+
+func (c *conn) request(flow packet.Flow, req *Request) {
+	c.pending = append(c.pending, &Transaction{Flow: flow, Request: req})
+	c.head = append(c.head, req.Method == http.MethodHead)
+}
+
+func (c *conn) response(flow packet.Flow, resp *Response) {
+	if len(c.pending) == 0 {
+		c.a.handler(&Transaction{Flow: flow.Reverse(), Response: resp})
+		return
+	}
+	t := c.pending[0]
+	c.pending, c.head = c.pending[1:], c.head[1:]
+	t.Response = resp
+	c.a.handler(t)
+}
+
+// nextIsHead reports whether the next response is to a HEAD request.
+func (c *conn) nextIsHead() bool {
+	return len(c.head) > 0 && c.head[0]
+}
+
+func (c *conn) end() {
+	c.open--
+	if c.open > 0 {
+		return
+	}
+	for _, t := range c.pending {
+		c.a.handler(t)
+	}
+	delete(c.a.conns, c.key)
+}
+
+type parseState uint8
+
+const (
+	stateStart parseState = iota
+	stateHeader
+	stateBody
+	stateChunkSize
+	stateChunkData
+	stateChunkEnd
+	stateTrailer
+	stateBodyUntilClose
+	// stateSync discards data until the start of a message is found, after
+	// data was lost or could not be parsed.
+	stateSync
+	// stateDone discards the rest of the stream, e.g. after a protocol
+	// upgrade.
+	stateDone
+)
+
+// maxHeaderSize limits the amount of data buffered while looking for the end
+// of a message header.
+const maxHeaderSize = 64 << 10
+
+// halfStream parses the messages of one direction of a connection.
+type halfStream struct {
+	c    *conn
+	flow packet.Flow
+	// isResponse is decided from the first bytes of the stream
+	decided    bool
+	isResponse bool
+
+	state     parseState
+	buf       []byte
+	remaining int64
+	req       *Request
+	resp      *Response
+}
+
+func (h *halfStream) Data(data []byte, ts time.Time) {
+	if !h.decided {
+		h.decided = true
+		h.isResponse = bytes.HasPrefix(data, []byte("HTTP/"))
+	}
+	for len(data) > 0 && h.state != stateDone {
+		data = h.parse(data, ts)
+	}
+}
+
+func (h *halfStream) Gap(n int) {
+	switch h.state {
+	case stateBody, stateChunkData:
+		// The lost data is part of the body
+		if int64(n) <= h.remaining {
+			h.body(int64(n))
+			h.remaining -= int64(n)
+			if h.remaining == 0 {
+				if h.state == stateBody {
+					h.finish()
+				} else {
+					h.state = stateChunkEnd
+				}
+			}
+			return
+		}
+	case stateBodyUntilClose:
+		h.body(int64(n))
+		return
+	case stateDone:
+		return
+	}
+	h.buf = h.buf[:0]
+	h.state = stateSync
+}
+
+func (h *halfStream) End() {
+	if h.state == stateBodyUntilClose {
+		h.finish()
+	}
+	h.c.end()
+}
+
+// parse consumes data according to the current state, and returns the
+// remaining data.
+func (h *halfStream) parse(data []byte, ts time.Time) []byte {
+	switch h.state {
+	case stateStart, stateSync:
+		if h.state == stateSync {
+			i := h.findStart(data)
+			if i < 0 {
+				return nil
+			}
+			data = data[i:]
+		}
+		h.state = stateHeader
+		h.buf = h.buf[:0]
+		if h.isResponse {
+			h.resp = &Response{Time: ts}
+		} else {
+			h.req = &Request{Time: ts}
+		}
+		return data
+
+	case stateHeader:
+		// Skip empty lines preceding a message (RFC 9112 section 2.2)
+		if len(h.buf) == 0 {
+			data = bytes.TrimLeft(data, "\r\n")
+			if len(data) == 0 {
+				return nil
+			}
+		}
+		start := len(h.buf) - 3
+		if start < 0 {
+			start = 0
+		}
+		h.buf = append(h.buf, data...)
+		i := bytes.Index(h.buf[start:], []byte("\r\n\r\n"))
+		if i < 0 {
+			if len(h.buf) > maxHeaderSize {
+				h.buf = h.buf[:0]
+				h.state = stateSync
+			}
+			return nil
+		}
+		end := start + i + 4
+		rest := data[len(data)-(len(h.buf)-end):]
+		if err := h.header(h.buf[:end]); err != nil {
+			h.buf = h.buf[:0]
+			h.state = stateSync
+			return rest
+		}
+		h.buf = h.buf[:0]
+		return rest
+
+	case stateBody, stateChunkData:
+		n := int64(len(data))
+		if n > h.remaining {
+			n = h.remaining
+		}
+		h.body(n)
+		h.remaining -= n
+		if h.remaining == 0 {
+			if h.state == stateBody {
+				h.finish()
+			} else {
+				h.state = stateChunkEnd
+			}
+		}
+		return data[n:]
+
+	case stateChunkSize, stateChunkEnd, stateTrailer:
+		h.buf = append(h.buf, data...)
+		i := bytes.Index(h.buf, []byte("\r\n"))
+		if i < 0 {
+			if len(h.buf) > maxHeaderSize {
+				h.buf = h.buf[:0]
+				h.state = stateSync
+			}
+			return nil
+		}
+		line := string(h.buf[:i])
+		rest := data[len(data)-(len(h.buf)-i-2):]
+		h.buf = h.buf[:0]
+		switch h.state {
+		case stateChunkSize:
+			// Ignore chunk extensions
+			if j := strings.IndexByte(line, ';'); j >= 0 {
+				line = line[:j]
+			}
+			size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+			switch {
+			case err != nil || size < 0:
+				h.state = stateSync
+			case size == 0:
+				h.state = stateTrailer
+			default:
+				h.remaining = size
+				h.state = stateChunkData
+			}
+		case stateChunkEnd:
+			h.state = stateChunkSize
+			if line != "" {
+				h.state = stateSync
+			}
+		case stateTrailer:
+			if line == "" {
+				h.finish()
+			}
+		}
+		return rest
+
+	case stateBodyUntilClose:
+		h.body(int64(len(data)))
+		return nil
+	}
+	return nil
+}
+
+// findStart returns the index of the first line in data which looks like the
+// start of a message, or -1.
+func (h *halfStream) findStart(data []byte) int {
+	for i := 0; i < len(data); {
+		line := data[i:]
+		if h.isResponse && bytes.HasPrefix(line, []byte("HTTP/1.")) {
+			return i
+		}
+		if !h.isResponse {
+			if sp := bytes.IndexByte(line, ' '); sp > 0 && isMethod(line[:sp]) {
+				return i
+			}
+		}
+		j := bytes.IndexByte(line, '\n')
+		if j < 0 {
+			return -1
+		}
+		i += j + 1
+	}
+	return -1
+}
+
+func isMethod(b []byte) bool {
+	switch string(b) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodConnect,
+		http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// header parses the start line and header fields of a message, and selects
+// how its body is delimited.
+func (h *halfStream) header(b []byte) error {
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(b)))
+	line, err := r.ReadLine()
+	if err != nil {
+		return err
+	}
+	mime, err := r.ReadMIMEHeader()
+	if err != nil {
+		return err
+	}
+	header := http.Header(mime)
+
+	var proto string
+	if h.isResponse {
+		var code, status string
+		proto, code, _ = strings.Cut(line, " ")
+		code, status, _ = strings.Cut(code, " ")
+		h.resp.StatusCode, err = strconv.Atoi(code)
+		if err != nil || len(code) != 3 {
+			return errors.New("malformed status line")
+		}
+		h.resp.Proto = proto
+		h.resp.Status = status
+		h.resp.Header = header
+	} else {
+		parts := strings.Split(line, " ")
+		if len(parts) != 3 {
+			return errors.New("malformed request line")
+		}
+		proto = parts[2]
+		h.req.Method, h.req.URL, h.req.Proto = parts[0], parts[1], proto
+		h.req.Header = header
+	}
+	if !strings.HasPrefix(proto, "HTTP/1.") {
+		return errors.New("unsupported protocol " + proto)
+	}
+
+	if h.isResponse {
+		code := h.resp.StatusCode
+		switch {
+		case code == http.StatusSwitchingProtocols:
+			// The connection no longer carries HTTP/1.x
+			h.c.response(h.flow, h.resp)
+			h.resp = nil
+			h.state = stateDone
+			return nil
+		case code >= 100 && code < 200:
+			// Interim responses are not matched with requests
+			h.state = stateStart
+			h.resp = nil
+			return nil
+		case code == http.StatusNoContent || code == http.StatusNotModified ||
+			h.c.nextIsHead():
+			h.finish()
+			return nil
+		}
+	}
+
+	te := strings.ToLower(header.Get("Transfer-Encoding"))
+	cl := header.Get("Content-Length")
+	switch {
+	case te != "" && te != "identity":
+		if !strings.HasSuffix(te, "chunked") {
+			// The body of a response is delimited by the connection close
+			if h.isResponse {
+				h.state = stateBodyUntilClose
+				return nil
+			}
+			return errors.New("unsupported transfer encoding " + te)
+		}
+		h.state = stateChunkSize
+	case cl != "":
+		n, err := strconv.ParseInt(strings.TrimSpace(cl), 10, 64)
+		if err != nil || n < 0 {
+			return errors.New("invalid content length")
+		}
+		if n == 0 {
+			h.finish()
+			return nil
+		}
+		h.remaining = n
+		h.state = stateBody
+	case h.isResponse:
+		h.state = stateBodyUntilClose
+	default:
+		// Requests without a length have no body
+		h.finish()
+	}
+	return nil
+}
+
+func (h *halfStream) body(n int64) {
+	if h.isResponse {
+		h.resp.BodySize += n
+	} else {
+		h.req.BodySize += n
+	}
+}
+
+// finish completes the current message.
+func (h *halfStream) finish() {
+	if h.isResponse {
+		h.c.response(h.flow, h.resp)
+		h.resp = nil
+	} else {
+		h.c.request(h.flow, h.req)
+		h.req = nil
+	}
+	h.state = stateStart
+}
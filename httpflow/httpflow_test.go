@@ -0,0 +1,130 @@
+package httpflow_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/httpflow"
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/reassembly"
+)
+
+type endpoint struct {
+	ip   [4]byte
+	port uint16
+	seq  uint32
+}
+
+// segment returns a decoded TCP segment from a to b, and advances the sequence
+// number of a.
+func segment(t *testing.T, a, b *endpoint, flags packet.TCPFlags, payload string) *packet.Packet {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], a.port)
+	binary.BigEndian.PutUint16(tcp[2:4], b.port)
+	binary.BigEndian.PutUint32(tcp[4:8], a.seq)
+	tcp[12] = 5 << 4
+	tcp[13] = uint8(flags)
+	tcp = append(tcp, payload...)
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(tcp)))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], a.ip[:])
+	copy(ip[16:20], b.ip[:])
+
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip...)
+	p, err := packet.Decode(append(frame, tcp...))
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	a.seq += uint32(len(payload))
+	if flags&(packet.TCPFlagSYN|packet.TCPFlagFIN) != 0 {
+		a.seq++
+	}
+	return &p
+}
+
+func TestAnalyzer(t *testing.T) {
+	var txs []*httpflow.Transaction
+	asm := reassembly.NewAssembler(httpflow.NewAnalyzer(func(tx *httpflow.Transaction) {
+		txs = append(txs, tx)
+	}))
+	ts := time.Unix(1700000000, 0)
+	assemble := func(p *packet.Packet) {
+		ts = ts.Add(time.Millisecond)
+		asm.Assemble(p, ts)
+	}
+
+	client := &endpoint{ip: [4]byte{10, 0, 0, 1}, port: 50000, seq: 1000}
+	server := &endpoint{ip: [4]byte{10, 0, 0, 2}, port: 8080, seq: 5000}
+	assemble(segment(t, client, server, packet.TCPFlagSYN, ""))
+	assemble(segment(t, server, client, packet.TCPFlagSYN|packet.TCPFlagACK, ""))
+
+	// Three pipelined requests, where the second segment arrives first
+	first := segment(t, client, server, packet.TCPFlagACK,
+		"POST /upload HTTP/1.1\r\nHost: lab\r\nContent-")
+	second := segment(t, client, server, packet.TCPFlagACK,
+		"Length: 5\r\n\r\nhelloGET /a HTTP/1.1\r\nHost: lab\r\n\r\n"+
+			"HEAD /b HTTP/1.1\r\nHost: lab\r\n\r\n")
+	assemble(second)
+	assemble(first)
+
+	assemble(segment(t, server, client, packet.TCPFlagACK,
+		"HTTP/1.1 100 Continue\r\n\r\n"+
+			"HTTP/1.1 201 Created\r\nTransfer-Encoding: chunked\r\n\r\n"+
+			"4\r\nabcd\r\n3;ext=1\r\nefg\r\n0\r\nX-Trailer: 1\r\n\r\n"+
+			"HTTP/1.1 200 OK\r\nContent-Length: 10\r\n\r\n0123"))
+	assemble(segment(t, server, client, packet.TCPFlagACK,
+		"456789HTTP/1.1 200 OK\r\nContent-Length: 1000\r\n\r\n"))
+	if len(txs) != 3 {
+		t.Fatalf("got %d transactions before close, want 3", len(txs))
+	}
+
+	// A request without response is emitted when the connection ends
+	assemble(segment(t, client, server, packet.TCPFlagACK, "DELETE /c HTTP/1.0\r\n\r\n"))
+	assemble(segment(t, client, server, packet.TCPFlagFIN|packet.TCPFlagACK, ""))
+	assemble(segment(t, server, client, packet.TCPFlagFIN|packet.TCPFlagACK, ""))
+
+	want := []struct {
+		method  string
+		url     string
+		reqBody int64
+		status  int
+		resBody int64
+	}{
+		{"POST", "/upload", 5, 201, 7},
+		{"GET", "/a", 0, 200, 10},
+		{"HEAD", "/b", 0, 200, 0},
+		{"DELETE", "/c", 0, 0, 0},
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("got %d transactions, want %d", len(txs), len(want))
+	}
+	for i, w := range want {
+		tx := txs[i]
+		if tx.Flow.Src.Port() != 50000 || tx.Flow.Dst.Port() != 8080 {
+			t.Errorf("%d: unexpected flow %v", i, tx.Flow)
+		}
+		req := tx.Request
+		if req == nil || req.Method != w.method || req.URL != w.url || req.BodySize != w.reqBody {
+			t.Errorf("%d: unexpected request %+v", i, req)
+			continue
+		}
+		if req.Header.Get("Host") != "lab" && w.method != "DELETE" {
+			t.Errorf("%d: missing host header", i)
+		}
+		if w.status == 0 {
+			if tx.Response != nil {
+				t.Errorf("%d: unexpected response %+v", i, tx.Response)
+			}
+			continue
+		}
+		res := tx.Response
+		if res == nil || res.StatusCode != w.status || res.BodySize != w.resBody {
+			t.Errorf("%d: unexpected response %+v", i, res)
+		}
+	}
+}
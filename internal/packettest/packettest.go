@@ -0,0 +1,70 @@
+// Package packettest builds packets for the tests of the other packages.
+package packettest
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// OnesSum returns the ones' complement sum of data added to sum, which is
+// 0xFFFF for data with a valid Internet checksum.
+func OnesSum(data []byte, sum uint32) uint16 {
+	for ; len(data) >= 2; data = data[2:] {
+		sum += uint32(binary.BigEndian.Uint16(data))
+	}
+	if len(data) == 1 {
+		sum += uint32(data[0]) << 8
+	}
+	for sum > 0xFFFF {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return uint16(sum)
+}
+
+// PseudoSum returns the sum of the pseudo-header of a transport segment of n
+// bytes.
+func PseudoSum(src, dst netip.Addr, proto byte, n int) uint32 {
+	return uint32(OnesSum(src.AsSlice(), 0)) + uint32(OnesSum(dst.AsSlice(), 0)) +
+		uint32(proto) + uint32(n)
+}
+
+// IPv4 returns an IPv4 packet with a valid header checksum, carrying a
+// transport segment whose checksum is set at csumOff unless it is negative.
+// The checksum of ICMP messages does not cover a pseudo-header.
+func IPv4(src, dst netip.Addr, proto byte, seg []byte, csumOff int) []byte {
+	if csumOff >= 0 {
+		var sum uint32
+		if proto != 1 {
+			sum = PseudoSum(src, dst, proto, len(seg))
+		}
+		binary.BigEndian.PutUint16(seg[csumOff:], 0)
+		binary.BigEndian.PutUint16(seg[csumOff:], ^OnesSum(seg, sum))
+	}
+	ip := make([]byte, 20, 20+len(seg))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(seg)))
+	ip[8] = 64
+	ip[9] = proto
+	copy(ip[12:16], src.AsSlice())
+	copy(ip[16:20], dst.AsSlice())
+	binary.BigEndian.PutUint16(ip[10:12], ^OnesSum(ip, 0))
+	return append(ip, seg...)
+}
+
+// Ethernet returns an Ethernet frame from 00:66:77:88:99:aa to
+// 00:11:22:33:44:55 carrying an IPv4 packet.
+func Ethernet(ip []byte) []byte {
+	frame := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x00, 0x66, 0x77, 0x88, 0x99, 0xAA, 0x08, 0x00}
+	return append(frame, ip...)
+}
+
+// UDPFrame returns an Ethernet frame with an IPv4 UDP datagram with valid
+// checksums.
+func UDPFrame(src, dst netip.AddrPort, payload []byte) []byte {
+	udp := make([]byte, 8+len(payload))
+	binary.BigEndian.PutUint16(udp[0:2], src.Port())
+	binary.BigEndian.PutUint16(udp[2:4], dst.Port())
+	binary.BigEndian.PutUint16(udp[4:6], uint16(len(udp)))
+	copy(udp[8:], payload)
+	return Ethernet(IPv4(src.Addr(), dst.Addr(), 17, udp, 6))
+}
@@ -0,0 +1,171 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+)
+
+// Templates of the exported flow records.
+const (
+	templateIDv4 = 256
+	templateIDv6 = 257
+)
+
+// flowFields are the fields of the exported flow records, where the address
+// fields are replaced by their IPv6 elements in the IPv6 template.
+var flowFields = []FieldSpec{
+	{FieldSourceIPv4Address, 4},
+	{FieldDestinationIPv4Address, 4},
+	{FieldSourceTransportPort, 2},
+	{FieldDestinationTransportPort, 2},
+	{FieldProtocolIdentifier, 1},
+	{FieldIPClassOfService, 1},
+	{FieldTCPControlBits, 2},
+	{FieldOctetDeltaCount, 8},
+	{FieldPacketDeltaCount, 8},
+	{FieldFlowStartMilliseconds, 8},
+	{FieldFlowEndMilliseconds, 8},
+	{FieldFlowEndReason, 1},
+}
+
+// maxMessageSize is the maximum size of exported messages, which fits in
+// the UDP payload of an Ethernet frame.
+const maxMessageSize = 1400
+
+// DefaultTemplateInterval is the default interval at which templates are
+// resent.
+const DefaultTemplateInterval = time.Minute
+
+// Exporter encodes flow records as IPFIX messages, and writes them to a
+// collector. The writer is typically a UDP connection returned by net.Dial,
+// and each message is written in a single call. It is safe for concurrent
+// use.
+type Exporter struct {
+	// TemplateInterval is the interval at which templates are resent, since
+	// templates may be lost over UDP.
+	TemplateInterval time.Duration
+
+	mu       sync.Mutex
+	w        io.Writer
+	domain   uint32
+	seq      uint32
+	template time.Time
+}
+
+// NewExporter returns an exporter which writes messages of the observation
+// domain to w.
+func NewExporter(w io.Writer, domain uint32) *Exporter {
+	return &Exporter{TemplateInterval: DefaultTemplateInterval, w: w, domain: domain}
+}
+
+// Export writes the records, in as many messages as needed. Templates are
+// sent before the first records, and when the template interval has passed.
+func (e *Exporter) Export(records []FlowRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := time.Now()
+	var msg []byte
+	if now.Sub(e.template) >= e.TemplateInterval {
+		msg = e.header(now)
+		msg = appendTemplate(msg, templateIDv4, false)
+		msg = appendTemplate(msg, templateIDv6, true)
+		e.template = now
+	}
+	for len(records) > 0 {
+		v6 := records[0].Flow.Src.Addr().Is6()
+		n := recordLen(v6)
+		if msg != nil && len(msg)+4+n > maxMessageSize {
+			if err := e.write(msg); err != nil {
+				return err
+			}
+			msg = nil
+		}
+		if msg == nil {
+			msg = e.header(now)
+		}
+		id := uint16(templateIDv4)
+		if v6 {
+			id = templateIDv6
+		}
+		set := len(msg)
+		msg = append(msg, byte(id>>8), byte(id), 0, 0)
+		for len(records) > 0 && records[0].Flow.Src.Addr().Is6() == v6 && len(msg)+n <= maxMessageSize {
+			msg = appendRecord(msg, &records[0])
+			records = records[1:]
+			e.seq++
+		}
+		binary.BigEndian.PutUint16(msg[set+2:set+4], uint16(len(msg)-set))
+	}
+	if msg != nil {
+		return e.write(msg)
+	}
+	return nil
+}
+
+// header returns a message header, whose sequence number is the number of
+// data records which were exported before the message.
+func (e *Exporter) header(now time.Time) []byte {
+	msg := make([]byte, 16, maxMessageSize)
+	binary.BigEndian.PutUint16(msg[0:2], 10)
+	binary.BigEndian.PutUint32(msg[4:8], uint32(now.Unix()))
+	binary.BigEndian.PutUint32(msg[8:12], e.seq)
+	binary.BigEndian.PutUint32(msg[12:16], e.domain)
+	return msg
+}
+
+func (e *Exporter) write(msg []byte) error {
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(msg)))
+	_, err := e.w.Write(msg)
+	return err
+}
+
+// appendTemplate appends a template set with the flow record template.
+func appendTemplate(b []byte, id uint16, v6 bool) []byte {
+	set := make([]byte, 8+4*len(flowFields))
+	binary.BigEndian.PutUint16(set[0:2], 2)
+	binary.BigEndian.PutUint16(set[2:4], uint16(len(set)))
+	binary.BigEndian.PutUint16(set[4:6], id)
+	binary.BigEndian.PutUint16(set[6:8], uint16(len(flowFields)))
+	for i, f := range flowFields {
+		switch {
+		case v6 && f.Field == FieldSourceIPv4Address:
+			f = FieldSpec{FieldSourceIPv6Address, 16}
+		case v6 && f.Field == FieldDestinationIPv4Address:
+			f = FieldSpec{FieldDestinationIPv6Address, 16}
+		}
+		binary.BigEndian.PutUint16(set[8+4*i:], f.ID)
+		binary.BigEndian.PutUint16(set[10+4*i:], f.Length)
+	}
+	return append(b, set...)
+}
+
+// recordLen returns the length of an encoded flow record.
+func recordLen(v6 bool) int {
+	n := 0
+	for _, f := range flowFields {
+		n += int(f.Length)
+	}
+	if v6 {
+		n += 2 * 12
+	}
+	return n
+}
+
+func appendRecord(b []byte, r *FlowRecord) []byte {
+	src, dst := r.Flow.Src.Addr().AsSlice(), r.Flow.Dst.Addr().AsSlice()
+	b = append(append(b, src...), dst...)
+	rec := make([]byte, recordLen(false)-8)
+	binary.BigEndian.PutUint16(rec[0:2], r.Flow.Src.Port())
+	binary.BigEndian.PutUint16(rec[2:4], r.Flow.Dst.Port())
+	rec[4] = uint8(r.Flow.Proto)
+	rec[5] = r.ClassOfService
+	binary.BigEndian.PutUint16(rec[6:8], uint16(r.TCPFlags))
+	binary.BigEndian.PutUint64(rec[8:16], r.Octets)
+	binary.BigEndian.PutUint64(rec[16:24], r.Packets)
+	binary.BigEndian.PutUint64(rec[24:32], uint64(r.Start.UnixMilli()))
+	binary.BigEndian.PutUint64(rec[32:40], uint64(r.End.UnixMilli()))
+	rec[40] = uint8(r.EndReason)
+	return append(b, rec...)
+}
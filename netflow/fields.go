@@ -0,0 +1,328 @@
+package netflow
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Field identifies an information element by its enterprise number and ID.
+// Elements of the IANA registry, which are also the field types of NetFlow v9,
+// have enterprise number 0.
+type Field struct {
+	Enterprise uint32
+	ID         uint16
+}
+
+// ReversePEN is the enterprise number of the reverse information elements of
+// bidirectional flows (RFC 5103). A reverse element has the ID of its forward
+// element.
+const ReversePEN = 29305
+
+// DataType is the abstract data type of an information element, which
+// selects the Go type of its decoded values.
+type DataType uint8
+
+const (
+	// DataTypeOctetArray values are []byte. Elements of unknown type are
+	// decoded as octet arrays.
+	DataTypeOctetArray DataType = iota
+	// DataTypeUnsigned values are uint64, and may use reduced-size encoding.
+	DataTypeUnsigned
+	// DataTypeSigned values are int64.
+	DataTypeSigned
+	// DataTypeFloat values are float64.
+	DataTypeFloat
+	// DataTypeBoolean values are bool.
+	DataTypeBoolean
+	// DataTypeMACAddress values are net.HardwareAddr.
+	DataTypeMACAddress
+	// DataTypeString values are string.
+	DataTypeString
+	// The date time types are time.Time values.
+	DataTypeDateTimeSeconds
+	DataTypeDateTimeMilliseconds
+	DataTypeDateTimeMicroseconds
+	DataTypeDateTimeNanoseconds
+	// The address types are netip.Addr values.
+	DataTypeIPv4Address
+	DataTypeIPv6Address
+)
+
+// Information elements of the IANA registry which are commonly exported.
+var (
+	FieldOctetDeltaCount               = Field{ID: 1}
+	FieldPacketDeltaCount              = Field{ID: 2}
+	FieldProtocolIdentifier            = Field{ID: 4}
+	FieldIPClassOfService              = Field{ID: 5}
+	FieldTCPControlBits                = Field{ID: 6}
+	FieldSourceTransportPort           = Field{ID: 7}
+	FieldSourceIPv4Address             = Field{ID: 8}
+	FieldSourceIPv4PrefixLength        = Field{ID: 9}
+	FieldIngressInterface              = Field{ID: 10}
+	FieldDestinationTransportPort      = Field{ID: 11}
+	FieldDestinationIPv4Address        = Field{ID: 12}
+	FieldDestinationIPv4PrefixLength   = Field{ID: 13}
+	FieldEgressInterface               = Field{ID: 14}
+	FieldIPNextHopIPv4Address          = Field{ID: 15}
+	FieldBGPSourceASNumber             = Field{ID: 16}
+	FieldBGPDestinationASNumber        = Field{ID: 17}
+	FieldFlowEndSysUpTime              = Field{ID: 21}
+	FieldFlowStartSysUpTime            = Field{ID: 22}
+	FieldSourceIPv6Address             = Field{ID: 27}
+	FieldDestinationIPv6Address        = Field{ID: 28}
+	FieldSamplingInterval              = Field{ID: 34}
+	FieldSourceMACAddress              = Field{ID: 56}
+	FieldVLANID                        = Field{ID: 58}
+	FieldDestinationMACAddress         = Field{ID: 80}
+	FieldInterfaceName                 = Field{ID: 82}
+	FieldFlowEndReason                 = Field{ID: 136}
+	FieldLineCardID                    = Field{ID: 141}
+	FieldMeteringProcessID             = Field{ID: 143}
+	FieldExportingProcessID            = Field{ID: 144}
+	FieldTemplateID                    = Field{ID: 145}
+	FieldFlowStartMilliseconds         = Field{ID: 152}
+	FieldFlowEndMilliseconds           = Field{ID: 153}
+	FieldSystemInitTimeMilliseconds    = Field{ID: 160}
+	FieldPostNATSourceIPv4Address      = Field{ID: 225}
+	FieldPostNATDestinationIPv4Address = Field{ID: 226}
+)
+
+type fieldInfo struct {
+	name string
+	typ  DataType
+}
+
+var fields = struct {
+	sync.RWMutex
+	m map[Field]fieldInfo
+}{m: map[Field]fieldInfo{
+	{ID: 1}:   {"octetDeltaCount", DataTypeUnsigned},
+	{ID: 2}:   {"packetDeltaCount", DataTypeUnsigned},
+	{ID: 3}:   {"deltaFlowCount", DataTypeUnsigned},
+	{ID: 4}:   {"protocolIdentifier", DataTypeUnsigned},
+	{ID: 5}:   {"ipClassOfService", DataTypeUnsigned},
+	{ID: 6}:   {"tcpControlBits", DataTypeUnsigned},
+	{ID: 7}:   {"sourceTransportPort", DataTypeUnsigned},
+	{ID: 8}:   {"sourceIPv4Address", DataTypeIPv4Address},
+	{ID: 9}:   {"sourceIPv4PrefixLength", DataTypeUnsigned},
+	{ID: 10}:  {"ingressInterface", DataTypeUnsigned},
+	{ID: 11}:  {"destinationTransportPort", DataTypeUnsigned},
+	{ID: 12}:  {"destinationIPv4Address", DataTypeIPv4Address},
+	{ID: 13}:  {"destinationIPv4PrefixLength", DataTypeUnsigned},
+	{ID: 14}:  {"egressInterface", DataTypeUnsigned},
+	{ID: 15}:  {"ipNextHopIPv4Address", DataTypeIPv4Address},
+	{ID: 16}:  {"bgpSourceAsNumber", DataTypeUnsigned},
+	{ID: 17}:  {"bgpDestinationAsNumber", DataTypeUnsigned},
+	{ID: 18}:  {"bgpNextHopIPv4Address", DataTypeIPv4Address},
+	{ID: 21}:  {"flowEndSysUpTime", DataTypeUnsigned},
+	{ID: 22}:  {"flowStartSysUpTime", DataTypeUnsigned},
+	{ID: 23}:  {"postOctetDeltaCount", DataTypeUnsigned},
+	{ID: 24}:  {"postPacketDeltaCount", DataTypeUnsigned},
+	{ID: 27}:  {"sourceIPv6Address", DataTypeIPv6Address},
+	{ID: 28}:  {"destinationIPv6Address", DataTypeIPv6Address},
+	{ID: 29}:  {"sourceIPv6PrefixLength", DataTypeUnsigned},
+	{ID: 30}:  {"destinationIPv6PrefixLength", DataTypeUnsigned},
+	{ID: 31}:  {"flowLabelIPv6", DataTypeUnsigned},
+	{ID: 32}:  {"icmpTypeCodeIPv4", DataTypeUnsigned},
+	{ID: 34}:  {"samplingInterval", DataTypeUnsigned},
+	{ID: 35}:  {"samplingAlgorithm", DataTypeUnsigned},
+	{ID: 52}:  {"minimumTTL", DataTypeUnsigned},
+	{ID: 53}:  {"maximumTTL", DataTypeUnsigned},
+	{ID: 56}:  {"sourceMacAddress", DataTypeMACAddress},
+	{ID: 57}:  {"postDestinationMacAddress", DataTypeMACAddress},
+	{ID: 58}:  {"vlanId", DataTypeUnsigned},
+	{ID: 61}:  {"flowDirection", DataTypeUnsigned},
+	{ID: 62}:  {"ipNextHopIPv6Address", DataTypeIPv6Address},
+	{ID: 80}:  {"destinationMacAddress", DataTypeMACAddress},
+	{ID: 81}:  {"postSourceMacAddress", DataTypeMACAddress},
+	{ID: 82}:  {"interfaceName", DataTypeString},
+	{ID: 83}:  {"interfaceDescription", DataTypeString},
+	{ID: 85}:  {"octetTotalCount", DataTypeUnsigned},
+	{ID: 86}:  {"packetTotalCount", DataTypeUnsigned},
+	{ID: 89}:  {"forwardingStatus", DataTypeUnsigned},
+	{ID: 95}:  {"applicationId", DataTypeOctetArray},
+	{ID: 96}:  {"applicationName", DataTypeString},
+	{ID: 130}: {"exporterIPv4Address", DataTypeIPv4Address},
+	{ID: 131}: {"exporterIPv6Address", DataTypeIPv6Address},
+	{ID: 136}: {"flowEndReason", DataTypeUnsigned},
+	{ID: 139}: {"icmpTypeCodeIPv6", DataTypeUnsigned},
+	{ID: 141}: {"lineCardId", DataTypeUnsigned},
+	{ID: 143}: {"meteringProcessId", DataTypeUnsigned},
+	{ID: 144}: {"exportingProcessId", DataTypeUnsigned},
+	{ID: 145}: {"templateId", DataTypeUnsigned},
+	{ID: 148}: {"flowId", DataTypeUnsigned},
+	{ID: 150}: {"flowStartSeconds", DataTypeDateTimeSeconds},
+	{ID: 151}: {"flowEndSeconds", DataTypeDateTimeSeconds},
+	{ID: 152}: {"flowStartMilliseconds", DataTypeDateTimeMilliseconds},
+	{ID: 153}: {"flowEndMilliseconds", DataTypeDateTimeMilliseconds},
+	{ID: 154}: {"flowStartMicroseconds", DataTypeDateTimeMicroseconds},
+	{ID: 155}: {"flowEndMicroseconds", DataTypeDateTimeMicroseconds},
+	{ID: 156}: {"flowStartNanoseconds", DataTypeDateTimeNanoseconds},
+	{ID: 157}: {"flowEndNanoseconds", DataTypeDateTimeNanoseconds},
+	{ID: 160}: {"systemInitTimeMilliseconds", DataTypeDateTimeMilliseconds},
+	{ID: 176}: {"icmpTypeIPv4", DataTypeUnsigned},
+	{ID: 177}: {"icmpCodeIPv4", DataTypeUnsigned},
+	{ID: 178}: {"icmpTypeIPv6", DataTypeUnsigned},
+	{ID: 179}: {"icmpCodeIPv6", DataTypeUnsigned},
+	{ID: 210}: {"paddingOctets", DataTypeOctetArray},
+	{ID: 225}: {"postNATSourceIPv4Address", DataTypeIPv4Address},
+	{ID: 226}: {"postNATDestinationIPv4Address", DataTypeIPv4Address},
+	{ID: 227}: {"postNAPTSourceTransportPort", DataTypeUnsigned},
+	{ID: 228}: {"postNAPTDestinationTransportPort", DataTypeUnsigned},
+	{ID: 234}: {"ingressVRFID", DataTypeUnsigned},
+	{ID: 235}: {"egressVRFID", DataTypeUnsigned},
+	{ID: 239}: {"biflowDirection", DataTypeUnsigned},
+}}
+
+// RegisterField registers the name and data type of an information element,
+// typically an enterprise-specific element. A registered element replaces
+// the built-in definition with the same enterprise number and ID.
+func RegisterField(f Field, name string, t DataType) {
+	fields.Lock()
+	defer fields.Unlock()
+	fields.m[f] = fieldInfo{name: name, typ: t}
+}
+
+// lookup returns the definition of f. Reverse elements have the definition
+// of their forward element, with the name prefixed by "reverse".
+func lookup(f Field) (fieldInfo, bool) {
+	fields.RLock()
+	defer fields.RUnlock()
+	info, ok := fields.m[f]
+	if ok || f.Enterprise != ReversePEN {
+		return info, ok
+	}
+	info, ok = fields.m[Field{ID: f.ID}]
+	if ok {
+		info.name = "reverse" + strings.ToUpper(info.name[:1]) + info.name[1:]
+	}
+	return info, ok
+}
+
+// Type returns the data type of the element, which is DataTypeOctetArray for
+// unknown elements.
+func (f Field) Type() DataType {
+	info, _ := lookup(f)
+	return info.typ
+}
+
+// String returns the name of the element. Unknown elements are named by
+// their ID, prefixed by "ie", with the enterprise number before a dot for
+// enterprise-specific elements, e.g. "ie9.12235".
+func (f Field) String() string {
+	if info, ok := lookup(f); ok {
+		return info.name
+	}
+	if f.Enterprise != 0 {
+		return fmt.Sprintf("ie%d.%d", f.Enterprise, f.ID)
+	}
+	return fmt.Sprintf("ie%d", f.ID)
+}
+
+// MarshalText encodes the field as its name, so that fields can be used as
+// JSON object keys.
+func (f Field) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// Fields contains the decoded values of a record, of the Go type selected by
+// the data type of each element.
+type Fields map[Field]any
+
+// MarshalJSON encodes the fields as an object keyed by element name, with
+// octet arrays in hex.
+func (f Fields) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(f))
+	for k, v := range f {
+		switch v := v.(type) {
+		case []byte:
+			m[k.String()] = hex.EncodeToString(v)
+		case net.HardwareAddr:
+			m[k.String()] = v.String()
+		default:
+			m[k.String()] = v
+		}
+	}
+	return json.Marshal(m)
+}
+
+// ntpEpoch is the epoch of the microsecond and nanosecond date time types,
+// which are encoded as NTP timestamps (RFC 7011 section 6.1.9).
+var ntpEpoch = time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// decodeValue decodes a value of f. Values which do not fit their data type
+// are decoded as octet arrays. The result does not reference b.
+func decodeValue(f Field, b []byte) any {
+	switch f.Type() {
+	case DataTypeUnsigned:
+		if len(b) <= 8 {
+			var v uint64
+			for _, c := range b {
+				v = v<<8 | uint64(c)
+			}
+			return v
+		}
+	case DataTypeSigned:
+		if len(b) > 0 && len(b) <= 8 {
+			v := int64(int8(b[0]))
+			for _, c := range b[1:] {
+				v = v<<8 | int64(c)
+			}
+			return v
+		}
+	case DataTypeFloat:
+		switch len(b) {
+		case 4:
+			return float64(math.Float32frombits(binary.BigEndian.Uint32(b)))
+		case 8:
+			return math.Float64frombits(binary.BigEndian.Uint64(b))
+		}
+	case DataTypeBoolean:
+		if len(b) == 1 {
+			// True is 1 and false is 2
+			return b[0] == 1
+		}
+	case DataTypeMACAddress:
+		if len(b) == 6 {
+			return net.HardwareAddr(append([]byte{}, b...))
+		}
+	case DataTypeString:
+		return string(b)
+	case DataTypeDateTimeSeconds:
+		if len(b) == 4 {
+			return time.Unix(int64(binary.BigEndian.Uint32(b)), 0).UTC()
+		}
+	case DataTypeDateTimeMilliseconds:
+		if len(b) == 8 {
+			return time.UnixMilli(int64(binary.BigEndian.Uint64(b))).UTC()
+		}
+	case DataTypeDateTimeMicroseconds, DataTypeDateTimeNanoseconds:
+		if len(b) == 8 {
+			sec := binary.BigEndian.Uint32(b[0:4])
+			frac := binary.BigEndian.Uint32(b[4:8])
+			if f.Type() == DataTypeDateTimeMicroseconds {
+				// The 11 lowest bits of the fraction are ignored
+				frac &^= 0x7FF
+			}
+			nsec := int64(frac) * int64(time.Second) >> 32
+			return ntpEpoch.Add(time.Duration(sec)*time.Second + time.Duration(nsec))
+		}
+	case DataTypeIPv4Address:
+		if len(b) == 4 {
+			return netip.AddrFrom4(*(*[4]byte)(b))
+		}
+	case DataTypeIPv6Address:
+		if len(b) == 16 {
+			return netip.AddrFrom16(*(*[16]byte)(b))
+		}
+	}
+	return append([]byte{}, b...)
+}
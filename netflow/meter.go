@@ -0,0 +1,154 @@
+package netflow
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// FlowEndReason is the reason a metered flow was exported, as encoded in the
+// flowEndReason element.
+type FlowEndReason uint8
+
+const (
+	FlowEndReasonIdleTimeout   FlowEndReason = 1
+	FlowEndReasonActiveTimeout FlowEndReason = 2
+	// FlowEndReasonEndOfFlow is set for TCP flows which were closed by a FIN
+	// or RST segment.
+	FlowEndReasonEndOfFlow FlowEndReason = 3
+	// FlowEndReasonForcedEnd is set for flows exported by Flush.
+	FlowEndReasonForcedEnd FlowEndReason = 4
+)
+
+// FlowRecord is a unidirectional flow which was metered from packets.
+type FlowRecord struct {
+	Flow  packet.Flow
+	Start time.Time
+	End   time.Time
+	// Octets is the sum of the IP lengths of the packets.
+	Octets  uint64
+	Packets uint64
+	// TCPFlags is the union of the flags of the TCP segments.
+	TCPFlags packet.TCPFlags
+	// ClassOfService is the TOS or traffic class of the first packet.
+	ClassOfService uint8
+	EndReason      FlowEndReason
+}
+
+// Meter aggregates packets into flows, keyed by their 5-tuple, and exports
+// flows when they end. It is safe for concurrent use.
+//
+// A flow is exported when no packets were received for the idle timeout,
+// when it has been active for the active timeout, or when a TCP FIN or RST
+// segment is received. Timeouts are checked as the timestamps of added
+// packets advance, so captures are metered by their own clock. Expire should
+// be called periodically for live captures, where packets may stop arriving.
+type Meter struct {
+	mu      sync.Mutex
+	active  time.Duration
+	idle    time.Duration
+	export  func([]FlowRecord)
+	flows   map[packet.Flow]*FlowRecord
+	expired time.Time
+}
+
+// NewMeter returns a meter with the given active and idle timeouts, which
+// calls export with the flows which ended. Export is called with the meter
+// locked, and must not call the meter.
+func NewMeter(active, idle time.Duration, export func([]FlowRecord)) *Meter {
+	return &Meter{
+		active: active,
+		idle:   idle,
+		export: export,
+		flows:  make(map[packet.Flow]*FlowRecord),
+	}
+}
+
+// Add meters a packet captured at ts. It reports false if the packet does not
+// belong to a TCP, UDP or SCTP flow.
+func (m *Meter) Add(p packet.Packet, ts time.Time) bool {
+	f, ok := p.Flow()
+	if !ok {
+		return false
+	}
+	var cos uint8
+	switch ip := p.Network.(type) {
+	case *packet.IPv4:
+		cos = ip.DSCP<<2 | ip.ECN
+	case *packet.IPv6:
+		cos = ip.TrafficClass
+	}
+	var flags packet.TCPFlags
+	if tcp, ok := p.Transport.(*packet.TCP); ok {
+		flags = tcp.Flags
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ts.Sub(m.expired) >= time.Second {
+		m.expire(ts)
+	}
+	r := m.flows[f]
+	if r != nil && ts.Sub(r.Start) >= m.active {
+		r.EndReason = FlowEndReasonActiveTimeout
+		m.export([]FlowRecord{*r})
+		r = nil
+	}
+	if r == nil {
+		r = &FlowRecord{Flow: f, Start: ts, ClassOfService: cos}
+		m.flows[f] = r
+	}
+	r.End = ts
+	r.Octets += uint64(len(p.Network.GetContents()))
+	r.Packets++
+	r.TCPFlags |= flags
+	if flags&(packet.TCPFlagFIN|packet.TCPFlagRST) != 0 {
+		r.EndReason = FlowEndReasonEndOfFlow
+		delete(m.flows, f)
+		m.export([]FlowRecord{*r})
+	}
+	return true
+}
+
+// Expire exports the flows which timed out at now.
+func (m *Meter) Expire(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expire(now)
+}
+
+func (m *Meter) expire(now time.Time) {
+	m.expired = now
+	var records []FlowRecord
+	for f, r := range m.flows {
+		switch {
+		case now.Sub(r.End) >= m.idle:
+			r.EndReason = FlowEndReasonIdleTimeout
+		case now.Sub(r.Start) >= m.active:
+			r.EndReason = FlowEndReasonActiveTimeout
+		default:
+			continue
+		}
+		records = append(records, *r)
+		delete(m.flows, f)
+	}
+	if len(records) > 0 {
+		m.export(records)
+	}
+}
+
+// Flush exports all flows, such as when a capture ends.
+func (m *Meter) Flush() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var records []FlowRecord
+	for f, r := range m.flows {
+		r.EndReason = FlowEndReasonForcedEnd
+		records = append(records, *r)
+		delete(m.flows, f)
+	}
+	if len(records) > 0 {
+		m.export(records)
+	}
+}
@@ -0,0 +1,150 @@
+package netflow_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/netflow"
+	"github.com/sebnyberg/net/packet"
+)
+
+// tcp4 returns a decoded TCP segment with a payload of n bytes.
+func tcp4(t *testing.T, src, dst [4]byte, sport, dport uint16, flags packet.TCPFlags, n int) packet.Packet {
+	tcp := make([]byte, 20+n)
+	copy(tcp[0:2], u16(sport))
+	copy(tcp[2:4], u16(dport))
+	tcp[12] = 5 << 4
+	tcp[13] = uint8(flags)
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	ip[1] = 0x2E << 2
+	copy(ip[2:4], u16(uint16(20+len(tcp))))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], src[:])
+	copy(ip[16:20], dst[:])
+	frame := concat([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip, tcp)
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	return p
+}
+
+func TestMeter(t *testing.T) {
+	var got []netflow.FlowRecord
+	m := netflow.NewMeter(time.Minute, 10*time.Second, func(r []netflow.FlowRecord) {
+		got = append(got, r...)
+	})
+	a, b := [4]byte{10, 0, 0, 1}, [4]byte{10, 0, 0, 2}
+	ts := time.Unix(1700000000, 0)
+
+	m.Add(tcp4(t, a, b, 40000, 80, packet.TCPFlagSYN, 0), ts)
+	m.Add(tcp4(t, b, a, 80, 40000, packet.TCPFlagSYN|packet.TCPFlagACK, 0), ts)
+	m.Add(tcp4(t, a, b, 40000, 80, packet.TCPFlagACK|packet.TCPFlagPSH, 100), ts.Add(time.Second))
+	m.Add(tcp4(t, a, b, 40000, 80, packet.TCPFlagFIN|packet.TCPFlagACK, 0), ts.Add(2*time.Second))
+	if len(got) != 1 {
+		t.Fatalf("got %d flows after FIN, want 1", len(got))
+	}
+	r := got[0]
+	if r.Packets != 3 || r.Octets != 3*40+100 || r.EndReason != netflow.FlowEndReasonEndOfFlow ||
+		r.TCPFlags != packet.TCPFlagSYN|packet.TCPFlagACK|packet.TCPFlagPSH|packet.TCPFlagFIN ||
+		r.ClassOfService != 0x2E<<2 || !r.End.Equal(ts.Add(2*time.Second)) {
+		t.Errorf("unexpected flow %+v", r)
+	}
+
+	// The reverse flow expires when later packets advance the clock
+	m.Add(tcp4(t, a, b, 40001, 80, packet.TCPFlagSYN, 0), ts.Add(15*time.Second))
+	if len(got) != 2 || got[1].EndReason != netflow.FlowEndReasonIdleTimeout || got[1].Flow.Src.Port() != 80 {
+		t.Fatalf("reverse flow was not expired, %+v", got)
+	}
+
+	// Long flows are exported at the active timeout
+	for i := 1; i <= 14; i++ {
+		m.Add(tcp4(t, a, b, 40001, 80, packet.TCPFlagACK, 10), ts.Add(time.Duration(15+5*i)*time.Second))
+	}
+	if len(got) != 3 || got[2].EndReason != netflow.FlowEndReasonActiveTimeout || got[2].Packets != 12 {
+		t.Fatalf("active flow was not exported, %+v", got)
+	}
+	m.Flush()
+	if len(got) != 4 || got[3].EndReason != netflow.FlowEndReasonForcedEnd || got[3].Packets != 3 {
+		t.Fatalf("flow was not flushed, %+v", got)
+	}
+}
+
+type messages [][]byte
+
+func (m *messages) Write(b []byte) (int, error) {
+	*m = append(*m, append([]byte{}, b...))
+	return len(b), nil
+}
+
+func TestExporter(t *testing.T) {
+	var w messages
+	e := netflow.NewExporter(&w, 7)
+	start := time.UnixMilli(1700000000123)
+	var records []netflow.FlowRecord
+	for i := 0; i < 40; i++ {
+		src := netip.MustParseAddr("192.0.2.1")
+		if i%2 == 1 {
+			src = netip.MustParseAddr("2001:db8::1")
+		}
+		records = append(records, netflow.FlowRecord{
+			Flow: packet.Flow{
+				Proto: packet.IPProtocolUDP,
+				Src:   netip.AddrPortFrom(src, uint16(1000+i)),
+				Dst:   netip.AddrPortFrom(src.Next(), 53),
+			},
+			Start:     start,
+			End:       start.Add(time.Second),
+			Octets:    uint64(100 * i),
+			Packets:   uint64(i),
+			EndReason: netflow.FlowEndReasonIdleTimeout,
+		})
+	}
+	if err := e.Export(records); err != nil {
+		t.Fatal(err)
+	}
+	if len(w) < 2 {
+		t.Fatalf("got %d messages, want records split across messages", len(w))
+	}
+
+	d := netflow.NewDecoder()
+	var decoded []netflow.Record
+	for i, b := range w {
+		if len(b) > 1400 {
+			t.Errorf("message %d is %d bytes", i, len(b))
+		}
+		m, err := d.Decode(exporter, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.ObservationDomain != 7 || m.Sequence != uint32(len(decoded)) || len(m.MissingTemplates) > 0 {
+			t.Fatalf("unexpected message %+v", m)
+		}
+		decoded = append(decoded, m.Records...)
+	}
+	if len(decoded) != len(records) {
+		t.Fatalf("decoded %d records, want %d", len(decoded), len(records))
+	}
+	for i, r := range decoded {
+		f := r.Fields
+		src := netflow.FieldSourceIPv4Address
+		if i%2 == 1 {
+			src = netflow.FieldSourceIPv6Address
+		}
+		if f[src] != records[i].Flow.Src.Addr() || f[netflow.FieldSourceTransportPort] != uint64(1000+i) ||
+			f[netflow.FieldOctetDeltaCount] != uint64(100*i) || f[netflow.FieldProtocolIdentifier] != uint64(17) ||
+			f[netflow.FieldFlowEndReason] != uint64(1) || !f[netflow.FieldFlowStartMilliseconds].(time.Time).Equal(start) {
+			t.Errorf("record %d has fields %v", i, f)
+		}
+	}
+
+	// Templates are only sent again after the template interval
+	w = nil
+	e.Export(records[:1])
+	if len(w) != 1 || len(w[0]) != 16+4+49 {
+		t.Errorf("unexpected messages %v", w)
+	}
+}
@@ -0,0 +1,402 @@
+// Package netflow decodes the flow records of NetFlow v5, NetFlow v9
+// (RFC 3954) and IPFIX (RFC 7011) export packets.
+//
+// NetFlow v9 and IPFIX records are encoded according to templates, which
+// exporters send periodically. A Decoder caches the templates of each
+// exporter, keyed by the address of the exporter and its observation domain,
+// and decodes data records whose template is known. Records of all versions
+// are decoded into Fields, which map information elements to typed values;
+// NetFlow v5 records use the equivalent IPFIX elements.
+//
+// A Meter aggregates captured packets into flow records, and an Exporter
+// sends them to a collector as IPFIX messages.
+package netflow
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// VariableLength is the field length of IPFIX fields whose length is encoded
+// in each record.
+const VariableLength = 0xFFFF
+
+// FieldSpec is a field of a template.
+type FieldSpec struct {
+	Field
+	Length uint16
+}
+
+// Template is a template or an options template.
+type Template struct {
+	ID uint16
+	// ScopeFieldCount is the number of scope fields at the start of Fields,
+	// which is non-zero for options templates.
+	ScopeFieldCount int
+	Fields          []FieldSpec
+}
+
+// minLen returns the minimum length of a record, where variable length
+// fields have a length of 1.
+func (t *Template) minLen() int {
+	n := 0
+	for _, f := range t.Fields {
+		if f.Length == VariableLength {
+			n++
+		} else {
+			n += int(f.Length)
+		}
+	}
+	return n
+}
+
+// Record is a flow record, or a data record of an options template.
+type Record struct {
+	TemplateID uint16 `json:"template_id"`
+	// Scope contains the scope fields of options records.
+	Scope  Fields `json:"scope,omitempty"`
+	Fields Fields `json:"fields"`
+}
+
+// Message is a decoded export packet.
+type Message struct {
+	Version    uint16         `json:"version"`
+	Exporter   netip.AddrPort `json:"exporter"`
+	ExportTime time.Time      `json:"export_time"`
+	// SysUptime is the time since the exporter was started, for NetFlow.
+	SysUptime time.Duration `json:"sys_uptime,omitempty"`
+	Sequence  uint32        `json:"sequence"`
+	// ObservationDomain is the observation domain ID of IPFIX, or the source
+	// ID of NetFlow v9. For NetFlow v5 it contains the engine type and ID.
+	ObservationDomain uint32 `json:"observation_domain"`
+	// SamplingInterval is set for NetFlow v5.
+	SamplingInterval uint16 `json:"sampling_interval,omitempty"`
+	// Templates contains the templates which were received in the message.
+	Templates []*Template `json:"-"`
+	Records   []Record    `json:"records"`
+	// MissingTemplates contains the IDs of the data sets which could not be
+	// decoded, since their template was not received yet.
+	MissingTemplates []uint16 `json:"missing_templates,omitempty"`
+}
+
+// UptimeTime returns the time of a sysUpTime timestamp of the exporter, such
+// as the flowStartSysUpTime field, in milliseconds.
+func (m *Message) UptimeTime(ms uint64) time.Time {
+	return m.ExportTime.Add(-m.SysUptime + time.Duration(ms)*time.Millisecond)
+}
+
+type templateKey struct {
+	exporter netip.AddrPort
+	version  uint16
+	domain   uint32
+	id       uint16
+}
+
+// Decoder decodes export packets. It is safe for concurrent use.
+type Decoder struct {
+	mu        sync.Mutex
+	templates map[templateKey]*Template
+}
+
+// NewDecoder returns a decoder with an empty template cache.
+func NewDecoder() *Decoder {
+	return &Decoder{templates: make(map[templateKey]*Template)}
+}
+
+// Decode decodes an export packet received from exporter. Templates in the
+// packet are added to the cache before the data sets which follow them are
+// decoded. If an error is returned, the message contains the records which
+// were decoded before the error. The message does not reference data.
+func (d *Decoder) Decode(exporter netip.AddrPort, data []byte) (*Message, error) {
+	if len(data) < 2 {
+		return nil, errors.New("export packet too small")
+	}
+	m := &Message{Version: binary.BigEndian.Uint16(data[0:2]), Exporter: exporter}
+	switch m.Version {
+	case 5:
+		return m, m.decodeV5(data)
+	case 9:
+		if len(data) < 20 {
+			return m, errors.New("netflow v9 header too small")
+		}
+		m.SysUptime = time.Duration(binary.BigEndian.Uint32(data[4:8])) * time.Millisecond
+		m.ExportTime = time.Unix(int64(binary.BigEndian.Uint32(data[8:12])), 0).UTC()
+		m.Sequence = binary.BigEndian.Uint32(data[12:16])
+		m.ObservationDomain = binary.BigEndian.Uint32(data[16:20])
+		return m, d.decodeSets(m, data[20:])
+	case 10:
+		if len(data) < 16 {
+			return m, errors.New("ipfix header too small")
+		}
+		n := int(binary.BigEndian.Uint16(data[2:4]))
+		if n < 16 || n > len(data) {
+			return m, errors.New("invalid ipfix message length")
+		}
+		m.ExportTime = time.Unix(int64(binary.BigEndian.Uint32(data[4:8])), 0).UTC()
+		m.Sequence = binary.BigEndian.Uint32(data[8:12])
+		m.ObservationDomain = binary.BigEndian.Uint32(data[12:16])
+		return m, d.decodeSets(m, data[16:n])
+	}
+	return m, fmt.Errorf("unsupported export version %d", m.Version)
+}
+
+// Serve decodes the export packets received on conn, and calls h for each
+// packet. It returns when reading from conn fails.
+func (d *Decoder) Serve(conn net.PacketConn, h func(*Message, error)) error {
+	buf := make([]byte, 65536)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		var exporter netip.AddrPort
+		if a, ok := addr.(*net.UDPAddr); ok {
+			exporter = a.AddrPort()
+		}
+		h(d.Decode(exporter, buf[:n]))
+	}
+}
+
+// v5 fields in the order of the record, with their offsets and lengths.
+var v5Fields = []struct {
+	field     Field
+	off, size int
+}{
+	{FieldSourceIPv4Address, 0, 4},
+	{FieldDestinationIPv4Address, 4, 4},
+	{FieldIPNextHopIPv4Address, 8, 4},
+	{FieldIngressInterface, 12, 2},
+	{FieldEgressInterface, 14, 2},
+	{FieldPacketDeltaCount, 16, 4},
+	{FieldOctetDeltaCount, 20, 4},
+	{FieldFlowStartSysUpTime, 24, 4},
+	{FieldFlowEndSysUpTime, 28, 4},
+	{FieldSourceTransportPort, 32, 2},
+	{FieldDestinationTransportPort, 34, 2},
+	{FieldTCPControlBits, 37, 1},
+	{FieldProtocolIdentifier, 38, 1},
+	{FieldIPClassOfService, 39, 1},
+	{FieldBGPSourceASNumber, 40, 2},
+	{FieldBGPDestinationASNumber, 42, 2},
+	{FieldSourceIPv4PrefixLength, 44, 1},
+	{FieldDestinationIPv4PrefixLength, 45, 1},
+}
+
+func (m *Message) decodeV5(data []byte) error {
+	if len(data) < 24 {
+		return errors.New("netflow v5 header too small")
+	}
+	count := int(binary.BigEndian.Uint16(data[2:4]))
+	m.SysUptime = time.Duration(binary.BigEndian.Uint32(data[4:8])) * time.Millisecond
+	m.ExportTime = time.Unix(int64(binary.BigEndian.Uint32(data[8:12])),
+		int64(binary.BigEndian.Uint32(data[12:16]))).UTC()
+	m.Sequence = binary.BigEndian.Uint32(data[16:20])
+	m.ObservationDomain = uint32(binary.BigEndian.Uint16(data[20:22]))
+	m.SamplingInterval = binary.BigEndian.Uint16(data[22:24]) & 0x3FFF
+	if 24+count*48 > len(data) {
+		return errors.New("invalid netflow v5 record count")
+	}
+	m.Records = make([]Record, count)
+	for i := range m.Records {
+		rec := data[24+i*48 : 24+(i+1)*48]
+		fields := make(Fields, len(v5Fields))
+		for _, f := range v5Fields {
+			fields[f.field] = decodeValue(f.field, rec[f.off:f.off+f.size])
+		}
+		m.Records[i] = Record{Fields: fields}
+	}
+	return nil
+}
+
+// decodeSets decodes the sets of a NetFlow v9 or IPFIX message.
+func (d *Decoder) decodeSets(m *Message, data []byte) error {
+	templateSet, optionsSet := uint16(0), uint16(1)
+	if m.Version == 10 {
+		templateSet, optionsSet = 2, 3
+	}
+	m.Records = []Record{}
+	for len(data) > 0 {
+		if len(data) < 4 {
+			return errors.New("set header too small")
+		}
+		id := binary.BigEndian.Uint16(data[0:2])
+		n := int(binary.BigEndian.Uint16(data[2:4]))
+		if n < 4 || n > len(data) {
+			return fmt.Errorf("invalid length of set %d", id)
+		}
+		body := data[4:n]
+		data = data[n:]
+		var err error
+		switch {
+		case id == templateSet:
+			err = d.decodeTemplates(m, body, false)
+		case id == optionsSet:
+			err = d.decodeTemplates(m, body, true)
+		case id >= 256:
+			err = d.decodeData(m, id, body)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) key(m *Message, id uint16) templateKey {
+	return templateKey{exporter: m.Exporter, version: m.Version, domain: m.ObservationDomain, id: id}
+}
+
+// decodeTemplates decodes the templates of a template set and adds them to
+// the cache. Template sets may end with padding.
+func (d *Decoder) decodeTemplates(m *Message, data []byte, options bool) error {
+	for len(data) >= 4 {
+		t := &Template{ID: binary.BigEndian.Uint16(data[0:2])}
+		var count int
+		switch {
+		case m.Version == 9 && options:
+			if len(data) < 6 {
+				return errors.New("options template too small")
+			}
+			scopeLen := int(binary.BigEndian.Uint16(data[2:4]))
+			optionLen := int(binary.BigEndian.Uint16(data[4:6]))
+			if scopeLen%4 != 0 || optionLen%4 != 0 {
+				return fmt.Errorf("invalid length of options template %d", t.ID)
+			}
+			t.ScopeFieldCount = scopeLen / 4
+			count = t.ScopeFieldCount + optionLen/4
+			data = data[6:]
+		case options:
+			if len(data) < 6 {
+				return errors.New("options template too small")
+			}
+			count = int(binary.BigEndian.Uint16(data[2:4]))
+			t.ScopeFieldCount = int(binary.BigEndian.Uint16(data[4:6]))
+			if t.ScopeFieldCount == 0 || t.ScopeFieldCount > count {
+				return fmt.Errorf("invalid scope field count of options template %d", t.ID)
+			}
+			data = data[6:]
+		default:
+			count = int(binary.BigEndian.Uint16(data[2:4]))
+			data = data[4:]
+		}
+		if t.ID < 256 {
+			if m.Version == 9 {
+				// Padding
+				return nil
+			}
+			if count == 0 {
+				// Withdrawal of all templates, which only applies to
+				// reliable transports
+				continue
+			}
+			return fmt.Errorf("invalid template id %d", t.ID)
+		}
+		if count == 0 {
+			// IPFIX template withdrawal
+			d.mu.Lock()
+			delete(d.templates, d.key(m, t.ID))
+			d.mu.Unlock()
+			continue
+		}
+
+		t.Fields = make([]FieldSpec, count)
+		for i := range t.Fields {
+			if len(data) < 4 {
+				return fmt.Errorf("template %d too small", t.ID)
+			}
+			f := FieldSpec{
+				Field:  Field{ID: binary.BigEndian.Uint16(data[0:2])},
+				Length: binary.BigEndian.Uint16(data[2:4]),
+			}
+			data = data[4:]
+			if m.Version == 10 && f.ID&0x8000 != 0 {
+				if len(data) < 4 {
+					return fmt.Errorf("template %d too small", t.ID)
+				}
+				f.ID &^= 0x8000
+				f.Enterprise = binary.BigEndian.Uint32(data[0:4])
+				data = data[4:]
+			}
+			if m.Version == 9 && i < t.ScopeFieldCount {
+				f.Field = v9Scope(f.ID)
+			}
+			t.Fields[i] = f
+		}
+		d.mu.Lock()
+		d.templates[d.key(m, t.ID)] = t
+		d.mu.Unlock()
+		m.Templates = append(m.Templates, t)
+	}
+	return nil
+}
+
+// v9Scope returns the information element which corresponds to a scope field
+// type of NetFlow v9, whose values overlap with other field types.
+func v9Scope(typ uint16) Field {
+	switch typ {
+	case 1: // System
+		return FieldExportingProcessID
+	case 2: // Interface
+		return FieldIngressInterface
+	case 3: // Line card
+		return FieldLineCardID
+	case 4: // Cache
+		return FieldMeteringProcessID
+	case 5: // Template
+		return FieldTemplateID
+	}
+	return Field{ID: typ}
+}
+
+// decodeData decodes the records of a data set. Data sets may end with
+// padding, which is shorter than a record.
+func (d *Decoder) decodeData(m *Message, id uint16, data []byte) error {
+	d.mu.Lock()
+	t := d.templates[d.key(m, id)]
+	d.mu.Unlock()
+	if t == nil {
+		m.MissingTemplates = append(m.MissingTemplates, id)
+		return nil
+	}
+	minLen := t.minLen()
+	if minLen == 0 {
+		return nil
+	}
+	for len(data) >= minLen {
+		rec := Record{TemplateID: id, Fields: make(Fields, len(t.Fields)-t.ScopeFieldCount)}
+		if t.ScopeFieldCount > 0 {
+			rec.Scope = make(Fields, t.ScopeFieldCount)
+		}
+		for i, f := range t.Fields {
+			n := int(f.Length)
+			if f.Length == VariableLength {
+				if len(data) < 1 {
+					return fmt.Errorf("record of template %d too small", id)
+				}
+				n, data = int(data[0]), data[1:]
+				if n == 255 {
+					if len(data) < 2 {
+						return fmt.Errorf("record of template %d too small", id)
+					}
+					n, data = int(binary.BigEndian.Uint16(data[0:2])), data[2:]
+				}
+			}
+			if n > len(data) {
+				return fmt.Errorf("record of template %d too small", id)
+			}
+			if i < t.ScopeFieldCount {
+				rec.Scope[f.Field] = decodeValue(f.Field, data[:n])
+			} else {
+				rec.Fields[f.Field] = decodeValue(f.Field, data[:n])
+			}
+			data = data[n:]
+		}
+		m.Records = append(m.Records, rec)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package netflow_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/netflow"
+)
+
+var exporter = netip.MustParseAddrPort("192.0.2.1:2055")
+
+func u16(v uint16) []byte { return []byte{byte(v >> 8), byte(v)} }
+func u32(v uint32) []byte { return []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)} }
+
+func concat(bs ...[]byte) []byte {
+	var b []byte
+	for _, x := range bs {
+		b = append(b, x...)
+	}
+	return b
+}
+
+func set(id uint16, body ...[]byte) []byte {
+	b := concat(body...)
+	return concat(u16(id), u16(uint16(4+len(b))), b)
+}
+
+func ipfix(domain uint32, sets ...[]byte) []byte {
+	b := concat(sets...)
+	return concat(u16(10), u16(uint16(16+len(b))), u32(1700000000), u32(7), u32(domain), b)
+}
+
+func TestDecodeV5(t *testing.T) {
+	hdr := concat(u16(5), u16(1), u32(60000), u32(1700000000), u32(500000000), u32(42),
+		[]byte{1, 2}, u16(0x4000|100))
+	rec := make([]byte, 48)
+	copy(rec[0:4], []byte{10, 0, 0, 1})
+	copy(rec[4:8], []byte{10, 0, 0, 2})
+	binary.BigEndian.PutUint32(rec[16:20], 3)
+	binary.BigEndian.PutUint32(rec[20:24], 180)
+	binary.BigEndian.PutUint32(rec[24:28], 59000)
+	binary.BigEndian.PutUint16(rec[32:34], 1234)
+	binary.BigEndian.PutUint16(rec[34:36], 53)
+	rec[38] = 17
+
+	m, err := netflow.NewDecoder().Decode(exporter, concat(hdr, rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != 5 || m.Sequence != 42 || m.ObservationDomain != 0x0102 || m.SamplingInterval != 100 {
+		t.Fatalf("unexpected header %+v", m)
+	}
+	if len(m.Records) != 1 {
+		t.Fatalf("got %d records, want 1", len(m.Records))
+	}
+	f := m.Records[0].Fields
+	if f[netflow.FieldSourceIPv4Address] != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("source is %v", f[netflow.FieldSourceIPv4Address])
+	}
+	if f[netflow.FieldOctetDeltaCount] != uint64(180) || f[netflow.FieldProtocolIdentifier] != uint64(17) ||
+		f[netflow.FieldDestinationTransportPort] != uint64(53) {
+		t.Errorf("unexpected fields %v", f)
+	}
+	start := m.UptimeTime(f[netflow.FieldFlowStartSysUpTime].(uint64))
+	if want := time.Unix(1700000000-1, 500000000).UTC(); !start.Equal(want) {
+		t.Errorf("flow start is %v, want %v", start, want)
+	}
+
+	if _, err := netflow.NewDecoder().Decode(exporter, hdr); err == nil {
+		t.Error("truncated v5 packet decoded without error")
+	}
+}
+
+func TestDecodeV9(t *testing.T) {
+	d := netflow.NewDecoder()
+	hdr := concat(u16(9), u16(2), u32(1000), u32(1700000000), u32(1), u32(5))
+	data := set(256, []byte{10, 0, 0, 1}, u32(99), u16(443))
+	tmpl := set(0, u16(256), u16(3), u16(8), u16(4), u16(1), u16(4), u16(7), u16(2))
+	opts := set(1, u16(257), u16(4), u16(4), u16(2), u16(4), u16(34), u16(4))
+	optsData := set(257, u32(3), u32(1000))
+
+	// Data before its template is reported as missing
+	m, err := d.Decode(exporter, concat(hdr, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Records) != 0 || len(m.MissingTemplates) != 1 || m.MissingTemplates[0] != 256 {
+		t.Fatalf("unexpected message %+v", m)
+	}
+
+	m, err = d.Decode(exporter, concat(hdr, tmpl, opts, data, optsData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Templates) != 2 || len(m.Records) != 2 {
+		t.Fatalf("got %d templates and %d records", len(m.Templates), len(m.Records))
+	}
+	f := m.Records[0].Fields
+	if f[netflow.FieldSourceIPv4Address] != netip.MustParseAddr("10.0.0.1") ||
+		f[netflow.FieldOctetDeltaCount] != uint64(99) || f[netflow.FieldSourceTransportPort] != uint64(443) {
+		t.Errorf("unexpected fields %v", f)
+	}
+	o := m.Records[1]
+	if o.TemplateID != 257 || o.Scope[netflow.FieldIngressInterface] != uint64(3) ||
+		o.Fields[netflow.FieldSamplingInterval] != uint64(1000) {
+		t.Errorf("unexpected options record %+v", o)
+	}
+
+	// Templates are cached per exporter
+	other := netip.MustParseAddrPort("192.0.2.2:2055")
+	if m, _ := d.Decode(other, concat(hdr, data)); len(m.MissingTemplates) != 1 {
+		t.Errorf("template of another exporter was used")
+	}
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	d := netflow.NewDecoder()
+	vendor := netflow.Field{Enterprise: 9, ID: 12235}
+	d.Decode(exporter, ipfix(1, set(2,
+		u16(300), u16(4),
+		u16(27), u16(16),
+		u16(82), u16(netflow.VariableLength),
+		u16(0x8000|12235), u16(2), u32(9),
+		u16(0x8000|1), u16(8), u32(netflow.ReversePEN),
+	)))
+
+	src := netip.MustParseAddr("2001:db8::1")
+	rec := concat(src.AsSlice(), []byte{4}, []byte("eth0"), u16(0xBEEF), make([]byte, 7), []byte{200})
+	long := strings.Repeat("x", 300)
+	rec2 := concat(src.AsSlice(), []byte{255}, u16(300), []byte(long), u16(1), make([]byte, 8))
+	m, err := d.Decode(exporter, ipfix(1, set(300, rec, rec2, []byte{0, 0})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Records) != 2 {
+		t.Fatalf("got %d records, want 2", len(m.Records))
+	}
+	f := m.Records[0].Fields
+	if f[netflow.FieldSourceIPv6Address] != src || f[netflow.FieldInterfaceName] != "eth0" {
+		t.Errorf("unexpected fields %v", f)
+	}
+	if b, ok := f[vendor].([]byte); !ok || binary.BigEndian.Uint16(b) != 0xBEEF {
+		t.Errorf("enterprise field is %v", f[vendor])
+	}
+	reverse := netflow.Field{Enterprise: netflow.ReversePEN, ID: 1}
+	if f[reverse] != uint64(200) || reverse.String() != "reverseOctetDeltaCount" {
+		t.Errorf("reverse field %v is %v", reverse, f[reverse])
+	}
+	if m.Records[1].Fields[netflow.FieldInterfaceName] != long {
+		t.Error("long variable length field was not decoded")
+	}
+
+	netflow.RegisterField(vendor, "vendorCounter", netflow.DataTypeUnsigned)
+	m, _ = d.Decode(exporter, ipfix(1, set(300, rec)))
+	if m.Records[0].Fields[vendor] != uint64(0xBEEF) {
+		t.Errorf("registered field is %v", m.Records[0].Fields[vendor])
+	}
+	b, err := json.Marshal(m.Records[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"vendorCounter":48879`) || !strings.Contains(string(b), `"sourceIPv6Address":"2001:db8::1"`) {
+		t.Errorf("unexpected json %s", b)
+	}
+
+	// Templates are cached per observation domain, and can be withdrawn
+	if m, _ := d.Decode(exporter, ipfix(2, set(300, rec))); len(m.MissingTemplates) != 1 {
+		t.Error("template of another observation domain was used")
+	}
+	d.Decode(exporter, ipfix(1, set(2, u16(300), u16(0))))
+	if m, _ := d.Decode(exporter, ipfix(1, set(300, rec))); len(m.MissingTemplates) != 1 {
+		t.Error("withdrawn template was used")
+	}
+}
+
+func TestServe(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	client.Write(ipfix(1, set(2, u16(256), u16(1), u16(4), u16(1))))
+
+	msgs := make(chan *netflow.Message, 1)
+	go netflow.NewDecoder().Serve(conn, func(m *netflow.Message, err error) {
+		if err == nil {
+			msgs <- m
+		}
+	})
+	select {
+	case m := <-msgs:
+		if !m.Exporter.Addr().IsLoopback() || len(m.Templates) != 1 {
+			t.Errorf("unexpected message %+v", m)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message was received")
+	}
+}
@@ -0,0 +1,213 @@
+package netlab
+
+import (
+	"errors"
+	"log"
+	"net/netip"
+	"sync"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// NAT is a source NAT element, which masquerades connections from inside
+// hosts behind an external address. Outbound handles packets leaving through
+// the external interface, and Inbound handles packets arriving on it.
+//
+// Each outbound flow is mapped to a free external port, which stays in use
+// until the flow is unmapped. ICMP echo requests are mapped like flows, with
+// the echo identifier as the port. Outbound packets of new flows are dropped
+// when all ports are in use. Inbound packets which do not belong to a mapped
+// flow are dropped. ICMP errors concerning mapped flows are translated along
+// with the packet they quote, in both directions. Other packets, such as
+// non-first fragments, would leak inside addresses, and are dropped.
+type NAT struct {
+	External netip.Addr
+
+	// MinPort and MaxPort are the range of external ports. They must not be
+	// changed once packets are handled.
+	MinPort uint16
+	MaxPort uint16
+
+	mu       sync.Mutex
+	nextPort uint16
+	// out maps inside flows to their external endpoint
+	out map[packet.Flow]netip.AddrPort
+	// in maps flows towards the external endpoint to the inside endpoint
+	in map[packet.Flow]netip.AddrPort
+	// ports contains the external ports in use
+	ports map[uint16]bool
+}
+
+// NewNAT returns a NAT element which translates to the external address,
+// using the external ports 20000-65535.
+func NewNAT(external netip.Addr) *NAT {
+	return &NAT{
+		External: external,
+		MinPort:  20000,
+		MaxPort:  65535,
+		nextPort: 20000,
+		out:      make(map[packet.Flow]netip.AddrPort),
+		in:       make(map[packet.Flow]netip.AddrPort),
+		ports:    make(map[uint16]bool),
+	}
+}
+
+// allocPort returns the next free external port. n.mu must be held.
+func (n *NAT) allocPort() (uint16, error) {
+	for i := 0; i <= int(n.MaxPort-n.MinPort); i++ {
+		port := n.nextPort
+		if port < n.MinPort || port > n.MaxPort {
+			port = n.MinPort
+		}
+		n.nextPort = port + 1
+		if !n.ports[port] {
+			n.ports[port] = true
+			return port, nil
+		}
+	}
+	return 0, errors.New("no free external ports")
+}
+
+// Unmap removes the mapping of an inside flow, such as when its connection is
+// closed, which frees its external port.
+func (n *NAT) Unmap(flow packet.Flow) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	ext, ok := n.out[flow]
+	if !ok {
+		return
+	}
+	delete(n.out, flow)
+	delete(n.in, packet.Flow{Proto: flow.Proto, Src: flow.Dst, Dst: ext})
+	delete(n.ports, ext.Port())
+}
+
+// isEchoRequest reports whether p is an ICMP or ICMPv6 echo request.
+func isEchoRequest(p packet.Packet) bool {
+	switch t := p.Transport.(type) {
+	case *packet.ICMP:
+		return t.ICMPType == packet.ICMPTypeEchoRequest
+	case *packet.ICMPv6:
+		return t.ICMPType == packet.ICMPv6TypeEchoRequest
+	}
+	return false
+}
+
+// Outbound rewrites the source of packets from the inside to the external
+// address. It is a PacketHandler.
+func (n *NAT) Outbound(p *NodePacket) Verdict {
+	flow, ok := p.Packet.Flow()
+	echo := !ok && isEchoRequest(p.Packet)
+	if echo {
+		flow, ok = p.Packet.EchoFlow()
+	}
+	if !ok {
+		// ICMP errors quote the packet which was received by the inside host
+		quoted, isErr := p.Packet.QuotedFlow()
+		if !isErr {
+			return VerdictDrop
+		}
+		return n.outboundError(p, quoted.Reverse())
+	}
+	n.mu.Lock()
+	ext, ok := n.out[flow]
+	if !ok {
+		port, err := n.allocPort()
+		if err != nil {
+			n.mu.Unlock()
+			log.Println("nat: failed to map flow,", err)
+			return VerdictDrop
+		}
+		ext = netip.AddrPortFrom(n.External, port)
+		n.out[flow] = ext
+		n.in[packet.Flow{Proto: flow.Proto, Src: flow.Dst, Dst: ext}] = flow.Src
+	}
+	n.mu.Unlock()
+
+	if err := p.Packet.SetSource(ext.Addr()); err != nil {
+		log.Println("nat: failed to rewrite source,", err)
+		return VerdictDrop
+	}
+	if echo {
+		if err := p.Packet.SetEchoID(ext.Port()); err != nil {
+			log.Println("nat: failed to rewrite echo identifier,", err)
+			return VerdictDrop
+		}
+		return VerdictAccept
+	}
+	if err := p.Packet.SetSourcePort(ext.Port()); err != nil {
+		log.Println("nat: failed to rewrite source port,", err)
+		return VerdictDrop
+	}
+	return VerdictAccept
+}
+
+// outboundError translates an ICMP error sent from the inside about a packet
+// of the mapped inside flow.
+func (n *NAT) outboundError(p *NodePacket, flow packet.Flow) Verdict {
+	n.mu.Lock()
+	ext, ok := n.out[flow]
+	n.mu.Unlock()
+	if !ok {
+		return VerdictDrop
+	}
+
+	// The quoted destination is only rewritten along with a source which
+	// equals it, so errors sent by inside routers take the address of the
+	// inside host first
+	for _, addr := range []netip.Addr{flow.Src.Addr(), ext.Addr()} {
+		if err := p.Packet.SetSource(addr); err != nil {
+			log.Println("nat: failed to rewrite source,", err)
+			return VerdictDrop
+		}
+	}
+	if err := p.Packet.SetSourcePort(ext.Port()); err != nil {
+		log.Println("nat: failed to rewrite source port,", err)
+		return VerdictDrop
+	}
+	return VerdictAccept
+}
+
+// Inbound rewrites the destination of replies and ICMP errors to the inside
+// host of the flow. It is a PacketHandler.
+func (n *NAT) Inbound(p *NodePacket) Verdict {
+	flow, ok := p.Packet.Flow()
+	var echo bool
+	if !ok && !isEchoRequest(p.Packet) {
+		// Echo replies are mapped by the identifier of their request
+		flow, echo = p.Packet.EchoFlow()
+		ok = echo
+	}
+	if !ok {
+		// ICMP errors quote the packet which was sent from the external
+		// address
+		quoted, isErr := p.Packet.QuotedFlow()
+		if !isErr {
+			return VerdictDrop
+		}
+		flow = quoted.Reverse()
+	}
+	n.mu.Lock()
+	inside, ok := n.in[flow]
+	n.mu.Unlock()
+	if !ok {
+		return VerdictDrop
+	}
+
+	if err := p.Packet.SetDestination(inside.Addr()); err != nil {
+		log.Println("nat: failed to rewrite destination,", err)
+		return VerdictDrop
+	}
+	if echo {
+		if err := p.Packet.SetEchoID(inside.Port()); err != nil {
+			log.Println("nat: failed to rewrite echo identifier,", err)
+			return VerdictDrop
+		}
+		return VerdictAccept
+	}
+	if err := p.Packet.SetDestinationPort(inside.Port()); err != nil {
+		log.Println("nat: failed to rewrite destination port,", err)
+		return VerdictDrop
+	}
+	return VerdictAccept
+}
@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/netip"
 
+	"github.com/sebnyberg/net/filter"
 	"github.com/sebnyberg/net/packet"
 )
 
@@ -242,6 +243,17 @@ const (
 // PacketHandler is called on ingress, local delivery, and egress.
 type PacketHandler func(p *NodePacket) Verdict
 
+// Filtered returns a handler which calls h only for packets matching the filter.
+// Other packets are accepted as-is.
+func Filtered(f *filter.Filter, h PacketHandler) PacketHandler {
+	return func(p *NodePacket) Verdict {
+		if !f.Match(&p.Packet) {
+			return VerdictAccept
+		}
+		return h(p)
+	}
+}
+
 // NodePacket describes a packet flowing through a node's routing system.
 // It is roughly equivalent to sk_buffer in Linux.
 type NodePacket struct {
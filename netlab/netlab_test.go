@@ -2,11 +2,14 @@ package netlab_test
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"net/netip"
 	"testing"
 
+	"github.com/sebnyberg/net/internal/packettest"
 	"github.com/sebnyberg/net/netlab"
+	"github.com/sebnyberg/net/packet"
 )
 
 func requireNoError(t *testing.T, v error, args ...any) {
@@ -37,3 +40,208 @@ func TestNetlab(t *testing.T) {
 
 	eth01.Send(context.Background(), []byte("hi"))
 }
+
+func TestNAT(t *testing.T) {
+	var (
+		inside = netip.MustParseAddrPort("192.168.1.10:5000")
+		remote = netip.MustParseAddrPort("93.184.216.34:53")
+	)
+	nat := netlab.NewNAT(netip.MustParseAddr("203.0.113.7"))
+
+	pkt, err := packet.Decode(packettest.UDPFrame(inside, remote, nil))
+	requireNoError(t, err)
+	out := &netlab.NodePacket{Packet: pkt}
+	if v := nat.Outbound(out); v != netlab.VerdictAccept {
+		t.Fatalf("outbound verdict = %v", v)
+	}
+	ext, _ := out.Packet.Flow()
+	if ext.Src.Addr() != nat.External || ext.Dst != remote {
+		t.Fatalf("unexpected translated flow %v", ext)
+	}
+
+	pkt, err = packet.Decode(packettest.UDPFrame(remote, ext.Src, nil))
+	requireNoError(t, err)
+	in := &netlab.NodePacket{Packet: pkt}
+	if v := nat.Inbound(in); v != netlab.VerdictAccept {
+		t.Fatalf("inbound verdict = %v", v)
+	}
+	if flow, _ := in.Packet.Flow(); flow.Dst != inside {
+		t.Errorf("reply destination = %v, want %v", flow.Dst, inside)
+	}
+
+	pkt, err = packet.Decode(packettest.UDPFrame(remote, netip.MustParseAddrPort("203.0.113.7:1"), nil))
+	requireNoError(t, err)
+	if v := nat.Inbound(&netlab.NodePacket{Packet: pkt}); v != netlab.VerdictDrop {
+		t.Errorf("unmapped inbound verdict = %v, want drop", v)
+	}
+}
+
+func TestNATPorts(t *testing.T) {
+	remote := netip.MustParseAddrPort("93.184.216.34:53")
+	nat := netlab.NewNAT(netip.MustParseAddr("203.0.113.7"))
+	nat.MinPort, nat.MaxPort = 65534, 65535
+
+	outbound := func(port uint16) (packet.Flow, uint16, netlab.Verdict) {
+		pkt, err := packet.Decode(packettest.UDPFrame(netip.AddrPortFrom(netip.MustParseAddr("192.168.1.10"), port), remote, nil))
+		requireNoError(t, err)
+		flow, _ := pkt.Flow()
+		p := &netlab.NodePacket{Packet: pkt}
+		v := nat.Outbound(p)
+		ext, _ := p.Packet.Flow()
+		return flow, ext.Src.Port(), v
+	}
+	first, port, _ := outbound(5000)
+	if port != 65534 {
+		t.Fatalf("first port = %d, want 65534", port)
+	}
+	if _, port, _ = outbound(5001); port != 65535 {
+		t.Fatalf("second port = %d, want 65535", port)
+	}
+
+	// The pool is exhausted without wrapping over ports in use
+	if _, _, v := outbound(5002); v != netlab.VerdictDrop {
+		t.Fatalf("verdict = %v, want drop", v)
+	}
+
+	// Unmapped ports are reused, and skipped while in use
+	nat.Unmap(first)
+	if _, port, v := outbound(5003); v != netlab.VerdictAccept || port != 65534 {
+		t.Errorf("got port %d, verdict %v, want 65534", port, v)
+	}
+	if _, port, _ = outbound(5001); port != 65535 {
+		t.Errorf("mapped flow moved to port %d", port)
+	}
+}
+
+// icmpFrame returns an Ethernet frame with an ICMP message with a valid
+// checksum, whose header carries the echo identifier id and is followed by
+// body.
+func icmpFrame(src, dst netip.Addr, typ byte, id uint16, body []byte) []byte {
+	icmp := append([]byte{typ, 0, 0, 0, byte(id >> 8), byte(id), 0, 0}, body...)
+	return packettest.Ethernet(packettest.IPv4(src, dst, 1, icmp, 2))
+}
+
+func TestNATEcho(t *testing.T) {
+	var (
+		inside = netip.MustParseAddr("192.168.1.10")
+		remote = netip.MustParseAddr("93.184.216.34")
+	)
+	nat := netlab.NewNAT(netip.MustParseAddr("203.0.113.7"))
+
+	pkt, err := packet.Decode(icmpFrame(inside, remote, 8, 1234, []byte("ping")))
+	requireNoError(t, err)
+	out := &netlab.NodePacket{Packet: pkt}
+	if v := nat.Outbound(out); v != netlab.VerdictAccept {
+		t.Fatalf("outbound verdict = %v", v)
+	}
+	// The identifier is mapped like a port
+	ext, _ := out.Packet.EchoFlow()
+	if ext.Src.Addr() != nat.External || ext.Src.Port() != 20000 || ext.Dst.Addr() != remote {
+		t.Fatalf("unexpected translated echo flow %v", ext)
+	}
+	msg := out.Packet.Transport.(*packet.ICMP)
+	if packettest.OnesSum(msg.Contents, 0) != 0xFFFF {
+		t.Errorf("invalid icmp checksum")
+	}
+
+	pkt, err = packet.Decode(icmpFrame(remote, nat.External, 0, ext.Src.Port(), []byte("ping")))
+	requireNoError(t, err)
+	in := &netlab.NodePacket{Packet: pkt}
+	if v := nat.Inbound(in); v != netlab.VerdictAccept {
+		t.Fatalf("inbound verdict = %v", v)
+	}
+	reply, _ := in.Packet.EchoFlow()
+	if reply.Dst != netip.AddrPortFrom(inside, 1234) {
+		t.Errorf("reply destination = %v, want %v:1234", reply.Dst, inside)
+	}
+	if packettest.OnesSum(in.Packet.Transport.(*packet.ICMP).Contents, 0) != 0xFFFF {
+		t.Errorf("invalid icmp checksum")
+	}
+
+	// Replies to unmapped identifiers, and requests from outside, are dropped
+	for _, frame := range [][]byte{
+		icmpFrame(remote, nat.External, 0, 1234, nil),
+		icmpFrame(remote, nat.External, 8, 20000, nil),
+	} {
+		pkt, err = packet.Decode(frame)
+		requireNoError(t, err)
+		if v := nat.Inbound(&netlab.NodePacket{Packet: pkt}); v != netlab.VerdictDrop {
+			t.Errorf("unmapped inbound echo verdict = %v, want drop", v)
+		}
+	}
+}
+
+func TestNATOutboundICMPError(t *testing.T) {
+	var (
+		inside = netip.MustParseAddrPort("192.168.1.10:5000")
+		remote = netip.MustParseAddrPort("93.184.216.34:53")
+		router = netip.MustParseAddr("192.168.1.1")
+	)
+	nat := netlab.NewNAT(netip.MustParseAddr("203.0.113.7"))
+	pkt, err := packet.Decode(packettest.UDPFrame(inside, remote, nil))
+	requireNoError(t, err)
+	out := &netlab.NodePacket{Packet: pkt}
+	if v := nat.Outbound(out); v != netlab.VerdictAccept {
+		t.Fatalf("outbound verdict = %v", v)
+	}
+	ext, _ := out.Packet.Flow()
+
+	// Errors about the inbound packets of the flow, sent by the inside host or
+	// by a router on the inside, quote the inside endpoint
+	quoted := packettest.UDPFrame(remote, inside, []byte("reply"))[14:]
+	for _, src := range []netip.Addr{inside.Addr(), router} {
+		pkt, err := packet.Decode(icmpFrame(src, remote.Addr(), 3, 0, quoted))
+		requireNoError(t, err)
+		p := &netlab.NodePacket{Packet: pkt}
+		if v := nat.Outbound(p); v != netlab.VerdictAccept {
+			t.Fatalf("%v: outbound verdict = %v", src, v)
+		}
+		ip := p.Packet.Network.(*packet.IPv4)
+		if ip.Source != nat.External || packettest.OnesSum(ip.Contents[:20], 0) != 0xFFFF {
+			t.Errorf("%v: unexpected source %v", src, ip.Source)
+		}
+		if flow, _ := p.Packet.QuotedFlow(); flow != ext.Reverse() {
+			t.Errorf("%v: quoted flow = %v, want %v", src, flow, ext.Reverse())
+		}
+		msg := p.Packet.Transport.(*packet.ICMP)
+		if packettest.OnesSum(msg.Contents, 0) != 0xFFFF || packettest.OnesSum(msg.Payload[:20], 0) != 0xFFFF {
+			t.Errorf("%v: invalid checksums", src)
+		}
+	}
+
+	// Errors about unmapped flows are dropped
+	other := packettest.UDPFrame(remote, netip.MustParseAddrPort("192.168.1.11:5000"), nil)[14:]
+	pkt, err = packet.Decode(icmpFrame(inside.Addr(), remote.Addr(), 3, 0, other))
+	requireNoError(t, err)
+	if v := nat.Outbound(&netlab.NodePacket{Packet: pkt}); v != netlab.VerdictDrop {
+		t.Errorf("unmapped error verdict = %v, want drop", v)
+	}
+}
+
+func TestNATOutboundDrop(t *testing.T) {
+	var (
+		inside = netip.MustParseAddr("192.168.1.10")
+		remote = netip.MustParseAddr("93.184.216.34")
+	)
+	nat := netlab.NewNAT(netip.MustParseAddr("203.0.113.7"))
+
+	// A non-first fragment, which carries no ports
+	frag := packettest.IPv4(inside, remote, 17, make([]byte, 16), -1)
+	frag[7] = 2
+	frag[10], frag[11] = 0, 0
+	binary.BigEndian.PutUint16(frag[10:12], ^packettest.OnesSum(frag[:20], 0))
+
+	for name, frame := range map[string][]byte{
+		"fragment":   packettest.Ethernet(frag),
+		"gre":        packettest.Ethernet(packettest.IPv4(inside, remote, 47, make([]byte, 4), -1)),
+		"echo reply": icmpFrame(inside, remote, 0, 1234, nil),
+	} {
+		pkt, _ := packet.Decode(frame)
+		if pkt.Network == nil {
+			t.Fatalf("%s: no network layer", name)
+		}
+		if v := nat.Outbound(&netlab.NodePacket{Packet: pkt}); v != netlab.VerdictDrop {
+			t.Errorf("%s: verdict = %v, want drop", name, v)
+		}
+	}
+}
@@ -2,13 +2,17 @@ package packet
 
 import (
 	"encoding/binary"
-	"errors"
+	"encoding/json"
+	"fmt"
 	"net"
 	"net/netip"
 )
 
-// Interface guard
-var _ Layer = new(ARP)
+// Interface guards
+var (
+	_ Layer          = new(ARP)
+	_ json.Marshaler = new(ARP)
+)
 
 type ARPType uint16
 
@@ -57,33 +61,34 @@ type ARP struct {
 
 func (a *ARP) Unmarshal(data []byte) error {
 	if len(data) < 8 {
-		return errors.New("ARP too short")
+		return fmt.Errorf("ARP too short: %w", ErrTruncated)
 	}
 	a.HType = ARPType(binary.BigEndian.Uint16(data[0:2]))
 	a.PType = EtherType(binary.BigEndian.Uint16(data[2:4]))
 	a.HLen = data[4]
 	a.PLen = data[5]
 	if len(data) < int(8+a.HLen*2+a.PLen*2) {
-		return errors.New("invalid ARP packet len")
+		return fmt.Errorf("invalid ARP packet len: %w", ErrInvalid)
 	}
 	a.Oper = ARPOpCode(binary.BigEndian.Uint16(data[6:8]))
 	a.SourceHW = net.HardwareAddr(data[8 : 8+a.HLen])
 	var ok bool
 	a.SourceIP, ok = netip.AddrFromSlice(data[8+a.HLen : 8+a.HLen+a.PLen])
 	if !ok {
-		return errors.New("invalid source IP addr")
+		return fmt.Errorf("invalid source IP addr: %w", ErrInvalid)
 	}
 	a.DestHW = net.HardwareAddr(data[8+a.HLen+a.PLen : 8+a.HLen*2+a.PLen])
 	a.DestIP, ok = netip.AddrFromSlice(data[8+a.HLen*2+a.PLen : 8+a.HLen*2+a.PLen*2])
 	if !ok {
-		return errors.New("invalid source IP addr")
+		return fmt.Errorf("invalid source IP addr: %w", ErrInvalid)
 	}
 	a.Contents = data
 	a.Payload = data[8+a.HLen*2+a.PLen*2:]
 	return nil
 }
+
 func (e ARP) Type() LayerType {
-	return LayerTypeEthernet
+	return LayerTypeARP
 }
 
 func (e ARP) GetContents() []byte {
@@ -93,3 +98,31 @@ func (e ARP) GetContents() []byte {
 func (e ARP) GetPayload() []byte {
 	return e.Payload
 }
+
+func (e ARP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string     `json:"type"`
+		HType    string     `json:"htype"`
+		PType    string     `json:"ptype"`
+		HLen     byte       `json:"hlen"`
+		PLen     byte       `json:"plen"`
+		Oper     string     `json:"oper"`
+		SourceHW string     `json:"source_hw"`
+		SourceIP netip.Addr `json:"source_ip"`
+		DestHW   string     `json:"dest_hw"`
+		DestIP   netip.Addr `json:"dest_ip"`
+		Length   int        `json:"length"`
+	}{
+		Type:     e.Type().String(),
+		HType:    e.HType.String(),
+		PType:    e.PType.String(),
+		HLen:     e.HLen,
+		PLen:     e.PLen,
+		Oper:     e.Oper.String(),
+		SourceHW: e.SourceHW.String(),
+		SourceIP: e.SourceIP,
+		DestHW:   e.DestHW.String(),
+		DestIP:   e.DestIP,
+		Length:   len(e.Contents),
+	})
+}
@@ -0,0 +1,768 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(BGP)
+	_ json.Marshaler = new(BGP)
+)
+
+type BGPMessageType uint8
+
+const (
+	BGPMessageTypeOpen         BGPMessageType = 1
+	BGPMessageTypeUpdate       BGPMessageType = 2
+	BGPMessageTypeNotification BGPMessageType = 3
+	BGPMessageTypeKeepalive    BGPMessageType = 4
+	BGPMessageTypeRouteRefresh BGPMessageType = 5
+)
+
+type BGPCapabilityCode uint8
+
+const (
+	BGPCapabilityCodeMultiprotocol        BGPCapabilityCode = 1
+	BGPCapabilityCodeRouteRefresh         BGPCapabilityCode = 2
+	BGPCapabilityCodeExtendedNextHop      BGPCapabilityCode = 5
+	BGPCapabilityCodeExtendedMessage      BGPCapabilityCode = 6
+	BGPCapabilityCodeGracefulRestart      BGPCapabilityCode = 64
+	BGPCapabilityCodeFourOctetAS          BGPCapabilityCode = 65
+	BGPCapabilityCodeAddPath              BGPCapabilityCode = 69
+	BGPCapabilityCodeEnhancedRouteRefresh BGPCapabilityCode = 70
+)
+
+// BGPAFI is an Address Family Identifier.
+type BGPAFI uint16
+
+const (
+	BGPAFIIPv4 BGPAFI = 1
+	BGPAFIIPv6 BGPAFI = 2
+)
+
+// BGPSAFI is a Subsequent Address Family Identifier.
+type BGPSAFI uint8
+
+const (
+	BGPSAFIUnicast   BGPSAFI = 1
+	BGPSAFIMulticast BGPSAFI = 2
+)
+
+type BGPAttributeType uint8
+
+const (
+	BGPAttributeTypeOrigin              BGPAttributeType = 1
+	BGPAttributeTypeASPath              BGPAttributeType = 2
+	BGPAttributeTypeNextHop             BGPAttributeType = 3
+	BGPAttributeTypeMED                 BGPAttributeType = 4
+	BGPAttributeTypeLocalPref           BGPAttributeType = 5
+	BGPAttributeTypeAtomicAggregate     BGPAttributeType = 6
+	BGPAttributeTypeAggregator          BGPAttributeType = 7
+	BGPAttributeTypeCommunities         BGPAttributeType = 8
+	BGPAttributeTypeOriginatorID        BGPAttributeType = 9
+	BGPAttributeTypeClusterList         BGPAttributeType = 10
+	BGPAttributeTypeMPReachNLRI         BGPAttributeType = 14
+	BGPAttributeTypeMPUnreachNLRI       BGPAttributeType = 15
+	BGPAttributeTypeExtendedCommunities BGPAttributeType = 16
+	BGPAttributeTypeAS4Path             BGPAttributeType = 17
+	BGPAttributeTypeAS4Aggregator       BGPAttributeType = 18
+	BGPAttributeTypeLargeCommunities    BGPAttributeType = 32
+)
+
+// BGPAttributeFlags are the flags of a path attribute.
+type BGPAttributeFlags uint8
+
+func (f BGPAttributeFlags) Optional() bool       { return f&0x80 != 0 }
+func (f BGPAttributeFlags) Transitive() bool     { return f&0x40 != 0 }
+func (f BGPAttributeFlags) Partial() bool        { return f&0x20 != 0 }
+func (f BGPAttributeFlags) ExtendedLength() bool { return f&0x10 != 0 }
+
+type BGPOrigin uint8
+
+const (
+	BGPOriginIGP        BGPOrigin = 0
+	BGPOriginEGP        BGPOrigin = 1
+	BGPOriginIncomplete BGPOrigin = 2
+)
+
+type BGPSegmentType uint8
+
+const (
+	BGPSegmentTypeSet            BGPSegmentType = 1
+	BGPSegmentTypeSequence       BGPSegmentType = 2
+	BGPSegmentTypeConfedSequence BGPSegmentType = 3
+	BGPSegmentTypeConfedSet      BGPSegmentType = 4
+)
+
+type BGPErrorCode uint8
+
+const (
+	BGPErrorCodeMessageHeader    BGPErrorCode = 1
+	BGPErrorCodeOpenMessage      BGPErrorCode = 2
+	BGPErrorCodeUpdateMessage    BGPErrorCode = 3
+	BGPErrorCodeHoldTimerExpired BGPErrorCode = 4
+	BGPErrorCodeFSM              BGPErrorCode = 5
+	BGPErrorCodeCease            BGPErrorCode = 6
+	BGPErrorCodeRouteRefresh     BGPErrorCode = 7
+)
+
+// BGPHeaderLen is the length of the header of BGP messages, which starts with
+// a marker of 16 0xFF octets.
+const BGPHeaderLen = 19
+
+// BGP is a BGP-4 message (RFC 4271). The body of OPEN, UPDATE and
+// NOTIFICATION messages is decoded into the field of the message type.
+//
+// A TCP segment need not contain a whole message, so BGP layers are only
+// decoded from segments which start with a complete message. The bgpflow
+// package decodes all messages of reassembled streams.
+type BGP struct {
+	Length       uint16
+	MessageType  BGPMessageType
+	Open         *BGPOpen
+	Update       *BGPUpdate
+	Notification *BGPNotification
+	PacketBytes
+}
+
+// Unmarshal decodes the first message in data. The size of the AS numbers of
+// UPDATE messages is detected from the encoding of the AS_PATH attribute.
+func (b *BGP) Unmarshal(data []byte) error {
+	return b.unmarshal(data, 0)
+}
+
+// UnmarshalAS decodes the first message in data like Unmarshal, but with AS
+// numbers of the size negotiated by the peers: 4 octets if both advertised
+// the four-octet AS capability, and 2 otherwise.
+func (b *BGP) UnmarshalAS(data []byte, fourOctetAS bool) error {
+	if fourOctetAS {
+		return b.unmarshal(data, 4)
+	}
+	return b.unmarshal(data, 2)
+}
+
+func (b *BGP) unmarshal(data []byte, asLen int) error {
+	if len(data) < BGPHeaderLen {
+		return fmt.Errorf("bgp message too small: %w", ErrTruncated)
+	}
+	*b = BGP{}
+	for _, c := range data[:16] {
+		if c != 0xFF {
+			return fmt.Errorf("invalid bgp marker: %w", ErrInvalid)
+		}
+	}
+	b.Length = binary.BigEndian.Uint16(data[16:18])
+	b.MessageType = BGPMessageType(data[18])
+	if b.Length < BGPHeaderLen || int(b.Length) > len(data) {
+		return fmt.Errorf("invalid bgp message length: %w", ErrInvalid)
+	}
+	b.Contents = data[:b.Length]
+	b.Payload = data[BGPHeaderLen:b.Length]
+
+	var err error
+	switch b.MessageType {
+	case BGPMessageTypeOpen:
+		b.Open = new(BGPOpen)
+		err = b.Open.unmarshal(b.Payload)
+	case BGPMessageTypeUpdate:
+		b.Update = new(BGPUpdate)
+		err = b.Update.unmarshal(b.Payload, asLen)
+	case BGPMessageTypeNotification:
+		if len(b.Payload) < 2 {
+			return fmt.Errorf("bgp notification too small: %w", ErrTruncated)
+		}
+		b.Notification = &BGPNotification{
+			Code:    BGPErrorCode(b.Payload[0]),
+			Subcode: b.Payload[1],
+			Data:    b.Payload[2:],
+		}
+	case BGPMessageTypeKeepalive:
+		if len(b.Payload) != 0 {
+			return fmt.Errorf("invalid bgp keepalive length: %w", ErrInvalid)
+		}
+	}
+	return err
+}
+
+func (b BGP) Type() LayerType {
+	return LayerTypeBGP
+}
+
+func (b BGP) GetContents() []byte {
+	return b.Contents
+}
+
+func (b BGP) GetPayload() []byte {
+	return b.Payload
+}
+
+func (b BGP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type         string           `json:"type"`
+		MessageType  string           `json:"message_type"`
+		Open         *BGPOpen         `json:"open,omitempty"`
+		Update       *BGPUpdate       `json:"update,omitempty"`
+		Notification *BGPNotification `json:"notification,omitempty"`
+		Length       int              `json:"length"`
+	}{
+		Type:         b.Type().String(),
+		MessageType:  b.MessageType.String(),
+		Open:         b.Open,
+		Update:       b.Update,
+		Notification: b.Notification,
+		Length:       len(b.Contents),
+	})
+}
+
+// BGPCapability is a capability advertised in an OPEN message (RFC 5492).
+type BGPCapability struct {
+	Code  BGPCapabilityCode
+	Value []byte
+}
+
+// BGPFamily is an address family, identified by its AFI and SAFI.
+type BGPFamily struct {
+	AFI  BGPAFI
+	SAFI BGPSAFI
+}
+
+// BGPOpen is the body of an OPEN message. Only the capabilities optional
+// parameter is decoded. Extended optional parameters (RFC 9072) are
+// supported.
+type BGPOpen struct {
+	Version uint8
+	// MyAS is AS_TRANS (23456) if the AS number does not fit in 2 octets.
+	MyAS         uint16
+	HoldTime     time.Duration
+	Identifier   netip.Addr
+	Capabilities []BGPCapability
+}
+
+func (o *BGPOpen) unmarshal(data []byte) error {
+	if len(data) < 10 {
+		return fmt.Errorf("bgp open message too small: %w", ErrTruncated)
+	}
+	o.Version = data[0]
+	o.MyAS = binary.BigEndian.Uint16(data[1:3])
+	o.HoldTime = time.Duration(binary.BigEndian.Uint16(data[3:5])) * time.Second
+	o.Identifier = netip.AddrFrom4(*(*[4]byte)(data[5:9]))
+
+	params := data[10:]
+	if int(data[9]) > len(params) {
+		return fmt.Errorf("invalid bgp optional parameters length: %w", ErrInvalid)
+	}
+	params = params[:data[9]]
+	lenSize := 1
+	if data[9] == 255 && len(params) >= 3 && params[0] == 255 {
+		// Extended optional parameters length
+		n := int(binary.BigEndian.Uint16(params[1:3]))
+		if 13+n > len(data) {
+			return fmt.Errorf("invalid bgp optional parameters length: %w", ErrInvalid)
+		}
+		params = data[13 : 13+n]
+		lenSize = 2
+	}
+	for len(params) > 0 {
+		if len(params) < 1+lenSize {
+			return fmt.Errorf("bgp optional parameter too small: %w", ErrTruncated)
+		}
+		n := int(params[1])
+		if lenSize == 2 {
+			n = int(binary.BigEndian.Uint16(params[1:3]))
+		}
+		if 1+lenSize+n > len(params) {
+			return fmt.Errorf("invalid bgp optional parameter length: %w", ErrInvalid)
+		}
+		typ, value := params[0], params[1+lenSize:1+lenSize+n]
+		params = params[1+lenSize+n:]
+		if typ != 2 {
+			continue
+		}
+		for len(value) > 0 {
+			if len(value) < 2 || 2+int(value[1]) > len(value) {
+				return fmt.Errorf("invalid bgp capability length: %w", ErrInvalid)
+			}
+			o.Capabilities = append(o.Capabilities, BGPCapability{
+				Code:  BGPCapabilityCode(value[0]),
+				Value: value[2 : 2+value[1]],
+			})
+			value = value[2+value[1]:]
+		}
+	}
+	return nil
+}
+
+// Capability returns the value of the first capability with the provided
+// code.
+func (o BGPOpen) Capability(code BGPCapabilityCode) ([]byte, bool) {
+	for _, c := range o.Capabilities {
+		if c.Code == code {
+			return c.Value, true
+		}
+	}
+	return nil, false
+}
+
+// AS returns the AS number of the speaker, from the four-octet AS capability
+// if it was advertised.
+func (o BGPOpen) AS() uint32 {
+	if v, ok := o.Capability(BGPCapabilityCodeFourOctetAS); ok && len(v) == 4 {
+		return binary.BigEndian.Uint32(v)
+	}
+	return uint32(o.MyAS)
+}
+
+// Families returns the address families of the multiprotocol capabilities.
+func (o BGPOpen) Families() []BGPFamily {
+	var res []BGPFamily
+	for _, c := range o.Capabilities {
+		if c.Code == BGPCapabilityCodeMultiprotocol && len(c.Value) == 4 {
+			res = append(res, BGPFamily{
+				AFI:  BGPAFI(binary.BigEndian.Uint16(c.Value[0:2])),
+				SAFI: BGPSAFI(c.Value[3]),
+			})
+		}
+	}
+	return res
+}
+
+func (o BGPOpen) MarshalJSON() ([]byte, error) {
+	type capability struct {
+		Code  string `json:"code"`
+		Value string `json:"value"`
+	}
+	v := struct {
+		Version      uint8        `json:"version"`
+		AS           uint32       `json:"as"`
+		HoldTime     float64      `json:"hold_time"`
+		Identifier   netip.Addr   `json:"identifier"`
+		Capabilities []capability `json:"capabilities"`
+	}{
+		Version:      o.Version,
+		AS:           o.AS(),
+		HoldTime:     o.HoldTime.Seconds(),
+		Identifier:   o.Identifier,
+		Capabilities: make([]capability, len(o.Capabilities)),
+	}
+	for i, c := range o.Capabilities {
+		v.Capabilities[i] = capability{Code: c.Code.String(), Value: hex.EncodeToString(c.Value)}
+	}
+	return json.Marshal(v)
+}
+
+// BGPPathAttribute is a path attribute of an UPDATE message.
+type BGPPathAttribute struct {
+	Flags    BGPAttributeFlags
+	AttrType BGPAttributeType
+	Value    []byte
+}
+
+// BGPASPathSegment is a segment of an AS_PATH or AS4_PATH attribute.
+type BGPASPathSegment struct {
+	SegmentType BGPSegmentType
+	ASNs        []uint32
+}
+
+// BGPCommunity is a community (RFC 1997), which is usually written as the AS
+// number in the high-order 16 bits and a value in the low-order bits.
+type BGPCommunity uint32
+
+func (c BGPCommunity) String() string {
+	return fmt.Sprintf("%d:%d", c>>16, c&0xFFFF)
+}
+
+// BGPLargeCommunity is a large community (RFC 8092).
+type BGPLargeCommunity struct {
+	GlobalAdmin uint32
+	LocalData1  uint32
+	LocalData2  uint32
+}
+
+func (c BGPLargeCommunity) String() string {
+	return fmt.Sprintf("%d:%d:%d", c.GlobalAdmin, c.LocalData1, c.LocalData2)
+}
+
+// BGPMPReach is the MP_REACH_NLRI attribute (RFC 4760). The next hops and
+// prefixes are only decoded for the unicast and multicast IPv4 and IPv6
+// families. An IPv6 next hop may be followed by a link-local address.
+type BGPMPReach struct {
+	BGPFamily
+	NextHops []netip.Addr
+	NLRI     []netip.Prefix
+}
+
+// BGPMPUnreach is the MP_UNREACH_NLRI attribute (RFC 4760).
+type BGPMPUnreach struct {
+	BGPFamily
+	WithdrawnRoutes []netip.Prefix
+}
+
+// BGPUpdate is the body of an UPDATE message. The well-known attributes,
+// communities and multiprotocol attributes are decoded into the fields below
+// the attributes; Attribute reports whether an attribute is present.
+// Prefixes with ADD-PATH identifiers (RFC 7911) are not supported.
+type BGPUpdate struct {
+	WithdrawnRoutes []netip.Prefix
+	Attributes      []BGPPathAttribute
+	NLRI            []netip.Prefix
+	// FourOctetAS reports whether the AS numbers of the AS_PATH attribute are
+	// encoded in 4 octets.
+	FourOctetAS bool
+
+	Origin           BGPOrigin
+	ASPath           []BGPASPathSegment
+	AS4Path          []BGPASPathSegment
+	NextHop          netip.Addr
+	MED              uint32
+	LocalPref        uint32
+	Communities      []BGPCommunity
+	LargeCommunities []BGPLargeCommunity
+	MPReach          *BGPMPReach
+	MPUnreach        *BGPMPUnreach
+}
+
+// unmarshal decodes an UPDATE body with AS numbers of asLen octets, or of the
+// detected size if asLen is zero.
+func (u *BGPUpdate) unmarshal(data []byte, asLen int) error {
+	if len(data) < 4 {
+		return fmt.Errorf("bgp update message too small: %w", ErrTruncated)
+	}
+	n := int(binary.BigEndian.Uint16(data[0:2]))
+	if 2+n+2 > len(data) {
+		return fmt.Errorf("invalid bgp withdrawn routes length: %w", ErrInvalid)
+	}
+	var err error
+	if u.WithdrawnRoutes, err = parseBGPPrefixes(data[2:2+n], BGPAFIIPv4); err != nil {
+		return err
+	}
+	data = data[2+n:]
+	n = int(binary.BigEndian.Uint16(data[0:2]))
+	if 2+n > len(data) {
+		return fmt.Errorf("invalid bgp path attributes length: %w", ErrInvalid)
+	}
+	attrs := data[2 : 2+n]
+	if u.NLRI, err = parseBGPPrefixes(data[2+n:], BGPAFIIPv4); err != nil {
+		return err
+	}
+
+	for len(attrs) > 0 {
+		if len(attrs) < 3 {
+			return fmt.Errorf("bgp path attribute too small: %w", ErrTruncated)
+		}
+		a := BGPPathAttribute{Flags: BGPAttributeFlags(attrs[0]), AttrType: BGPAttributeType(attrs[1])}
+		hdrLen, l := 3, int(attrs[2])
+		if a.Flags.ExtendedLength() {
+			if len(attrs) < 4 {
+				return fmt.Errorf("bgp path attribute too small: %w", ErrTruncated)
+			}
+			hdrLen, l = 4, int(binary.BigEndian.Uint16(attrs[2:4]))
+		}
+		if hdrLen+l > len(attrs) {
+			return fmt.Errorf("invalid length of bgp %v attribute: %w", a.AttrType, ErrInvalid)
+		}
+		a.Value = attrs[hdrLen : hdrLen+l]
+		attrs = attrs[hdrLen+l:]
+		u.Attributes = append(u.Attributes, a)
+		if err := u.decodeAttribute(a, asLen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *BGPUpdate) decodeAttribute(a BGPPathAttribute, asLen int) error {
+	v := a.Value
+	invalid := func() error {
+		return fmt.Errorf("invalid length of bgp %v attribute: %w", a.AttrType, ErrInvalid)
+	}
+	var err error
+	switch a.AttrType {
+	case BGPAttributeTypeOrigin:
+		if len(v) != 1 {
+			return invalid()
+		}
+		u.Origin = BGPOrigin(v[0])
+	case BGPAttributeTypeASPath:
+		if asLen == 0 {
+			asLen = 2
+			// Both sizes may parse for paths which are empty or only contain
+			// small AS numbers in 4 octets, in which case 4 is correct
+			if _, err := parseBGPASPath(v, 4); err == nil {
+				asLen = 4
+			}
+		}
+		u.FourOctetAS = asLen == 4
+		u.ASPath, err = parseBGPASPath(v, asLen)
+	case BGPAttributeTypeAS4Path:
+		u.AS4Path, err = parseBGPASPath(v, 4)
+	case BGPAttributeTypeNextHop:
+		if len(v) != 4 {
+			return invalid()
+		}
+		u.NextHop = netip.AddrFrom4(*(*[4]byte)(v))
+	case BGPAttributeTypeMED, BGPAttributeTypeLocalPref:
+		if len(v) != 4 {
+			return invalid()
+		}
+		if a.AttrType == BGPAttributeTypeMED {
+			u.MED = binary.BigEndian.Uint32(v)
+		} else {
+			u.LocalPref = binary.BigEndian.Uint32(v)
+		}
+	case BGPAttributeTypeCommunities:
+		if len(v)%4 != 0 {
+			return invalid()
+		}
+		for ; len(v) > 0; v = v[4:] {
+			u.Communities = append(u.Communities, BGPCommunity(binary.BigEndian.Uint32(v)))
+		}
+	case BGPAttributeTypeLargeCommunities:
+		if len(v)%12 != 0 {
+			return invalid()
+		}
+		for ; len(v) > 0; v = v[12:] {
+			u.LargeCommunities = append(u.LargeCommunities, BGPLargeCommunity{
+				GlobalAdmin: binary.BigEndian.Uint32(v[0:4]),
+				LocalData1:  binary.BigEndian.Uint32(v[4:8]),
+				LocalData2:  binary.BigEndian.Uint32(v[8:12]),
+			})
+		}
+	case BGPAttributeTypeMPReachNLRI:
+		if len(v) < 5 || 5+int(v[3]) > len(v) {
+			return invalid()
+		}
+		r := &BGPMPReach{BGPFamily: BGPFamily{
+			AFI:  BGPAFI(binary.BigEndian.Uint16(v[0:2])),
+			SAFI: BGPSAFI(v[2]),
+		}}
+		nh, nlri := v[4:4+v[3]], v[5+v[3]:]
+		if r.prefixes() {
+			switch len(nh) {
+			case 4:
+				r.NextHops = []netip.Addr{netip.AddrFrom4(*(*[4]byte)(nh))}
+			case 16, 32:
+				for ; len(nh) > 0; nh = nh[16:] {
+					r.NextHops = append(r.NextHops, netip.AddrFrom16(*(*[16]byte)(nh)))
+				}
+			default:
+				return fmt.Errorf("invalid bgp next hop length: %w", ErrInvalid)
+			}
+			r.NLRI, err = parseBGPPrefixes(nlri, r.AFI)
+		}
+		u.MPReach = r
+	case BGPAttributeTypeMPUnreachNLRI:
+		if len(v) < 3 {
+			return invalid()
+		}
+		r := &BGPMPUnreach{BGPFamily: BGPFamily{
+			AFI:  BGPAFI(binary.BigEndian.Uint16(v[0:2])),
+			SAFI: BGPSAFI(v[2]),
+		}}
+		if r.prefixes() {
+			r.WithdrawnRoutes, err = parseBGPPrefixes(v[3:], r.AFI)
+		}
+		u.MPUnreach = r
+	}
+	return err
+}
+
+// prefixes reports whether the NLRI of the family are plain IP prefixes.
+func (f BGPFamily) prefixes() bool {
+	return (f.AFI == BGPAFIIPv4 || f.AFI == BGPAFIIPv6) &&
+		(f.SAFI == BGPSAFIUnicast || f.SAFI == BGPSAFIMulticast)
+}
+
+// Attribute returns the first path attribute of type t.
+func (u BGPUpdate) Attribute(t BGPAttributeType) (BGPPathAttribute, bool) {
+	for _, a := range u.Attributes {
+		if a.AttrType == t {
+			return a, true
+		}
+	}
+	return BGPPathAttribute{}, false
+}
+
+func (u BGPUpdate) MarshalJSON() ([]byte, error) {
+	type attribute struct {
+		Flags uint8  `json:"flags"`
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	type segment struct {
+		Type string   `json:"type"`
+		ASNs []uint32 `json:"asns"`
+	}
+	type family struct {
+		AFI  string `json:"afi"`
+		SAFI string `json:"safi"`
+	}
+	type mpReach struct {
+		family
+		NextHops []netip.Addr   `json:"next_hops"`
+		NLRI     []netip.Prefix `json:"nlri"`
+	}
+	type mpUnreach struct {
+		family
+		WithdrawnRoutes []netip.Prefix `json:"withdrawn_routes"`
+	}
+	segments := func(path []BGPASPathSegment) []segment {
+		if path == nil {
+			return nil
+		}
+		res := make([]segment, len(path))
+		for i, s := range path {
+			res[i] = segment{Type: s.SegmentType.String(), ASNs: s.ASNs}
+		}
+		return res
+	}
+	prefixes := func(p []netip.Prefix) []netip.Prefix {
+		if p == nil {
+			return []netip.Prefix{}
+		}
+		return p
+	}
+	v := struct {
+		WithdrawnRoutes  []netip.Prefix `json:"withdrawn_routes"`
+		Attributes       []attribute    `json:"attributes"`
+		NLRI             []netip.Prefix `json:"nlri"`
+		FourOctetAS      bool           `json:"four_octet_as"`
+		Origin           string         `json:"origin,omitempty"`
+		ASPath           []segment      `json:"as_path,omitempty"`
+		AS4Path          []segment      `json:"as4_path,omitempty"`
+		NextHop          *netip.Addr    `json:"next_hop,omitempty"`
+		MED              *uint32        `json:"med,omitempty"`
+		LocalPref        *uint32        `json:"local_pref,omitempty"`
+		Communities      []string       `json:"communities,omitempty"`
+		LargeCommunities []string       `json:"large_communities,omitempty"`
+		MPReach          *mpReach       `json:"mp_reach,omitempty"`
+		MPUnreach        *mpUnreach     `json:"mp_unreach,omitempty"`
+	}{
+		WithdrawnRoutes: prefixes(u.WithdrawnRoutes),
+		Attributes:      make([]attribute, len(u.Attributes)),
+		NLRI:            prefixes(u.NLRI),
+		FourOctetAS:     u.FourOctetAS,
+		ASPath:          segments(u.ASPath),
+		AS4Path:         segments(u.AS4Path),
+	}
+	for i, a := range u.Attributes {
+		v.Attributes[i] = attribute{Flags: uint8(a.Flags), Type: a.AttrType.String(), Value: hex.EncodeToString(a.Value)}
+	}
+	has := func(t BGPAttributeType) bool {
+		_, ok := u.Attribute(t)
+		return ok
+	}
+	if has(BGPAttributeTypeOrigin) {
+		v.Origin = u.Origin.String()
+	}
+	if has(BGPAttributeTypeNextHop) {
+		v.NextHop = &u.NextHop
+	}
+	if has(BGPAttributeTypeMED) {
+		v.MED = &u.MED
+	}
+	if has(BGPAttributeTypeLocalPref) {
+		v.LocalPref = &u.LocalPref
+	}
+	for _, c := range u.Communities {
+		v.Communities = append(v.Communities, c.String())
+	}
+	for _, c := range u.LargeCommunities {
+		v.LargeCommunities = append(v.LargeCommunities, c.String())
+	}
+	if r := u.MPReach; r != nil {
+		v.MPReach = &mpReach{
+			family:   family{AFI: r.AFI.String(), SAFI: r.SAFI.String()},
+			NextHops: r.NextHops,
+			NLRI:     prefixes(r.NLRI),
+		}
+		if r.NextHops == nil {
+			v.MPReach.NextHops = []netip.Addr{}
+		}
+	}
+	if r := u.MPUnreach; r != nil {
+		v.MPUnreach = &mpUnreach{
+			family:          family{AFI: r.AFI.String(), SAFI: r.SAFI.String()},
+			WithdrawnRoutes: prefixes(r.WithdrawnRoutes),
+		}
+	}
+	return json.Marshal(v)
+}
+
+// parseBGPASPath parses the segments of an AS path with AS numbers of asLen
+// octets.
+func parseBGPASPath(data []byte, asLen int) ([]BGPASPathSegment, error) {
+	res := []BGPASPathSegment{}
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("bgp as path segment too small: %w", ErrTruncated)
+		}
+		s := BGPASPathSegment{SegmentType: BGPSegmentType(data[0])}
+		n := int(data[1])
+		if s.SegmentType < BGPSegmentTypeSet || s.SegmentType > BGPSegmentTypeConfedSet || n == 0 {
+			return nil, fmt.Errorf("invalid bgp as path segment: %w", ErrInvalid)
+		}
+		if 2+n*asLen > len(data) {
+			return nil, fmt.Errorf("invalid bgp as path segment length: %w", ErrInvalid)
+		}
+		s.ASNs = make([]uint32, n)
+		for i := range s.ASNs {
+			b := data[2+i*asLen:]
+			if asLen == 4 {
+				s.ASNs[i] = binary.BigEndian.Uint32(b)
+			} else {
+				s.ASNs[i] = uint32(binary.BigEndian.Uint16(b))
+			}
+		}
+		res = append(res, s)
+		data = data[2+n*asLen:]
+	}
+	return res, nil
+}
+
+// parseBGPPrefixes parses a list of prefixes, encoded as a length in bits
+// followed by the significant octets of the address.
+func parseBGPPrefixes(data []byte, afi BGPAFI) ([]netip.Prefix, error) {
+	size := 4
+	if afi == BGPAFIIPv6 {
+		size = 16
+	}
+	var res []netip.Prefix
+	for len(data) > 0 {
+		bits := int(data[0])
+		n := (bits + 7) / 8
+		if bits > size*8 || 1+n > len(data) {
+			return nil, fmt.Errorf("invalid bgp prefix length: %w", ErrInvalid)
+		}
+		var b [16]byte
+		copy(b[:], data[1:1+n])
+		addr, _ := netip.AddrFromSlice(b[:size])
+		res = append(res, netip.PrefixFrom(addr, bits).Masked())
+		data = data[1+n:]
+	}
+	return res, nil
+}
+
+// BGPNotification is the body of a NOTIFICATION message.
+type BGPNotification struct {
+	Code    BGPErrorCode
+	Subcode uint8
+	Data    []byte
+}
+
+func (n BGPNotification) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    string `json:"code"`
+		Subcode uint8  `json:"subcode"`
+		Data    string `json:"data"`
+	}{
+		Code:    n.Code.String(),
+		Subcode: n.Subcode,
+		Data:    hex.EncodeToString(n.Data),
+	})
+}
@@ -0,0 +1,127 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// bgpMessage returns a BGP message with the provided type and body.
+func bgpMessage(typ packet.BGPMessageType, body []byte) []byte {
+	msg := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0, 0, byte(typ),
+	}
+	binary.BigEndian.PutUint16(msg[16:18], uint16(19+len(body)))
+	return append(msg, body...)
+}
+
+func TestBGPOpen(t *testing.T) {
+	caps := []byte{
+		1, 4, 0, 2, 0, 1, // multiprotocol, ipv6 unicast
+		65, 4, 0xFA, 0x56, 0xEA, 0x00, // as 4200000000
+	}
+	body := []byte{4, 0x5B, 0xA0, 0, 90, 192, 0, 2, 1, byte(2 + len(caps)), 2, byte(len(caps))}
+	var b packet.BGP
+	if err := b.Unmarshal(bgpMessage(packet.BGPMessageTypeOpen, append(body, caps...))); err != nil {
+		t.Fatalf("unmarshal failed, %v", err)
+	}
+	o := b.Open
+	if o == nil || o.Version != 4 || o.MyAS != 23456 || o.HoldTime != 90*time.Second ||
+		o.Identifier != netip.MustParseAddr("192.0.2.1") || len(o.Capabilities) != 2 {
+		t.Fatalf("unexpected open %+v", o)
+	}
+	if o.AS() != 4200000000 {
+		t.Errorf("unexpected as %d", o.AS())
+	}
+	if f := o.Families(); len(f) != 1 || f[0].AFI != packet.BGPAFIIPv6 || f[0].SAFI != packet.BGPSAFIUnicast {
+		t.Errorf("unexpected families %v", f)
+	}
+}
+
+func TestBGPUpdate(t *testing.T) {
+	attr := func(flags byte, typ packet.BGPAttributeType, v ...byte) []byte {
+		return append([]byte{flags, byte(typ), byte(len(v))}, v...)
+	}
+	var attrs []byte
+	attrs = append(attrs, attr(0x40, packet.BGPAttributeTypeOrigin, 0)...)
+	attrs = append(attrs, attr(0x40, packet.BGPAttributeTypeASPath,
+		2, 2, 0xFA, 0x56, 0xEA, 0x00, 0, 0, 0xFD, 0xE9)...) // 4200000000 65001
+	attrs = append(attrs, attr(0x40, packet.BGPAttributeTypeNextHop, 192, 0, 2, 1)...)
+	attrs = append(attrs, attr(0x80, packet.BGPAttributeTypeMED, 0, 0, 0, 50)...)
+	attrs = append(attrs, attr(0xC0, packet.BGPAttributeTypeCommunities, 0xFD, 0xE9, 0, 100)...)
+	attrs = append(attrs, attr(0xC0, packet.BGPAttributeTypeLargeCommunities,
+		0xFA, 0x56, 0xEA, 0x00, 0, 0, 0, 1, 0, 0, 0, 2)...)
+	mp := []byte{0, 2, 1, 32}
+	mp = append(mp, netip.MustParseAddr("2001:db8::1").AsSlice()...)
+	mp = append(mp, netip.MustParseAddr("fe80::1").AsSlice()...)
+	mp = append(mp, 0, 32, 0x20, 0x01, 0x0D, 0xB8)
+	attrs = append(attrs, attr(0x80, packet.BGPAttributeTypeMPReachNLRI, mp...)...)
+	attrs = append(attrs, attr(0x80, packet.BGPAttributeTypeMPUnreachNLRI, 0, 2, 1, 48, 0x20, 0x01, 0x0D, 0xB8, 0, 1)...)
+
+	body := []byte{0, 2, 8, 10, 0, byte(len(attrs))}
+	body = append(append(body, attrs...), 24, 198, 51, 100)
+	msg := bgpMessage(packet.BGPMessageTypeUpdate, body)
+
+	var b packet.BGP
+	if err := b.Unmarshal(msg); err != nil {
+		t.Fatalf("unmarshal failed, %v", err)
+	}
+	u := b.Update
+	if len(u.WithdrawnRoutes) != 1 || u.WithdrawnRoutes[0] != netip.MustParsePrefix("10.0.0.0/8") {
+		t.Errorf("unexpected withdrawn routes %v", u.WithdrawnRoutes)
+	}
+	if len(u.NLRI) != 1 || u.NLRI[0] != netip.MustParsePrefix("198.51.100.0/24") {
+		t.Errorf("unexpected nlri %v", u.NLRI)
+	}
+	if !u.FourOctetAS || len(u.ASPath) != 1 || u.ASPath[0].SegmentType != packet.BGPSegmentTypeSequence ||
+		len(u.ASPath[0].ASNs) != 2 || u.ASPath[0].ASNs[0] != 4200000000 || u.ASPath[0].ASNs[1] != 65001 {
+		t.Errorf("unexpected as path %+v", u.ASPath)
+	}
+	if u.NextHop != netip.MustParseAddr("192.0.2.1") || u.MED != 50 || u.Origin != packet.BGPOriginIGP {
+		t.Errorf("unexpected attributes %+v", u)
+	}
+	if len(u.Communities) != 1 || u.Communities[0].String() != "65001:100" {
+		t.Errorf("unexpected communities %v", u.Communities)
+	}
+	if len(u.LargeCommunities) != 1 || u.LargeCommunities[0].String() != "4200000000:1:2" {
+		t.Errorf("unexpected large communities %v", u.LargeCommunities)
+	}
+	r := u.MPReach
+	if r == nil || r.AFI != packet.BGPAFIIPv6 || len(r.NextHops) != 2 ||
+		r.NextHops[1] != netip.MustParseAddr("fe80::1") ||
+		len(r.NLRI) != 1 || r.NLRI[0] != netip.MustParsePrefix("2001:db8::/32") {
+		t.Errorf("unexpected mp reach %+v", r)
+	}
+	if w := u.MPUnreach; w == nil || len(w.WithdrawnRoutes) != 1 ||
+		w.WithdrawnRoutes[0] != netip.MustParsePrefix("2001:db8:1::/48") {
+		t.Errorf("unexpected mp unreach %+v", w)
+	}
+	if _, err := json.Marshal(b); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+
+	// The same path with 2-octet AS numbers
+	if err := b.UnmarshalAS(msg, false); err == nil {
+		t.Errorf("expected error for 2-octet as path")
+	}
+}
+
+func TestBGPFromTCP(t *testing.T) {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], 179)
+	binary.BigEndian.PutUint16(tcp[2:4], 50000)
+	tcp[12] = 5 << 4
+	tcp = append(tcp, bgpMessage(packet.BGPMessageTypeKeepalive, nil)...)
+	tcp = append(tcp, bgpMessage(packet.BGPMessageTypeNotification, []byte{6, 2})...)
+	p := decodeIPv4(t, ipv4Packet(netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2"), 6, tcp, -1))
+	b, ok := p.Application.(*packet.BGP)
+	if !ok || b.MessageType != packet.BGPMessageTypeKeepalive || b.Length != 19 {
+		t.Fatalf("unexpected application %+v", p.Application)
+	}
+}
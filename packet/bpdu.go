@@ -0,0 +1,304 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(BPDU)
+	_ json.Marshaler = new(BPDU)
+)
+
+type BPDUType uint8
+
+const (
+	BPDUTypeConfig BPDUType = 0x00
+	BPDUTypeRST    BPDUType = 0x02
+	BPDUTypeTCN    BPDUType = 0x80
+)
+
+// STPPortRole is the role of the sending port, as encoded in the flags of
+// RST and MST BPDUs.
+type STPPortRole uint8
+
+const (
+	STPPortRoleUnknown         STPPortRole = 0
+	STPPortRoleAlternateBackup STPPortRole = 1
+	STPPortRoleRoot            STPPortRole = 2
+	STPPortRoleDesignated      STPPortRole = 3
+)
+
+// BPDUFlags are the flags of configuration, RST and MST BPDUs, and of MSTI
+// configuration messages.
+type BPDUFlags uint8
+
+func (f BPDUFlags) TopologyChange() bool    { return f&0x01 != 0 }
+func (f BPDUFlags) Proposal() bool          { return f&0x02 != 0 }
+func (f BPDUFlags) PortRole() STPPortRole   { return STPPortRole(f >> 2 & 0x03) }
+func (f BPDUFlags) Learning() bool          { return f&0x10 != 0 }
+func (f BPDUFlags) Forwarding() bool        { return f&0x20 != 0 }
+func (f BPDUFlags) Agreement() bool         { return f&0x40 != 0 }
+func (f BPDUFlags) TopologyChangeAck() bool { return f&0x80 != 0 }
+
+// BridgeID identifies a bridge by its priority and MAC address. With the
+// extended system ID, the low 12 bits of the priority field contain the
+// VLAN or MST instance.
+type BridgeID struct {
+	Priority uint16
+	SystemID uint16
+	MAC      net.HardwareAddr
+}
+
+func parseBridgeID(b []byte) BridgeID {
+	prio := binary.BigEndian.Uint16(b[0:2])
+	return BridgeID{
+		Priority: prio & 0xF000,
+		SystemID: prio & 0x0FFF,
+		MAC:      net.HardwareAddr(b[2:8]),
+	}
+}
+
+func (id BridgeID) String() string {
+	return fmt.Sprintf("%d.%d.%v", id.Priority, id.SystemID, id.MAC)
+}
+
+// PortID identifies the port of a bridge.
+type PortID struct {
+	Priority uint8
+	Number   uint16
+}
+
+func (id PortID) String() string {
+	return fmt.Sprintf("%d.%d", id.Priority, id.Number)
+}
+
+// MSTI is the configuration message of a multiple spanning tree instance.
+type MSTI struct {
+	Flags                BPDUFlags
+	RegionalRootID       BridgeID
+	InternalRootPathCost uint32
+	BridgePriority       uint16
+	PortPriority         uint8
+	RemainingHops        uint8
+}
+
+// BPDU is a Bridge Protocol Data Unit of the spanning tree protocols STP,
+// RSTP and MSTP (IEEE 802.1D, 802.1Q). The MST fields are only set for
+// version 3 BPDUs.
+type BPDU struct {
+	ProtocolID   uint16
+	Version      uint8
+	BPDUType     BPDUType
+	Flags        BPDUFlags
+	RootID       BridgeID
+	RootPathCost uint32
+	BridgeID     BridgeID
+	PortID       PortID
+	MessageAge   time.Duration
+	MaxAge       time.Duration
+	HelloTime    time.Duration
+	ForwardDelay time.Duration
+
+	// MST configuration identifier
+	MSTConfigName     string
+	MSTConfigRevision uint16
+	MSTConfigDigest   [16]byte
+
+	CISTInternalRootPathCost uint32
+	CISTBridgeID             BridgeID
+	CISTRemainingHops        uint8
+	MSTIs                    []MSTI
+
+	PacketBytes
+}
+
+// stpTime decodes a timer, which is given in units of 1/256 seconds.
+func stpTime(b []byte) time.Duration {
+	return time.Duration(binary.BigEndian.Uint16(b)) * time.Second / 256
+}
+
+func (b *BPDU) Unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("bpdu too small: %w", ErrTruncated)
+	}
+	*b = BPDU{MSTIs: b.MSTIs[:0]}
+	b.ProtocolID = binary.BigEndian.Uint16(data[0:2])
+	b.Version = data[2]
+	b.BPDUType = BPDUType(data[3])
+	b.Contents = data
+	if b.ProtocolID != 0 {
+		return fmt.Errorf("unknown bpdu protocol id %d: %w", b.ProtocolID, ErrUnsupported)
+	}
+
+	switch b.BPDUType {
+	case BPDUTypeTCN:
+		b.Payload = data[4:]
+		return nil
+	case BPDUTypeConfig, BPDUTypeRST:
+	default:
+		return fmt.Errorf("unknown bpdu type %#x: %w", data[3], ErrUnsupported)
+	}
+	if len(data) < 35 {
+		return fmt.Errorf("configuration bpdu too small: %w", ErrTruncated)
+	}
+	b.Flags = BPDUFlags(data[4])
+	b.RootID = parseBridgeID(data[5:13])
+	b.RootPathCost = binary.BigEndian.Uint32(data[13:17])
+	b.BridgeID = parseBridgeID(data[17:25])
+	port := binary.BigEndian.Uint16(data[25:27])
+	b.PortID = PortID{Priority: uint8(port >> 8 & 0xF0), Number: port & 0x0FFF}
+	b.MessageAge = stpTime(data[27:29])
+	b.MaxAge = stpTime(data[29:31])
+	b.HelloTime = stpTime(data[31:33])
+	b.ForwardDelay = stpTime(data[33:35])
+	b.Payload = data[35:]
+	if b.BPDUType == BPDUTypeConfig {
+		return nil
+	}
+
+	// The Version 1 Length of RST BPDUs is always zero
+	if len(data) < 36 {
+		return fmt.Errorf("rst bpdu too small: %w", ErrTruncated)
+	}
+	b.Payload = data[36:]
+	if b.Version < 3 || len(data) < 38 {
+		return nil
+	}
+
+	v3Len := int(binary.BigEndian.Uint16(data[36:38]))
+	if v3Len < 64 || len(data) < 38+v3Len || (v3Len-64)%16 != 0 {
+		return fmt.Errorf("invalid mst bpdu length: %w", ErrInvalid)
+	}
+	mst := data[38 : 38+v3Len]
+	b.MSTConfigName = string(bytes.TrimRight(mst[1:33], "\x00"))
+	b.MSTConfigRevision = binary.BigEndian.Uint16(mst[33:35])
+	copy(b.MSTConfigDigest[:], mst[35:51])
+	b.CISTInternalRootPathCost = binary.BigEndian.Uint32(mst[51:55])
+	b.CISTBridgeID = parseBridgeID(mst[55:63])
+	b.CISTRemainingHops = mst[63]
+	for rec := mst[64:]; len(rec) > 0; rec = rec[16:] {
+		b.MSTIs = append(b.MSTIs, MSTI{
+			Flags:                BPDUFlags(rec[0]),
+			RegionalRootID:       parseBridgeID(rec[1:9]),
+			InternalRootPathCost: binary.BigEndian.Uint32(rec[9:13]),
+			BridgePriority:       uint16(rec[13]&0xF0) << 8,
+			PortPriority:         rec[14] & 0xF0,
+			RemainingHops:        rec[15],
+		})
+	}
+	b.Payload = data[38+v3Len:]
+	return nil
+}
+
+func (b BPDU) Type() LayerType {
+	return LayerTypeBPDU
+}
+
+func (b BPDU) GetContents() []byte {
+	return b.Contents
+}
+
+func (b BPDU) GetPayload() []byte {
+	return b.Payload
+}
+
+type bpduFlagsJSON struct {
+	TopologyChange    bool   `json:"topology_change"`
+	Proposal          bool   `json:"proposal"`
+	PortRole          string `json:"port_role"`
+	Learning          bool   `json:"learning"`
+	Forwarding        bool   `json:"forwarding"`
+	Agreement         bool   `json:"agreement"`
+	TopologyChangeAck bool   `json:"topology_change_ack"`
+}
+
+func (f BPDUFlags) json() bpduFlagsJSON {
+	return bpduFlagsJSON{
+		TopologyChange:    f.TopologyChange(),
+		Proposal:          f.Proposal(),
+		PortRole:          f.PortRole().String(),
+		Learning:          f.Learning(),
+		Forwarding:        f.Forwarding(),
+		Agreement:         f.Agreement(),
+		TopologyChangeAck: f.TopologyChangeAck(),
+	}
+}
+
+func (b BPDU) MarshalJSON() ([]byte, error) {
+	type msti struct {
+		Flags                bpduFlagsJSON `json:"flags"`
+		RegionalRootID       string        `json:"regional_root_id"`
+		InternalRootPathCost uint32        `json:"internal_root_path_cost"`
+		BridgePriority       uint16        `json:"bridge_priority"`
+		PortPriority         uint8         `json:"port_priority"`
+		RemainingHops        uint8         `json:"remaining_hops"`
+	}
+	type mst struct {
+		ConfigName               string `json:"config_name"`
+		ConfigRevision           uint16 `json:"config_revision"`
+		ConfigDigest             string `json:"config_digest"`
+		CISTInternalRootPathCost uint32 `json:"cist_internal_root_path_cost"`
+		CISTBridgeID             string `json:"cist_bridge_id"`
+		CISTRemainingHops        uint8  `json:"cist_remaining_hops"`
+		MSTIs                    []msti `json:"mstis"`
+	}
+	v := struct {
+		Type           string        `json:"type"`
+		Version        uint8         `json:"version"`
+		BPDUType       string        `json:"bpdu_type"`
+		Flags          bpduFlagsJSON `json:"flags"`
+		RootID         string        `json:"root_id"`
+		RootPathCost   uint32        `json:"root_path_cost"`
+		BridgeID       string        `json:"bridge_id"`
+		PortID         string        `json:"port_id"`
+		MessageAgeMS   int64         `json:"message_age_ms"`
+		MaxAgeMS       int64         `json:"max_age_ms"`
+		HelloTimeMS    int64         `json:"hello_time_ms"`
+		ForwardDelayMS int64         `json:"forward_delay_ms"`
+		MST            *mst          `json:"mst,omitempty"`
+		Length         int           `json:"length"`
+	}{
+		Type:           b.Type().String(),
+		Version:        b.Version,
+		BPDUType:       b.BPDUType.String(),
+		Flags:          b.Flags.json(),
+		RootID:         b.RootID.String(),
+		RootPathCost:   b.RootPathCost,
+		BridgeID:       b.BridgeID.String(),
+		PortID:         b.PortID.String(),
+		MessageAgeMS:   b.MessageAge.Milliseconds(),
+		MaxAgeMS:       b.MaxAge.Milliseconds(),
+		HelloTimeMS:    b.HelloTime.Milliseconds(),
+		ForwardDelayMS: b.ForwardDelay.Milliseconds(),
+		Length:         len(b.Contents),
+	}
+	if b.Version >= 3 && b.CISTBridgeID.MAC != nil {
+		v.MST = &mst{
+			ConfigName:               b.MSTConfigName,
+			ConfigRevision:           b.MSTConfigRevision,
+			ConfigDigest:             hex.EncodeToString(b.MSTConfigDigest[:]),
+			CISTInternalRootPathCost: b.CISTInternalRootPathCost,
+			CISTBridgeID:             b.CISTBridgeID.String(),
+			CISTRemainingHops:        b.CISTRemainingHops,
+			MSTIs:                    make([]msti, len(b.MSTIs)),
+		}
+		for i, m := range b.MSTIs {
+			v.MST.MSTIs[i] = msti{
+				Flags:                m.Flags.json(),
+				RegionalRootID:       m.RegionalRootID.String(),
+				InternalRootPathCost: m.InternalRootPathCost,
+				BridgePriority:       m.BridgePriority,
+				PortPriority:         m.PortPriority,
+				RemainingHops:        m.RemainingHops,
+			}
+		}
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,94 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestBPDUMST(t *testing.T) {
+	bpdu := []byte{
+		0x00, 0x00, 0x03, 0x02, // protocol, version 3, RST/MST
+		0x3C,                                           // forwarding, learning, designated
+		0x80, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x01, // root 32768.0
+		0x00, 0x00, 0x00, 0x00, // root path cost
+		0x80, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x01, // regional root
+		0x80, 0x02, // port 128.2
+		0x00, 0x00, 0x14, 0x00, 0x02, 0x00, 0x0F, 0x00, // 0s, 20s, 2s, 15s
+		0x00,       // version 1 length
+		0x00, 0x50, // version 3 length, one msti
+	}
+	mst := make([]byte, 64+16)
+	copy(mst[1:], "lab")
+	binary.BigEndian.PutUint16(mst[33:35], 7)
+	binary.BigEndian.PutUint32(mst[51:55], 20000)
+	copy(mst[55:63], []byte{0x70, 0x00, 0x02, 0x00, 0x00, 0x00, 0x00, 0x02})
+	mst[63] = 20
+	msti := mst[64:]
+	msti[0] = 0x0A // proposal, root
+	copy(msti[1:9], []byte{0x10, 0x05, 0x02, 0x00, 0x00, 0x00, 0x00, 0x03})
+	msti[13] = 0x20
+	msti[14] = 0x80
+	msti[15] = 19
+	bpdu = append(bpdu, mst...)
+
+	frame := []byte{0x01, 0x80, 0xC2, 0x00, 0x00, 0x00, 2, 0, 0, 0, 0, 2, 0, 0}
+	binary.BigEndian.PutUint16(frame[12:14], uint16(3+len(bpdu)))
+	frame = append(frame, 0x42, 0x42, 0x03)
+	p, err := packet.Decode(append(frame, bpdu...))
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	b, ok := p.Network.(*packet.BPDU)
+	if !ok {
+		t.Fatalf("expected bpdu, got %T", p.Network)
+	}
+	if b.Version != 3 || b.BPDUType != packet.BPDUTypeRST {
+		t.Errorf("unexpected version %d type %v", b.Version, b.BPDUType)
+	}
+	if !b.Flags.Forwarding() || !b.Flags.Learning() ||
+		b.Flags.PortRole() != packet.STPPortRoleDesignated {
+		t.Errorf("unexpected flags %#x", uint8(b.Flags))
+	}
+	if got := b.RootID.String(); got != "32768.0.02:00:00:00:00:01" {
+		t.Errorf("unexpected root id %v", got)
+	}
+	if b.PortID != (packet.PortID{Priority: 128, Number: 2}) {
+		t.Errorf("unexpected port id %v", b.PortID)
+	}
+	if b.MaxAge != 20*time.Second || b.HelloTime != 2*time.Second || b.ForwardDelay != 15*time.Second {
+		t.Errorf("unexpected timers %v %v %v", b.MaxAge, b.HelloTime, b.ForwardDelay)
+	}
+	if b.MSTConfigName != "lab" || b.MSTConfigRevision != 7 ||
+		b.CISTInternalRootPathCost != 20000 || b.CISTBridgeID.Priority != 28672 ||
+		b.CISTRemainingHops != 20 {
+		t.Errorf("unexpected mst configuration %+v", b)
+	}
+	if len(b.MSTIs) != 1 {
+		t.Fatalf("expected one msti, got %d", len(b.MSTIs))
+	}
+	m := b.MSTIs[0]
+	if !m.Flags.Proposal() || m.Flags.PortRole() != packet.STPPortRoleRoot ||
+		m.RegionalRootID.SystemID != 5 || m.BridgePriority != 8192 ||
+		m.PortPriority != 128 || m.RemainingHops != 19 {
+		t.Errorf("unexpected msti %+v", m)
+	}
+	if _, err := b.MarshalJSON(); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestBPDUTCN(t *testing.T) {
+	var b packet.BPDU
+	if err := b.Unmarshal([]byte{0, 0, 0, 0x80}); err != nil {
+		t.Fatal(err)
+	}
+	if b.BPDUType != packet.BPDUTypeTCN {
+		t.Errorf("unexpected type %v", b.BPDUType)
+	}
+	if err := b.Unmarshal([]byte{0, 0, 0, 0x00, 0}); err == nil {
+		t.Errorf("expected error for truncated configuration bpdu")
+	}
+}
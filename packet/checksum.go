@@ -0,0 +1,37 @@
+package packet
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// checksum returns the Internet checksum (RFC 1071) of data, given the
+// partial sum of any preceding data such as a pseudo-header.
+func checksum(data []byte, sum uint32) uint16 {
+	for len(data) >= 2 {
+		sum += uint32(binary.BigEndian.Uint16(data))
+		data = data[2:]
+	}
+	if len(data) == 1 {
+		sum += uint32(data[0]) << 8
+	}
+	for sum > 0xFFFF {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// pseudoHeaderSum returns the partial sum of the pseudo-header used by
+// transport-layer checksums.
+func pseudoHeaderSum(src, dst netip.Addr, proto IPProtocol, length int) uint32 {
+	var sum uint32
+	for _, addr := range []netip.Addr{src, dst} {
+		b := addr.AsSlice()
+		for i := 0; i < len(b); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i:]))
+		}
+	}
+	sum += uint32(proto)
+	sum += uint32(length>>16) + uint32(length&0xFFFF)
+	return sum
+}
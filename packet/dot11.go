@@ -0,0 +1,465 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(Dot11)
+	_ Layer          = new(Dot11Mgmt)
+	_ json.Marshaler = new(Dot11)
+	_ json.Marshaler = new(Dot11Mgmt)
+)
+
+// Dot11Type is the type and subtype of an 802.11 frame, with the type in the
+// upper and the subtype in the lower four bits.
+type Dot11Type uint8
+
+const (
+	Dot11TypeAssocReq    Dot11Type = 0x00
+	Dot11TypeAssocResp   Dot11Type = 0x01
+	Dot11TypeReassocReq  Dot11Type = 0x02
+	Dot11TypeReassocResp Dot11Type = 0x03
+	Dot11TypeProbeReq    Dot11Type = 0x04
+	Dot11TypeProbeResp   Dot11Type = 0x05
+	Dot11TypeBeacon      Dot11Type = 0x08
+	Dot11TypeATIM        Dot11Type = 0x09
+	Dot11TypeDisassoc    Dot11Type = 0x0A
+	Dot11TypeAuth        Dot11Type = 0x0B
+	Dot11TypeDeauth      Dot11Type = 0x0C
+	Dot11TypeAction      Dot11Type = 0x0D
+	Dot11TypeActionNoAck Dot11Type = 0x0E
+
+	Dot11TypeBlockAckReq Dot11Type = 0x18
+	Dot11TypeBlockAck    Dot11Type = 0x19
+	Dot11TypePSPoll      Dot11Type = 0x1A
+	Dot11TypeRTS         Dot11Type = 0x1B
+	Dot11TypeCTS         Dot11Type = 0x1C
+	Dot11TypeACK         Dot11Type = 0x1D
+	Dot11TypeCFEnd       Dot11Type = 0x1E
+	Dot11TypeCFEndAck    Dot11Type = 0x1F
+
+	Dot11TypeData    Dot11Type = 0x20
+	Dot11TypeNull    Dot11Type = 0x24
+	Dot11TypeQoSData Dot11Type = 0x28
+	Dot11TypeQoSNull Dot11Type = 0x2C
+)
+
+// IsManagement reports whether t is a management frame type.
+func (t Dot11Type) IsManagement() bool { return t>>4 == 0 }
+
+// IsControl reports whether t is a control frame type.
+func (t Dot11Type) IsControl() bool { return t>>4 == 1 }
+
+// IsData reports whether t is a data frame type.
+func (t Dot11Type) IsData() bool { return t>>4 == 2 }
+
+// IsQoS reports whether t is a QoS data frame type, with a QoS control field.
+func (t Dot11Type) IsQoS() bool { return t.IsData() && t&0x08 != 0 }
+
+// Dot11Flags is the flags byte of the frame control field.
+type Dot11Flags uint8
+
+func (f Dot11Flags) ToDS() bool      { return f&0x01 != 0 }
+func (f Dot11Flags) FromDS() bool    { return f&0x02 != 0 }
+func (f Dot11Flags) MoreFrag() bool  { return f&0x04 != 0 }
+func (f Dot11Flags) Retry() bool     { return f&0x08 != 0 }
+func (f Dot11Flags) PowerMgmt() bool { return f&0x10 != 0 }
+func (f Dot11Flags) MoreData() bool  { return f&0x20 != 0 }
+func (f Dot11Flags) Protected() bool { return f&0x40 != 0 }
+func (f Dot11Flags) Order() bool     { return f&0x80 != 0 }
+
+// Dot11 is the MAC header of an IEEE 802.11 frame. The addresses which are
+// not present in the frame are nil. The payload contains the frame body.
+type Dot11 struct {
+	Version        uint8
+	Dot11Type      Dot11Type
+	Flags          Dot11Flags
+	DurationID     uint16
+	Address1       net.HardwareAddr
+	Address2       net.HardwareAddr
+	Address3       net.HardwareAddr
+	Address4       net.HardwareAddr
+	SequenceNumber uint16
+	FragmentNumber uint8
+	QoSControl     uint16
+	HTControl      uint32
+	PacketBytes
+}
+
+func (d *Dot11) Unmarshal(data []byte) error {
+	if len(data) < 10 {
+		return fmt.Errorf("802.11 header too small: %w", ErrTruncated)
+	}
+	*d = Dot11{}
+	d.Version = data[0] & 0x03
+	typ, subtype := data[0]>>2&0x03, data[0]>>4
+	d.Dot11Type = Dot11Type(typ<<4 | subtype)
+	d.Flags = Dot11Flags(data[1])
+	d.DurationID = binary.LittleEndian.Uint16(data[2:4])
+	d.Address1 = net.HardwareAddr(data[4:10])
+	hdrLen := 10
+
+	addr := func() net.HardwareAddr {
+		a := net.HardwareAddr(data[hdrLen : hdrLen+6])
+		hdrLen += 6
+		return a
+	}
+	switch {
+	case d.Dot11Type.IsControl():
+		// CTS and ACK frames only have the receiver address
+		if d.Dot11Type != Dot11TypeCTS && d.Dot11Type != Dot11TypeACK {
+			if len(data) < 16 {
+				return fmt.Errorf("%v frame too small: %w", d.Dot11Type, ErrTruncated)
+			}
+			d.Address2 = addr()
+		}
+	case d.Dot11Type.IsManagement(), d.Dot11Type.IsData():
+		n := 24
+		if d.Dot11Type.IsData() && d.Flags.ToDS() && d.Flags.FromDS() {
+			n += 6
+		}
+		if d.Dot11Type.IsQoS() {
+			n += 2
+		}
+		// The HT control field is present in QoS data and management frames
+		if d.Flags.Order() && (d.Dot11Type.IsQoS() || d.Dot11Type.IsManagement()) {
+			n += 4
+		}
+		if len(data) < n {
+			return fmt.Errorf("%v frame too small: %w", d.Dot11Type, ErrTruncated)
+		}
+		d.Address2 = addr()
+		d.Address3 = addr()
+		seq := binary.LittleEndian.Uint16(data[hdrLen:])
+		d.FragmentNumber = uint8(seq & 0x0F)
+		d.SequenceNumber = seq >> 4
+		hdrLen += 2
+		if d.Dot11Type.IsData() && d.Flags.ToDS() && d.Flags.FromDS() {
+			d.Address4 = addr()
+		}
+		if d.Dot11Type.IsQoS() {
+			d.QoSControl = binary.LittleEndian.Uint16(data[hdrLen:])
+			hdrLen += 2
+		}
+		if hdrLen < n {
+			d.HTControl = binary.LittleEndian.Uint32(data[hdrLen:])
+			hdrLen += 4
+		}
+	default:
+		return fmt.Errorf("unsupported 802.11 frame type %#x: %w", uint8(d.Dot11Type), ErrUnsupported)
+	}
+	d.Contents = data
+	d.Payload = data[hdrLen:]
+	return nil
+}
+
+// TID returns the traffic identifier of QoS data frames.
+func (d Dot11) TID() uint8 {
+	return uint8(d.QoSControl & 0x0F)
+}
+
+// Transmitter returns the address of the transmitting station, if present.
+func (d Dot11) Transmitter() net.HardwareAddr {
+	return d.Address2
+}
+
+// BSSID returns the BSSID of management frames and of data frames within a
+// BSS. It is nil for other frames.
+func (d Dot11) BSSID() net.HardwareAddr {
+	switch {
+	case d.Dot11Type.IsManagement():
+		return d.Address3
+	case d.Dot11Type.IsData() && !d.Flags.ToDS() && !d.Flags.FromDS():
+		return d.Address3
+	case d.Dot11Type.IsData() && d.Flags.ToDS() && !d.Flags.FromDS():
+		return d.Address1
+	case d.Dot11Type.IsData() && !d.Flags.ToDS() && d.Flags.FromDS():
+		return d.Address2
+	}
+	return nil
+}
+
+func (d Dot11) Type() LayerType {
+	return LayerTypeDot11
+}
+
+func (d Dot11) GetContents() []byte {
+	return d.Contents
+}
+
+func (d Dot11) GetPayload() []byte {
+	return d.Payload
+}
+
+func hwAddrString(a net.HardwareAddr) string {
+	if a == nil {
+		return ""
+	}
+	return a.String()
+}
+
+func (d Dot11) MarshalJSON() ([]byte, error) {
+	type flags struct {
+		ToDS      bool `json:"to_ds"`
+		FromDS    bool `json:"from_ds"`
+		MoreFrag  bool `json:"more_frag"`
+		Retry     bool `json:"retry"`
+		PowerMgmt bool `json:"power_mgmt"`
+		MoreData  bool `json:"more_data"`
+		Protected bool `json:"protected"`
+		Order     bool `json:"order"`
+	}
+	return json.Marshal(struct {
+		Type           string `json:"type"`
+		Version        uint8  `json:"version"`
+		Dot11Type      string `json:"dot11_type"`
+		Flags          flags  `json:"flags"`
+		DurationID     uint16 `json:"duration_id"`
+		Address1       string `json:"address1"`
+		Address2       string `json:"address2,omitempty"`
+		Address3       string `json:"address3,omitempty"`
+		Address4       string `json:"address4,omitempty"`
+		SequenceNumber uint16 `json:"sequence_number"`
+		FragmentNumber uint8  `json:"fragment_number"`
+		QoSControl     uint16 `json:"qos_control,omitempty"`
+		HTControl      uint32 `json:"ht_control,omitempty"`
+		Length         int    `json:"length"`
+	}{
+		Type:      d.Type().String(),
+		Version:   d.Version,
+		Dot11Type: d.Dot11Type.String(),
+		Flags: flags{
+			ToDS:      d.Flags.ToDS(),
+			FromDS:    d.Flags.FromDS(),
+			MoreFrag:  d.Flags.MoreFrag(),
+			Retry:     d.Flags.Retry(),
+			PowerMgmt: d.Flags.PowerMgmt(),
+			MoreData:  d.Flags.MoreData(),
+			Protected: d.Flags.Protected(),
+			Order:     d.Flags.Order(),
+		},
+		DurationID:     d.DurationID,
+		Address1:       hwAddrString(d.Address1),
+		Address2:       hwAddrString(d.Address2),
+		Address3:       hwAddrString(d.Address3),
+		Address4:       hwAddrString(d.Address4),
+		SequenceNumber: d.SequenceNumber,
+		FragmentNumber: d.FragmentNumber,
+		QoSControl:     d.QoSControl,
+		HTControl:      d.HTControl,
+		Length:         len(d.Contents),
+	})
+}
+
+// Dot11IEID is the element ID of an 802.11 information element.
+type Dot11IEID uint8
+
+const (
+	Dot11IEIDSSID                   Dot11IEID = 0
+	Dot11IEIDSupportedRates         Dot11IEID = 1
+	Dot11IEIDDSParameterSet         Dot11IEID = 3
+	Dot11IEIDTIM                    Dot11IEID = 5
+	Dot11IEIDCountry                Dot11IEID = 7
+	Dot11IEIDBSSLoad                Dot11IEID = 11
+	Dot11IEIDChallengeText          Dot11IEID = 16
+	Dot11IEIDPowerConstraint        Dot11IEID = 32
+	Dot11IEIDERP                    Dot11IEID = 42
+	Dot11IEIDHTCapabilities         Dot11IEID = 45
+	Dot11IEIDRSN                    Dot11IEID = 48
+	Dot11IEIDExtendedSupportedRates Dot11IEID = 50
+	Dot11IEIDHTOperation            Dot11IEID = 61
+	Dot11IEIDExtendedCapabilities   Dot11IEID = 127
+	Dot11IEIDVHTCapabilities        Dot11IEID = 191
+	Dot11IEIDVHTOperation           Dot11IEID = 192
+	Dot11IEIDVendorSpecific         Dot11IEID = 221
+	Dot11IEIDExtension              Dot11IEID = 255
+)
+
+// Dot11IE is an information element of a management frame.
+type Dot11IE struct {
+	ID   Dot11IEID
+	Data []byte
+}
+
+// Dot11Mgmt is the body of an 802.11 management frame. The fixed fields
+// which are not part of the frame's subtype are zero. Action frames and
+// protected frames are not decoded beyond the type.
+type Dot11Mgmt struct {
+	Dot11Type Dot11Type
+
+	// Beacons and probe responses
+	Timestamp      uint64
+	BeaconInterval uint16
+
+	Capability     uint16
+	ListenInterval uint16
+	// CurrentAP is set for reassociation requests.
+	CurrentAP     net.HardwareAddr
+	AuthAlgorithm uint16
+	AuthSequence  uint16
+	StatusCode    uint16
+	ReasonCode    uint16
+	AssociationID uint16
+
+	IEs []Dot11IE
+	PacketBytes
+}
+
+// Unmarshal decodes a management frame body. The Dot11Type field must be
+// set by the caller.
+func (m *Dot11Mgmt) Unmarshal(data []byte) error {
+	*m = Dot11Mgmt{Dot11Type: m.Dot11Type, IEs: m.IEs[:0]}
+	m.Contents = data
+	u16 := func(off int) uint16 { return binary.LittleEndian.Uint16(data[off:]) }
+	var n int
+	switch m.Dot11Type {
+	case Dot11TypeBeacon, Dot11TypeProbeResp:
+		n = 12
+		if len(data) >= n {
+			m.Timestamp = binary.LittleEndian.Uint64(data[0:8])
+			m.BeaconInterval = u16(8)
+			m.Capability = u16(10)
+		}
+	case Dot11TypeProbeReq:
+	case Dot11TypeAuth:
+		n = 6
+		if len(data) >= n {
+			m.AuthAlgorithm, m.AuthSequence, m.StatusCode = u16(0), u16(2), u16(4)
+		}
+	case Dot11TypeDeauth, Dot11TypeDisassoc:
+		n = 2
+		if len(data) >= n {
+			m.ReasonCode = u16(0)
+		}
+	case Dot11TypeAssocReq:
+		n = 4
+		if len(data) >= n {
+			m.Capability, m.ListenInterval = u16(0), u16(2)
+		}
+	case Dot11TypeReassocReq:
+		n = 10
+		if len(data) >= n {
+			m.Capability, m.ListenInterval = u16(0), u16(2)
+			m.CurrentAP = net.HardwareAddr(data[4:10])
+		}
+	case Dot11TypeAssocResp, Dot11TypeReassocResp:
+		n = 6
+		if len(data) >= n {
+			m.Capability, m.StatusCode = u16(0), u16(2)
+			m.AssociationID = u16(4) & 0x3FFF
+		}
+	default:
+		m.Payload = data
+		return nil
+	}
+	if len(data) < n {
+		return fmt.Errorf("%v body too small: %w", m.Dot11Type, ErrTruncated)
+	}
+	m.Payload = data[n:]
+
+	for b := m.Payload; len(b) > 0; {
+		if len(b) < 2 {
+			return fmt.Errorf("802.11 information element exceeds frame: %w", ErrTruncated)
+		}
+		l := int(b[1])
+		if 2+l > len(b) {
+			return fmt.Errorf("802.11 information element exceeds frame: %w", ErrTruncated)
+		}
+		m.IEs = append(m.IEs, Dot11IE{ID: Dot11IEID(b[0]), Data: b[2 : 2+l]})
+		b = b[2+l:]
+	}
+	return nil
+}
+
+// IE returns the data of the first information element with the ID.
+func (m Dot11Mgmt) IE(id Dot11IEID) ([]byte, bool) {
+	for _, ie := range m.IEs {
+		if ie.ID == id {
+			return ie.Data, true
+		}
+	}
+	return nil, false
+}
+
+// SSID returns the SSID element. It reports false if the frame has no SSID
+// element, and returns an empty string for the wildcard SSID.
+func (m Dot11Mgmt) SSID() (string, bool) {
+	b, ok := m.IE(Dot11IEIDSSID)
+	return string(b), ok
+}
+
+// Channel returns the current channel from the DS parameter set element.
+func (m Dot11Mgmt) Channel() (uint8, bool) {
+	b, ok := m.IE(Dot11IEIDDSParameterSet)
+	if !ok || len(b) != 1 {
+		return 0, false
+	}
+	return b[0], true
+}
+
+func (m Dot11Mgmt) Type() LayerType {
+	return LayerTypeDot11Mgmt
+}
+
+func (m Dot11Mgmt) GetContents() []byte {
+	return m.Contents
+}
+
+func (m Dot11Mgmt) GetPayload() []byte {
+	return m.Payload
+}
+
+func (m Dot11Mgmt) MarshalJSON() ([]byte, error) {
+	type ie struct {
+		ID   string `json:"id"`
+		Data string `json:"data"`
+	}
+	v := struct {
+		Type           string  `json:"type"`
+		Dot11Type      string  `json:"dot11_type"`
+		Timestamp      *uint64 `json:"timestamp,omitempty"`
+		BeaconInterval *uint16 `json:"beacon_interval,omitempty"`
+		Capability     *uint16 `json:"capability,omitempty"`
+		ListenInterval *uint16 `json:"listen_interval,omitempty"`
+		CurrentAP      string  `json:"current_ap,omitempty"`
+		AuthAlgorithm  *uint16 `json:"auth_algorithm,omitempty"`
+		AuthSequence   *uint16 `json:"auth_sequence,omitempty"`
+		StatusCode     *uint16 `json:"status_code,omitempty"`
+		ReasonCode     *uint16 `json:"reason_code,omitempty"`
+		AssociationID  *uint16 `json:"association_id,omitempty"`
+		SSID           *string `json:"ssid,omitempty"`
+		IEs            []ie    `json:"ies"`
+		Length         int     `json:"length"`
+	}{
+		Type:      m.Type().String(),
+		Dot11Type: m.Dot11Type.String(),
+		CurrentAP: hwAddrString(m.CurrentAP),
+		IEs:       make([]ie, len(m.IEs)),
+		Length:    len(m.Contents),
+	}
+	switch m.Dot11Type {
+	case Dot11TypeBeacon, Dot11TypeProbeResp:
+		v.Timestamp, v.BeaconInterval, v.Capability = &m.Timestamp, &m.BeaconInterval, &m.Capability
+	case Dot11TypeAuth:
+		v.AuthAlgorithm, v.AuthSequence, v.StatusCode = &m.AuthAlgorithm, &m.AuthSequence, &m.StatusCode
+	case Dot11TypeDeauth, Dot11TypeDisassoc:
+		v.ReasonCode = &m.ReasonCode
+	case Dot11TypeAssocReq, Dot11TypeReassocReq:
+		v.Capability, v.ListenInterval = &m.Capability, &m.ListenInterval
+	case Dot11TypeAssocResp, Dot11TypeReassocResp:
+		v.Capability, v.StatusCode, v.AssociationID = &m.Capability, &m.StatusCode, &m.AssociationID
+	}
+	if ssid, ok := m.SSID(); ok {
+		v.SSID = &ssid
+	}
+	for i, e := range m.IEs {
+		v.IEs[i] = ie{ID: e.ID.String(), Data: hex.EncodeToString(e.Data)}
+	}
+	return json.Marshal(v)
+}
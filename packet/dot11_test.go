@@ -0,0 +1,129 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/pcap"
+)
+
+func TestRadiotapBeacon(t *testing.T) {
+	rt := []byte{
+		0, 0, 16, 0, // version, pad, length
+		0x2E, 0, 0, 0, // flags, rate, channel, antenna signal
+		0x10,       // fcs at end
+		12,         // 6 Mbps
+		0x85, 0x09, // 2437 MHz
+		0xA0, 0x00, // channel flags
+		0xC4, // -60 dBm
+		0,    // padding
+	}
+	bssid := []byte{0x02, 0xAA, 0xBB, 0xCC, 0xDD, 0xEE}
+	beacon := []byte{0x80, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	beacon = append(beacon, bssid...)
+	beacon = append(beacon, bssid...)
+	beacon = append(beacon, 0x10, 0x00) // sequence number 1
+	fixed := make([]byte, 12)
+	binary.LittleEndian.PutUint16(fixed[8:10], 100)
+	binary.LittleEndian.PutUint16(fixed[10:12], 0x0431)
+	beacon = append(beacon, fixed...)
+	beacon = append(beacon, 0, 3, 'l', 'a', 'b') // ssid
+	beacon = append(beacon, 3, 1, 6)             // channel 6
+	frame := append(append(rt, beacon...), 0xDE, 0xAD, 0xBE, 0xEF)
+
+	p, err := packet.DecodeLinkType(pcap.LinkTypeIEEE80211Radiotap, frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	r := p.Radiotap
+	if r == nil || r.Rate != 12 || r.ChannelFrequency != 2437 || r.AntennaSignal != -60 || !r.Flags.FCS() {
+		t.Fatalf("unexpected radiotap %+v", r)
+	}
+	d := p.Dot11
+	if d == nil || d.Dot11Type != packet.Dot11TypeBeacon || d.SequenceNumber != 1 ||
+		d.BSSID().String() != "02:aa:bb:cc:dd:ee" {
+		t.Fatalf("unexpected 802.11 header %+v", d)
+	}
+	m, ok := p.Network.(*packet.Dot11Mgmt)
+	if !ok {
+		t.Fatalf("expected management frame, got %T", p.Network)
+	}
+	if ssid, ok := m.SSID(); !ok || ssid != "lab" {
+		t.Errorf("unexpected ssid %q", ssid)
+	}
+	if ch, ok := m.Channel(); !ok || ch != 6 {
+		t.Errorf("unexpected channel %d", ch)
+	}
+	if m.BeaconInterval != 100 || m.Capability != 0x0431 || len(m.IEs) != 2 {
+		t.Errorf("unexpected beacon %+v", m)
+	}
+	if _, err := json.Marshal(p); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestDot11VendorIE(t *testing.T) {
+	// Vendor specific elements may have the maximum length of 255 octets
+	vendor := append([]byte{221, 255, 0x00, 0x50, 0xF2}, make([]byte, 252)...)
+	body := append(make([]byte, 12), vendor...)
+	body = append(body, 0, 3, 'l', 'a', 'b')
+
+	m := &packet.Dot11Mgmt{Dot11Type: packet.Dot11TypeBeacon}
+	if err := m.Unmarshal(body); err != nil {
+		t.Fatal(err)
+	}
+	if ie, ok := m.IE(packet.Dot11IEIDVendorSpecific); !ok || len(ie) != 255 {
+		t.Errorf("unexpected vendor element of %d bytes", len(ie))
+	}
+	if ssid, ok := m.SSID(); !ok || ssid != "lab" {
+		t.Errorf("unexpected ssid %q", ssid)
+	}
+
+	// The element length must not exceed the frame
+	if err := m.Unmarshal(body[:12+254]); err == nil {
+		t.Error("truncated element decoded without error")
+	}
+}
+
+func TestDot11QoSData(t *testing.T) {
+	// From a station to the AP, with TID 5
+	hdr := []byte{0x88, 0x01, 0x00, 0x00}
+	hdr = append(hdr, 0x02, 0xAA, 0xBB, 0xCC, 0xDD, 0xEE) // bssid
+	hdr = append(hdr, 0x02, 0x00, 0x00, 0x00, 0x00, 0x01) // station
+	hdr = append(hdr, 0x02, 0x00, 0x00, 0x00, 0x00, 0x02) // destination
+	hdr = append(hdr, 0x00, 0x00, 0x05, 0x00)
+	llc := []byte{0xAA, 0xAA, 0x03, 0, 0, 0, 0x08, 0x00}
+	ip := ipv4Packet(netip.MustParseAddr("192.168.0.10"), netip.MustParseAddr("192.168.0.1"),
+		17, []byte{0, 68, 0, 67, 0, 8, 0, 0}, -1)
+	frame := append(append(hdr, llc...), ip...)
+
+	p, err := packet.DecodeLinkType(pcap.LinkTypeIEEE80211, frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	if p.Link != nil || p.Dot11 == nil || p.Dot11.TID() != 5 || !p.Dot11.Flags.ToDS() {
+		t.Fatalf("unexpected 802.11 header %+v", p.Dot11)
+	}
+	if p.SNAP == nil {
+		t.Fatalf("missing snap header")
+	}
+	if _, ok := p.Transport.(*packet.UDP); !ok {
+		t.Errorf("expected udp, got %T", p.Transport)
+	}
+	if len(p.Layers()) != 5 {
+		t.Errorf("unexpected layers %v", p.Layers())
+	}
+}
+
+func TestDot11CTS(t *testing.T) {
+	p, err := packet.DecodeDot11([]byte{0xC4, 0x00, 0x2C, 0x01, 0x02, 0, 0, 0, 0, 1})
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	if p.Dot11.Dot11Type != packet.Dot11TypeCTS || p.Dot11.Address2 != nil || p.Dot11.DurationID != 300 {
+		t.Errorf("unexpected cts %+v", p.Dot11)
+	}
+}
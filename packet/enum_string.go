@@ -1,4 +1,4 @@
-// Code generated by "stringer -type=LayerType,EtherType,ARPType,ARPOpCode -output enum_string.go"; DO NOT EDIT.
+// Code generated by "stringer -type=LayerType,EtherType,ARPType,ARPOpCode,IPProtocol,ICMPType,ICMPv6Type,IGMPType,GroupRecordType,SCTPChunkType,LLCSAP,BPDUType,STPPortRole,TLSContentType,TLSHandshakeType,TLSExtensionType,QUICPacketType,QUICFrameType,NTPMode,PTPMessageType,PPPoECode,PPPoETagType,PPPProtocol,PPPControlCode,PAPCode,CHAPCode,Dot11Type,Dot11IEID,BGPMessageType,BGPCapabilityCode,BGPAFI,BGPSAFI,BGPAttributeType,BGPOrigin,BGPSegmentType,BGPErrorCode,OSPFType,OSPFLSType,RTCPPacketType,RTCPSDESType,SNMPVersion,SNMPPDUType,SNMPErrorStatus,SNMPValueType,GTPUMessageType,GTPExtensionType,GTPv2MessageType,GTPv2IEType -output enum_string.go"; DO NOT EDIT.
 
 package packet
 
@@ -12,50 +12,115 @@ func _() {
 	_ = x[LayerTypeEthernet-1]
 	_ = x[LayerTypeIPv4-2]
 	_ = x[LayerTypeARP-3]
+	_ = x[LayerTypeTCP-4]
+	_ = x[LayerTypeUDP-5]
+	_ = x[LayerTypeIPv6-6]
+	_ = x[LayerTypeICMPv6-7]
+	_ = x[LayerTypeIGMP-8]
+	_ = x[LayerTypeMLD-9]
+	_ = x[LayerTypeSCTP-10]
+	_ = x[LayerTypeTLS-11]
+	_ = x[LayerTypeQUIC-12]
+	_ = x[LayerTypeICMP-13]
+	_ = x[LayerTypeLLC-14]
+	_ = x[LayerTypeSNAP-15]
+	_ = x[LayerTypeBPDU-16]
+	_ = x[LayerTypeNTP-17]
+	_ = x[LayerTypePTP-18]
+	_ = x[LayerTypePPPoE-19]
+	_ = x[LayerTypePPP-20]
+	_ = x[LayerTypePPPControl-21]
+	_ = x[LayerTypePAP-22]
+	_ = x[LayerTypeCHAP-23]
+	_ = x[LayerTypeRadiotap-24]
+	_ = x[LayerTypeDot11-25]
+	_ = x[LayerTypeDot11Mgmt-26]
+	_ = x[LayerTypeAH-27]
+	_ = x[LayerTypeESP-28]
+	_ = x[LayerTypeBGP-29]
+	_ = x[LayerTypeOSPF-30]
+	_ = x[LayerTypeRTP-31]
+	_ = x[LayerTypeRTCP-32]
+	_ = x[LayerTypeSNMP-33]
+	_ = x[LayerTypeGTPU-34]
+	_ = x[LayerTypeGTPv2-35]
+	_ = x[LayerTypeMACsec-36]
 }
 
-const _LayerType_name = "LayerTypeUnknownLayerTypeEthernetLayerTypeIPv4LayerTypeARP"
+const _LayerType_name = "LayerTypeUnknownLayerTypeEthernetLayerTypeIPv4LayerTypeARPLayerTypeTCPLayerTypeUDPLayerTypeIPv6LayerTypeICMPv6LayerTypeIGMPLayerTypeMLDLayerTypeSCTPLayerTypeTLSLayerTypeQUICLayerTypeICMPLayerTypeLLCLayerTypeSNAPLayerTypeBPDULayerTypeNTPLayerTypePTPLayerTypePPPoELayerTypePPPLayerTypePPPControlLayerTypePAPLayerTypeCHAPLayerTypeRadiotapLayerTypeDot11LayerTypeDot11MgmtLayerTypeAHLayerTypeESPLayerTypeBGPLayerTypeOSPFLayerTypeRTPLayerTypeRTCPLayerTypeSNMPLayerTypeGTPULayerTypeGTPv2LayerTypeMACsec"
 
-var _LayerType_index = [...]uint8{0, 16, 33, 46, 58}
+var _LayerType_index = [...]uint16{0, 16, 33, 46, 58, 70, 82, 95, 110, 123, 135, 148, 160, 173, 186, 198, 211, 224, 236, 248, 262, 274, 293, 305, 318, 335, 349, 367, 378, 390, 402, 415, 427, 440, 453, 466, 480, 495}
 
 func (i LayerType) String() string {
-	if i >= LayerType(len(_LayerType_index)-1) {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_LayerType_index)-1 {
 		return "LayerType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _LayerType_name[_LayerType_index[i]:_LayerType_index[i+1]]
+	return _LayerType_name[_LayerType_index[idx]:_LayerType_index[idx+1]]
 }
 func _() {
 	// An "invalid array index" compiler error signifies that the constant values have changed.
 	// Re-run the stringer command to generate them again.
 	var x [1]struct{}
+	_ = x[EthernetTypeLLC-0]
+	_ = x[EtherTypeMaxLength-1500]
 	_ = x[EtherTypeTooLow-2047]
 	_ = x[EthernetTypeIPv4-2048]
 	_ = x[EthernetTypeARP-2054]
+	_ = x[EthernetTypeDot1Q-33024]
 	_ = x[EthernetTypeIPv6-34525]
 	_ = x[EtherTypeTooHigh-34526]
+	_ = x[EthernetTypePPPoEDiscovery-34915]
+	_ = x[EthernetTypePPPoESession-34916]
+	_ = x[EthernetTypeQinQ-34984]
+	_ = x[EthernetTypeMACsec-35045]
+	_ = x[EthernetTypePTP-35063]
 }
 
 const (
-	_EtherType_name_0 = "EtherTypeTooLowEthernetTypeIPv4"
-	_EtherType_name_1 = "EthernetTypeARP"
-	_EtherType_name_2 = "EthernetTypeIPv6EtherTypeTooHigh"
+	_EtherType_name_0 = "EthernetTypeLLC"
+	_EtherType_name_1 = "EtherTypeMaxLength"
+	_EtherType_name_2 = "EtherTypeTooLowEthernetTypeIPv4"
+	_EtherType_name_3 = "EthernetTypeARP"
+	_EtherType_name_4 = "EthernetTypeDot1Q"
+	_EtherType_name_5 = "EthernetTypeIPv6EtherTypeTooHigh"
+	_EtherType_name_6 = "EthernetTypePPPoEDiscoveryEthernetTypePPPoESession"
+	_EtherType_name_7 = "EthernetTypeQinQ"
+	_EtherType_name_8 = "EthernetTypeMACsec"
+	_EtherType_name_9 = "EthernetTypePTP"
 )
 
 var (
-	_EtherType_index_0 = [...]uint8{0, 15, 31}
-	_EtherType_index_2 = [...]uint8{0, 16, 32}
+	_EtherType_index_2 = [...]uint8{0, 15, 31}
+	_EtherType_index_5 = [...]uint8{0, 16, 32}
+	_EtherType_index_6 = [...]uint8{0, 26, 50}
 )
 
 func (i EtherType) String() string {
 	switch {
+	case i == 0:
+		return _EtherType_name_0
+	case i == 1500:
+		return _EtherType_name_1
 	case 2047 <= i && i <= 2048:
 		i -= 2047
-		return _EtherType_name_0[_EtherType_index_0[i]:_EtherType_index_0[i+1]]
+		return _EtherType_name_2[_EtherType_index_2[i]:_EtherType_index_2[i+1]]
 	case i == 2054:
-		return _EtherType_name_1
+		return _EtherType_name_3
+	case i == 33024:
+		return _EtherType_name_4
 	case 34525 <= i && i <= 34526:
 		i -= 34525
-		return _EtherType_name_2[_EtherType_index_2[i]:_EtherType_index_2[i+1]]
+		return _EtherType_name_5[_EtherType_index_5[i]:_EtherType_index_5[i+1]]
+	case 34915 <= i && i <= 34916:
+		i -= 34915
+		return _EtherType_name_6[_EtherType_index_6[i]:_EtherType_index_6[i+1]]
+	case i == 34984:
+		return _EtherType_name_7
+	case i == 35045:
+		return _EtherType_name_8
+	case i == 35063:
+		return _EtherType_name_9
 	default:
 		return "EtherType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -72,11 +137,11 @@ const _ARPType_name = "ARPTypeEther"
 var _ARPType_index = [...]uint8{0, 12}
 
 func (i ARPType) String() string {
-	i -= 1
-	if i >= ARPType(len(_ARPType_index)-1) {
-		return "ARPType(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_ARPType_index)-1 {
+		return "ARPType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _ARPType_name[_ARPType_index[i]:_ARPType_index[i+1]]
+	return _ARPType_name[_ARPType_index[idx]:_ARPType_index[idx+1]]
 }
 func _() {
 	// An "invalid array index" compiler error signifies that the constant values have changed.
@@ -91,9 +156,1702 @@ const _ARPOpCode_name = "ARPOPCodeRequestARPOPCodeReply"
 var _ARPOpCode_index = [...]uint8{0, 16, 30}
 
 func (i ARPOpCode) String() string {
-	i -= 1
-	if i >= ARPOpCode(len(_ARPOpCode_index)-1) {
-		return "ARPOpCode(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_ARPOpCode_index)-1 {
+		return "ARPOpCode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _ARPOpCode_name[_ARPOpCode_index[idx]:_ARPOpCode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[IPProtocolIPv6HopByHop-0]
+	_ = x[IPProtocolICMP-1]
+	_ = x[IPProtocolIGMP-2]
+	_ = x[IPProtocolIPv4-4]
+	_ = x[IPProtocolTCP-6]
+	_ = x[IPProtocolUDP-17]
+	_ = x[IPProtocolIPv6-41]
+	_ = x[IPProtocolIPv6Route-43]
+	_ = x[IPProtocolIPv6Frag-44]
+	_ = x[IPProtocolESP-50]
+	_ = x[IPProtocolAH-51]
+	_ = x[IPProtocolICMPv6-58]
+	_ = x[IPProtocolIPv6NoNext-59]
+	_ = x[IPProtocolIPv6Opts-60]
+	_ = x[IPProtocolOSPF-89]
+	_ = x[IPProtocolSCTP-132]
+}
+
+const (
+	_IPProtocol_name_0 = "IPProtocolIPv6HopByHopIPProtocolICMPIPProtocolIGMP"
+	_IPProtocol_name_1 = "IPProtocolIPv4"
+	_IPProtocol_name_2 = "IPProtocolTCP"
+	_IPProtocol_name_3 = "IPProtocolUDP"
+	_IPProtocol_name_4 = "IPProtocolIPv6"
+	_IPProtocol_name_5 = "IPProtocolIPv6RouteIPProtocolIPv6Frag"
+	_IPProtocol_name_6 = "IPProtocolESPIPProtocolAH"
+	_IPProtocol_name_7 = "IPProtocolICMPv6IPProtocolIPv6NoNextIPProtocolIPv6Opts"
+	_IPProtocol_name_8 = "IPProtocolOSPF"
+	_IPProtocol_name_9 = "IPProtocolSCTP"
+)
+
+var (
+	_IPProtocol_index_0 = [...]uint8{0, 22, 36, 50}
+	_IPProtocol_index_5 = [...]uint8{0, 19, 37}
+	_IPProtocol_index_6 = [...]uint8{0, 13, 25}
+	_IPProtocol_index_7 = [...]uint8{0, 16, 36, 54}
+)
+
+func (i IPProtocol) String() string {
+	switch {
+	case i <= 2:
+		return _IPProtocol_name_0[_IPProtocol_index_0[i]:_IPProtocol_index_0[i+1]]
+	case i == 4:
+		return _IPProtocol_name_1
+	case i == 6:
+		return _IPProtocol_name_2
+	case i == 17:
+		return _IPProtocol_name_3
+	case i == 41:
+		return _IPProtocol_name_4
+	case 43 <= i && i <= 44:
+		i -= 43
+		return _IPProtocol_name_5[_IPProtocol_index_5[i]:_IPProtocol_index_5[i+1]]
+	case 50 <= i && i <= 51:
+		i -= 50
+		return _IPProtocol_name_6[_IPProtocol_index_6[i]:_IPProtocol_index_6[i+1]]
+	case 58 <= i && i <= 60:
+		i -= 58
+		return _IPProtocol_name_7[_IPProtocol_index_7[i]:_IPProtocol_index_7[i+1]]
+	case i == 89:
+		return _IPProtocol_name_8
+	case i == 132:
+		return _IPProtocol_name_9
+	default:
+		return "IPProtocol(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[ICMPTypeEchoReply-0]
+	_ = x[ICMPTypeDestinationUnreachable-3]
+	_ = x[ICMPTypeSourceQuench-4]
+	_ = x[ICMPTypeRedirect-5]
+	_ = x[ICMPTypeEchoRequest-8]
+	_ = x[ICMPTypeRouterAdvertisement-9]
+	_ = x[ICMPTypeRouterSolicitation-10]
+	_ = x[ICMPTypeTimeExceeded-11]
+	_ = x[ICMPTypeParameterProblem-12]
+	_ = x[ICMPTypeTimestamp-13]
+	_ = x[ICMPTypeTimestampReply-14]
+}
+
+const (
+	_ICMPType_name_0 = "ICMPTypeEchoReply"
+	_ICMPType_name_1 = "ICMPTypeDestinationUnreachableICMPTypeSourceQuenchICMPTypeRedirect"
+	_ICMPType_name_2 = "ICMPTypeEchoRequestICMPTypeRouterAdvertisementICMPTypeRouterSolicitationICMPTypeTimeExceededICMPTypeParameterProblemICMPTypeTimestampICMPTypeTimestampReply"
+)
+
+var (
+	_ICMPType_index_1 = [...]uint8{0, 30, 50, 66}
+	_ICMPType_index_2 = [...]uint8{0, 19, 46, 72, 92, 116, 133, 155}
+)
+
+func (i ICMPType) String() string {
+	switch {
+	case i == 0:
+		return _ICMPType_name_0
+	case 3 <= i && i <= 5:
+		i -= 3
+		return _ICMPType_name_1[_ICMPType_index_1[i]:_ICMPType_index_1[i+1]]
+	case 8 <= i && i <= 14:
+		i -= 8
+		return _ICMPType_name_2[_ICMPType_index_2[i]:_ICMPType_index_2[i+1]]
+	default:
+		return "ICMPType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[ICMPv6TypeDestinationUnreachable-1]
+	_ = x[ICMPv6TypePacketTooBig-2]
+	_ = x[ICMPv6TypeTimeExceeded-3]
+	_ = x[ICMPv6TypeParameterProblem-4]
+	_ = x[ICMPv6TypeEchoRequest-128]
+	_ = x[ICMPv6TypeEchoReply-129]
+	_ = x[ICMPv6TypeMLDQuery-130]
+	_ = x[ICMPv6TypeMLDv1Report-131]
+	_ = x[ICMPv6TypeMLDv1Done-132]
+	_ = x[ICMPv6TypeRouterSolicitation-133]
+	_ = x[ICMPv6TypeRouterAdvertisement-134]
+	_ = x[ICMPv6TypeNeighborSolicitation-135]
+	_ = x[ICMPv6TypeNeighborAdvertisement-136]
+	_ = x[ICMPv6TypeRedirect-137]
+	_ = x[ICMPv6TypeMLDv2Report-143]
+}
+
+const (
+	_ICMPv6Type_name_0 = "ICMPv6TypeDestinationUnreachableICMPv6TypePacketTooBigICMPv6TypeTimeExceededICMPv6TypeParameterProblem"
+	_ICMPv6Type_name_1 = "ICMPv6TypeEchoRequestICMPv6TypeEchoReplyICMPv6TypeMLDQueryICMPv6TypeMLDv1ReportICMPv6TypeMLDv1DoneICMPv6TypeRouterSolicitationICMPv6TypeRouterAdvertisementICMPv6TypeNeighborSolicitationICMPv6TypeNeighborAdvertisementICMPv6TypeRedirect"
+	_ICMPv6Type_name_2 = "ICMPv6TypeMLDv2Report"
+)
+
+var (
+	_ICMPv6Type_index_0 = [...]uint8{0, 32, 54, 76, 102}
+	_ICMPv6Type_index_1 = [...]uint8{0, 21, 40, 58, 79, 98, 126, 155, 185, 216, 234}
+)
+
+func (i ICMPv6Type) String() string {
+	switch {
+	case 1 <= i && i <= 4:
+		i -= 1
+		return _ICMPv6Type_name_0[_ICMPv6Type_index_0[i]:_ICMPv6Type_index_0[i+1]]
+	case 128 <= i && i <= 137:
+		i -= 128
+		return _ICMPv6Type_name_1[_ICMPv6Type_index_1[i]:_ICMPv6Type_index_1[i+1]]
+	case i == 143:
+		return _ICMPv6Type_name_2
+	default:
+		return "ICMPv6Type(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[IGMPTypeMembershipQuery-17]
+	_ = x[IGMPTypeV1MembershipReport-18]
+	_ = x[IGMPTypeV2MembershipReport-22]
+	_ = x[IGMPTypeV2LeaveGroup-23]
+	_ = x[IGMPTypeV3MembershipReport-34]
+}
+
+const (
+	_IGMPType_name_0 = "IGMPTypeMembershipQueryIGMPTypeV1MembershipReport"
+	_IGMPType_name_1 = "IGMPTypeV2MembershipReportIGMPTypeV2LeaveGroup"
+	_IGMPType_name_2 = "IGMPTypeV3MembershipReport"
+)
+
+var (
+	_IGMPType_index_0 = [...]uint8{0, 23, 49}
+	_IGMPType_index_1 = [...]uint8{0, 26, 46}
+)
+
+func (i IGMPType) String() string {
+	switch {
+	case 17 <= i && i <= 18:
+		i -= 17
+		return _IGMPType_name_0[_IGMPType_index_0[i]:_IGMPType_index_0[i+1]]
+	case 22 <= i && i <= 23:
+		i -= 22
+		return _IGMPType_name_1[_IGMPType_index_1[i]:_IGMPType_index_1[i+1]]
+	case i == 34:
+		return _IGMPType_name_2
+	default:
+		return "IGMPType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[GroupRecordModeIsInclude-1]
+	_ = x[GroupRecordModeIsExclude-2]
+	_ = x[GroupRecordChangeToInclude-3]
+	_ = x[GroupRecordChangeToExclude-4]
+	_ = x[GroupRecordAllowNewSources-5]
+	_ = x[GroupRecordBlockOldSources-6]
+}
+
+const _GroupRecordType_name = "GroupRecordModeIsIncludeGroupRecordModeIsExcludeGroupRecordChangeToIncludeGroupRecordChangeToExcludeGroupRecordAllowNewSourcesGroupRecordBlockOldSources"
+
+var _GroupRecordType_index = [...]uint8{0, 24, 48, 74, 100, 126, 152}
+
+func (i GroupRecordType) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_GroupRecordType_index)-1 {
+		return "GroupRecordType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _GroupRecordType_name[_GroupRecordType_index[idx]:_GroupRecordType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[SCTPChunkTypeData-0]
+	_ = x[SCTPChunkTypeInit-1]
+	_ = x[SCTPChunkTypeInitAck-2]
+	_ = x[SCTPChunkTypeSACK-3]
+	_ = x[SCTPChunkTypeHeartbeat-4]
+	_ = x[SCTPChunkTypeHeartbeatAck-5]
+	_ = x[SCTPChunkTypeAbort-6]
+	_ = x[SCTPChunkTypeShutdown-7]
+	_ = x[SCTPChunkTypeShutdownAck-8]
+	_ = x[SCTPChunkTypeError-9]
+	_ = x[SCTPChunkTypeCookieEcho-10]
+	_ = x[SCTPChunkTypeCookieAck-11]
+	_ = x[SCTPChunkTypeECNE-12]
+	_ = x[SCTPChunkTypeCWR-13]
+	_ = x[SCTPChunkTypeShutdownComplete-14]
+	_ = x[SCTPChunkTypeAuth-15]
+	_ = x[SCTPChunkTypeIData-64]
+	_ = x[SCTPChunkTypeASCONFAck-128]
+	_ = x[SCTPChunkTypeReConfig-130]
+	_ = x[SCTPChunkTypePad-132]
+	_ = x[SCTPChunkTypeForwardTSN-192]
+	_ = x[SCTPChunkTypeASCONF-193]
+}
+
+const (
+	_SCTPChunkType_name_0 = "SCTPChunkTypeDataSCTPChunkTypeInitSCTPChunkTypeInitAckSCTPChunkTypeSACKSCTPChunkTypeHeartbeatSCTPChunkTypeHeartbeatAckSCTPChunkTypeAbortSCTPChunkTypeShutdownSCTPChunkTypeShutdownAckSCTPChunkTypeErrorSCTPChunkTypeCookieEchoSCTPChunkTypeCookieAckSCTPChunkTypeECNESCTPChunkTypeCWRSCTPChunkTypeShutdownCompleteSCTPChunkTypeAuth"
+	_SCTPChunkType_name_1 = "SCTPChunkTypeIData"
+	_SCTPChunkType_name_2 = "SCTPChunkTypeASCONFAck"
+	_SCTPChunkType_name_3 = "SCTPChunkTypeReConfig"
+	_SCTPChunkType_name_4 = "SCTPChunkTypePad"
+	_SCTPChunkType_name_5 = "SCTPChunkTypeForwardTSNSCTPChunkTypeASCONF"
+)
+
+var (
+	_SCTPChunkType_index_0 = [...]uint16{0, 17, 34, 54, 71, 93, 118, 136, 157, 181, 199, 222, 244, 261, 277, 306, 323}
+	_SCTPChunkType_index_5 = [...]uint8{0, 23, 42}
+)
+
+func (i SCTPChunkType) String() string {
+	switch {
+	case i <= 15:
+		return _SCTPChunkType_name_0[_SCTPChunkType_index_0[i]:_SCTPChunkType_index_0[i+1]]
+	case i == 64:
+		return _SCTPChunkType_name_1
+	case i == 128:
+		return _SCTPChunkType_name_2
+	case i == 130:
+		return _SCTPChunkType_name_3
+	case i == 132:
+		return _SCTPChunkType_name_4
+	case 192 <= i && i <= 193:
+		i -= 192
+		return _SCTPChunkType_name_5[_SCTPChunkType_index_5[i]:_SCTPChunkType_index_5[i+1]]
+	default:
+		return "SCTPChunkType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[LLCSAPNull-0]
+	_ = x[LLCSAPSTP-66]
+	_ = x[LLCSAPSNAP-170]
+	_ = x[LLCSAPIPX-224]
+	_ = x[LLCSAPNetBIOS-240]
+	_ = x[LLCSAPGlobal-254]
+}
+
+const (
+	_LLCSAP_name_0 = "LLCSAPNull"
+	_LLCSAP_name_1 = "LLCSAPSTP"
+	_LLCSAP_name_2 = "LLCSAPSNAP"
+	_LLCSAP_name_3 = "LLCSAPIPX"
+	_LLCSAP_name_4 = "LLCSAPNetBIOS"
+	_LLCSAP_name_5 = "LLCSAPGlobal"
+)
+
+func (i LLCSAP) String() string {
+	switch {
+	case i == 0:
+		return _LLCSAP_name_0
+	case i == 66:
+		return _LLCSAP_name_1
+	case i == 170:
+		return _LLCSAP_name_2
+	case i == 224:
+		return _LLCSAP_name_3
+	case i == 240:
+		return _LLCSAP_name_4
+	case i == 254:
+		return _LLCSAP_name_5
+	default:
+		return "LLCSAP(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BPDUTypeConfig-0]
+	_ = x[BPDUTypeRST-2]
+	_ = x[BPDUTypeTCN-128]
+}
+
+const (
+	_BPDUType_name_0 = "BPDUTypeConfig"
+	_BPDUType_name_1 = "BPDUTypeRST"
+	_BPDUType_name_2 = "BPDUTypeTCN"
+)
+
+func (i BPDUType) String() string {
+	switch {
+	case i == 0:
+		return _BPDUType_name_0
+	case i == 2:
+		return _BPDUType_name_1
+	case i == 128:
+		return _BPDUType_name_2
+	default:
+		return "BPDUType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[STPPortRoleUnknown-0]
+	_ = x[STPPortRoleAlternateBackup-1]
+	_ = x[STPPortRoleRoot-2]
+	_ = x[STPPortRoleDesignated-3]
+}
+
+const _STPPortRole_name = "STPPortRoleUnknownSTPPortRoleAlternateBackupSTPPortRoleRootSTPPortRoleDesignated"
+
+var _STPPortRole_index = [...]uint8{0, 18, 44, 59, 80}
+
+func (i STPPortRole) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_STPPortRole_index)-1 {
+		return "STPPortRole(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _STPPortRole_name[_STPPortRole_index[idx]:_STPPortRole_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[TLSContentTypeChangeCipherSpec-20]
+	_ = x[TLSContentTypeAlert-21]
+	_ = x[TLSContentTypeHandshake-22]
+	_ = x[TLSContentTypeApplicationData-23]
+	_ = x[TLSContentTypeHeartbeat-24]
+}
+
+const _TLSContentType_name = "TLSContentTypeChangeCipherSpecTLSContentTypeAlertTLSContentTypeHandshakeTLSContentTypeApplicationDataTLSContentTypeHeartbeat"
+
+var _TLSContentType_index = [...]uint8{0, 30, 49, 72, 101, 124}
+
+func (i TLSContentType) String() string {
+	idx := int(i) - 20
+	if i < 20 || idx >= len(_TLSContentType_index)-1 {
+		return "TLSContentType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _TLSContentType_name[_TLSContentType_index[idx]:_TLSContentType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[TLSHandshakeTypeHelloRequest-0]
+	_ = x[TLSHandshakeTypeClientHello-1]
+	_ = x[TLSHandshakeTypeServerHello-2]
+	_ = x[TLSHandshakeTypeNewSessionTicket-4]
+	_ = x[TLSHandshakeTypeEndOfEarlyData-5]
+	_ = x[TLSHandshakeTypeEncryptedExtensions-8]
+	_ = x[TLSHandshakeTypeCertificate-11]
+	_ = x[TLSHandshakeTypeServerKeyExchange-12]
+	_ = x[TLSHandshakeTypeCertificateRequest-13]
+	_ = x[TLSHandshakeTypeServerHelloDone-14]
+	_ = x[TLSHandshakeTypeCertificateVerify-15]
+	_ = x[TLSHandshakeTypeClientKeyExchange-16]
+	_ = x[TLSHandshakeTypeFinished-20]
+	_ = x[TLSHandshakeTypeKeyUpdate-24]
+}
+
+const (
+	_TLSHandshakeType_name_0 = "TLSHandshakeTypeHelloRequestTLSHandshakeTypeClientHelloTLSHandshakeTypeServerHello"
+	_TLSHandshakeType_name_1 = "TLSHandshakeTypeNewSessionTicketTLSHandshakeTypeEndOfEarlyData"
+	_TLSHandshakeType_name_2 = "TLSHandshakeTypeEncryptedExtensions"
+	_TLSHandshakeType_name_3 = "TLSHandshakeTypeCertificateTLSHandshakeTypeServerKeyExchangeTLSHandshakeTypeCertificateRequestTLSHandshakeTypeServerHelloDoneTLSHandshakeTypeCertificateVerifyTLSHandshakeTypeClientKeyExchange"
+	_TLSHandshakeType_name_4 = "TLSHandshakeTypeFinished"
+	_TLSHandshakeType_name_5 = "TLSHandshakeTypeKeyUpdate"
+)
+
+var (
+	_TLSHandshakeType_index_0 = [...]uint8{0, 28, 55, 82}
+	_TLSHandshakeType_index_1 = [...]uint8{0, 32, 62}
+	_TLSHandshakeType_index_3 = [...]uint8{0, 27, 60, 94, 125, 158, 191}
+)
+
+func (i TLSHandshakeType) String() string {
+	switch {
+	case i <= 2:
+		return _TLSHandshakeType_name_0[_TLSHandshakeType_index_0[i]:_TLSHandshakeType_index_0[i+1]]
+	case 4 <= i && i <= 5:
+		i -= 4
+		return _TLSHandshakeType_name_1[_TLSHandshakeType_index_1[i]:_TLSHandshakeType_index_1[i+1]]
+	case i == 8:
+		return _TLSHandshakeType_name_2
+	case 11 <= i && i <= 16:
+		i -= 11
+		return _TLSHandshakeType_name_3[_TLSHandshakeType_index_3[i]:_TLSHandshakeType_index_3[i+1]]
+	case i == 20:
+		return _TLSHandshakeType_name_4
+	case i == 24:
+		return _TLSHandshakeType_name_5
+	default:
+		return "TLSHandshakeType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[TLSExtensionTypeServerName-0]
+	_ = x[TLSExtensionTypeMaxFragmentLength-1]
+	_ = x[TLSExtensionTypeStatusRequest-5]
+	_ = x[TLSExtensionTypeSupportedGroups-10]
+	_ = x[TLSExtensionTypeECPointFormats-11]
+	_ = x[TLSExtensionTypeSignatureAlgorithms-13]
+	_ = x[TLSExtensionTypeUseSRTP-14]
+	_ = x[TLSExtensionTypeHeartbeat-15]
+	_ = x[TLSExtensionTypeALPN-16]
+	_ = x[TLSExtensionTypeSignedCertTimestamp-18]
+	_ = x[TLSExtensionTypePadding-21]
+	_ = x[TLSExtensionTypeEncryptThenMAC-22]
+	_ = x[TLSExtensionTypeExtendedMasterSecret-23]
+	_ = x[TLSExtensionTypeCompressCertificate-27]
+	_ = x[TLSExtensionTypeRecordSizeLimit-28]
+	_ = x[TLSExtensionTypeSessionTicket-35]
+	_ = x[TLSExtensionTypePreSharedKey-41]
+	_ = x[TLSExtensionTypeEarlyData-42]
+	_ = x[TLSExtensionTypeSupportedVersions-43]
+	_ = x[TLSExtensionTypeCookie-44]
+	_ = x[TLSExtensionTypePSKKeyExchangeModes-45]
+	_ = x[TLSExtensionTypeCertificateAuthorities-47]
+	_ = x[TLSExtensionTypePostHandshakeAuth-49]
+	_ = x[TLSExtensionTypeSignatureAlgorithmsCert-50]
+	_ = x[TLSExtensionTypeKeyShare-51]
+	_ = x[TLSExtensionTypeQUICTransportParameters-57]
+	_ = x[TLSExtensionTypeApplicationSettings-17513]
+	_ = x[TLSExtensionTypeEncryptedClientHello-65037]
+	_ = x[TLSExtensionTypeRenegotiationInfo-65281]
+}
+
+const _TLSExtensionType_name = "TLSExtensionTypeServerNameTLSExtensionTypeMaxFragmentLengthTLSExtensionTypeStatusRequestTLSExtensionTypeSupportedGroupsTLSExtensionTypeECPointFormatsTLSExtensionTypeSignatureAlgorithmsTLSExtensionTypeUseSRTPTLSExtensionTypeHeartbeatTLSExtensionTypeALPNTLSExtensionTypeSignedCertTimestampTLSExtensionTypePaddingTLSExtensionTypeEncryptThenMACTLSExtensionTypeExtendedMasterSecretTLSExtensionTypeCompressCertificateTLSExtensionTypeRecordSizeLimitTLSExtensionTypeSessionTicketTLSExtensionTypePreSharedKeyTLSExtensionTypeEarlyDataTLSExtensionTypeSupportedVersionsTLSExtensionTypeCookieTLSExtensionTypePSKKeyExchangeModesTLSExtensionTypeCertificateAuthoritiesTLSExtensionTypePostHandshakeAuthTLSExtensionTypeSignatureAlgorithmsCertTLSExtensionTypeKeyShareTLSExtensionTypeQUICTransportParametersTLSExtensionTypeApplicationSettingsTLSExtensionTypeEncryptedClientHelloTLSExtensionTypeRenegotiationInfo"
+
+var _TLSExtensionType_map = map[TLSExtensionType]string{
+	0:     _TLSExtensionType_name[0:26],
+	1:     _TLSExtensionType_name[26:59],
+	5:     _TLSExtensionType_name[59:88],
+	10:    _TLSExtensionType_name[88:119],
+	11:    _TLSExtensionType_name[119:149],
+	13:    _TLSExtensionType_name[149:184],
+	14:    _TLSExtensionType_name[184:207],
+	15:    _TLSExtensionType_name[207:232],
+	16:    _TLSExtensionType_name[232:252],
+	18:    _TLSExtensionType_name[252:287],
+	21:    _TLSExtensionType_name[287:310],
+	22:    _TLSExtensionType_name[310:340],
+	23:    _TLSExtensionType_name[340:376],
+	27:    _TLSExtensionType_name[376:411],
+	28:    _TLSExtensionType_name[411:442],
+	35:    _TLSExtensionType_name[442:471],
+	41:    _TLSExtensionType_name[471:499],
+	42:    _TLSExtensionType_name[499:524],
+	43:    _TLSExtensionType_name[524:557],
+	44:    _TLSExtensionType_name[557:579],
+	45:    _TLSExtensionType_name[579:614],
+	47:    _TLSExtensionType_name[614:652],
+	49:    _TLSExtensionType_name[652:685],
+	50:    _TLSExtensionType_name[685:724],
+	51:    _TLSExtensionType_name[724:748],
+	57:    _TLSExtensionType_name[748:787],
+	17513: _TLSExtensionType_name[787:822],
+	65037: _TLSExtensionType_name[822:858],
+	65281: _TLSExtensionType_name[858:891],
+}
+
+func (i TLSExtensionType) String() string {
+	if str, ok := _TLSExtensionType_map[i]; ok {
+		return str
+	}
+	return "TLSExtensionType(" + strconv.FormatInt(int64(i), 10) + ")"
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[QUICPacketTypeInitial-0]
+	_ = x[QUICPacketTypeZeroRTT-1]
+	_ = x[QUICPacketTypeHandshake-2]
+	_ = x[QUICPacketTypeRetry-3]
+	_ = x[QUICPacketTypeVersionNegotiation-4]
+	_ = x[QUICPacketTypeOneRTT-5]
+}
+
+const _QUICPacketType_name = "QUICPacketTypeInitialQUICPacketTypeZeroRTTQUICPacketTypeHandshakeQUICPacketTypeRetryQUICPacketTypeVersionNegotiationQUICPacketTypeOneRTT"
+
+var _QUICPacketType_index = [...]uint8{0, 21, 42, 65, 84, 116, 136}
+
+func (i QUICPacketType) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_QUICPacketType_index)-1 {
+		return "QUICPacketType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _QUICPacketType_name[_QUICPacketType_index[idx]:_QUICPacketType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[QUICFrameTypePadding-0]
+	_ = x[QUICFrameTypePing-1]
+	_ = x[QUICFrameTypeACK-2]
+	_ = x[QUICFrameTypeACKECN-3]
+	_ = x[QUICFrameTypeCrypto-6]
+	_ = x[QUICFrameTypeConnectionClose-28]
+	_ = x[QUICFrameTypeApplicationClose-29]
+}
+
+const (
+	_QUICFrameType_name_0 = "QUICFrameTypePaddingQUICFrameTypePingQUICFrameTypeACKQUICFrameTypeACKECN"
+	_QUICFrameType_name_1 = "QUICFrameTypeCrypto"
+	_QUICFrameType_name_2 = "QUICFrameTypeConnectionCloseQUICFrameTypeApplicationClose"
+)
+
+var (
+	_QUICFrameType_index_0 = [...]uint8{0, 20, 37, 53, 72}
+	_QUICFrameType_index_2 = [...]uint8{0, 28, 57}
+)
+
+func (i QUICFrameType) String() string {
+	switch {
+	case i <= 3:
+		return _QUICFrameType_name_0[_QUICFrameType_index_0[i]:_QUICFrameType_index_0[i+1]]
+	case i == 6:
+		return _QUICFrameType_name_1
+	case 28 <= i && i <= 29:
+		i -= 28
+		return _QUICFrameType_name_2[_QUICFrameType_index_2[i]:_QUICFrameType_index_2[i+1]]
+	default:
+		return "QUICFrameType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[NTPModeReserved-0]
+	_ = x[NTPModeSymmetricActive-1]
+	_ = x[NTPModeSymmetricPassive-2]
+	_ = x[NTPModeClient-3]
+	_ = x[NTPModeServer-4]
+	_ = x[NTPModeBroadcast-5]
+	_ = x[NTPModeControl-6]
+	_ = x[NTPModePrivate-7]
+}
+
+const _NTPMode_name = "NTPModeReservedNTPModeSymmetricActiveNTPModeSymmetricPassiveNTPModeClientNTPModeServerNTPModeBroadcastNTPModeControlNTPModePrivate"
+
+var _NTPMode_index = [...]uint8{0, 15, 37, 60, 73, 86, 102, 116, 130}
+
+func (i NTPMode) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_NTPMode_index)-1 {
+		return "NTPMode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _NTPMode_name[_NTPMode_index[idx]:_NTPMode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PTPMessageTypeSync-0]
+	_ = x[PTPMessageTypeDelayReq-1]
+	_ = x[PTPMessageTypePdelayReq-2]
+	_ = x[PTPMessageTypePdelayResp-3]
+	_ = x[PTPMessageTypeFollowUp-8]
+	_ = x[PTPMessageTypeDelayResp-9]
+	_ = x[PTPMessageTypePdelayRespFollowUp-10]
+	_ = x[PTPMessageTypeAnnounce-11]
+	_ = x[PTPMessageTypeSignaling-12]
+	_ = x[PTPMessageTypeManagement-13]
+}
+
+const (
+	_PTPMessageType_name_0 = "PTPMessageTypeSyncPTPMessageTypeDelayReqPTPMessageTypePdelayReqPTPMessageTypePdelayResp"
+	_PTPMessageType_name_1 = "PTPMessageTypeFollowUpPTPMessageTypeDelayRespPTPMessageTypePdelayRespFollowUpPTPMessageTypeAnnouncePTPMessageTypeSignalingPTPMessageTypeManagement"
+)
+
+var (
+	_PTPMessageType_index_0 = [...]uint8{0, 18, 40, 63, 87}
+	_PTPMessageType_index_1 = [...]uint8{0, 22, 45, 77, 99, 122, 146}
+)
+
+func (i PTPMessageType) String() string {
+	switch {
+	case i <= 3:
+		return _PTPMessageType_name_0[_PTPMessageType_index_0[i]:_PTPMessageType_index_0[i+1]]
+	case 8 <= i && i <= 13:
+		i -= 8
+		return _PTPMessageType_name_1[_PTPMessageType_index_1[i]:_PTPMessageType_index_1[i+1]]
+	default:
+		return "PTPMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PPPoECodeSession-0]
+	_ = x[PPPoECodePADO-7]
+	_ = x[PPPoECodePADI-9]
+	_ = x[PPPoECodePADR-25]
+	_ = x[PPPoECodePADS-101]
+	_ = x[PPPoECodePADT-167]
+}
+
+const (
+	_PPPoECode_name_0 = "PPPoECodeSession"
+	_PPPoECode_name_1 = "PPPoECodePADO"
+	_PPPoECode_name_2 = "PPPoECodePADI"
+	_PPPoECode_name_3 = "PPPoECodePADR"
+	_PPPoECode_name_4 = "PPPoECodePADS"
+	_PPPoECode_name_5 = "PPPoECodePADT"
+)
+
+func (i PPPoECode) String() string {
+	switch {
+	case i == 0:
+		return _PPPoECode_name_0
+	case i == 7:
+		return _PPPoECode_name_1
+	case i == 9:
+		return _PPPoECode_name_2
+	case i == 25:
+		return _PPPoECode_name_3
+	case i == 101:
+		return _PPPoECode_name_4
+	case i == 167:
+		return _PPPoECode_name_5
+	default:
+		return "PPPoECode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PPPoETagTypeEndOfList-0]
+	_ = x[PPPoETagTypeServiceName-257]
+	_ = x[PPPoETagTypeACName-258]
+	_ = x[PPPoETagTypeHostUniq-259]
+	_ = x[PPPoETagTypeACCookie-260]
+	_ = x[PPPoETagTypeVendorSpecific-261]
+	_ = x[PPPoETagTypeRelaySessionID-272]
+	_ = x[PPPoETagTypePPPMaxPayload-288]
+	_ = x[PPPoETagTypeServiceNameError-513]
+	_ = x[PPPoETagTypeACSystemError-514]
+	_ = x[PPPoETagTypeGenericError-515]
+}
+
+const (
+	_PPPoETagType_name_0 = "PPPoETagTypeEndOfList"
+	_PPPoETagType_name_1 = "PPPoETagTypeServiceNamePPPoETagTypeACNamePPPoETagTypeHostUniqPPPoETagTypeACCookiePPPoETagTypeVendorSpecific"
+	_PPPoETagType_name_2 = "PPPoETagTypeRelaySessionID"
+	_PPPoETagType_name_3 = "PPPoETagTypePPPMaxPayload"
+	_PPPoETagType_name_4 = "PPPoETagTypeServiceNameErrorPPPoETagTypeACSystemErrorPPPoETagTypeGenericError"
+)
+
+var (
+	_PPPoETagType_index_1 = [...]uint8{0, 23, 41, 61, 81, 107}
+	_PPPoETagType_index_4 = [...]uint8{0, 28, 53, 77}
+)
+
+func (i PPPoETagType) String() string {
+	switch {
+	case i == 0:
+		return _PPPoETagType_name_0
+	case 257 <= i && i <= 261:
+		i -= 257
+		return _PPPoETagType_name_1[_PPPoETagType_index_1[i]:_PPPoETagType_index_1[i+1]]
+	case i == 272:
+		return _PPPoETagType_name_2
+	case i == 288:
+		return _PPPoETagType_name_3
+	case 513 <= i && i <= 515:
+		i -= 513
+		return _PPPoETagType_name_4[_PPPoETagType_index_4[i]:_PPPoETagType_index_4[i+1]]
+	default:
+		return "PPPoETagType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PPPProtocolIPv4-33]
+	_ = x[PPPProtocolIPv6-87]
+	_ = x[PPPProtocolIPCP-32801]
+	_ = x[PPPProtocolIPV6CP-32855]
+	_ = x[PPPProtocolLCP-49185]
+	_ = x[PPPProtocolPAP-49187]
+	_ = x[PPPProtocolCHAP-49699]
+}
+
+const (
+	_PPPProtocol_name_0 = "PPPProtocolIPv4"
+	_PPPProtocol_name_1 = "PPPProtocolIPv6"
+	_PPPProtocol_name_2 = "PPPProtocolIPCP"
+	_PPPProtocol_name_3 = "PPPProtocolIPV6CP"
+	_PPPProtocol_name_4 = "PPPProtocolLCP"
+	_PPPProtocol_name_5 = "PPPProtocolPAP"
+	_PPPProtocol_name_6 = "PPPProtocolCHAP"
+)
+
+func (i PPPProtocol) String() string {
+	switch {
+	case i == 33:
+		return _PPPProtocol_name_0
+	case i == 87:
+		return _PPPProtocol_name_1
+	case i == 32801:
+		return _PPPProtocol_name_2
+	case i == 32855:
+		return _PPPProtocol_name_3
+	case i == 49185:
+		return _PPPProtocol_name_4
+	case i == 49187:
+		return _PPPProtocol_name_5
+	case i == 49699:
+		return _PPPProtocol_name_6
+	default:
+		return "PPPProtocol(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PPPControlCodeConfigureRequest-1]
+	_ = x[PPPControlCodeConfigureAck-2]
+	_ = x[PPPControlCodeConfigureNak-3]
+	_ = x[PPPControlCodeConfigureReject-4]
+	_ = x[PPPControlCodeTerminateRequest-5]
+	_ = x[PPPControlCodeTerminateAck-6]
+	_ = x[PPPControlCodeCodeReject-7]
+	_ = x[PPPControlCodeProtocolReject-8]
+	_ = x[PPPControlCodeEchoRequest-9]
+	_ = x[PPPControlCodeEchoReply-10]
+	_ = x[PPPControlCodeDiscardRequest-11]
+}
+
+const _PPPControlCode_name = "PPPControlCodeConfigureRequestPPPControlCodeConfigureAckPPPControlCodeConfigureNakPPPControlCodeConfigureRejectPPPControlCodeTerminateRequestPPPControlCodeTerminateAckPPPControlCodeCodeRejectPPPControlCodeProtocolRejectPPPControlCodeEchoRequestPPPControlCodeEchoReplyPPPControlCodeDiscardRequest"
+
+var _PPPControlCode_index = [...]uint16{0, 30, 56, 82, 111, 141, 167, 191, 219, 244, 267, 295}
+
+func (i PPPControlCode) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_PPPControlCode_index)-1 {
+		return "PPPControlCode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _PPPControlCode_name[_PPPControlCode_index[idx]:_PPPControlCode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PAPCodeAuthenticateRequest-1]
+	_ = x[PAPCodeAuthenticateAck-2]
+	_ = x[PAPCodeAuthenticateNak-3]
+}
+
+const _PAPCode_name = "PAPCodeAuthenticateRequestPAPCodeAuthenticateAckPAPCodeAuthenticateNak"
+
+var _PAPCode_index = [...]uint8{0, 26, 48, 70}
+
+func (i PAPCode) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_PAPCode_index)-1 {
+		return "PAPCode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _PAPCode_name[_PAPCode_index[idx]:_PAPCode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[CHAPCodeChallenge-1]
+	_ = x[CHAPCodeResponse-2]
+	_ = x[CHAPCodeSuccess-3]
+	_ = x[CHAPCodeFailure-4]
+}
+
+const _CHAPCode_name = "CHAPCodeChallengeCHAPCodeResponseCHAPCodeSuccessCHAPCodeFailure"
+
+var _CHAPCode_index = [...]uint8{0, 17, 33, 48, 63}
+
+func (i CHAPCode) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_CHAPCode_index)-1 {
+		return "CHAPCode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _CHAPCode_name[_CHAPCode_index[idx]:_CHAPCode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[Dot11TypeAssocReq-0]
+	_ = x[Dot11TypeAssocResp-1]
+	_ = x[Dot11TypeReassocReq-2]
+	_ = x[Dot11TypeReassocResp-3]
+	_ = x[Dot11TypeProbeReq-4]
+	_ = x[Dot11TypeProbeResp-5]
+	_ = x[Dot11TypeBeacon-8]
+	_ = x[Dot11TypeATIM-9]
+	_ = x[Dot11TypeDisassoc-10]
+	_ = x[Dot11TypeAuth-11]
+	_ = x[Dot11TypeDeauth-12]
+	_ = x[Dot11TypeAction-13]
+	_ = x[Dot11TypeActionNoAck-14]
+	_ = x[Dot11TypeBlockAckReq-24]
+	_ = x[Dot11TypeBlockAck-25]
+	_ = x[Dot11TypePSPoll-26]
+	_ = x[Dot11TypeRTS-27]
+	_ = x[Dot11TypeCTS-28]
+	_ = x[Dot11TypeACK-29]
+	_ = x[Dot11TypeCFEnd-30]
+	_ = x[Dot11TypeCFEndAck-31]
+	_ = x[Dot11TypeData-32]
+	_ = x[Dot11TypeNull-36]
+	_ = x[Dot11TypeQoSData-40]
+	_ = x[Dot11TypeQoSNull-44]
+}
+
+const (
+	_Dot11Type_name_0 = "Dot11TypeAssocReqDot11TypeAssocRespDot11TypeReassocReqDot11TypeReassocRespDot11TypeProbeReqDot11TypeProbeResp"
+	_Dot11Type_name_1 = "Dot11TypeBeaconDot11TypeATIMDot11TypeDisassocDot11TypeAuthDot11TypeDeauthDot11TypeActionDot11TypeActionNoAck"
+	_Dot11Type_name_2 = "Dot11TypeBlockAckReqDot11TypeBlockAckDot11TypePSPollDot11TypeRTSDot11TypeCTSDot11TypeACKDot11TypeCFEndDot11TypeCFEndAckDot11TypeData"
+	_Dot11Type_name_3 = "Dot11TypeNull"
+	_Dot11Type_name_4 = "Dot11TypeQoSData"
+	_Dot11Type_name_5 = "Dot11TypeQoSNull"
+)
+
+var (
+	_Dot11Type_index_0 = [...]uint8{0, 17, 35, 54, 74, 91, 109}
+	_Dot11Type_index_1 = [...]uint8{0, 15, 28, 45, 58, 73, 88, 108}
+	_Dot11Type_index_2 = [...]uint8{0, 20, 37, 52, 64, 76, 88, 102, 119, 132}
+)
+
+func (i Dot11Type) String() string {
+	switch {
+	case i <= 5:
+		return _Dot11Type_name_0[_Dot11Type_index_0[i]:_Dot11Type_index_0[i+1]]
+	case 8 <= i && i <= 14:
+		i -= 8
+		return _Dot11Type_name_1[_Dot11Type_index_1[i]:_Dot11Type_index_1[i+1]]
+	case 24 <= i && i <= 32:
+		i -= 24
+		return _Dot11Type_name_2[_Dot11Type_index_2[i]:_Dot11Type_index_2[i+1]]
+	case i == 36:
+		return _Dot11Type_name_3
+	case i == 40:
+		return _Dot11Type_name_4
+	case i == 44:
+		return _Dot11Type_name_5
+	default:
+		return "Dot11Type(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[Dot11IEIDSSID-0]
+	_ = x[Dot11IEIDSupportedRates-1]
+	_ = x[Dot11IEIDDSParameterSet-3]
+	_ = x[Dot11IEIDTIM-5]
+	_ = x[Dot11IEIDCountry-7]
+	_ = x[Dot11IEIDBSSLoad-11]
+	_ = x[Dot11IEIDChallengeText-16]
+	_ = x[Dot11IEIDPowerConstraint-32]
+	_ = x[Dot11IEIDERP-42]
+	_ = x[Dot11IEIDHTCapabilities-45]
+	_ = x[Dot11IEIDRSN-48]
+	_ = x[Dot11IEIDExtendedSupportedRates-50]
+	_ = x[Dot11IEIDHTOperation-61]
+	_ = x[Dot11IEIDExtendedCapabilities-127]
+	_ = x[Dot11IEIDVHTCapabilities-191]
+	_ = x[Dot11IEIDVHTOperation-192]
+	_ = x[Dot11IEIDVendorSpecific-221]
+	_ = x[Dot11IEIDExtension-255]
+}
+
+const _Dot11IEID_name = "Dot11IEIDSSIDDot11IEIDSupportedRatesDot11IEIDDSParameterSetDot11IEIDTIMDot11IEIDCountryDot11IEIDBSSLoadDot11IEIDChallengeTextDot11IEIDPowerConstraintDot11IEIDERPDot11IEIDHTCapabilitiesDot11IEIDRSNDot11IEIDExtendedSupportedRatesDot11IEIDHTOperationDot11IEIDExtendedCapabilitiesDot11IEIDVHTCapabilitiesDot11IEIDVHTOperationDot11IEIDVendorSpecificDot11IEIDExtension"
+
+var _Dot11IEID_map = map[Dot11IEID]string{
+	0:   _Dot11IEID_name[0:13],
+	1:   _Dot11IEID_name[13:36],
+	3:   _Dot11IEID_name[36:59],
+	5:   _Dot11IEID_name[59:71],
+	7:   _Dot11IEID_name[71:87],
+	11:  _Dot11IEID_name[87:103],
+	16:  _Dot11IEID_name[103:125],
+	32:  _Dot11IEID_name[125:149],
+	42:  _Dot11IEID_name[149:161],
+	45:  _Dot11IEID_name[161:184],
+	48:  _Dot11IEID_name[184:196],
+	50:  _Dot11IEID_name[196:227],
+	61:  _Dot11IEID_name[227:247],
+	127: _Dot11IEID_name[247:276],
+	191: _Dot11IEID_name[276:300],
+	192: _Dot11IEID_name[300:321],
+	221: _Dot11IEID_name[321:344],
+	255: _Dot11IEID_name[344:362],
+}
+
+func (i Dot11IEID) String() string {
+	if str, ok := _Dot11IEID_map[i]; ok {
+		return str
+	}
+	return "Dot11IEID(" + strconv.FormatInt(int64(i), 10) + ")"
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPMessageTypeOpen-1]
+	_ = x[BGPMessageTypeUpdate-2]
+	_ = x[BGPMessageTypeNotification-3]
+	_ = x[BGPMessageTypeKeepalive-4]
+	_ = x[BGPMessageTypeRouteRefresh-5]
+}
+
+const _BGPMessageType_name = "BGPMessageTypeOpenBGPMessageTypeUpdateBGPMessageTypeNotificationBGPMessageTypeKeepaliveBGPMessageTypeRouteRefresh"
+
+var _BGPMessageType_index = [...]uint8{0, 18, 38, 64, 87, 113}
+
+func (i BGPMessageType) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_BGPMessageType_index)-1 {
+		return "BGPMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _BGPMessageType_name[_BGPMessageType_index[idx]:_BGPMessageType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPCapabilityCodeMultiprotocol-1]
+	_ = x[BGPCapabilityCodeRouteRefresh-2]
+	_ = x[BGPCapabilityCodeExtendedNextHop-5]
+	_ = x[BGPCapabilityCodeExtendedMessage-6]
+	_ = x[BGPCapabilityCodeGracefulRestart-64]
+	_ = x[BGPCapabilityCodeFourOctetAS-65]
+	_ = x[BGPCapabilityCodeAddPath-69]
+	_ = x[BGPCapabilityCodeEnhancedRouteRefresh-70]
+}
+
+const (
+	_BGPCapabilityCode_name_0 = "BGPCapabilityCodeMultiprotocolBGPCapabilityCodeRouteRefresh"
+	_BGPCapabilityCode_name_1 = "BGPCapabilityCodeExtendedNextHopBGPCapabilityCodeExtendedMessage"
+	_BGPCapabilityCode_name_2 = "BGPCapabilityCodeGracefulRestartBGPCapabilityCodeFourOctetAS"
+	_BGPCapabilityCode_name_3 = "BGPCapabilityCodeAddPathBGPCapabilityCodeEnhancedRouteRefresh"
+)
+
+var (
+	_BGPCapabilityCode_index_0 = [...]uint8{0, 30, 59}
+	_BGPCapabilityCode_index_1 = [...]uint8{0, 32, 64}
+	_BGPCapabilityCode_index_2 = [...]uint8{0, 32, 60}
+	_BGPCapabilityCode_index_3 = [...]uint8{0, 24, 61}
+)
+
+func (i BGPCapabilityCode) String() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _BGPCapabilityCode_name_0[_BGPCapabilityCode_index_0[i]:_BGPCapabilityCode_index_0[i+1]]
+	case 5 <= i && i <= 6:
+		i -= 5
+		return _BGPCapabilityCode_name_1[_BGPCapabilityCode_index_1[i]:_BGPCapabilityCode_index_1[i+1]]
+	case 64 <= i && i <= 65:
+		i -= 64
+		return _BGPCapabilityCode_name_2[_BGPCapabilityCode_index_2[i]:_BGPCapabilityCode_index_2[i+1]]
+	case 69 <= i && i <= 70:
+		i -= 69
+		return _BGPCapabilityCode_name_3[_BGPCapabilityCode_index_3[i]:_BGPCapabilityCode_index_3[i+1]]
+	default:
+		return "BGPCapabilityCode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPAFIIPv4-1]
+	_ = x[BGPAFIIPv6-2]
+}
+
+const _BGPAFI_name = "BGPAFIIPv4BGPAFIIPv6"
+
+var _BGPAFI_index = [...]uint8{0, 10, 20}
+
+func (i BGPAFI) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_BGPAFI_index)-1 {
+		return "BGPAFI(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _BGPAFI_name[_BGPAFI_index[idx]:_BGPAFI_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPSAFIUnicast-1]
+	_ = x[BGPSAFIMulticast-2]
+}
+
+const _BGPSAFI_name = "BGPSAFIUnicastBGPSAFIMulticast"
+
+var _BGPSAFI_index = [...]uint8{0, 14, 30}
+
+func (i BGPSAFI) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_BGPSAFI_index)-1 {
+		return "BGPSAFI(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _BGPSAFI_name[_BGPSAFI_index[idx]:_BGPSAFI_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPAttributeTypeOrigin-1]
+	_ = x[BGPAttributeTypeASPath-2]
+	_ = x[BGPAttributeTypeNextHop-3]
+	_ = x[BGPAttributeTypeMED-4]
+	_ = x[BGPAttributeTypeLocalPref-5]
+	_ = x[BGPAttributeTypeAtomicAggregate-6]
+	_ = x[BGPAttributeTypeAggregator-7]
+	_ = x[BGPAttributeTypeCommunities-8]
+	_ = x[BGPAttributeTypeOriginatorID-9]
+	_ = x[BGPAttributeTypeClusterList-10]
+	_ = x[BGPAttributeTypeMPReachNLRI-14]
+	_ = x[BGPAttributeTypeMPUnreachNLRI-15]
+	_ = x[BGPAttributeTypeExtendedCommunities-16]
+	_ = x[BGPAttributeTypeAS4Path-17]
+	_ = x[BGPAttributeTypeAS4Aggregator-18]
+	_ = x[BGPAttributeTypeLargeCommunities-32]
+}
+
+const (
+	_BGPAttributeType_name_0 = "BGPAttributeTypeOriginBGPAttributeTypeASPathBGPAttributeTypeNextHopBGPAttributeTypeMEDBGPAttributeTypeLocalPrefBGPAttributeTypeAtomicAggregateBGPAttributeTypeAggregatorBGPAttributeTypeCommunitiesBGPAttributeTypeOriginatorIDBGPAttributeTypeClusterList"
+	_BGPAttributeType_name_1 = "BGPAttributeTypeMPReachNLRIBGPAttributeTypeMPUnreachNLRIBGPAttributeTypeExtendedCommunitiesBGPAttributeTypeAS4PathBGPAttributeTypeAS4Aggregator"
+	_BGPAttributeType_name_2 = "BGPAttributeTypeLargeCommunities"
+)
+
+var (
+	_BGPAttributeType_index_0 = [...]uint8{0, 22, 44, 67, 86, 111, 142, 168, 195, 223, 250}
+	_BGPAttributeType_index_1 = [...]uint8{0, 27, 56, 91, 114, 143}
+)
+
+func (i BGPAttributeType) String() string {
+	switch {
+	case 1 <= i && i <= 10:
+		i -= 1
+		return _BGPAttributeType_name_0[_BGPAttributeType_index_0[i]:_BGPAttributeType_index_0[i+1]]
+	case 14 <= i && i <= 18:
+		i -= 14
+		return _BGPAttributeType_name_1[_BGPAttributeType_index_1[i]:_BGPAttributeType_index_1[i+1]]
+	case i == 32:
+		return _BGPAttributeType_name_2
+	default:
+		return "BGPAttributeType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPOriginIGP-0]
+	_ = x[BGPOriginEGP-1]
+	_ = x[BGPOriginIncomplete-2]
+}
+
+const _BGPOrigin_name = "BGPOriginIGPBGPOriginEGPBGPOriginIncomplete"
+
+var _BGPOrigin_index = [...]uint8{0, 12, 24, 43}
+
+func (i BGPOrigin) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_BGPOrigin_index)-1 {
+		return "BGPOrigin(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _BGPOrigin_name[_BGPOrigin_index[idx]:_BGPOrigin_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPSegmentTypeSet-1]
+	_ = x[BGPSegmentTypeSequence-2]
+	_ = x[BGPSegmentTypeConfedSequence-3]
+	_ = x[BGPSegmentTypeConfedSet-4]
+}
+
+const _BGPSegmentType_name = "BGPSegmentTypeSetBGPSegmentTypeSequenceBGPSegmentTypeConfedSequenceBGPSegmentTypeConfedSet"
+
+var _BGPSegmentType_index = [...]uint8{0, 17, 39, 67, 90}
+
+func (i BGPSegmentType) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_BGPSegmentType_index)-1 {
+		return "BGPSegmentType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _BGPSegmentType_name[_BGPSegmentType_index[idx]:_BGPSegmentType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[BGPErrorCodeMessageHeader-1]
+	_ = x[BGPErrorCodeOpenMessage-2]
+	_ = x[BGPErrorCodeUpdateMessage-3]
+	_ = x[BGPErrorCodeHoldTimerExpired-4]
+	_ = x[BGPErrorCodeFSM-5]
+	_ = x[BGPErrorCodeCease-6]
+	_ = x[BGPErrorCodeRouteRefresh-7]
+}
+
+const _BGPErrorCode_name = "BGPErrorCodeMessageHeaderBGPErrorCodeOpenMessageBGPErrorCodeUpdateMessageBGPErrorCodeHoldTimerExpiredBGPErrorCodeFSMBGPErrorCodeCeaseBGPErrorCodeRouteRefresh"
+
+var _BGPErrorCode_index = [...]uint8{0, 25, 48, 73, 101, 116, 133, 157}
+
+func (i BGPErrorCode) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_BGPErrorCode_index)-1 {
+		return "BGPErrorCode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _BGPErrorCode_name[_BGPErrorCode_index[idx]:_BGPErrorCode_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[OSPFTypeHello-1]
+	_ = x[OSPFTypeDBD-2]
+	_ = x[OSPFTypeLSR-3]
+	_ = x[OSPFTypeLSU-4]
+	_ = x[OSPFTypeLSAck-5]
+}
+
+const _OSPFType_name = "OSPFTypeHelloOSPFTypeDBDOSPFTypeLSROSPFTypeLSUOSPFTypeLSAck"
+
+var _OSPFType_index = [...]uint8{0, 13, 24, 35, 46, 59}
+
+func (i OSPFType) String() string {
+	idx := int(i) - 1
+	if i < 1 || idx >= len(_OSPFType_index)-1 {
+		return "OSPFType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _OSPFType_name[_OSPFType_index[idx]:_OSPFType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[OSPFLSTypeRouter-1]
+	_ = x[OSPFLSTypeNetwork-2]
+	_ = x[OSPFLSTypeSummary-3]
+	_ = x[OSPFLSTypeASBRSummary-4]
+	_ = x[OSPFLSTypeASExternal-5]
+	_ = x[OSPFLSTypeNSSA-7]
+	_ = x[OSPFLSTypeV3Router-8193]
+	_ = x[OSPFLSTypeV3Network-8194]
+	_ = x[OSPFLSTypeV3InterAreaPrefix-8195]
+	_ = x[OSPFLSTypeV3InterAreaRouter-8196]
+	_ = x[OSPFLSTypeV3ASExternal-16389]
+	_ = x[OSPFLSTypeV3NSSA-8199]
+	_ = x[OSPFLSTypeV3Link-8]
+	_ = x[OSPFLSTypeV3IntraAreaPrefix-8201]
+}
+
+const (
+	_OSPFLSType_name_0 = "OSPFLSTypeRouterOSPFLSTypeNetworkOSPFLSTypeSummaryOSPFLSTypeASBRSummaryOSPFLSTypeASExternal"
+	_OSPFLSType_name_1 = "OSPFLSTypeNSSAOSPFLSTypeV3Link"
+	_OSPFLSType_name_2 = "OSPFLSTypeV3RouterOSPFLSTypeV3NetworkOSPFLSTypeV3InterAreaPrefixOSPFLSTypeV3InterAreaRouter"
+	_OSPFLSType_name_3 = "OSPFLSTypeV3NSSA"
+	_OSPFLSType_name_4 = "OSPFLSTypeV3IntraAreaPrefix"
+	_OSPFLSType_name_5 = "OSPFLSTypeV3ASExternal"
+)
+
+var (
+	_OSPFLSType_index_0 = [...]uint8{0, 16, 33, 50, 71, 91}
+	_OSPFLSType_index_1 = [...]uint8{0, 14, 30}
+	_OSPFLSType_index_2 = [...]uint8{0, 18, 37, 64, 91}
+)
+
+func (i OSPFLSType) String() string {
+	switch {
+	case 1 <= i && i <= 5:
+		i -= 1
+		return _OSPFLSType_name_0[_OSPFLSType_index_0[i]:_OSPFLSType_index_0[i+1]]
+	case 7 <= i && i <= 8:
+		i -= 7
+		return _OSPFLSType_name_1[_OSPFLSType_index_1[i]:_OSPFLSType_index_1[i+1]]
+	case 8193 <= i && i <= 8196:
+		i -= 8193
+		return _OSPFLSType_name_2[_OSPFLSType_index_2[i]:_OSPFLSType_index_2[i+1]]
+	case i == 8199:
+		return _OSPFLSType_name_3
+	case i == 8201:
+		return _OSPFLSType_name_4
+	case i == 16389:
+		return _OSPFLSType_name_5
+	default:
+		return "OSPFLSType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[RTCPPacketTypeSR-200]
+	_ = x[RTCPPacketTypeRR-201]
+	_ = x[RTCPPacketTypeSDES-202]
+	_ = x[RTCPPacketTypeBYE-203]
+	_ = x[RTCPPacketTypeAPP-204]
+	_ = x[RTCPPacketTypeRTPFB-205]
+	_ = x[RTCPPacketTypePSFB-206]
+	_ = x[RTCPPacketTypeXR-207]
+}
+
+const _RTCPPacketType_name = "RTCPPacketTypeSRRTCPPacketTypeRRRTCPPacketTypeSDESRTCPPacketTypeBYERTCPPacketTypeAPPRTCPPacketTypeRTPFBRTCPPacketTypePSFBRTCPPacketTypeXR"
+
+var _RTCPPacketType_index = [...]uint8{0, 16, 32, 50, 67, 84, 103, 121, 137}
+
+func (i RTCPPacketType) String() string {
+	idx := int(i) - 200
+	if i < 200 || idx >= len(_RTCPPacketType_index)-1 {
+		return "RTCPPacketType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _RTCPPacketType_name[_RTCPPacketType_index[idx]:_RTCPPacketType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[RTCPSDESTypeEnd-0]
+	_ = x[RTCPSDESTypeCNAME-1]
+	_ = x[RTCPSDESTypeName-2]
+	_ = x[RTCPSDESTypeEmail-3]
+	_ = x[RTCPSDESTypePhone-4]
+	_ = x[RTCPSDESTypeLoc-5]
+	_ = x[RTCPSDESTypeTool-6]
+	_ = x[RTCPSDESTypeNote-7]
+	_ = x[RTCPSDESTypePriv-8]
+}
+
+const _RTCPSDESType_name = "RTCPSDESTypeEndRTCPSDESTypeCNAMERTCPSDESTypeNameRTCPSDESTypeEmailRTCPSDESTypePhoneRTCPSDESTypeLocRTCPSDESTypeToolRTCPSDESTypeNoteRTCPSDESTypePriv"
+
+var _RTCPSDESType_index = [...]uint8{0, 15, 32, 48, 65, 82, 97, 113, 129, 145}
+
+func (i RTCPSDESType) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_RTCPSDESType_index)-1 {
+		return "RTCPSDESType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _RTCPSDESType_name[_RTCPSDESType_index[idx]:_RTCPSDESType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[SNMPVersion1-0]
+	_ = x[SNMPVersion2c-1]
+	_ = x[SNMPVersion3-3]
+}
+
+const (
+	_SNMPVersion_name_0 = "SNMPVersion1SNMPVersion2c"
+	_SNMPVersion_name_1 = "SNMPVersion3"
+)
+
+var (
+	_SNMPVersion_index_0 = [...]uint8{0, 12, 25}
+)
+
+func (i SNMPVersion) String() string {
+	switch {
+	case i <= 1:
+		return _SNMPVersion_name_0[_SNMPVersion_index_0[i]:_SNMPVersion_index_0[i+1]]
+	case i == 3:
+		return _SNMPVersion_name_1
+	default:
+		return "SNMPVersion(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[SNMPPDUTypeGetRequest-0]
+	_ = x[SNMPPDUTypeGetNextRequest-1]
+	_ = x[SNMPPDUTypeResponse-2]
+	_ = x[SNMPPDUTypeSetRequest-3]
+	_ = x[SNMPPDUTypeTrap-4]
+	_ = x[SNMPPDUTypeGetBulkRequest-5]
+	_ = x[SNMPPDUTypeInformRequest-6]
+	_ = x[SNMPPDUTypeTrapV2-7]
+	_ = x[SNMPPDUTypeReport-8]
+}
+
+const _SNMPPDUType_name = "SNMPPDUTypeGetRequestSNMPPDUTypeGetNextRequestSNMPPDUTypeResponseSNMPPDUTypeSetRequestSNMPPDUTypeTrapSNMPPDUTypeGetBulkRequestSNMPPDUTypeInformRequestSNMPPDUTypeTrapV2SNMPPDUTypeReport"
+
+var _SNMPPDUType_index = [...]uint8{0, 21, 46, 65, 86, 101, 126, 150, 167, 184}
+
+func (i SNMPPDUType) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_SNMPPDUType_index)-1 {
+		return "SNMPPDUType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _SNMPPDUType_name[_SNMPPDUType_index[idx]:_SNMPPDUType_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[SNMPErrorStatusNoError-0]
+	_ = x[SNMPErrorStatusTooBig-1]
+	_ = x[SNMPErrorStatusNoSuchName-2]
+	_ = x[SNMPErrorStatusBadValue-3]
+	_ = x[SNMPErrorStatusReadOnly-4]
+	_ = x[SNMPErrorStatusGenErr-5]
+	_ = x[SNMPErrorStatusNoAccess-6]
+	_ = x[SNMPErrorStatusWrongType-7]
+	_ = x[SNMPErrorStatusWrongLength-8]
+	_ = x[SNMPErrorStatusWrongEncoding-9]
+	_ = x[SNMPErrorStatusWrongValue-10]
+	_ = x[SNMPErrorStatusNoCreation-11]
+	_ = x[SNMPErrorStatusInconsistentValue-12]
+	_ = x[SNMPErrorStatusResourceUnavailable-13]
+	_ = x[SNMPErrorStatusCommitFailed-14]
+	_ = x[SNMPErrorStatusUndoFailed-15]
+	_ = x[SNMPErrorStatusAuthorizationError-16]
+	_ = x[SNMPErrorStatusNotWritable-17]
+	_ = x[SNMPErrorStatusInconsistentName-18]
+}
+
+const _SNMPErrorStatus_name = "SNMPErrorStatusNoErrorSNMPErrorStatusTooBigSNMPErrorStatusNoSuchNameSNMPErrorStatusBadValueSNMPErrorStatusReadOnlySNMPErrorStatusGenErrSNMPErrorStatusNoAccessSNMPErrorStatusWrongTypeSNMPErrorStatusWrongLengthSNMPErrorStatusWrongEncodingSNMPErrorStatusWrongValueSNMPErrorStatusNoCreationSNMPErrorStatusInconsistentValueSNMPErrorStatusResourceUnavailableSNMPErrorStatusCommitFailedSNMPErrorStatusUndoFailedSNMPErrorStatusAuthorizationErrorSNMPErrorStatusNotWritableSNMPErrorStatusInconsistentName"
+
+var _SNMPErrorStatus_index = [...]uint16{0, 22, 43, 68, 91, 114, 135, 158, 182, 208, 236, 261, 286, 318, 352, 379, 404, 437, 463, 494}
+
+func (i SNMPErrorStatus) String() string {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_SNMPErrorStatus_index)-1 {
+		return "SNMPErrorStatus(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _SNMPErrorStatus_name[_SNMPErrorStatus_index[idx]:_SNMPErrorStatus_index[idx+1]]
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[SNMPValueTypeInteger-2]
+	_ = x[SNMPValueTypeOctetString-4]
+	_ = x[SNMPValueTypeNull-5]
+	_ = x[SNMPValueTypeOID-6]
+	_ = x[SNMPValueTypeIPAddress-64]
+	_ = x[SNMPValueTypeCounter32-65]
+	_ = x[SNMPValueTypeGauge32-66]
+	_ = x[SNMPValueTypeTimeTicks-67]
+	_ = x[SNMPValueTypeOpaque-68]
+	_ = x[SNMPValueTypeCounter64-70]
+	_ = x[SNMPValueTypeNoSuchObject-128]
+	_ = x[SNMPValueTypeNoSuchInstance-129]
+	_ = x[SNMPValueTypeEndOfMibView-130]
+}
+
+const (
+	_SNMPValueType_name_0 = "SNMPValueTypeInteger"
+	_SNMPValueType_name_1 = "SNMPValueTypeOctetStringSNMPValueTypeNullSNMPValueTypeOID"
+	_SNMPValueType_name_2 = "SNMPValueTypeIPAddressSNMPValueTypeCounter32SNMPValueTypeGauge32SNMPValueTypeTimeTicksSNMPValueTypeOpaque"
+	_SNMPValueType_name_3 = "SNMPValueTypeCounter64"
+	_SNMPValueType_name_4 = "SNMPValueTypeNoSuchObjectSNMPValueTypeNoSuchInstanceSNMPValueTypeEndOfMibView"
+)
+
+var (
+	_SNMPValueType_index_1 = [...]uint8{0, 24, 41, 57}
+	_SNMPValueType_index_2 = [...]uint8{0, 22, 44, 64, 86, 105}
+	_SNMPValueType_index_4 = [...]uint8{0, 25, 52, 77}
+)
+
+func (i SNMPValueType) String() string {
+	switch {
+	case i == 2:
+		return _SNMPValueType_name_0
+	case 4 <= i && i <= 6:
+		i -= 4
+		return _SNMPValueType_name_1[_SNMPValueType_index_1[i]:_SNMPValueType_index_1[i+1]]
+	case 64 <= i && i <= 68:
+		i -= 64
+		return _SNMPValueType_name_2[_SNMPValueType_index_2[i]:_SNMPValueType_index_2[i+1]]
+	case i == 70:
+		return _SNMPValueType_name_3
+	case 128 <= i && i <= 130:
+		i -= 128
+		return _SNMPValueType_name_4[_SNMPValueType_index_4[i]:_SNMPValueType_index_4[i+1]]
+	default:
+		return "SNMPValueType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[GTPUMessageTypeEchoRequest-1]
+	_ = x[GTPUMessageTypeEchoResponse-2]
+	_ = x[GTPUMessageTypeErrorIndication-26]
+	_ = x[GTPUMessageTypeSupportedExtHeaders-31]
+	_ = x[GTPUMessageTypeTunnelStatus-253]
+	_ = x[GTPUMessageTypeEndMarker-254]
+	_ = x[GTPUMessageTypeGPDU-255]
+}
+
+const (
+	_GTPUMessageType_name_0 = "GTPUMessageTypeEchoRequestGTPUMessageTypeEchoResponse"
+	_GTPUMessageType_name_1 = "GTPUMessageTypeErrorIndication"
+	_GTPUMessageType_name_2 = "GTPUMessageTypeSupportedExtHeaders"
+	_GTPUMessageType_name_3 = "GTPUMessageTypeTunnelStatusGTPUMessageTypeEndMarkerGTPUMessageTypeGPDU"
+)
+
+var (
+	_GTPUMessageType_index_0 = [...]uint8{0, 26, 53}
+	_GTPUMessageType_index_3 = [...]uint8{0, 27, 51, 70}
+)
+
+func (i GTPUMessageType) String() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _GTPUMessageType_name_0[_GTPUMessageType_index_0[i]:_GTPUMessageType_index_0[i+1]]
+	case i == 26:
+		return _GTPUMessageType_name_1
+	case i == 31:
+		return _GTPUMessageType_name_2
+	case 253 <= i && i <= 255:
+		i -= 253
+		return _GTPUMessageType_name_3[_GTPUMessageType_index_3[i]:_GTPUMessageType_index_3[i+1]]
+	default:
+		return "GTPUMessageType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[GTPExtensionTypeNone-0]
+	_ = x[GTPExtensionTypeServiceClass-32]
+	_ = x[GTPExtensionTypeUDPPort-64]
+	_ = x[GTPExtensionTypeRANContainer-129]
+	_ = x[GTPExtensionTypeLongPDCPPDUNumber-130]
+	_ = x[GTPExtensionTypeXwRANContainer-131]
+	_ = x[GTPExtensionTypeNRRANContainer-132]
+	_ = x[GTPExtensionTypePDUSessionContainer-133]
+	_ = x[GTPExtensionTypePDCPPDUNumber-192]
+}
+
+const (
+	_GTPExtensionType_name_0 = "GTPExtensionTypeNone"
+	_GTPExtensionType_name_1 = "GTPExtensionTypeServiceClass"
+	_GTPExtensionType_name_2 = "GTPExtensionTypeUDPPort"
+	_GTPExtensionType_name_3 = "GTPExtensionTypeRANContainerGTPExtensionTypeLongPDCPPDUNumberGTPExtensionTypeXwRANContainerGTPExtensionTypeNRRANContainerGTPExtensionTypePDUSessionContainer"
+	_GTPExtensionType_name_4 = "GTPExtensionTypePDCPPDUNumber"
+)
+
+var (
+	_GTPExtensionType_index_3 = [...]uint8{0, 28, 61, 91, 121, 156}
+)
+
+func (i GTPExtensionType) String() string {
+	switch {
+	case i == 0:
+		return _GTPExtensionType_name_0
+	case i == 32:
+		return _GTPExtensionType_name_1
+	case i == 64:
+		return _GTPExtensionType_name_2
+	case 129 <= i && i <= 133:
+		i -= 129
+		return _GTPExtensionType_name_3[_GTPExtensionType_index_3[i]:_GTPExtensionType_index_3[i+1]]
+	case i == 192:
+		return _GTPExtensionType_name_4
+	default:
+		return "GTPExtensionType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[GTPv2MessageTypeEchoRequest-1]
+	_ = x[GTPv2MessageTypeEchoResponse-2]
+	_ = x[GTPv2MessageTypeVersionNotSupported-3]
+	_ = x[GTPv2MessageTypeCreateSessionRequest-32]
+	_ = x[GTPv2MessageTypeCreateSessionResponse-33]
+	_ = x[GTPv2MessageTypeModifyBearerRequest-34]
+	_ = x[GTPv2MessageTypeModifyBearerResponse-35]
+	_ = x[GTPv2MessageTypeDeleteSessionRequest-36]
+	_ = x[GTPv2MessageTypeDeleteSessionResponse-37]
+	_ = x[GTPv2MessageTypeDownlinkDataNotificationFailed-70]
+	_ = x[GTPv2MessageTypeCreateBearerRequest-95]
+	_ = x[GTPv2MessageTypeCreateBearerResponse-96]
+	_ = x[GTPv2MessageTypeUpdateBearerRequest-97]
+	_ = x[GTPv2MessageTypeUpdateBearerResponse-98]
+	_ = x[GTPv2MessageTypeDeleteBearerRequest-99]
+	_ = x[GTPv2MessageTypeDeleteBearerResponse-100]
+	_ = x[GTPv2MessageTypeReleaseAccessBearersRequest-170]
+	_ = x[GTPv2MessageTypeReleaseAccessBearersResponse-171]
+	_ = x[GTPv2MessageTypeDownlinkDataNotification-176]
+	_ = x[GTPv2MessageTypeDownlinkDataNotificationAck-177]
+}
+
+const (
+	_GTPv2MessageType_name_0 = "GTPv2MessageTypeEchoRequestGTPv2MessageTypeEchoResponseGTPv2MessageTypeVersionNotSupported"
+	_GTPv2MessageType_name_1 = "GTPv2MessageTypeCreateSessionRequestGTPv2MessageTypeCreateSessionResponseGTPv2MessageTypeModifyBearerRequestGTPv2MessageTypeModifyBearerResponseGTPv2MessageTypeDeleteSessionRequestGTPv2MessageTypeDeleteSessionResponse"
+	_GTPv2MessageType_name_2 = "GTPv2MessageTypeDownlinkDataNotificationFailed"
+	_GTPv2MessageType_name_3 = "GTPv2MessageTypeCreateBearerRequestGTPv2MessageTypeCreateBearerResponseGTPv2MessageTypeUpdateBearerRequestGTPv2MessageTypeUpdateBearerResponseGTPv2MessageTypeDeleteBearerRequestGTPv2MessageTypeDeleteBearerResponse"
+	_GTPv2MessageType_name_4 = "GTPv2MessageTypeReleaseAccessBearersRequestGTPv2MessageTypeReleaseAccessBearersResponse"
+	_GTPv2MessageType_name_5 = "GTPv2MessageTypeDownlinkDataNotificationGTPv2MessageTypeDownlinkDataNotificationAck"
+)
+
+var (
+	_GTPv2MessageType_index_0 = [...]uint8{0, 27, 55, 90}
+	_GTPv2MessageType_index_1 = [...]uint8{0, 36, 73, 108, 144, 180, 217}
+	_GTPv2MessageType_index_3 = [...]uint8{0, 35, 71, 106, 142, 177, 213}
+	_GTPv2MessageType_index_4 = [...]uint8{0, 43, 87}
+	_GTPv2MessageType_index_5 = [...]uint8{0, 40, 83}
+)
+
+func (i GTPv2MessageType) String() string {
+	switch {
+	case 1 <= i && i <= 3:
+		i -= 1
+		return _GTPv2MessageType_name_0[_GTPv2MessageType_index_0[i]:_GTPv2MessageType_index_0[i+1]]
+	case 32 <= i && i <= 37:
+		i -= 32
+		return _GTPv2MessageType_name_1[_GTPv2MessageType_index_1[i]:_GTPv2MessageType_index_1[i+1]]
+	case i == 70:
+		return _GTPv2MessageType_name_2
+	case 95 <= i && i <= 100:
+		i -= 95
+		return _GTPv2MessageType_name_3[_GTPv2MessageType_index_3[i]:_GTPv2MessageType_index_3[i+1]]
+	case 170 <= i && i <= 171:
+		i -= 170
+		return _GTPv2MessageType_name_4[_GTPv2MessageType_index_4[i]:_GTPv2MessageType_index_4[i+1]]
+	case 176 <= i && i <= 177:
+		i -= 176
+		return _GTPv2MessageType_name_5[_GTPv2MessageType_index_5[i]:_GTPv2MessageType_index_5[i+1]]
+	default:
+		return "GTPv2MessageType(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[GTPv2IETypeIMSI-1]
+	_ = x[GTPv2IETypeCause-2]
+	_ = x[GTPv2IETypeRecovery-3]
+	_ = x[GTPv2IETypeAPN-71]
+	_ = x[GTPv2IETypeAMBR-72]
+	_ = x[GTPv2IETypeEBI-73]
+	_ = x[GTPv2IETypeIPAddress-74]
+	_ = x[GTPv2IETypeMEI-75]
+	_ = x[GTPv2IETypeMSISDN-76]
+	_ = x[GTPv2IETypeIndication-77]
+	_ = x[GTPv2IETypePCO-78]
+	_ = x[GTPv2IETypePAA-79]
+	_ = x[GTPv2IETypeBearerQoS-80]
+	_ = x[GTPv2IETypeFlowQoS-81]
+	_ = x[GTPv2IETypeRATType-82]
+	_ = x[GTPv2IETypeServingNetwork-83]
+	_ = x[GTPv2IETypeBearerTFT-84]
+	_ = x[GTPv2IETypeTAD-85]
+	_ = x[GTPv2IETypeULI-86]
+	_ = x[GTPv2IETypeFTEID-87]
+	_ = x[GTPv2IETypeTMSI-88]
+	_ = x[GTPv2IETypeGlobalCNID-89]
+	_ = x[GTPv2IETypeS103PDF-90]
+	_ = x[GTPv2IETypeS1UDF-91]
+	_ = x[GTPv2IETypeDelayValue-92]
+	_ = x[GTPv2IETypeBearerContext-93]
+	_ = x[GTPv2IETypeChargingID-94]
+	_ = x[GTPv2IETypeChargingCharacteristics-95]
+	_ = x[GTPv2IETypeTraceInformation-96]
+	_ = x[GTPv2IETypeBearerFlags-97]
+	_ = x[GTPv2IETypePDNType-99]
+	_ = x[GTPv2IETypePTI-100]
+	_ = x[GTPv2IETypePDNConnection-109]
+	_ = x[GTPv2IETypeUETimeZone-114]
+	_ = x[GTPv2IETypeAPNRestriction-127]
+	_ = x[GTPv2IETypeSelectionMode-128]
+	_ = x[GTPv2IETypeFQDN-136]
+	_ = x[GTPv2IETypeOverloadControlInfo-180]
+	_ = x[GTPv2IETypeLoadControlInfo-181]
+	_ = x[GTPv2IETypePrivateExtension-255]
+}
+
+const (
+	_GTPv2IEType_name_0 = "GTPv2IETypeIMSIGTPv2IETypeCauseGTPv2IETypeRecovery"
+	_GTPv2IEType_name_1 = "GTPv2IETypeAPNGTPv2IETypeAMBRGTPv2IETypeEBIGTPv2IETypeIPAddressGTPv2IETypeMEIGTPv2IETypeMSISDNGTPv2IETypeIndicationGTPv2IETypePCOGTPv2IETypePAAGTPv2IETypeBearerQoSGTPv2IETypeFlowQoSGTPv2IETypeRATTypeGTPv2IETypeServingNetworkGTPv2IETypeBearerTFTGTPv2IETypeTADGTPv2IETypeULIGTPv2IETypeFTEIDGTPv2IETypeTMSIGTPv2IETypeGlobalCNIDGTPv2IETypeS103PDFGTPv2IETypeS1UDFGTPv2IETypeDelayValueGTPv2IETypeBearerContextGTPv2IETypeChargingIDGTPv2IETypeChargingCharacteristicsGTPv2IETypeTraceInformationGTPv2IETypeBearerFlags"
+	_GTPv2IEType_name_2 = "GTPv2IETypePDNTypeGTPv2IETypePTI"
+	_GTPv2IEType_name_3 = "GTPv2IETypePDNConnection"
+	_GTPv2IEType_name_4 = "GTPv2IETypeUETimeZone"
+	_GTPv2IEType_name_5 = "GTPv2IETypeAPNRestrictionGTPv2IETypeSelectionMode"
+	_GTPv2IEType_name_6 = "GTPv2IETypeFQDN"
+	_GTPv2IEType_name_7 = "GTPv2IETypeOverloadControlInfoGTPv2IETypeLoadControlInfo"
+	_GTPv2IEType_name_8 = "GTPv2IETypePrivateExtension"
+)
+
+var (
+	_GTPv2IEType_index_0 = [...]uint8{0, 15, 31, 50}
+	_GTPv2IEType_index_1 = [...]uint16{0, 14, 29, 43, 63, 77, 94, 115, 129, 143, 163, 181, 199, 224, 244, 258, 272, 288, 303, 324, 342, 358, 379, 403, 424, 458, 485, 507}
+	_GTPv2IEType_index_2 = [...]uint8{0, 18, 32}
+	_GTPv2IEType_index_5 = [...]uint8{0, 25, 49}
+	_GTPv2IEType_index_7 = [...]uint8{0, 30, 56}
+)
+
+func (i GTPv2IEType) String() string {
+	switch {
+	case 1 <= i && i <= 3:
+		i -= 1
+		return _GTPv2IEType_name_0[_GTPv2IEType_index_0[i]:_GTPv2IEType_index_0[i+1]]
+	case 71 <= i && i <= 97:
+		i -= 71
+		return _GTPv2IEType_name_1[_GTPv2IEType_index_1[i]:_GTPv2IEType_index_1[i+1]]
+	case 99 <= i && i <= 100:
+		i -= 99
+		return _GTPv2IEType_name_2[_GTPv2IEType_index_2[i]:_GTPv2IEType_index_2[i+1]]
+	case i == 109:
+		return _GTPv2IEType_name_3
+	case i == 114:
+		return _GTPv2IEType_name_4
+	case 127 <= i && i <= 128:
+		i -= 127
+		return _GTPv2IEType_name_5[_GTPv2IEType_index_5[i]:_GTPv2IEType_index_5[i+1]]
+	case i == 136:
+		return _GTPv2IEType_name_6
+	case 180 <= i && i <= 181:
+		i -= 180
+		return _GTPv2IEType_name_7[_GTPv2IEType_index_7[i]:_GTPv2IEType_index_7[i+1]]
+	case i == 255:
+		return _GTPv2IEType_name_8
+	default:
+		return "GTPv2IEType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _ARPOpCode_name[_ARPOpCode_index[i]:_ARPOpCode_index[i+1]]
 }
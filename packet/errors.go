@@ -0,0 +1,24 @@
+package packet
+
+import "errors"
+
+// Errors returned by the decoders wrap one of the following errors, which
+// tell why decoding failed. The error messages describe the layer and field
+// at fault.
+var (
+	// ErrTruncated is returned when a layer needs more bytes than remain in
+	// the packet, such as for captures with a short snap length.
+	ErrTruncated = errors.New("packet truncated")
+
+	// ErrInvalid is returned when a field has a value which the protocol
+	// does not allow, such as a length shorter than the header.
+	ErrInvalid = errors.New("invalid packet")
+
+	// ErrUnsupported is returned for versions and types which are not
+	// decoded by this package.
+	ErrUnsupported = errors.New("unsupported packet")
+
+	// ErrAuthFailed is returned when the integrity check of a decrypted
+	// layer fails.
+	ErrAuthFailed = errors.New("authentication failed")
+)
@@ -2,7 +2,7 @@ package packet
 
 import (
 	"encoding/binary"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"net"
 )
@@ -10,36 +10,97 @@ import (
 type EtherType uint16
 
 const (
+	// EthernetTypeLLC is a non-standard value used for 802.3 frames, whose
+	// type field contains the length of an LLC frame.
+	EthernetTypeLLC EtherType = 0
+	// EtherTypeMaxLength is the largest value of the type field which is a
+	// length.
+	EtherTypeMaxLength EtherType = 0x05DC
+
+	// Deprecated: EtherTypes from 0x0600 are accepted.
 	EtherTypeTooLow EtherType = 0x07FF
 
-	EthernetTypeIPv4 EtherType = 0x0800
-	EthernetTypeARP  EtherType = 0x0806
-	EthernetTypeIPv6 EtherType = 0x86DD
+	EthernetTypeIPv4  EtherType = 0x0800
+	EthernetTypeARP   EtherType = 0x0806
+	EthernetTypeDot1Q EtherType = 0x8100
+	EthernetTypeIPv6  EtherType = 0x86DD
 
+	// Deprecated: EtherTypes above EthernetTypeIPv6 are accepted.
 	EtherTypeTooHigh EtherType = 0x86DE
+
+	EthernetTypePPPoEDiscovery EtherType = 0x8863
+	EthernetTypePPPoESession   EtherType = 0x8864
+	EthernetTypeQinQ           EtherType = 0x88A8
+	EthernetTypeMACsec         EtherType = 0x88E5
+	EthernetTypePTP            EtherType = 0x88F7
 )
 
-// Interface guard
-var _ Layer = new(Ethernet)
+// VLAN is an IEEE 802.1Q tag.
+type VLAN struct {
+	// TPID is the tag protocol identifier, i.e. the type preceding the tag.
+	TPID         EtherType
+	Priority     uint8
+	DropEligible bool
+	ID           uint16
+}
+
+// Interface guards
+var (
+	_ Layer          = new(Ethernet)
+	_ json.Marshaler = new(Ethernet)
+)
 
 type Ethernet struct {
 	PacketBytes
-	Destination  net.HardwareAddr
-	Source       net.HardwareAddr
+	Destination net.HardwareAddr
+	Source      net.HardwareAddr
+	// VLANs contains the 802.1Q tags of the frame, outermost first.
+	VLANs []VLAN
+	// EthernetType is the type of the payload, following any VLAN tags. It is
+	// EthernetTypeLLC for 802.3 frames.
 	EthernetType EtherType
+	// Length is the payload length of 802.3 frames.
+	Length uint16
 }
 
 func (e *Ethernet) Unmarshal(data []byte) error {
 	if len(data) < 14 {
-		return errors.New("ethernet packet too small")
+		return fmt.Errorf("ethernet packet too small: %w", ErrTruncated)
 	}
 	e.Destination = net.HardwareAddr(data[0:6])
 	e.Source = net.HardwareAddr(data[6:12])
 	e.EthernetType = EtherType(binary.BigEndian.Uint16(data[12:14]))
+	e.VLANs = e.VLANs[:0]
+	e.Length = 0
+	hdrLen := 14
+	for e.EthernetType == EthernetTypeDot1Q || e.EthernetType == EthernetTypeQinQ {
+		if len(data) < hdrLen+4 {
+			return fmt.Errorf("vlan tag too small: %w", ErrTruncated)
+		}
+		tci := binary.BigEndian.Uint16(data[hdrLen : hdrLen+2])
+		e.VLANs = append(e.VLANs, VLAN{
+			TPID:         e.EthernetType,
+			Priority:     uint8(tci >> 13),
+			DropEligible: tci&0x1000 != 0,
+			ID:           tci & 0x0FFF,
+		})
+		e.EthernetType = EtherType(binary.BigEndian.Uint16(data[hdrLen+2 : hdrLen+4]))
+		hdrLen += 4
+	}
 	e.Contents = data
-	e.Payload = data[14:]
-	if e.EthernetType <= EtherTypeTooLow || e.EthernetType >= EtherTypeTooHigh {
-		return fmt.Errorf("unknown ether type, %x", e.EthernetType)
+	e.Payload = data[hdrLen:]
+	if e.EthernetType <= EtherTypeMaxLength {
+		e.Length = uint16(e.EthernetType)
+		e.EthernetType = EthernetTypeLLC
+		if int(e.Length) > len(e.Payload) {
+			return fmt.Errorf("802.3 frame length exceeds frame: %w", ErrTruncated)
+		}
+		// Strip padding
+		e.Payload = e.Payload[:e.Length]
+		return nil
+	}
+	if e.EthernetType < 0x0600 {
+		return fmt.Errorf("unknown ether type, %x: %w", e.EthernetType, ErrUnsupported)
 	}
 	return nil
 }
@@ -56,6 +117,36 @@ func (e Ethernet) GetPayload() []byte {
 	return e.Payload
 }
 
+func (e Ethernet) MarshalJSON() ([]byte, error) {
+	type vlan struct {
+		TPID         string `json:"tpid"`
+		Priority     uint8  `json:"priority"`
+		DropEligible bool   `json:"drop_eligible"`
+		ID           uint16 `json:"id"`
+	}
+	vlans := make([]vlan, len(e.VLANs))
+	for i, v := range e.VLANs {
+		vlans[i] = vlan{v.TPID.String(), v.Priority, v.DropEligible, v.ID}
+	}
+	return json.Marshal(struct {
+		Type         string `json:"type"`
+		Destination  string `json:"destination"`
+		Source       string `json:"source"`
+		VLANs        []vlan `json:"vlans"`
+		EthernetType string `json:"ethernet_type"`
+		FrameLength  uint16 `json:"frame_length,omitempty"`
+		Length       int    `json:"length"`
+	}{
+		Type:         e.Type().String(),
+		Destination:  e.Destination.String(),
+		Source:       e.Source.String(),
+		VLANs:        vlans,
+		EthernetType: e.EthernetType.String(),
+		FrameLength:  e.Length,
+		Length:       len(e.Contents),
+	})
+}
+
 // PacketFromEthernet creates a new packet from an ethernet descriptor.
 func PacketFromEthernet(e *Ethernet) (Packet, error) {
 	var p Packet
@@ -0,0 +1,107 @@
+package packet
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+)
+
+// Flow identifies one direction of a transport-layer conversation by its
+// protocol and endpoints. Flows are comparable and can be used as map keys.
+type Flow struct {
+	Proto IPProtocol
+	Src   netip.AddrPort
+	Dst   netip.AddrPort
+}
+
+// Flow returns the flow of the packet. It reports false if the packet does not
+// contain an IP network layer and a TCP, UDP or SCTP transport layer.
+func (p Packet) Flow() (Flow, bool) {
+	var f Flow
+	src, dst, ok := p.addrs()
+	if !ok {
+		return f, false
+	}
+	var sport, dport uint16
+	switch t := p.Transport.(type) {
+	case *TCP:
+		f.Proto, sport, dport = IPProtocolTCP, t.SourcePort, t.DestinationPort
+	case *UDP:
+		f.Proto, sport, dport = IPProtocolUDP, t.SourcePort, t.DestinationPort
+	case *SCTP:
+		f.Proto, sport, dport = IPProtocolSCTP, t.SourcePort, t.DestinationPort
+	default:
+		return f, false
+	}
+	f.Src = netip.AddrPortFrom(src, sport)
+	f.Dst = netip.AddrPortFrom(dst, dport)
+	return f, true
+}
+
+// EchoFlow returns the flow of an ICMP or ICMPv6 echo request or reply, which
+// have no ports. The echo identifier takes the place of the port of the host
+// which sent the request, and the port of the other host is zero, so the flow
+// of a reply is the reverse of the flow of its request. It reports false for
+// other packets.
+func (p Packet) EchoFlow() (Flow, bool) {
+	var f Flow
+	src, dst, ok := p.addrs()
+	if !ok {
+		return f, false
+	}
+	var id uint16
+	var request bool
+	switch t := p.Transport.(type) {
+	case *ICMP:
+		if t.ICMPType != ICMPTypeEchoRequest && t.ICMPType != ICMPTypeEchoReply {
+			return f, false
+		}
+		f.Proto, request = IPProtocolICMP, t.ICMPType == ICMPTypeEchoRequest
+		id = binary.BigEndian.Uint16(t.Data[0:2])
+	case *ICMPv6:
+		if t.ICMPType != ICMPv6TypeEchoRequest && t.ICMPType != ICMPv6TypeEchoReply || len(t.Payload) < 4 {
+			return f, false
+		}
+		f.Proto, request = IPProtocolICMPv6, t.ICMPType == ICMPv6TypeEchoRequest
+		id = binary.BigEndian.Uint16(t.Payload[0:2])
+	default:
+		return f, false
+	}
+	f.Src = netip.AddrPortFrom(src, 0)
+	f.Dst = netip.AddrPortFrom(dst, 0)
+	if request {
+		f.Src = netip.AddrPortFrom(src, id)
+	} else {
+		f.Dst = netip.AddrPortFrom(dst, id)
+	}
+	return f, true
+}
+
+// addrs returns the addresses of the IP network layer of the packet.
+func (p Packet) addrs() (src, dst netip.Addr, ok bool) {
+	switch ip := p.Network.(type) {
+	case *IPv4:
+		return ip.Source, ip.Destination, true
+	case *IPv6:
+		return ip.Source, ip.Destination, true
+	}
+	return src, dst, false
+}
+
+// Reverse returns the flow of the opposite direction.
+func (f Flow) Reverse() Flow {
+	return Flow{Proto: f.Proto, Src: f.Dst, Dst: f.Src}
+}
+
+// Canonical returns the same flow for both directions of a conversation, which
+// is either f or its reverse.
+func (f Flow) Canonical() Flow {
+	if c := f.Src.Addr().Compare(f.Dst.Addr()); c > 0 || c == 0 && f.Src.Port() > f.Dst.Port() {
+		return f.Reverse()
+	}
+	return f
+}
+
+func (f Flow) String() string {
+	return fmt.Sprintf("%v %v -> %v", f.Proto, f.Src, f.Dst)
+}
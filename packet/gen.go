@@ -1,3 +1,3 @@
 package packet
 
-//go:generate stringer -type=LayerType,EtherType,ARPType,ARPOpCode -output enum_string.go
+//go:generate stringer -type=LayerType,EtherType,ARPType,ARPOpCode,IPProtocol,ICMPType,ICMPv6Type,IGMPType,GroupRecordType,SCTPChunkType,LLCSAP,BPDUType,STPPortRole,TLSContentType,TLSHandshakeType,TLSExtensionType,QUICPacketType,QUICFrameType,NTPMode,PTPMessageType,PPPoECode,PPPoETagType,PPPProtocol,PPPControlCode,PAPCode,CHAPCode,Dot11Type,Dot11IEID,BGPMessageType,BGPCapabilityCode,BGPAFI,BGPSAFI,BGPAttributeType,BGPOrigin,BGPSegmentType,BGPErrorCode,OSPFType,OSPFLSType,RTCPPacketType,RTCPSDESType,SNMPVersion,SNMPPDUType,SNMPErrorStatus,SNMPValueType,GTPUMessageType,GTPExtensionType,GTPv2MessageType,GTPv2IEType -output enum_string.go
@@ -0,0 +1,233 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(GTPU)
+	_ json.Marshaler = new(GTPU)
+)
+
+type GTPUMessageType uint8
+
+const (
+	GTPUMessageTypeEchoRequest         GTPUMessageType = 1
+	GTPUMessageTypeEchoResponse        GTPUMessageType = 2
+	GTPUMessageTypeErrorIndication     GTPUMessageType = 26
+	GTPUMessageTypeSupportedExtHeaders GTPUMessageType = 31
+	GTPUMessageTypeTunnelStatus        GTPUMessageType = 253
+	GTPUMessageTypeEndMarker           GTPUMessageType = 254
+	GTPUMessageTypeGPDU                GTPUMessageType = 255
+)
+
+type GTPExtensionType uint8
+
+const (
+	GTPExtensionTypeNone                GTPExtensionType = 0x00
+	GTPExtensionTypeServiceClass        GTPExtensionType = 0x20
+	GTPExtensionTypeUDPPort             GTPExtensionType = 0x40
+	GTPExtensionTypeRANContainer        GTPExtensionType = 0x81
+	GTPExtensionTypeLongPDCPPDUNumber   GTPExtensionType = 0x82
+	GTPExtensionTypeXwRANContainer      GTPExtensionType = 0x83
+	GTPExtensionTypeNRRANContainer      GTPExtensionType = 0x84
+	GTPExtensionTypePDUSessionContainer GTPExtensionType = 0x85
+	GTPExtensionTypePDCPPDUNumber       GTPExtensionType = 0xC0
+)
+
+// GTPExtensionHeader is an extension header of a GTP-U packet. Content
+// excludes the length and next extension header type octets.
+type GTPExtensionHeader struct {
+	Type    GTPExtensionType
+	Content []byte
+}
+
+// GTPPDUSessionContainer is the PDU Session Container extension header of
+// 5G user plane packets (TS 38.415). PDUType is 0 for downlink and 1 for
+// uplink packets.
+type GTPPDUSessionContainer struct {
+	PDUType uint8
+	// QFI is the QoS flow identifier.
+	QFI uint8
+	// PPI is the paging policy indicator of downlink packets, if present.
+	PPI uint8
+	// RQI is the reflective QoS indicator of downlink packets.
+	RQI bool
+}
+
+// GTPU is a GTPv1 user plane packet (TS 29.281). The payload of G-PDU
+// packets is the user packet, which is decoded into the inner packet.
+type GTPU struct {
+	Version uint8
+	// ProtocolType is 1 for GTP and 0 for GTP'.
+	ProtocolType uint8
+	MessageType  GTPUMessageType
+	// Length is the length of the packet after the mandatory header.
+	Length uint16
+	TEID   uint32
+	// Sequence and NPDU are only valid if their flags are set.
+	HasSequence bool
+	Sequence    uint16
+	HasNPDU     bool
+	NPDU        uint8
+	Extensions  []GTPExtensionHeader
+	// PDUSession is set if the packet has a PDU Session Container.
+	PDUSession *GTPPDUSessionContainer
+	PacketBytes
+}
+
+func (g *GTPU) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("gtp-u packet too small: %w", ErrTruncated)
+	}
+	*g = GTPU{Extensions: g.Extensions[:0]}
+	g.Version = data[0] >> 5
+	if g.Version != 1 {
+		return fmt.Errorf("gtp-u packets must be v1, was %v: %w", g.Version, ErrUnsupported)
+	}
+	g.ProtocolType = data[0] >> 4 & 0x01
+	g.MessageType = GTPUMessageType(data[1])
+	g.Length = binary.BigEndian.Uint16(data[2:4])
+	g.TEID = binary.BigEndian.Uint32(data[4:8])
+	end := 8 + int(g.Length)
+	if end > len(data) {
+		return fmt.Errorf("invalid gtp-u length: %w", ErrInvalid)
+	}
+	n := 8
+	if data[0]&0x07 != 0 {
+		// The optional fields are present if any of the E, S and PN flags
+		// are set
+		if end < 12 {
+			return fmt.Errorf("gtp-u optional fields exceed packet: %w", ErrTruncated)
+		}
+		g.HasSequence = data[0]&0x02 != 0
+		g.Sequence = binary.BigEndian.Uint16(data[8:10])
+		g.HasNPDU = data[0]&0x01 != 0
+		g.NPDU = data[10]
+		next := GTPExtensionType(data[11])
+		n = 12
+		for data[0]&0x04 != 0 && next != GTPExtensionTypeNone {
+			if n >= end {
+				return fmt.Errorf("gtp-u extension header exceeds packet: %w", ErrTruncated)
+			}
+			l := 4 * int(data[n])
+			if l == 0 || n+l > end {
+				return fmt.Errorf("invalid length of gtp-u extension header %v: %w", next, ErrInvalid)
+			}
+			h := GTPExtensionHeader{Type: next, Content: data[n+1 : n+l-1]}
+			if h.Type == GTPExtensionTypePDUSessionContainer {
+				g.PDUSession = parsePDUSessionContainer(h.Content)
+			}
+			g.Extensions = append(g.Extensions, h)
+			next = GTPExtensionType(data[n+l-1])
+			n += l
+		}
+	}
+	g.Contents = data[:end]
+	g.Payload = data[n:end]
+	return nil
+}
+
+func parsePDUSessionContainer(b []byte) *GTPPDUSessionContainer {
+	if len(b) < 2 {
+		return nil
+	}
+	c := &GTPPDUSessionContainer{PDUType: b[0] >> 4, QFI: b[1] & 0x3F}
+	if c.PDUType == 0 {
+		c.RQI = b[1]&0x40 != 0
+		// The PPI follows if the PPP flag is set
+		if b[1]&0x80 != 0 && len(b) >= 3 {
+			c.PPI = b[2] >> 5
+		}
+	}
+	return c
+}
+
+func (g GTPU) Type() LayerType {
+	return LayerTypeGTPU
+}
+
+func (g GTPU) GetContents() []byte {
+	return g.Contents
+}
+
+func (g GTPU) GetPayload() []byte {
+	return g.Payload
+}
+
+func (g GTPU) MarshalJSON() ([]byte, error) {
+	type extension struct {
+		Type    string `json:"type"`
+		Content string `json:"content"`
+	}
+	type pduSession struct {
+		PDUType uint8 `json:"pdu_type"`
+		QFI     uint8 `json:"qfi"`
+		PPI     uint8 `json:"ppi"`
+		RQI     bool  `json:"rqi"`
+	}
+	v := struct {
+		Type         string      `json:"type"`
+		Version      uint8       `json:"version"`
+		ProtocolType uint8       `json:"protocol_type"`
+		MessageType  string      `json:"message_type"`
+		TEID         uint32      `json:"teid"`
+		Sequence     *uint16     `json:"sequence,omitempty"`
+		NPDU         *uint8      `json:"npdu,omitempty"`
+		Extensions   []extension `json:"extensions"`
+		PDUSession   *pduSession `json:"pdu_session,omitempty"`
+		Length       int         `json:"length"`
+	}{
+		Type:         g.Type().String(),
+		Version:      g.Version,
+		ProtocolType: g.ProtocolType,
+		MessageType:  g.MessageType.String(),
+		TEID:         g.TEID,
+		Extensions:   make([]extension, len(g.Extensions)),
+		Length:       len(g.Contents),
+	}
+	if g.HasSequence {
+		v.Sequence = &g.Sequence
+	}
+	if g.HasNPDU {
+		v.NPDU = &g.NPDU
+	}
+	for i, e := range g.Extensions {
+		v.Extensions[i] = extension{Type: e.Type.String(), Content: hex.EncodeToString(e.Content)}
+	}
+	if s := g.PDUSession; s != nil {
+		v.PDUSession = &pduSession{PDUType: s.PDUType, QFI: s.QFI, PPI: s.PPI, RQI: s.RQI}
+	}
+	return json.Marshal(v)
+}
+
+// decodeGTPU decodes a GTP-U packet, and the user packet of G-PDUs into the
+// inner packet. As for other UDP payloads, failure to decode is not an error.
+func (p *Packet) decodeGTPU(b []byte) {
+	g := new(GTPU)
+	if g.Unmarshal(b) != nil {
+		return
+	}
+	p.Application = g
+	if g.MessageType != GTPUMessageTypeGPDU || len(g.Payload) == 0 {
+		return
+	}
+	switch g.Payload[0] >> 4 {
+	case 4:
+		ip := new(IPv4)
+		if ip.Unmarshal(g.Payload) == nil {
+			p.Inner = &Packet{Network: ip, dec: p.dec}
+			p.Inner.decodeIPv4(ip)
+		}
+	case 6:
+		ip := new(IPv6)
+		if ip.Unmarshal(g.Payload) == nil {
+			p.Inner = &Packet{Network: ip, dec: p.dec}
+			p.Inner.decodeIPv6(ip)
+		}
+	}
+}
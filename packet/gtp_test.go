@@ -0,0 +1,158 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestGTPU(t *testing.T) {
+	inner := ipv4Packet(netip.MustParseAddr("10.45.0.2"), netip.MustParseAddr("198.51.100.7"), 17,
+		[]byte{0x9C, 0x40, 0x00, 0x09, 0x00, 0x0C, 0x00, 0x00, 'p', 'i', 'n', 'g'}, 6)
+	// Sequence number and a downlink PDU Session Container with PPI 5, RQI
+	// and QFI 9
+	gtp := []byte{0x36, 0xFF, 0, 0, 0x11, 0x22, 0x33, 0x44, 0x01, 0x02, 0x00, 0x85}
+	gtp = append(gtp, 0x02, 0x00, 0xC9, 0xA0, 0, 0, 0, 0x00)
+	gtp = append(gtp, inner...)
+	binary.BigEndian.PutUint16(gtp[2:4], uint16(len(gtp)-8))
+
+	p := decodeUDP(t, 2152, 2152, gtp)
+	g, ok := p.Application.(*packet.GTPU)
+	if !ok {
+		t.Fatalf("expected gtp-u, got %T", p.Application)
+	}
+	if g.MessageType != packet.GTPUMessageTypeGPDU || g.TEID != 0x11223344 || !g.HasSequence || g.Sequence != 0x0102 || g.HasNPDU {
+		t.Errorf("unexpected header %+v", g)
+	}
+	if len(g.Extensions) != 1 || g.Extensions[0].Type != packet.GTPExtensionTypePDUSessionContainer {
+		t.Fatalf("unexpected extensions %+v", g.Extensions)
+	}
+	if s := g.PDUSession; s == nil || s.PDUType != 0 || s.QFI != 9 || s.PPI != 5 || !s.RQI {
+		t.Errorf("unexpected pdu session container %+v", g.PDUSession)
+	}
+	if len(g.Contents) != 20+len(inner) || len(g.Payload) != len(inner) {
+		t.Errorf("got %d contents and %d payload bytes", len(g.Contents), len(g.Payload))
+	}
+
+	if p.Inner == nil {
+		t.Fatal("inner packet was not decoded")
+	}
+	ip, ok := p.Inner.Network.(*packet.IPv4)
+	if !ok || ip.Source != netip.MustParseAddr("10.45.0.2") {
+		t.Fatalf("unexpected inner network layer %+v", p.Inner.Network)
+	}
+	if udp, ok := p.Inner.Transport.(*packet.UDP); !ok || udp.DestinationPort != 9 || string(udp.Payload) != "ping" {
+		t.Errorf("unexpected inner transport layer %+v", p.Inner.Transport)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"pdu_session":{"pdu_type":0,"qfi":9,"ppi":5,"rqi":true}`) {
+		t.Errorf("unexpected json %s", data)
+	}
+
+	// Extension headers must be within the packet
+	gtp[12] = 0x20
+	if err := new(packet.GTPU).Unmarshal(gtp); err == nil {
+		t.Error("invalid extension header length decoded without error")
+	}
+}
+
+// gtpv2IE encodes a GTPv2 information element.
+func gtpv2IE(typ, instance byte, parts ...[]byte) []byte {
+	var v []byte
+	for _, p := range parts {
+		v = append(v, p...)
+	}
+	return append([]byte{typ, byte(len(v) >> 8), byte(len(v)), instance}, v...)
+}
+
+func TestGTPv2CreateSessionRequest(t *testing.T) {
+	ies := gtpv2IE(1, 0, []byte{0x00, 0x01, 0x01, 0x21, 0x43, 0x65, 0x87, 0xF9})
+	ies = append(ies, gtpv2IE(87, 0, []byte{0x80 | 10, 0xAA, 0xBB, 0xCC, 0xDD, 192, 0, 2, 10})...)
+	ies = append(ies, gtpv2IE(71, 0, []byte("\x08internet\x03mnc"))...)
+	ies = append(ies, gtpv2IE(93, 0,
+		gtpv2IE(73, 0, []byte{5}),
+		gtpv2IE(87, 2, []byte{0xC0, 0, 0, 0, 1, 192, 0, 2, 20, 0x20, 0x01, 0x0D, 0xB8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}))...)
+	msg := []byte{0x48, 32, 0, 0, 0, 0, 0, 0, 0x00, 0x0A, 0xBC, 0x00}
+	msg = append(msg, ies...)
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(msg)-4))
+
+	p := decodeUDP(t, 2123, 2123, msg)
+	g, ok := p.Application.(*packet.GTPv2)
+	if !ok {
+		t.Fatalf("expected gtpv2, got %T", p.Application)
+	}
+	if g.MessageType != packet.GTPv2MessageTypeCreateSessionRequest || !g.HasTEID || g.TEID != 0 || g.Sequence != 0xABC || len(g.IEs) != 4 {
+		t.Fatalf("unexpected message %+v", g)
+	}
+	if imsi, ok := g.IE(packet.GTPv2IETypeIMSI, 0); !ok || imsi.TBCD() != "001010123456789" {
+		t.Errorf("unexpected imsi %+v", imsi)
+	}
+	if apn, _ := g.IE(packet.GTPv2IETypeAPN, 0); apn.APN() != "internet.mnc" {
+		t.Errorf("unexpected apn %q", apn.APN())
+	}
+	fteid, _ := g.IE(packet.GTPv2IETypeFTEID, 0)
+	if f, err := fteid.FTEID(); err != nil || f.InterfaceType != 10 || f.TEID != 0xAABBCCDD ||
+		f.IPv4 != netip.MustParseAddr("192.0.2.10") || f.IPv6.IsValid() {
+		t.Errorf("unexpected f-teid %+v, %v", f, err)
+	}
+
+	bearer, ok := g.IE(packet.GTPv2IETypeBearerContext, 0)
+	if !ok || len(bearer.IEs) != 2 {
+		t.Fatalf("unexpected bearer context %+v", bearer)
+	}
+	if ebi, _ := bearer.IE(packet.GTPv2IETypeEBI, 0); ebi.Type != packet.GTPv2IETypeEBI {
+		t.Error("bearer context has no ebi")
+	} else if v, err := ebi.Uint(); err != nil || v != 5 {
+		t.Errorf("ebi is %v, %v", v, err)
+	}
+	fteid, _ = bearer.IE(packet.GTPv2IETypeFTEID, 2)
+	if f, err := fteid.FTEID(); err != nil || f.TEID != 1 || f.IPv4 != netip.MustParseAddr("192.0.2.20") ||
+		f.IPv6 != netip.MustParseAddr("2001:db8::1") {
+		t.Errorf("unexpected bearer f-teid %+v, %v", f, err)
+	}
+
+	data, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `{"type":"GTPv2IETypeBearerContext","instance":0,"ies":[{"type":"GTPv2IETypeEBI","instance":0,"value":"05"}`) {
+		t.Errorf("unexpected json %s", data)
+	}
+
+	// IEs must be within the message
+	msg[len(msg)-27]++
+	if err := new(packet.GTPv2).Unmarshal(msg); err == nil {
+		t.Error("invalid ie length decoded without error")
+	}
+}
+
+func TestGTPv2Piggybacked(t *testing.T) {
+	resp := []byte{0x58, 33, 0, 13, 0, 0, 0, 1, 0, 0, 7, 0}
+	resp = append(resp, gtpv2IE(2, 0, []byte{16})...)
+	req := []byte{0x48, 95, 0, 8, 0, 0, 0, 2, 0, 0, 8, 0}
+
+	g := new(packet.GTPv2)
+	if err := g.Unmarshal(append(resp, req...)); err != nil {
+		t.Fatal(err)
+	}
+	if cause, _ := g.IE(packet.GTPv2IETypeCause, 0); len(cause.Value) != 1 || cause.Value[0] != 16 {
+		t.Errorf("unexpected cause %+v", cause)
+	}
+	if g.Piggybacked == nil || g.Piggybacked.MessageType != packet.GTPv2MessageTypeCreateBearerRequest ||
+		g.Piggybacked.TEID != 2 || g.Piggybacked.Sequence != 8 {
+		t.Errorf("unexpected piggybacked message %+v", g.Piggybacked)
+	}
+
+	// GTPv1 control plane messages are not decoded
+	if p := decodeUDP(t, 2123, 2123, []byte{0x32, 16, 0, 4, 0, 0, 0, 0, 0, 1, 0, 0}); p.Application != nil {
+		t.Errorf("decoded gtpv1 message as %T", p.Application)
+	}
+}
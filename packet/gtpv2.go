@@ -0,0 +1,387 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(GTPv2)
+	_ json.Marshaler = new(GTPv2)
+)
+
+type GTPv2MessageType uint8
+
+const (
+	GTPv2MessageTypeEchoRequest                    GTPv2MessageType = 1
+	GTPv2MessageTypeEchoResponse                   GTPv2MessageType = 2
+	GTPv2MessageTypeVersionNotSupported            GTPv2MessageType = 3
+	GTPv2MessageTypeCreateSessionRequest           GTPv2MessageType = 32
+	GTPv2MessageTypeCreateSessionResponse          GTPv2MessageType = 33
+	GTPv2MessageTypeModifyBearerRequest            GTPv2MessageType = 34
+	GTPv2MessageTypeModifyBearerResponse           GTPv2MessageType = 35
+	GTPv2MessageTypeDeleteSessionRequest           GTPv2MessageType = 36
+	GTPv2MessageTypeDeleteSessionResponse          GTPv2MessageType = 37
+	GTPv2MessageTypeDownlinkDataNotificationFailed GTPv2MessageType = 70
+	GTPv2MessageTypeCreateBearerRequest            GTPv2MessageType = 95
+	GTPv2MessageTypeCreateBearerResponse           GTPv2MessageType = 96
+	GTPv2MessageTypeUpdateBearerRequest            GTPv2MessageType = 97
+	GTPv2MessageTypeUpdateBearerResponse           GTPv2MessageType = 98
+	GTPv2MessageTypeDeleteBearerRequest            GTPv2MessageType = 99
+	GTPv2MessageTypeDeleteBearerResponse           GTPv2MessageType = 100
+	GTPv2MessageTypeReleaseAccessBearersRequest    GTPv2MessageType = 170
+	GTPv2MessageTypeReleaseAccessBearersResponse   GTPv2MessageType = 171
+	GTPv2MessageTypeDownlinkDataNotification       GTPv2MessageType = 176
+	GTPv2MessageTypeDownlinkDataNotificationAck    GTPv2MessageType = 177
+)
+
+type GTPv2IEType uint8
+
+const (
+	GTPv2IETypeIMSI                    GTPv2IEType = 1
+	GTPv2IETypeCause                   GTPv2IEType = 2
+	GTPv2IETypeRecovery                GTPv2IEType = 3
+	GTPv2IETypeAPN                     GTPv2IEType = 71
+	GTPv2IETypeAMBR                    GTPv2IEType = 72
+	GTPv2IETypeEBI                     GTPv2IEType = 73
+	GTPv2IETypeIPAddress               GTPv2IEType = 74
+	GTPv2IETypeMEI                     GTPv2IEType = 75
+	GTPv2IETypeMSISDN                  GTPv2IEType = 76
+	GTPv2IETypeIndication              GTPv2IEType = 77
+	GTPv2IETypePCO                     GTPv2IEType = 78
+	GTPv2IETypePAA                     GTPv2IEType = 79
+	GTPv2IETypeBearerQoS               GTPv2IEType = 80
+	GTPv2IETypeFlowQoS                 GTPv2IEType = 81
+	GTPv2IETypeRATType                 GTPv2IEType = 82
+	GTPv2IETypeServingNetwork          GTPv2IEType = 83
+	GTPv2IETypeBearerTFT               GTPv2IEType = 84
+	GTPv2IETypeTAD                     GTPv2IEType = 85
+	GTPv2IETypeULI                     GTPv2IEType = 86
+	GTPv2IETypeFTEID                   GTPv2IEType = 87
+	GTPv2IETypeTMSI                    GTPv2IEType = 88
+	GTPv2IETypeGlobalCNID              GTPv2IEType = 89
+	GTPv2IETypeS103PDF                 GTPv2IEType = 90
+	GTPv2IETypeS1UDF                   GTPv2IEType = 91
+	GTPv2IETypeDelayValue              GTPv2IEType = 92
+	GTPv2IETypeBearerContext           GTPv2IEType = 93
+	GTPv2IETypeChargingID              GTPv2IEType = 94
+	GTPv2IETypeChargingCharacteristics GTPv2IEType = 95
+	GTPv2IETypeTraceInformation        GTPv2IEType = 96
+	GTPv2IETypeBearerFlags             GTPv2IEType = 97
+	GTPv2IETypePDNType                 GTPv2IEType = 99
+	GTPv2IETypePTI                     GTPv2IEType = 100
+	GTPv2IETypePDNConnection           GTPv2IEType = 109
+	GTPv2IETypeUETimeZone              GTPv2IEType = 114
+	GTPv2IETypeAPNRestriction          GTPv2IEType = 127
+	GTPv2IETypeSelectionMode           GTPv2IEType = 128
+	GTPv2IETypeFQDN                    GTPv2IEType = 136
+	GTPv2IETypeOverloadControlInfo     GTPv2IEType = 180
+	GTPv2IETypeLoadControlInfo         GTPv2IEType = 181
+	GTPv2IETypePrivateExtension        GTPv2IEType = 255
+)
+
+// grouped reports whether IEs of the type contain other IEs.
+func (t GTPv2IEType) grouped() bool {
+	switch t {
+	case GTPv2IETypeBearerContext, GTPv2IETypePDNConnection,
+		GTPv2IETypeOverloadControlInfo, GTPv2IETypeLoadControlInfo:
+		return true
+	}
+	return false
+}
+
+// GTPv2IE is an information element. The IEs of grouped IEs, such as bearer
+// contexts, are decoded into IEs.
+type GTPv2IE struct {
+	Type     GTPv2IEType
+	Instance uint8
+	Value    []byte
+	IEs      []GTPv2IE
+}
+
+// GTPv2FTEID is a fully qualified tunnel endpoint identifier.
+type GTPv2FTEID struct {
+	// InterfaceType identifies the interface, such as 0 for S1-U eNodeB
+	// GTP-U and 10 for S11 MME GTP-C.
+	InterfaceType uint8
+	TEID          uint32
+	IPv4          netip.Addr
+	IPv6          netip.Addr
+}
+
+// TBCD decodes a telephony binary coded decimal value, as used by the IMSI,
+// MSISDN and MEI IEs.
+func (ie GTPv2IE) TBCD() string {
+	var sb strings.Builder
+	for _, b := range ie.Value {
+		for _, d := range [2]byte{b & 0x0F, b >> 4} {
+			if d == 0x0F {
+				return sb.String()
+			}
+			sb.WriteByte('0' + d)
+		}
+	}
+	return sb.String()
+}
+
+// APN decodes the labels of an APN IE into a dotted name.
+func (ie GTPv2IE) APN() string {
+	var labels []string
+	for b := ie.Value; len(b) > 0; {
+		l := int(b[0])
+		if 1+l > len(b) {
+			break
+		}
+		labels = append(labels, string(b[1:1+l]))
+		b = b[1+l:]
+	}
+	return strings.Join(labels, ".")
+}
+
+// Uint decodes the value as an unsigned integer, such as the cause value of
+// Cause IEs, the EPS bearer ID of EBI IEs and the restart counter of Recovery
+// IEs, which are all in the first octet.
+func (ie GTPv2IE) Uint() (uint8, error) {
+	if len(ie.Value) < 1 {
+		return 0, fmt.Errorf("gtpv2 %v ie too small: %w", ie.Type, ErrTruncated)
+	}
+	if ie.Type == GTPv2IETypeEBI {
+		return ie.Value[0] & 0x0F, nil
+	}
+	return ie.Value[0], nil
+}
+
+// FTEID decodes an F-TEID IE.
+func (ie GTPv2IE) FTEID() (GTPv2FTEID, error) {
+	var f GTPv2FTEID
+	b := ie.Value
+	if len(b) < 5 {
+		return f, fmt.Errorf("gtpv2 f-teid too small: %w", ErrTruncated)
+	}
+	f.InterfaceType = b[0] & 0x3F
+	f.TEID = binary.BigEndian.Uint32(b[1:5])
+	n := 5
+	if b[0]&0x80 != 0 {
+		if n+4 > len(b) {
+			return f, fmt.Errorf("gtpv2 f-teid ipv4 address exceeds ie: %w", ErrTruncated)
+		}
+		f.IPv4 = netip.AddrFrom4(*(*[4]byte)(b[n : n+4]))
+		n += 4
+	}
+	if b[0]&0x40 != 0 {
+		if n+16 > len(b) {
+			return f, fmt.Errorf("gtpv2 f-teid ipv6 address exceeds ie: %w", ErrTruncated)
+		}
+		f.IPv6 = netip.AddrFrom16(*(*[16]byte)(b[n : n+16]))
+	}
+	return f, nil
+}
+
+// PAA decodes the addresses of a PDN Address Allocation IE. The IPv6 address
+// is the prefix, whose length is returned.
+func (ie GTPv2IE) PAA() (ipv4, ipv6 netip.Addr, prefixLen uint8, err error) {
+	b := ie.Value
+	if len(b) < 1 {
+		return ipv4, ipv6, 0, fmt.Errorf("gtpv2 paa too small: %w", ErrTruncated)
+	}
+	typ := b[0] & 0x07
+	b = b[1:]
+	if typ == 2 || typ == 3 {
+		if len(b) < 17 {
+			return ipv4, ipv6, 0, fmt.Errorf("gtpv2 paa ipv6 prefix exceeds ie: %w", ErrTruncated)
+		}
+		prefixLen = b[0]
+		ipv6 = netip.AddrFrom16(*(*[16]byte)(b[1:17]))
+		b = b[17:]
+	}
+	if typ == 1 || typ == 3 {
+		if len(b) < 4 {
+			return ipv4, ipv6, 0, fmt.Errorf("gtpv2 paa ipv4 address exceeds ie: %w", ErrTruncated)
+		}
+		ipv4 = netip.AddrFrom4(*(*[4]byte)(b[0:4]))
+	}
+	return ipv4, ipv6, prefixLen, nil
+}
+
+// GTPv2 is a GTPv2 control plane message (TS 29.274). The TEID is only valid
+// if HasTEID is set, which is the case for all but a few messages, such as
+// echo messages. A message may be followed by a piggybacked message, such as
+// a Create Bearer Request after a Create Session Response.
+type GTPv2 struct {
+	Version     uint8
+	Piggyback   bool
+	HasTEID     bool
+	HasPriority bool
+	MessageType GTPv2MessageType
+	// Length is the length of the message after the first four octets.
+	Length   uint16
+	TEID     uint32
+	Sequence uint32
+	// Priority is the message priority, if HasPriority is set.
+	Priority    uint8
+	IEs         []GTPv2IE
+	Piggybacked *GTPv2
+	PacketBytes
+}
+
+func (g *GTPv2) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("gtpv2 message too small: %w", ErrTruncated)
+	}
+	*g = GTPv2{}
+	g.Version = data[0] >> 5
+	if g.Version != 2 {
+		return fmt.Errorf("gtpv2 messages must be v2, was %v: %w", g.Version, ErrUnsupported)
+	}
+	g.Piggyback = data[0]&0x10 != 0
+	g.HasTEID = data[0]&0x08 != 0
+	g.HasPriority = data[0]&0x04 != 0
+	g.MessageType = GTPv2MessageType(data[1])
+	g.Length = binary.BigEndian.Uint16(data[2:4])
+	end := 4 + int(g.Length)
+	if end > len(data) {
+		return fmt.Errorf("invalid gtpv2 length: %w", ErrInvalid)
+	}
+	n := 4
+	if g.HasTEID {
+		if end < 12 {
+			return fmt.Errorf("gtpv2 header exceeds message: %w", ErrTruncated)
+		}
+		g.TEID = binary.BigEndian.Uint32(data[4:8])
+		n = 8
+	}
+	if n+4 > end {
+		return fmt.Errorf("gtpv2 header exceeds message: %w", ErrTruncated)
+	}
+	g.Sequence = binary.BigEndian.Uint32(data[n:n+4]) >> 8
+	if g.HasPriority {
+		g.Priority = data[n+3] >> 4
+	}
+	n += 4
+	ies, err := parseGTPv2IEs(data[n:end], 0)
+	if err != nil {
+		return err
+	}
+	g.IEs = ies
+	g.Contents = data[:end]
+	if g.Piggyback && end < len(data) {
+		next := new(GTPv2)
+		if err := next.Unmarshal(data[end:]); err != nil {
+			return fmt.Errorf("invalid piggybacked gtpv2 message: %w", err)
+		}
+		g.Piggybacked = next
+	}
+	return nil
+}
+
+// maxGTPv2Depth limits the nesting of grouped IEs.
+const maxGTPv2Depth = 8
+
+func parseGTPv2IEs(b []byte, depth int) ([]GTPv2IE, error) {
+	var ies []GTPv2IE
+	for len(b) > 0 {
+		if len(b) < 4 {
+			return nil, fmt.Errorf("gtpv2 ie header too small: %w", ErrTruncated)
+		}
+		ie := GTPv2IE{Type: GTPv2IEType(b[0]), Instance: b[3] & 0x0F}
+		n := 4 + int(binary.BigEndian.Uint16(b[1:3]))
+		if n > len(b) {
+			return nil, fmt.Errorf("gtpv2 %v ie exceeds message: %w", ie.Type, ErrTruncated)
+		}
+		ie.Value = b[4:n]
+		if ie.Type.grouped() && depth < maxGTPv2Depth {
+			var err error
+			if ie.IEs, err = parseGTPv2IEs(ie.Value, depth+1); err != nil {
+				return nil, err
+			}
+		}
+		ies = append(ies, ie)
+		b = b[n:]
+	}
+	return ies, nil
+}
+
+// IE returns the first top-level IE with the type and instance.
+func (g GTPv2) IE(t GTPv2IEType, instance uint8) (GTPv2IE, bool) {
+	return findGTPv2IE(g.IEs, t, instance)
+}
+
+// IE returns the first IE of a grouped IE with the type and instance.
+func (ie GTPv2IE) IE(t GTPv2IEType, instance uint8) (GTPv2IE, bool) {
+	return findGTPv2IE(ie.IEs, t, instance)
+}
+
+func findGTPv2IE(ies []GTPv2IE, t GTPv2IEType, instance uint8) (GTPv2IE, bool) {
+	for _, ie := range ies {
+		if ie.Type == t && ie.Instance == instance {
+			return ie, true
+		}
+	}
+	return GTPv2IE{}, false
+}
+
+func (g GTPv2) Type() LayerType {
+	return LayerTypeGTPv2
+}
+
+func (g GTPv2) GetContents() []byte {
+	return g.Contents
+}
+
+func (g GTPv2) GetPayload() []byte {
+	return g.Payload
+}
+
+type gtpv2IEJSON struct {
+	Type     string        `json:"type"`
+	Instance uint8         `json:"instance"`
+	Value    string        `json:"value,omitempty"`
+	IEs      []gtpv2IEJSON `json:"ies,omitempty"`
+}
+
+func gtpv2IEsJSON(ies []GTPv2IE) []gtpv2IEJSON {
+	v := make([]gtpv2IEJSON, len(ies))
+	for i, ie := range ies {
+		v[i] = gtpv2IEJSON{Type: ie.Type.String(), Instance: ie.Instance}
+		if ie.IEs != nil {
+			v[i].IEs = gtpv2IEsJSON(ie.IEs)
+		} else {
+			v[i].Value = hex.EncodeToString(ie.Value)
+		}
+	}
+	return v
+}
+
+func (g GTPv2) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type        string        `json:"type"`
+		Version     uint8         `json:"version"`
+		MessageType string        `json:"message_type"`
+		TEID        *uint32       `json:"teid,omitempty"`
+		Sequence    uint32        `json:"sequence"`
+		Priority    *uint8        `json:"priority,omitempty"`
+		IEs         []gtpv2IEJSON `json:"ies"`
+		Piggybacked *GTPv2        `json:"piggybacked,omitempty"`
+		Length      int           `json:"length"`
+	}{
+		Type:        g.Type().String(),
+		Version:     g.Version,
+		MessageType: g.MessageType.String(),
+		Sequence:    g.Sequence,
+		IEs:         gtpv2IEsJSON(g.IEs),
+		Piggybacked: g.Piggybacked,
+		Length:      len(g.Contents),
+	}
+	if g.HasTEID {
+		v.TEID = &g.TEID
+	}
+	if g.HasPriority {
+		v.Priority = &g.Priority
+	}
+	return json.Marshal(v)
+}
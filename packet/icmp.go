@@ -0,0 +1,97 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(ICMP)
+	_ json.Marshaler = new(ICMP)
+)
+
+type ICMPType uint8
+
+const (
+	ICMPTypeEchoReply              ICMPType = 0
+	ICMPTypeDestinationUnreachable ICMPType = 3
+	ICMPTypeSourceQuench           ICMPType = 4
+	ICMPTypeRedirect               ICMPType = 5
+	ICMPTypeEchoRequest            ICMPType = 8
+	ICMPTypeRouterAdvertisement    ICMPType = 9
+	ICMPTypeRouterSolicitation     ICMPType = 10
+	ICMPTypeTimeExceeded           ICMPType = 11
+	ICMPTypeParameterProblem       ICMPType = 12
+	ICMPTypeTimestamp              ICMPType = 13
+	ICMPTypeTimestampReply         ICMPType = 14
+)
+
+// IsError reports whether messages of the type are errors, which quote the
+// beginning of the packet that caused them.
+func (t ICMPType) IsError() bool {
+	switch t {
+	case ICMPTypeDestinationUnreachable, ICMPTypeSourceQuench, ICMPTypeRedirect,
+		ICMPTypeTimeExceeded, ICMPTypeParameterProblem:
+		return true
+	}
+	return false
+}
+
+// ICMP is an ICMP message for IPv4. The message body, following the 4 bytes
+// of the header which depend on the type, is stored in the payload. For error
+// messages, the payload is the quoted packet.
+type ICMP struct {
+	ICMPType ICMPType
+	Code     uint8
+	Checksum uint16
+	// Data contains the type dependent header field, such as the identifier
+	// and sequence number of echo messages.
+	Data [4]byte
+	PacketBytes
+}
+
+func (m *ICMP) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("icmp message too small: %w", ErrTruncated)
+	}
+	m.ICMPType = ICMPType(data[0])
+	m.Code = data[1]
+	m.Checksum = binary.BigEndian.Uint16(data[2:4])
+	copy(m.Data[:], data[4:8])
+	m.Contents = data
+	m.Payload = data[8:]
+	return nil
+}
+
+func (m ICMP) Type() LayerType {
+	return LayerTypeICMP
+}
+
+func (m ICMP) GetContents() []byte {
+	return m.Contents
+}
+
+func (m ICMP) GetPayload() []byte {
+	return m.Payload
+}
+
+func (m ICMP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string `json:"type"`
+		ICMPType string `json:"icmp_type"`
+		Code     uint8  `json:"code"`
+		Checksum uint16 `json:"checksum"`
+		Data     string `json:"data"`
+		Length   int    `json:"length"`
+	}{
+		Type:     m.Type().String(),
+		ICMPType: m.ICMPType.String(),
+		Code:     m.Code,
+		Checksum: m.Checksum,
+		Data:     hex.EncodeToString(m.Data[:]),
+		Length:   len(m.Contents),
+	})
+}
@@ -0,0 +1,88 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(ICMPv6)
+	_ json.Marshaler = new(ICMPv6)
+)
+
+type ICMPv6Type uint8
+
+const (
+	ICMPv6TypeDestinationUnreachable ICMPv6Type = 1
+	ICMPv6TypePacketTooBig           ICMPv6Type = 2
+	ICMPv6TypeTimeExceeded           ICMPv6Type = 3
+	ICMPv6TypeParameterProblem       ICMPv6Type = 4
+	ICMPv6TypeEchoRequest            ICMPv6Type = 128
+	ICMPv6TypeEchoReply              ICMPv6Type = 129
+	ICMPv6TypeMLDQuery               ICMPv6Type = 130
+	ICMPv6TypeMLDv1Report            ICMPv6Type = 131
+	ICMPv6TypeMLDv1Done              ICMPv6Type = 132
+	ICMPv6TypeRouterSolicitation     ICMPv6Type = 133
+	ICMPv6TypeRouterAdvertisement    ICMPv6Type = 134
+	ICMPv6TypeNeighborSolicitation   ICMPv6Type = 135
+	ICMPv6TypeNeighborAdvertisement  ICMPv6Type = 136
+	ICMPv6TypeRedirect               ICMPv6Type = 137
+	ICMPv6TypeMLDv2Report            ICMPv6Type = 143
+)
+
+// IsError reports whether messages of the type are errors, which quote the
+// beginning of the packet that caused them.
+func (t ICMPv6Type) IsError() bool {
+	return t < 128
+}
+
+// ICMPv6 is an ICMPv6 message. The message body, following the checksum, is
+// stored in the payload. MLD messages are decoded into MLD instead.
+type ICMPv6 struct {
+	ICMPType ICMPv6Type
+	Code     uint8
+	Checksum uint16
+	PacketBytes
+}
+
+func (m *ICMPv6) Unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("icmpv6 message too small: %w", ErrTruncated)
+	}
+	m.ICMPType = ICMPv6Type(data[0])
+	m.Code = data[1]
+	m.Checksum = binary.BigEndian.Uint16(data[2:4])
+	m.Contents = data
+	m.Payload = data[4:]
+	return nil
+}
+
+func (m ICMPv6) Type() LayerType {
+	return LayerTypeICMPv6
+}
+
+func (m ICMPv6) GetContents() []byte {
+	return m.Contents
+}
+
+func (m ICMPv6) GetPayload() []byte {
+	return m.Payload
+}
+
+func (m ICMPv6) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string `json:"type"`
+		ICMPType string `json:"icmp_type"`
+		Code     uint8  `json:"code"`
+		Checksum uint16 `json:"checksum"`
+		Length   int    `json:"length"`
+	}{
+		Type:     m.Type().String(),
+		ICMPType: m.ICMPType.String(),
+		Code:     m.Code,
+		Checksum: m.Checksum,
+		Length:   len(m.Contents),
+	})
+}
@@ -0,0 +1,350 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(IGMP)
+	_ json.Marshaler = new(IGMP)
+)
+
+type IGMPType uint8
+
+const (
+	IGMPTypeMembershipQuery    IGMPType = 0x11
+	IGMPTypeV1MembershipReport IGMPType = 0x12
+	IGMPTypeV2MembershipReport IGMPType = 0x16
+	IGMPTypeV2LeaveGroup       IGMPType = 0x17
+	IGMPTypeV3MembershipReport IGMPType = 0x22
+)
+
+// GroupRecordType is the type of an IGMPv3 or MLDv2 group record.
+type GroupRecordType uint8
+
+const (
+	GroupRecordModeIsInclude   GroupRecordType = 1
+	GroupRecordModeIsExclude   GroupRecordType = 2
+	GroupRecordChangeToInclude GroupRecordType = 3
+	GroupRecordChangeToExclude GroupRecordType = 4
+	GroupRecordAllowNewSources GroupRecordType = 5
+	GroupRecordBlockOldSources GroupRecordType = 6
+)
+
+// GroupRecord is a group record of an IGMPv3 or MLDv2 membership report. It
+// describes the sources a host wants to receive traffic for the group from.
+type GroupRecord struct {
+	RecordType       GroupRecordType
+	MulticastAddress netip.Addr
+	Sources          []netip.Addr
+	AuxData          []byte
+}
+
+// IGMP is an IGMP message of version 1, 2 or 3. The version of queries is
+// determined from their length and max response code, as described in
+// RFC 3376 section 7.1.
+type IGMP struct {
+	Version      uint8
+	IGMPType     IGMPType
+	MaxRespTime  time.Duration
+	Checksum     uint16
+	GroupAddress netip.Addr
+
+	// IGMPv3 query fields
+	SuppressRouterProcessing bool
+	RobustnessValue          uint8
+	QueryInterval            time.Duration
+	Sources                  []netip.Addr
+
+	// IGMPv3 report fields
+	GroupRecords []GroupRecord
+
+	PacketBytes
+}
+
+func (g *IGMP) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("igmp message too small: %w", ErrTruncated)
+	}
+	g.IGMPType = IGMPType(data[0])
+	code := data[1]
+	g.Checksum = binary.BigEndian.Uint16(data[2:4])
+	g.GroupAddress = netip.AddrFrom4(*(*[4]byte)(data[4:8]))
+	g.MaxRespTime = 0
+	g.SuppressRouterProcessing = false
+	g.RobustnessValue = 0
+	g.QueryInterval = 0
+	g.Sources = nil
+	g.GroupRecords = nil
+	g.Contents = data
+	g.Payload = nil
+
+	switch g.IGMPType {
+	case IGMPTypeMembershipQuery:
+		switch {
+		case len(data) >= 12:
+			g.Version = 3
+			g.MaxRespTime = time.Duration(decodeExpCode(uint32(code), 4)) * 100 * time.Millisecond
+			g.SuppressRouterProcessing = data[8]&0x08 != 0
+			g.RobustnessValue = data[8] & 0x07
+			g.QueryInterval = time.Duration(decodeExpCode(uint32(data[9]), 4)) * time.Second
+			var err error
+			g.Sources, _, err = parseAddrs(data[12:], int(binary.BigEndian.Uint16(data[10:12])), 4)
+			if err != nil {
+				return err
+			}
+		case code == 0:
+			g.Version = 1
+		default:
+			g.Version = 2
+			g.MaxRespTime = time.Duration(code) * 100 * time.Millisecond
+		}
+	case IGMPTypeV1MembershipReport:
+		g.Version = 1
+	case IGMPTypeV2MembershipReport, IGMPTypeV2LeaveGroup:
+		g.Version = 2
+		g.MaxRespTime = time.Duration(code) * 100 * time.Millisecond
+	case IGMPTypeV3MembershipReport:
+		g.Version = 3
+		g.GroupAddress = netip.Addr{}
+		var err error
+		g.GroupRecords, err = parseGroupRecords(data[8:], int(binary.BigEndian.Uint16(data[6:8])), 4)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown igmp type %#x: %w", data[0], ErrUnsupported)
+	}
+	return nil
+}
+
+// Marshal encodes the message according to its type and version, and fills
+// in the checksum.
+func (g IGMP) Marshal() ([]byte, error) {
+	var b []byte
+	switch {
+	case g.IGMPType == IGMPTypeV3MembershipReport:
+		b = make([]byte, 8)
+		binary.BigEndian.PutUint16(b[6:8], uint16(len(g.GroupRecords)))
+		var err error
+		b, err = appendGroupRecords(b, g.GroupRecords, 4)
+		if err != nil {
+			return nil, err
+		}
+	case g.IGMPType == IGMPTypeMembershipQuery && g.Version == 3:
+		b = make([]byte, 12, 12+4*len(g.Sources))
+		b[1] = uint8(encodeExpCode(uint32(g.MaxRespTime/(100*time.Millisecond)), 4))
+		if g.SuppressRouterProcessing {
+			b[8] |= 0x08
+		}
+		b[8] |= g.RobustnessValue & 0x07
+		b[9] = uint8(encodeExpCode(uint32(g.QueryInterval/time.Second), 4))
+		binary.BigEndian.PutUint16(b[10:12], uint16(len(g.Sources)))
+		var err error
+		b, err = appendAddrs(b, g.Sources, 4)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		b = make([]byte, 8)
+		if g.Version >= 2 {
+			respTime := g.MaxRespTime / (100 * time.Millisecond)
+			if respTime > 255 {
+				respTime = 255
+			}
+			b[1] = uint8(respTime)
+		}
+	}
+	b[0] = uint8(g.IGMPType)
+	if g.IGMPType != IGMPTypeV3MembershipReport {
+		if g.GroupAddress.IsValid() && !g.GroupAddress.Is4() {
+			return nil, errors.New("igmp group address must be IPv4")
+		}
+		if g.GroupAddress.IsValid() {
+			copy(b[4:8], g.GroupAddress.AsSlice())
+		}
+	}
+	binary.BigEndian.PutUint16(b[2:4], checksum(b, 0))
+	return b, nil
+}
+
+func (g IGMP) Type() LayerType {
+	return LayerTypeIGMP
+}
+
+func (g IGMP) GetContents() []byte {
+	return g.Contents
+}
+
+func (g IGMP) GetPayload() []byte {
+	return g.Payload
+}
+
+func (g IGMP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type                     string            `json:"type"`
+		Version                  uint8             `json:"version"`
+		IGMPType                 string            `json:"igmp_type"`
+		MaxRespTimeMS            int64             `json:"max_resp_time_ms"`
+		Checksum                 uint16            `json:"checksum"`
+		GroupAddress             netip.Addr        `json:"group_address"`
+		SuppressRouterProcessing bool              `json:"suppress_router_processing"`
+		RobustnessValue          uint8             `json:"robustness_value"`
+		QueryIntervalMS          int64             `json:"query_interval_ms"`
+		Sources                  []netip.Addr      `json:"sources"`
+		GroupRecords             []groupRecordJSON `json:"group_records"`
+		Length                   int               `json:"length"`
+	}{
+		Type:                     g.Type().String(),
+		Version:                  g.Version,
+		IGMPType:                 g.IGMPType.String(),
+		MaxRespTimeMS:            g.MaxRespTime.Milliseconds(),
+		Checksum:                 g.Checksum,
+		GroupAddress:             g.GroupAddress,
+		SuppressRouterProcessing: g.SuppressRouterProcessing,
+		RobustnessValue:          g.RobustnessValue,
+		QueryIntervalMS:          g.QueryInterval.Milliseconds(),
+		Sources:                  nonNilAddrs(g.Sources),
+		GroupRecords:             groupRecordsJSON(g.GroupRecords),
+		Length:                   len(g.Contents),
+	})
+}
+
+type groupRecordJSON struct {
+	RecordType       string       `json:"record_type"`
+	MulticastAddress netip.Addr   `json:"multicast_address"`
+	Sources          []netip.Addr `json:"sources"`
+	AuxData          string       `json:"aux_data"`
+}
+
+func groupRecordsJSON(records []GroupRecord) []groupRecordJSON {
+	res := make([]groupRecordJSON, len(records))
+	for i, r := range records {
+		res[i] = groupRecordJSON{
+			RecordType:       r.RecordType.String(),
+			MulticastAddress: r.MulticastAddress,
+			Sources:          nonNilAddrs(r.Sources),
+			AuxData:          hex.EncodeToString(r.AuxData),
+		}
+	}
+	return res
+}
+
+func nonNilAddrs(addrs []netip.Addr) []netip.Addr {
+	if addrs == nil {
+		return []netip.Addr{}
+	}
+	return addrs
+}
+
+// parseAddrs parses n addresses of the given size from the start of data,
+// returning the addresses and the remaining data.
+func parseAddrs(data []byte, n, size int) ([]netip.Addr, []byte, error) {
+	if len(data) < n*size {
+		return nil, nil, fmt.Errorf("source list too small: %w", ErrTruncated)
+	}
+	if n == 0 {
+		return nil, data, nil
+	}
+	addrs := make([]netip.Addr, n)
+	for i := range addrs {
+		addrs[i], _ = netip.AddrFromSlice(data[i*size : (i+1)*size])
+	}
+	return addrs, data[n*size:], nil
+}
+
+func appendAddrs(b []byte, addrs []netip.Addr, size int) ([]byte, error) {
+	for _, addr := range addrs {
+		if addr.BitLen() != size*8 {
+			return nil, fmt.Errorf("invalid address %v", addr)
+		}
+		b = append(b, addr.AsSlice()...)
+	}
+	return b, nil
+}
+
+// parseGroupRecords parses n IGMPv3 or MLDv2 group records with addresses of
+// the given size.
+func parseGroupRecords(data []byte, n, size int) ([]GroupRecord, error) {
+	// Each record has at least a header and multicast address
+	if n*(4+size) > len(data) {
+		return nil, fmt.Errorf("group records exceed packet: %w", ErrTruncated)
+	}
+	records := make([]GroupRecord, n)
+	for i := range records {
+		if len(data) < 4+size {
+			return nil, fmt.Errorf("group record too small: %w", ErrTruncated)
+		}
+		r := &records[i]
+		r.RecordType = GroupRecordType(data[0])
+		auxLen := int(data[1]) * 4
+		nsrc := int(binary.BigEndian.Uint16(data[2:4]))
+		r.MulticastAddress, _ = netip.AddrFromSlice(data[4 : 4+size])
+		var err error
+		r.Sources, data, err = parseAddrs(data[4+size:], nsrc, size)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) < auxLen {
+			return nil, fmt.Errorf("group record aux data too small: %w", ErrTruncated)
+		}
+		if auxLen > 0 {
+			r.AuxData = data[:auxLen]
+		}
+		data = data[auxLen:]
+	}
+	return records, nil
+}
+
+func appendGroupRecords(b []byte, records []GroupRecord, size int) ([]byte, error) {
+	for _, r := range records {
+		if len(r.AuxData)%4 != 0 {
+			return nil, errors.New("group record aux data must be a multiple of 4 bytes")
+		}
+		b = append(b, uint8(r.RecordType), uint8(len(r.AuxData)/4))
+		b = append(b, uint8(len(r.Sources)>>8), uint8(len(r.Sources)))
+		var err error
+		b, err = appendAddrs(b, []netip.Addr{r.MulticastAddress}, size)
+		if err != nil {
+			return nil, err
+		}
+		b, err = appendAddrs(b, r.Sources, size)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, r.AuxData...)
+	}
+	return b, nil
+}
+
+// decodeExpCode decodes the floating-point format used by the Max Resp Code
+// and QQIC fields of IGMPv3 and MLDv2, given the number of mantissa bits.
+func decodeExpCode(code uint32, mantBits uint) uint32 {
+	if code < 1<<(mantBits+3) {
+		return code
+	}
+	mant := code & (1<<mantBits - 1)
+	exp := (code >> mantBits) & 0x07
+	return (mant | 1<<mantBits) << (exp + 3)
+}
+
+// encodeExpCode is the inverse of decodeExpCode. Values which cannot be
+// represented are rounded down, and values that are too large saturate.
+func encodeExpCode(v uint32, mantBits uint) uint32 {
+	if v < 1<<(mantBits+3) {
+		return v
+	}
+	for exp := uint32(0); exp < 8; exp++ {
+		if mant := v >> (exp + 3); mant < 1<<(mantBits+1) {
+			return 1<<(mantBits+3) | exp<<mantBits | mant&(1<<mantBits-1)
+		}
+	}
+	return 1<<(mantBits+4) - 1
+}
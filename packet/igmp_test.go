@@ -0,0 +1,156 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestIGMPv3Report(t *testing.T) {
+	report := packet.IGMP{
+		IGMPType: packet.IGMPTypeV3MembershipReport,
+		GroupRecords: []packet.GroupRecord{
+			{
+				RecordType:       packet.GroupRecordModeIsInclude,
+				MulticastAddress: netip.MustParseAddr("232.1.1.1"),
+				Sources: []netip.Addr{
+					netip.MustParseAddr("10.0.0.1"),
+					netip.MustParseAddr("10.0.0.2"),
+				},
+			},
+			{
+				RecordType:       packet.GroupRecordChangeToExclude,
+				MulticastAddress: netip.MustParseAddr("239.255.255.250"),
+				AuxData:          []byte{1, 2, 3, 4},
+			},
+		},
+	}
+	msg, err := report.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IPv4 header with the Router Alert option, sent to 224.0.0.22
+	ip := []byte{
+		0x46, 0xc0, 0, 0, 0, 0, 0, 0, 1, 2, 0, 0,
+		192, 168, 0, 2, 224, 0, 0, 22,
+		0x94, 0x04, 0, 0,
+	}
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(ip)+len(msg)))
+	frame := append([]byte{
+		0x01, 0x00, 0x5e, 0x00, 0x00, 0x16, 0x02, 0, 0, 0, 0, 0x0a, 0x08, 0x00,
+	}, append(ip, msg...)...)
+
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	if !p.Network.(*packet.IPv4).RouterAlert() {
+		t.Errorf("expected router alert option")
+	}
+	got, ok := p.Transport.(*packet.IGMP)
+	if !ok {
+		t.Fatalf("expected igmp layer, got %T", p.Transport)
+	}
+	if got.Version != 3 {
+		t.Errorf("version = %v, want 3", got.Version)
+	}
+	if !reflect.DeepEqual(got.GroupRecords, report.GroupRecords) {
+		t.Errorf("group records = %+v, want %+v", got.GroupRecords, report.GroupRecords)
+	}
+	if sum := onesSum(msg, 0); sum != 0xFFFF {
+		t.Errorf("invalid checksum")
+	}
+
+	// The record count must fit within the message
+	bad := []byte{0x22, 0, 0, 0, 0, 0, 0xFF, 0xFF, 1, 0, 0, 0, 232, 1, 1, 1}
+	if err := new(packet.IGMP).Unmarshal(bad); err == nil {
+		t.Error("excessive record count decoded without error")
+	}
+}
+
+func TestIGMPQueryVersion(t *testing.T) {
+	for _, tc := range []struct {
+		query packet.IGMP
+		want  uint8
+	}{
+		{packet.IGMP{IGMPType: packet.IGMPTypeMembershipQuery, Version: 1}, 1},
+		{packet.IGMP{IGMPType: packet.IGMPTypeMembershipQuery, Version: 2, MaxRespTime: 10 * time.Second}, 2},
+		{packet.IGMP{
+			IGMPType:        packet.IGMPTypeMembershipQuery,
+			Version:         3,
+			MaxRespTime:     20 * time.Second,
+			RobustnessValue: 2,
+			QueryInterval:   125 * time.Second,
+			GroupAddress:    netip.MustParseAddr("232.1.1.1"),
+			Sources:         []netip.Addr{netip.MustParseAddr("10.0.0.1")},
+		}, 3},
+	} {
+		b, err := tc.query.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got packet.IGMP
+		if err := got.Unmarshal(b); err != nil {
+			t.Fatal(err)
+		}
+		if got.Version != tc.want {
+			t.Errorf("version = %v, want %v", got.Version, tc.want)
+		}
+		if got.MaxRespTime != tc.query.MaxRespTime || got.QueryInterval != tc.query.QueryInterval ||
+			!reflect.DeepEqual(got.Sources, tc.query.Sources) {
+			t.Errorf("v%d query did not round-trip, got %+v", tc.want, got)
+		}
+	}
+}
+
+func TestMLDv2Query(t *testing.T) {
+	src := netip.MustParseAddr("fe80::1")
+	dst := netip.MustParseAddr("ff02::1")
+	query := packet.MLD{
+		ICMPType:         packet.ICMPv6TypeMLDQuery,
+		Version:          2,
+		MaxResponseDelay: 40960 * time.Millisecond,
+		RobustnessValue:  2,
+		QueryInterval:    125 * time.Second,
+		MulticastAddress: netip.MustParseAddr("ff3e::8000:1"),
+		Sources:          []netip.Addr{netip.MustParseAddr("2001:db8::1")},
+	}
+	msg, err := query.Marshal(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// IPv6 header followed by a Hop-by-Hop header with the Router Alert option
+	ip := make([]byte, 48)
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], uint16(8+len(msg)))
+	ip[7] = 1
+	copy(ip[8:24], src.AsSlice())
+	copy(ip[24:40], dst.AsSlice())
+	copy(ip[40:48], []byte{58, 0, 0x05, 0x02, 0, 0, 1, 0})
+	frame := append([]byte{
+		0x33, 0x33, 0, 0, 0, 1, 0x02, 0, 0, 0, 0, 0x0a, 0x86, 0xdd,
+	}, append(ip, msg...)...)
+
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	if !p.Network.(*packet.IPv6).RouterAlert() {
+		t.Errorf("expected router alert option")
+	}
+	got, ok := p.Transport.(*packet.MLD)
+	if !ok {
+		t.Fatalf("expected mld layer, got %T", p.Transport)
+	}
+	if got.Version != 2 || got.MaxResponseDelay != query.MaxResponseDelay ||
+		got.QueryInterval != query.QueryInterval || got.MulticastAddress != query.MulticastAddress ||
+		!reflect.DeepEqual(got.Sources, query.Sources) {
+		t.Errorf("query did not round-trip, got %+v", got)
+	}
+}
@@ -2,12 +2,49 @@ package packet
 
 import (
 	"encoding/binary"
-	"errors"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"net/netip"
 )
 
-var _ Layer = new(IPv4)
+var (
+	_ Layer          = new(IPv4)
+	_ json.Marshaler = new(IPv4)
+)
+
+// IPProtocol is the protocol number carried in the IPv4 Protocol and IPv6 Next
+// Header fields.
+type IPProtocol uint8
+
+const (
+	IPProtocolIPv6HopByHop IPProtocol = 0
+	IPProtocolICMP         IPProtocol = 1
+	IPProtocolIGMP         IPProtocol = 2
+	IPProtocolIPv4         IPProtocol = 4
+	IPProtocolTCP          IPProtocol = 6
+	IPProtocolUDP          IPProtocol = 17
+	IPProtocolIPv6         IPProtocol = 41
+	IPProtocolIPv6Route    IPProtocol = 43
+	IPProtocolIPv6Frag     IPProtocol = 44
+	IPProtocolESP          IPProtocol = 50
+	IPProtocolAH           IPProtocol = 51
+	IPProtocolICMPv6       IPProtocol = 58
+	IPProtocolIPv6NoNext   IPProtocol = 59
+	IPProtocolIPv6Opts     IPProtocol = 60
+	IPProtocolOSPF         IPProtocol = 89
+	IPProtocolSCTP         IPProtocol = 132
+)
+
+// IPv4OptionRouterAlert is the type of the Router Alert option (RFC 2113).
+const IPv4OptionRouterAlert = 0x94
+
+// IPv4Option is an option of the IPv4 header. Data excludes the type and length
+// octets.
+type IPv4Option struct {
+	Type uint8
+	Data []byte
+}
 
 type IPv4 struct {
 	IHL            uint8
@@ -18,25 +55,29 @@ type IPv4 struct {
 	Flags          uint8
 	FragOffset     uint16
 	Hops           uint8
-	Proto          uint8
+	Proto          IPProtocol
 	HeaderChecksum uint16
 	Source         netip.Addr
 	Destination    netip.Addr
-	// Todo: options
+	Options        []IPv4Option
+	// Truncated is set if the packet was captured without the end of its
+	// payload, in which case Contents and Payload end with the captured data.
+	Truncated bool
 	PacketBytes
 }
 
 func (p *IPv4) Unmarshal(data []byte) error {
 	if len(data) < 20 {
-		return errors.New("ip packet too small")
+		return fmt.Errorf("ip packet too small: %w", ErrTruncated)
 	}
 	ver := data[0] >> 4
 	if ver != 4 {
-		return fmt.Errorf("ip packets must be v4, was %v", ver)
+		return fmt.Errorf("ip packets must be v4, was %v: %w", ver, ErrUnsupported)
 	}
 	p.IHL = uint8(data[0] & 0x0F)
-	if p.IHL != 5 {
-		return errors.New("ip options not supported")
+	hdrLen := int(p.IHL) * 4
+	if hdrLen < 20 || hdrLen > len(data) {
+		return fmt.Errorf("invalid ip header length: %w", ErrInvalid)
 	}
 	p.DSCP = uint8(data[1] >> 2)
 	p.ECN = uint8(data[1] & 0x03)
@@ -46,24 +87,66 @@ func (p *IPv4) Unmarshal(data []byte) error {
 	p.Flags = uint8(flagsFragOff >> 13)
 	p.FragOffset = flagsFragOff & 0x1FFF
 	p.Hops = data[8]
-	p.Proto = data[9]
+	p.Proto = IPProtocol(data[9])
 	p.HeaderChecksum = binary.BigEndian.Uint16(data[10:12])
 	var ok bool
 	p.Source, ok = netip.AddrFromSlice(data[12:16])
 	if !ok {
-		return errors.New("invalid source ip")
+		return fmt.Errorf("invalid source ip: %w", ErrInvalid)
 	}
 	p.Destination, ok = netip.AddrFromSlice(data[16:20])
 	if !ok {
-		return errors.New("invalid destination ip")
+		return fmt.Errorf("invalid destination ip: %w", ErrInvalid)
+	}
+	if int(p.TotalLen) < hdrLen {
+		return fmt.Errorf("invalid ip total length: %w", ErrInvalid)
+	}
+	end := int(p.TotalLen)
+	p.Truncated = end > len(data)
+	if p.Truncated {
+		end = len(data)
 	}
-	p.Contents = data
-	p.Payload = data[24:]
+	var err error
+	p.Options, err = parseIPv4Options(p.Options[:0], data[20:hdrLen])
+	if err != nil {
+		return err
+	}
+	p.Contents = data[:end]
+	p.Payload = data[hdrLen:end]
 	return nil
 }
 
+func parseIPv4Options(opts []IPv4Option, data []byte) ([]IPv4Option, error) {
+	for len(data) > 0 {
+		switch typ := data[0]; typ {
+		case 0: // End of options list
+			return opts, nil
+		case 1: // No operation
+			data = data[1:]
+		default:
+			if len(data) < 2 || data[1] < 2 || int(data[1]) > len(data) {
+				return opts, fmt.Errorf("invalid length of ip option %d: %w", typ, ErrInvalid)
+			}
+			opts = append(opts, IPv4Option{Type: typ, Data: data[2:data[1]]})
+			data = data[data[1]:]
+		}
+	}
+	return opts, nil
+}
+
+// RouterAlert reports whether the packet carries the Router Alert option, i.e.
+// whether routers should examine it even though it is not addressed to them.
+func (p IPv4) RouterAlert() bool {
+	for _, opt := range p.Options {
+		if opt.Type == IPv4OptionRouterAlert {
+			return true
+		}
+	}
+	return false
+}
+
 func (e IPv4) Type() LayerType {
-	return LayerTypeEthernet
+	return LayerTypeIPv4
 }
 
 func (e IPv4) GetContents() []byte {
@@ -73,3 +156,51 @@ func (e IPv4) GetContents() []byte {
 func (e IPv4) GetPayload() []byte {
 	return e.Payload
 }
+
+func (e IPv4) MarshalJSON() ([]byte, error) {
+	options := make([]option, len(e.Options))
+	for i, opt := range e.Options {
+		options[i] = option{opt.Type, hex.EncodeToString(opt.Data)}
+	}
+	return json.Marshal(struct {
+		Type           string     `json:"type"`
+		IHL            uint8      `json:"ihl"`
+		DSCP           uint8      `json:"dscp"`
+		ECN            uint8      `json:"ecn"`
+		TotalLen       uint16     `json:"total_len"`
+		ID             uint16     `json:"id"`
+		Flags          uint8      `json:"flags"`
+		FragOffset     uint16     `json:"frag_offset"`
+		Hops           uint8      `json:"hops"`
+		Proto          string     `json:"proto"`
+		HeaderChecksum uint16     `json:"header_checksum"`
+		Source         netip.Addr `json:"source"`
+		Destination    netip.Addr `json:"destination"`
+		Options        []option   `json:"options"`
+		Truncated      bool       `json:"truncated,omitempty"`
+		Length         int        `json:"length"`
+	}{
+		Type:           e.Type().String(),
+		IHL:            e.IHL,
+		DSCP:           e.DSCP,
+		ECN:            e.ECN,
+		TotalLen:       e.TotalLen,
+		ID:             e.ID,
+		Flags:          e.Flags,
+		FragOffset:     e.FragOffset,
+		Hops:           e.Hops,
+		Proto:          e.Proto.String(),
+		HeaderChecksum: e.HeaderChecksum,
+		Source:         e.Source,
+		Destination:    e.Destination,
+		Options:        options,
+		Truncated:      e.Truncated,
+		Length:         len(e.Contents),
+	})
+}
+
+// option is the JSON representation of IPv4 and IPv6 options.
+type option struct {
+	Type uint8  `json:"type"`
+	Data string `json:"data"`
+}
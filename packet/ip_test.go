@@ -0,0 +1,53 @@
+package packet_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestIPv4Truncated(t *testing.T) {
+	src, dst := netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2")
+	seg := append([]byte{0x30, 0x39, 0, 53, 0, 20, 0, 0}, "hello, world"...)
+	ip := ipv4Packet(src, dst, 17, seg, 6)
+
+	// Captured with a snapshot length which cuts the UDP payload
+	p := decodeIPv4(t, ip[:20+8+4])
+	v4, ok := p.Network.(*packet.IPv4)
+	if !ok || !v4.Truncated || v4.TotalLen != 40 || len(v4.Contents) != 32 || v4.Source != src {
+		t.Fatalf("unexpected network layer %+v", p.Network)
+	}
+	u, ok := p.Transport.(*packet.UDP)
+	if !ok || !u.Truncated || u.Length != 20 || string(u.Payload) != "hell" {
+		t.Fatalf("unexpected transport layer %+v", p.Transport)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"truncated":true`) {
+		t.Errorf("unexpected json %s", data)
+	}
+
+	// Complete packets are not truncated
+	p = decodeIPv4(t, ip)
+	if p.Network.(*packet.IPv4).Truncated || p.Transport.(*packet.UDP).Truncated {
+		t.Error("complete packet was truncated")
+	}
+
+	// The total length must cover the header
+	ip[3] = 19
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip...)
+	if _, err := packet.Decode(frame); !errors.Is(err, packet.ErrInvalid) {
+		t.Errorf("got %v, want an invalid packet error for total length below the header length", err)
+	}
+
+	// Headers which do not fit the capture are truncated
+	if _, err := packet.Decode(frame[:14+10]); !errors.Is(err, packet.ErrTruncated) {
+		t.Errorf("got %v, want a truncated packet error", err)
+	}
+}
@@ -0,0 +1,335 @@
+package packet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(AH)
+	_ Layer          = new(ESP)
+	_ json.Marshaler = new(AH)
+	_ json.Marshaler = new(ESP)
+)
+
+// AH is an IPsec Authentication Header (RFC 4302). The payload is the
+// authenticated packet which follows the header.
+type AH struct {
+	NextHeader IPProtocol
+	// PayloadLen is the length of the header in 4-octet units, minus 2.
+	PayloadLen uint8
+	SPI        uint32
+	Sequence   uint32
+	ICV        []byte
+	PacketBytes
+}
+
+func (a *AH) Unmarshal(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("ah header too small: %w", ErrTruncated)
+	}
+	a.NextHeader = IPProtocol(data[0])
+	a.PayloadLen = data[1]
+	hdrLen := (int(a.PayloadLen) + 2) * 4
+	if hdrLen < 12 || hdrLen > len(data) {
+		return fmt.Errorf("invalid ah header length: %w", ErrInvalid)
+	}
+	a.SPI = binary.BigEndian.Uint32(data[4:8])
+	a.Sequence = binary.BigEndian.Uint32(data[8:12])
+	a.ICV = data[12:hdrLen]
+	a.Contents = data
+	a.Payload = data[hdrLen:]
+	return nil
+}
+
+func (a AH) Type() LayerType {
+	return LayerTypeAH
+}
+
+func (a AH) GetContents() []byte {
+	return a.Contents
+}
+
+func (a AH) GetPayload() []byte {
+	return a.Payload
+}
+
+func (a AH) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"type"`
+		NextHeader string `json:"next_header"`
+		SPI        uint32 `json:"spi"`
+		Sequence   uint32 `json:"sequence"`
+		ICV        string `json:"icv"`
+		Length     int    `json:"length"`
+	}{
+		Type:       a.Type().String(),
+		NextHeader: a.NextHeader.String(),
+		SPI:        a.SPI,
+		Sequence:   a.Sequence,
+		ICV:        hex.EncodeToString(a.ICV),
+		Length:     len(a.Contents),
+	})
+}
+
+// ESP is an IPsec Encapsulating Security Payload packet (RFC 4303). Until the
+// packet is decrypted, the payload is the encrypted data which follows the
+// sequence number, and only the SPI and sequence number are known.
+type ESP struct {
+	SPI      uint32
+	Sequence uint32
+
+	// AuthFailed is set if the packet could not be verified and decrypted
+	// with the keys of its security association when it was decoded.
+	AuthFailed bool
+
+	// Decrypted is set if the packet was decrypted with the keys of a
+	// security association. Payload then contains the plaintext payload
+	// data, without the padding and trailer, and the fields below are set.
+	Decrypted  bool
+	IV         []byte
+	PadLength  uint8
+	NextHeader IPProtocol
+	ICV        []byte
+	PacketBytes
+}
+
+func (e *ESP) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("esp packet too small: %w", ErrTruncated)
+	}
+	*e = ESP{}
+	e.SPI = binary.BigEndian.Uint32(data[0:4])
+	e.Sequence = binary.BigEndian.Uint32(data[4:8])
+	e.Contents = data
+	e.Payload = data[8:]
+	return nil
+}
+
+// Decrypt verifies and decrypts the packet with the keys of sa. The plaintext
+// is written to a new buffer, so the contents of the packet are not modified.
+// Extended sequence numbers are not supported.
+func (e *ESP) Decrypt(sa SecurityAssociation) error {
+	if err := sa.validate(); err != nil {
+		return err
+	}
+	b := e.Contents
+	var plain []byte
+	switch sa.Algorithm {
+	case ESPAlgorithmAESGCM:
+		icvLen := sa.icvLen()
+		if len(b) < 16+icvLen {
+			return fmt.Errorf("esp packet too small: %w", ErrTruncated)
+		}
+		salt := sa.Key[len(sa.Key)-4:]
+		block, _ := aes.NewCipher(sa.Key[:len(sa.Key)-4])
+		aead, _ := cipher.NewGCMWithTagSize(block, icvLen)
+		nonce := make([]byte, 0, 12)
+		nonce = append(append(nonce, salt...), b[8:16]...)
+		var err error
+		plain, err = aead.Open(nil, nonce, b[16:], b[:8])
+		if err != nil {
+			return fmt.Errorf("esp authentication failed: %w", ErrAuthFailed)
+		}
+		e.IV, e.ICV = b[8:16], b[len(b)-icvLen:]
+	default:
+		icvLen := sa.icvLen()
+		n := len(b) - 8 - aes.BlockSize - icvLen
+		if n < 0 || n%aes.BlockSize != 0 {
+			return fmt.Errorf("invalid esp ciphertext length: %w", ErrInvalid)
+		}
+		mac := hmac.New(sa.hash(), sa.AuthKey)
+		mac.Write(b[:len(b)-icvLen])
+		icv := b[len(b)-icvLen:]
+		if !hmac.Equal(mac.Sum(nil)[:icvLen], icv) {
+			return fmt.Errorf("esp authentication failed: %w", ErrAuthFailed)
+		}
+		block, _ := aes.NewCipher(sa.Key)
+		iv := b[8 : 8+aes.BlockSize]
+		plain = make([]byte, n)
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, b[8+aes.BlockSize:len(b)-icvLen])
+		e.IV, e.ICV = iv, icv
+	}
+
+	// Trailer
+	if len(plain) < 2 {
+		return fmt.Errorf("esp payload too small: %w", ErrTruncated)
+	}
+	e.PadLength = plain[len(plain)-2]
+	e.NextHeader = IPProtocol(plain[len(plain)-1])
+	if int(e.PadLength)+2 > len(plain) {
+		return fmt.Errorf("invalid esp padding length: %w", ErrInvalid)
+	}
+	e.Payload = plain[:len(plain)-2-int(e.PadLength)]
+	e.Decrypted = true
+	return nil
+}
+
+func (e ESP) Type() LayerType {
+	return LayerTypeESP
+}
+
+func (e ESP) GetContents() []byte {
+	return e.Contents
+}
+
+func (e ESP) GetPayload() []byte {
+	return e.Payload
+}
+
+func (e ESP) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type       string `json:"type"`
+		SPI        uint32 `json:"spi"`
+		Sequence   uint32 `json:"sequence"`
+		AuthFailed bool   `json:"auth_failed"`
+		Decrypted  bool   `json:"decrypted"`
+		IV         string `json:"iv,omitempty"`
+		PadLength  *uint8 `json:"pad_length,omitempty"`
+		NextHeader string `json:"next_header,omitempty"`
+		ICV        string `json:"icv,omitempty"`
+		Length     int    `json:"length"`
+	}{
+		Type:       e.Type().String(),
+		SPI:        e.SPI,
+		Sequence:   e.Sequence,
+		AuthFailed: e.AuthFailed,
+		Decrypted:  e.Decrypted,
+		Length:     len(e.Contents),
+	}
+	if e.Decrypted {
+		v.IV = hex.EncodeToString(e.IV)
+		v.PadLength = &e.PadLength
+		v.NextHeader = e.NextHeader.String()
+		v.ICV = hex.EncodeToString(e.ICV)
+	}
+	return json.Marshal(v)
+}
+
+// ESPAlgorithm is the combination of encryption and integrity algorithms of an
+// ESP security association.
+type ESPAlgorithm uint8
+
+const (
+	// ESPAlgorithmAESGCM is AES-GCM with an 8-octet IV (RFC 4106). The key is
+	// followed by the 4-octet salt, as in the keying material of IKEv2.
+	ESPAlgorithmAESGCM ESPAlgorithm = iota + 1
+
+	// The AES-CBC algorithms use a 16-octet IV (RFC 3602), and a truncated
+	// HMAC as ICV (RFC 2404, RFC 4868).
+	ESPAlgorithmAESCBCHMACSHA1
+	ESPAlgorithmAESCBCHMACSHA256
+	ESPAlgorithmAESCBCHMACSHA384
+	ESPAlgorithmAESCBCHMACSHA512
+)
+
+// SecurityAssociation contains the keys of an inbound or outbound ESP
+// security association.
+type SecurityAssociation struct {
+	SPI       uint32
+	Algorithm ESPAlgorithm
+
+	// Key is the encryption key, followed by the salt for AES-GCM.
+	Key []byte
+
+	// AuthKey is the HMAC key of the AES-CBC algorithms.
+	AuthKey []byte
+
+	// ICVLength is the length of the AES-GCM ICV, which may be 12 or 16
+	// octets. The default is 16.
+	ICVLength int
+}
+
+func (sa SecurityAssociation) validate() error {
+	switch sa.Algorithm {
+	case ESPAlgorithmAESGCM:
+		if n := len(sa.Key) - 4; n != 16 && n != 24 && n != 32 {
+			return fmt.Errorf("invalid aes-gcm key length %d", len(sa.Key))
+		}
+		if sa.ICVLength != 0 && sa.ICVLength != 12 && sa.ICVLength != 16 {
+			return fmt.Errorf("invalid aes-gcm icv length %d", sa.ICVLength)
+		}
+	case ESPAlgorithmAESCBCHMACSHA1, ESPAlgorithmAESCBCHMACSHA256,
+		ESPAlgorithmAESCBCHMACSHA384, ESPAlgorithmAESCBCHMACSHA512:
+		if n := len(sa.Key); n != 16 && n != 24 && n != 32 {
+			return fmt.Errorf("invalid aes-cbc key length %d", n)
+		}
+		if len(sa.AuthKey) == 0 {
+			return errors.New("missing hmac key")
+		}
+	default:
+		return fmt.Errorf("unsupported esp algorithm %d", sa.Algorithm)
+	}
+	return nil
+}
+
+func (sa SecurityAssociation) hash() func() hash.Hash {
+	switch sa.Algorithm {
+	case ESPAlgorithmAESCBCHMACSHA1:
+		return sha1.New
+	case ESPAlgorithmAESCBCHMACSHA256:
+		return sha256.New
+	case ESPAlgorithmAESCBCHMACSHA384:
+		return sha512.New384
+	}
+	return sha512.New
+}
+
+func (sa SecurityAssociation) icvLen() int {
+	switch sa.Algorithm {
+	case ESPAlgorithmAESGCM:
+		if sa.ICVLength == 0 {
+			return 16
+		}
+		return sa.ICVLength
+	case ESPAlgorithmAESCBCHMACSHA1:
+		return 12
+	}
+	// Half the length of the hash
+	return sa.hash()().Size() / 2
+}
+
+// AddSA adds the keys of a security association, so that decoded ESP packets
+// with its SPI are decrypted, and their payload decoded. A previously added
+// association with the same SPI is replaced. SPIs are assumed to be unique
+// across destinations.
+func (d *Decoder) AddSA(sa SecurityAssociation) error {
+	if err := sa.validate(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sas == nil {
+		d.sas = make(map[uint32]SecurityAssociation)
+	}
+	d.sas[sa.SPI] = sa
+	return nil
+}
+
+// RemoveSA removes the security association with the provided SPI.
+func (d *Decoder) RemoveSA(spi uint32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.sas, spi)
+}
+
+func (d *Decoder) lookupSA(spi uint32) (SecurityAssociation, bool) {
+	if d == nil {
+		return SecurityAssociation{}, false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	sa, ok := d.sas[spi]
+	return sa, ok
+}
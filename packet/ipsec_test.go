@@ -0,0 +1,155 @@
+package packet_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// espTrailer pads the payload to a multiple of blockSize and appends the ESP
+// trailer.
+func espTrailer(payload []byte, blockSize int, next packet.IPProtocol) []byte {
+	res := append([]byte{}, payload...)
+	pad := (blockSize - (len(res)+2)%blockSize) % blockSize
+	for i := 1; i <= pad; i++ {
+		res = append(res, byte(i))
+	}
+	return append(res, byte(pad), byte(next))
+}
+
+// decodeIPv4With decodes an IPv4 packet with the keys of d.
+func decodeIPv4With(t *testing.T, d *packet.Decoder, ip []byte) packet.Packet {
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip...)
+	p, err := d.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	return p
+}
+
+func TestESPGCMTransport(t *testing.T) {
+	sa := packet.SecurityAssociation{
+		SPI:       0x1001,
+		Algorithm: packet.ESPAlgorithmAESGCM,
+		Key:       bytes.Repeat([]byte{0x11}, 20),
+	}
+	var d packet.Decoder
+	if err := d.AddSA(sa); err != nil {
+		t.Fatal(err)
+	}
+
+	udp := []byte{0x30, 0x39, 0, 53, 0, 12, 0, 0, 'p', 'i', 'n', 'g'}
+	hdr := []byte{0, 0, 0x10, 0x01, 0, 0, 0, 7}
+	iv := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	block, _ := aes.NewCipher(sa.Key[:16])
+	aead, _ := cipher.NewGCM(block)
+	nonce := append(append([]byte{}, sa.Key[16:]...), iv...)
+	esp := append(append(hdr, iv...), aead.Seal(nil, nonce, espTrailer(udp, 4, packet.IPProtocolUDP), hdr)...)
+
+	src, dst := netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2")
+	p := decodeIPv4With(t, &d, ipv4Packet(src, dst, 50, esp, -1))
+	if p.ESP == nil || !p.ESP.Decrypted || p.ESP.SPI != 0x1001 || p.ESP.Sequence != 7 ||
+		p.ESP.NextHeader != packet.IPProtocolUDP {
+		t.Fatalf("unexpected esp %+v", p.ESP)
+	}
+	u, ok := p.Transport.(*packet.UDP)
+	if !ok || u.DestinationPort != 53 || string(u.Payload) != "ping" {
+		t.Fatalf("unexpected transport %+v", p.Transport)
+	}
+	if _, err := json.Marshal(p); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+
+	// Tampered packets fail authentication, which is reported on the ESP
+	// layer
+	b := ipv4Packet(src, dst, 50, esp, -1)
+	b[len(b)-1] ^= 1
+	p = decodeIPv4With(t, &d, b)
+	if p.ESP == nil || !p.ESP.AuthFailed || p.ESP.Decrypted || p.Network == nil || p.Transport != nil {
+		t.Errorf("unexpected tampered packet %+v", p)
+	}
+	if data, err := json.Marshal(p.ESP); err != nil || !strings.Contains(string(data), `"auth_failed":true`) {
+		t.Errorf("unexpected json %s, %v", data, err)
+	}
+
+	// Without the keys, the packet is not decrypted
+	d.RemoveSA(sa.SPI)
+	p = decodeIPv4(t, ipv4Packet(src, dst, 50, esp, -1))
+	if p.ESP == nil || p.ESP.AuthFailed || p.ESP.Decrypted || p.Transport != nil {
+		t.Errorf("unexpected encrypted packet %+v", p.ESP)
+	}
+}
+
+func TestESPCBCTunnel(t *testing.T) {
+	sa := packet.SecurityAssociation{
+		SPI:       0x2002,
+		Algorithm: packet.ESPAlgorithmAESCBCHMACSHA256,
+		Key:       bytes.Repeat([]byte{0x22}, 16),
+		AuthKey:   bytes.Repeat([]byte{0x33}, 32),
+	}
+	var d packet.Decoder
+	if err := d.AddSA(sa); err != nil {
+		t.Fatal(err)
+	}
+
+	inner := ipv4Packet(netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.1.1"),
+		17, []byte{0, 68, 0, 67, 0, 8, 0, 0}, -1)
+	plain := espTrailer(inner, aes.BlockSize, packet.IPProtocolIPv4)
+	iv := bytes.Repeat([]byte{0x44}, aes.BlockSize)
+	block, _ := aes.NewCipher(sa.Key)
+	ct := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, plain)
+	esp := make([]byte, 8)
+	binary.BigEndian.PutUint32(esp[0:4], sa.SPI)
+	binary.BigEndian.PutUint32(esp[4:8], 1)
+	esp = append(append(esp, iv...), ct...)
+	mac := hmac.New(sha256.New, sa.AuthKey)
+	mac.Write(esp)
+	esp = append(esp, mac.Sum(nil)[:16]...)
+
+	p := decodeIPv4With(t, &d, ipv4Packet(netip.MustParseAddr("198.51.100.1"),
+		netip.MustParseAddr("198.51.100.2"), 50, esp, -1))
+	if p.ESP == nil || !p.ESP.Decrypted || p.Transport != nil {
+		t.Fatalf("unexpected esp %+v", p.ESP)
+	}
+	if p.Inner == nil {
+		t.Fatalf("missing inner packet")
+	}
+	ip, ok := p.Inner.Network.(*packet.IPv4)
+	if !ok || ip.Source != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("unexpected inner network %+v", p.Inner.Network)
+	}
+	if _, ok := p.Inner.Transport.(*packet.UDP); !ok {
+		t.Errorf("expected inner udp, got %T", p.Inner.Transport)
+	}
+	if n := len(p.Layers()); n != 5 {
+		t.Errorf("expected 5 layers, got %d", n)
+	}
+}
+
+func TestAH(t *testing.T) {
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], 179)
+	binary.BigEndian.PutUint16(tcp[2:4], 40000)
+	tcp[12] = 5 << 4
+	ah := []byte{6, 4, 0, 0, 0, 0, 0x30, 0x03, 0, 0, 0, 9}
+	ah = append(ah, bytes.Repeat([]byte{0xAA}, 12)...)
+	p := decodeIPv4(t, ipv4Packet(netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"), 51, append(ah, tcp...), -1))
+	if p.AH == nil || p.AH.SPI != 0x3003 || p.AH.Sequence != 9 || len(p.AH.ICV) != 12 ||
+		p.AH.NextHeader != packet.IPProtocolTCP {
+		t.Fatalf("unexpected ah %+v", p.AH)
+	}
+	if tcp, ok := p.Transport.(*packet.TCP); !ok || tcp.SourcePort != 179 {
+		t.Errorf("unexpected transport %+v", p.Transport)
+	}
+}
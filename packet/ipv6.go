@@ -0,0 +1,179 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+)
+
+var (
+	_ Layer          = new(IPv6)
+	_ json.Marshaler = new(IPv6)
+)
+
+// IPv6OptionRouterAlert is the type of the Router Alert hop-by-hop option
+// (RFC 2711).
+const IPv6OptionRouterAlert = 0x05
+
+// IPv6Option is an option of the Hop-by-Hop or Destination Options extension
+// headers. Data excludes the type and length octets.
+type IPv6Option struct {
+	Type uint8
+	Data []byte
+}
+
+type IPv6 struct {
+	TrafficClass uint8
+	FlowLabel    uint32
+	PayloadLen   uint16
+	NextHeader   IPProtocol
+	HopLimit     uint8
+	Source       netip.Addr
+	Destination  netip.Addr
+	// HopByHop contains the options of the Hop-by-Hop extension header.
+	HopByHop []IPv6Option
+	// FragOffset is the offset of the Fragment extension header, if present.
+	FragOffset uint16
+	// Proto is the protocol of the payload, following any extension headers.
+	Proto IPProtocol
+	// Truncated is set if the packet was captured without the end of its
+	// payload, in which case Contents and Payload end with the captured data.
+	Truncated bool
+	PacketBytes
+}
+
+func (p *IPv6) Unmarshal(data []byte) error {
+	if len(data) < 40 {
+		return fmt.Errorf("ipv6 packet too small: %w", ErrTruncated)
+	}
+	if ver := data[0] >> 4; ver != 6 {
+		return fmt.Errorf("ipv6 packets must be v6, was %v: %w", ver, ErrUnsupported)
+	}
+	p.TrafficClass = uint8(binary.BigEndian.Uint16(data[0:2]) >> 4)
+	p.FlowLabel = binary.BigEndian.Uint32(data[0:4]) & 0x000FFFFF
+	p.PayloadLen = binary.BigEndian.Uint16(data[4:6])
+	p.NextHeader = IPProtocol(data[6])
+	p.HopLimit = data[7]
+	p.Source = netip.AddrFrom16(*(*[16]byte)(data[8:24]))
+	p.Destination = netip.AddrFrom16(*(*[16]byte)(data[24:40]))
+	end := 40 + int(p.PayloadLen)
+	p.Truncated = end > len(data)
+	if p.Truncated {
+		end = len(data)
+	}
+	p.Contents = data[:end]
+	p.HopByHop = p.HopByHop[:0]
+	p.FragOffset = 0
+
+	// Walk the extension headers
+	payload := p.Contents[40:]
+	p.Proto = p.NextHeader
+	for {
+		switch p.Proto {
+		case IPProtocolIPv6HopByHop, IPProtocolIPv6Route, IPProtocolIPv6Opts:
+			if len(payload) < 8 || len(payload) < (int(payload[1])+1)*8 {
+				return fmt.Errorf("ipv6 extension header too small: %w", ErrTruncated)
+			}
+			hdrLen := (int(payload[1]) + 1) * 8
+			if p.Proto == IPProtocolIPv6HopByHop {
+				var err error
+				p.HopByHop, err = parseIPv6Options(p.HopByHop, payload[2:hdrLen])
+				if err != nil {
+					return err
+				}
+			}
+			p.Proto = IPProtocol(payload[0])
+			payload = payload[hdrLen:]
+		case IPProtocolIPv6Frag:
+			if len(payload) < 8 {
+				return fmt.Errorf("ipv6 fragment header too small: %w", ErrTruncated)
+			}
+			p.FragOffset = binary.BigEndian.Uint16(payload[2:4]) >> 3
+			p.Proto = IPProtocol(payload[0])
+			payload = payload[8:]
+		default:
+			p.Payload = payload
+			return nil
+		}
+	}
+}
+
+func parseIPv6Options(opts []IPv6Option, data []byte) ([]IPv6Option, error) {
+	for len(data) > 0 {
+		typ := data[0]
+		if typ == 0 { // Pad1
+			data = data[1:]
+			continue
+		}
+		if len(data) < 2 || int(data[1])+2 > len(data) {
+			return opts, fmt.Errorf("invalid length of ipv6 option %d: %w", typ, ErrInvalid)
+		}
+		l := int(data[1])
+		if typ != 1 { // PadN
+			opts = append(opts, IPv6Option{Type: typ, Data: data[2 : 2+l]})
+		}
+		data = data[2+l:]
+	}
+	return opts, nil
+}
+
+// RouterAlert reports whether the packet carries the Router Alert hop-by-hop
+// option.
+func (p IPv6) RouterAlert() bool {
+	for _, opt := range p.HopByHop {
+		if opt.Type == IPv6OptionRouterAlert {
+			return true
+		}
+	}
+	return false
+}
+
+func (p IPv6) Type() LayerType {
+	return LayerTypeIPv6
+}
+
+func (p IPv6) GetContents() []byte {
+	return p.Contents
+}
+
+func (p IPv6) GetPayload() []byte {
+	return p.Payload
+}
+
+func (p IPv6) MarshalJSON() ([]byte, error) {
+	hopByHop := make([]option, len(p.HopByHop))
+	for i, opt := range p.HopByHop {
+		hopByHop[i] = option{opt.Type, hex.EncodeToString(opt.Data)}
+	}
+	return json.Marshal(struct {
+		Type         string     `json:"type"`
+		TrafficClass uint8      `json:"traffic_class"`
+		FlowLabel    uint32     `json:"flow_label"`
+		PayloadLen   uint16     `json:"payload_len"`
+		NextHeader   string     `json:"next_header"`
+		HopLimit     uint8      `json:"hop_limit"`
+		Source       netip.Addr `json:"source"`
+		Destination  netip.Addr `json:"destination"`
+		HopByHop     []option   `json:"hop_by_hop"`
+		FragOffset   uint16     `json:"frag_offset"`
+		Proto        string     `json:"proto"`
+		Truncated    bool       `json:"truncated,omitempty"`
+		Length       int        `json:"length"`
+	}{
+		Type:         p.Type().String(),
+		TrafficClass: p.TrafficClass,
+		FlowLabel:    p.FlowLabel,
+		PayloadLen:   p.PayloadLen,
+		NextHeader:   p.NextHeader.String(),
+		HopLimit:     p.HopLimit,
+		Source:       p.Source,
+		Destination:  p.Destination,
+		HopByHop:     hopByHop,
+		FragOffset:   p.FragOffset,
+		Proto:        p.Proto.String(),
+		Truncated:    p.Truncated,
+		Length:       len(p.Contents),
+	})
+}
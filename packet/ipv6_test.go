@@ -0,0 +1,57 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestIPv6LongOption(t *testing.T) {
+	// Hop-by-Hop header with a 255 octet option, padded with PadN to 264
+	// octets, followed by no next header
+	hbh := []byte{59, 32, 0x3E, 255}
+	hbh = append(hbh, make([]byte, 255)...)
+	hbh = append(hbh, 1, 3, 0, 0, 0)
+	ip := make([]byte, 40, 40+len(hbh))
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], uint16(len(hbh)))
+	ip = append(ip, hbh...)
+
+	p := new(packet.IPv6)
+	if err := p.Unmarshal(ip); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.HopByHop) != 1 || p.HopByHop[0].Type != 0x3E || len(p.HopByHop[0].Data) != 255 {
+		t.Errorf("unexpected options %+v", p.HopByHop)
+	}
+
+	// An option which exceeds the header is an error
+	ip[40+4+255+1] = 255
+	if err := p.Unmarshal(ip); err == nil {
+		t.Error("invalid option length decoded without error")
+	}
+}
+
+func TestIPv6Truncated(t *testing.T) {
+	ip := make([]byte, 40, 40+8+12)
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], 8+12)
+	ip[6] = 17
+	ip = append(ip, 0x30, 0x39, 0, 53, 0, 20, 0, 0)
+	ip = append(ip, "hello, world"...)
+
+	// Captured with a snapshot length which cuts the UDP payload
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x86, 0xDD}, ip[:40+8+4]...)
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v6, ok := p.Network.(*packet.IPv6)
+	if !ok || !v6.Truncated || v6.PayloadLen != 20 || len(v6.Payload) != 12 {
+		t.Fatalf("unexpected network layer %+v", p.Network)
+	}
+	if u, ok := p.Transport.(*packet.UDP); !ok || !u.Truncated || string(u.Payload) != "hell" {
+		t.Errorf("unexpected transport layer %+v", p.Transport)
+	}
+}
@@ -0,0 +1,98 @@
+package packet_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		frame string
+		want  string
+	}{
+		{
+			name: "tcp",
+			frame: "02000000000b02000000000a8100206408004500002800004000400600000a0102030101" +
+				"0101c35001bb000000010000000050120400000000000000",
+			want: `{"link":{"type":"LayerTypeEthernet","destination":"02:00:00:00:00:0b",` +
+				`"source":"02:00:00:00:00:0a","vlans":[{"tpid":"EthernetTypeDot1Q","priority":1,` +
+				`"drop_eligible":false,"id":100}],"ethernet_type":"EthernetTypeIPv4","length":60},` +
+				`"network":{"type":"LayerTypeIPv4","ihl":5,"dscp":0,"ecn":0,"total_len":40,"id":0,` +
+				`"flags":2,"frag_offset":0,"hops":64,"proto":"IPProtocolTCP","header_checksum":0,` +
+				`"source":"10.1.2.3","destination":"1.1.1.1","options":[],"length":40},` +
+				`"transport":{"type":"LayerTypeTCP","source_port":50000,"destination_port":443,` +
+				`"seq":1,"ack":0,"data_offset":5,"flags":["SYN","ACK"],"window":1024,"checksum":0,` +
+				`"urgent":0,"length":20}}`,
+		},
+		{
+			name: "arp",
+			frame: "ffffffffffff02000000000a0806000108000604000102000000000ac0a80002000000000000" +
+				"c0a80001",
+			want: `{"link":{"type":"LayerTypeEthernet","destination":"ff:ff:ff:ff:ff:ff",` +
+				`"source":"02:00:00:00:00:0a","vlans":[],"ethernet_type":"EthernetTypeARP","length":42},` +
+				`"network":{"type":"LayerTypeARP","htype":"ARPTypeEther","ptype":"EthernetTypeIPv4",` +
+				`"hlen":6,"plen":4,"oper":"ARPOPCodeRequest","source_hw":"02:00:00:00:00:0a",` +
+				`"source_ip":"192.168.0.2","dest_hw":"00:00:00:00:00:00","dest_ip":"192.168.0.1",` +
+				`"length":28}}`,
+		},
+		{
+			name: "snap",
+			frame: "ffffffffffff02000000000a0024aaaa030000000806000108000604000102000000000ac0a8" +
+				"0002000000000000c0a80001",
+			want: `{"link":{"type":"LayerTypeEthernet","destination":"ff:ff:ff:ff:ff:ff",` +
+				`"source":"02:00:00:00:00:0a","vlans":[],"ethernet_type":"EthernetTypeLLC",` +
+				`"frame_length":36,"length":50},"llc":{"type":"LayerTypeLLC","dsap":"LLCSAPSNAP",` +
+				`"group":false,"ssap":"LLCSAPSNAP","response":false,"control":3,"length":36},` +
+				`"snap":{"type":"LayerTypeSNAP","oui":"000000","protocol_id":2054,"length":33},` +
+				`"network":{"type":"LayerTypeARP","htype":"ARPTypeEther","ptype":"EthernetTypeIPv4",` +
+				`"hlen":6,"plen":4,"oper":"ARPOPCodeRequest","source_hw":"02:00:00:00:00:0a",` +
+				`"source_ip":"192.168.0.2","dest_hw":"00:00:00:00:00:00","dest_ip":"192.168.0.1",` +
+				`"length":28}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tc.frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := packet.Decode(b)
+			if err != nil {
+				t.Fatalf("decode failed, %v", err)
+			}
+			got, err := json.Marshal(p)
+			if err != nil {
+				t.Fatalf("marshal failed, %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("unexpected json\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+// opaqueLayer is a layer which does not implement json.Marshaler.
+type opaqueLayer struct {
+	packet.PacketBytes
+}
+
+func (opaqueLayer) Type() packet.LayerType { return packet.LayerTypeRTP }
+
+func (l opaqueLayer) GetContents() []byte { return l.Contents }
+
+func (l opaqueLayer) GetPayload() []byte { return l.Payload }
+
+func TestMarshalJSONGeneric(t *testing.T) {
+	var p packet.Packet
+	p.Application = opaqueLayer{packet.PacketBytes{Contents: []byte{1, 2, 3}}}
+	got, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("marshal failed, %v", err)
+	}
+	if want := `{"application":{"type":"LayerTypeRTP","length":3}}`; string(got) != want {
+		t.Errorf("unexpected json\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -5,10 +5,43 @@ package packet
 type LayerType uint8
 
 const (
-	LayerTypeUnknown  LayerType = 0
-	LayerTypeEthernet LayerType = 1
-	LayerTypeIPv4     LayerType = 2
-	LayerTypeARP      LayerType = 3
+	LayerTypeUnknown    LayerType = 0
+	LayerTypeEthernet   LayerType = 1
+	LayerTypeIPv4       LayerType = 2
+	LayerTypeARP        LayerType = 3
+	LayerTypeTCP        LayerType = 4
+	LayerTypeUDP        LayerType = 5
+	LayerTypeIPv6       LayerType = 6
+	LayerTypeICMPv6     LayerType = 7
+	LayerTypeIGMP       LayerType = 8
+	LayerTypeMLD        LayerType = 9
+	LayerTypeSCTP       LayerType = 10
+	LayerTypeTLS        LayerType = 11
+	LayerTypeQUIC       LayerType = 12
+	LayerTypeICMP       LayerType = 13
+	LayerTypeLLC        LayerType = 14
+	LayerTypeSNAP       LayerType = 15
+	LayerTypeBPDU       LayerType = 16
+	LayerTypeNTP        LayerType = 17
+	LayerTypePTP        LayerType = 18
+	LayerTypePPPoE      LayerType = 19
+	LayerTypePPP        LayerType = 20
+	LayerTypePPPControl LayerType = 21
+	LayerTypePAP        LayerType = 22
+	LayerTypeCHAP       LayerType = 23
+	LayerTypeRadiotap   LayerType = 24
+	LayerTypeDot11      LayerType = 25
+	LayerTypeDot11Mgmt  LayerType = 26
+	LayerTypeAH         LayerType = 27
+	LayerTypeESP        LayerType = 28
+	LayerTypeBGP        LayerType = 29
+	LayerTypeOSPF       LayerType = 30
+	LayerTypeRTP        LayerType = 31
+	LayerTypeRTCP       LayerType = 32
+	LayerTypeSNMP       LayerType = 33
+	LayerTypeGTPU       LayerType = 34
+	LayerTypeGTPv2      LayerType = 35
+	LayerTypeMACsec     LayerType = 36
 )
 
 // Layer contains a decoded layer instance, such as an Ethernet frame, or an IP
@@ -0,0 +1,150 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(LLC)
+	_ Layer          = new(SNAP)
+	_ json.Marshaler = new(LLC)
+	_ json.Marshaler = new(SNAP)
+)
+
+// LLCSAP is an IEEE 802.2 service access point, identifying the protocol of
+// LLC frames.
+type LLCSAP uint8
+
+const (
+	LLCSAPNull    LLCSAP = 0x00
+	LLCSAPSTP     LLCSAP = 0x42
+	LLCSAPSNAP    LLCSAP = 0xAA
+	LLCSAPIPX     LLCSAP = 0xE0
+	LLCSAPNetBIOS LLCSAP = 0xF0
+	LLCSAPGlobal  LLCSAP = 0xFE
+)
+
+// LLC is an IEEE 802.2 Logical Link Control header, which follows the header
+// of 802.3 length frames.
+type LLC struct {
+	DSAP LLCSAP
+	// Group is set if the DSAP is a group address.
+	Group bool
+	SSAP  LLCSAP
+	// Response is set for response frames.
+	Response bool
+	// Control is one byte for unnumbered (U-format) frames, and two bytes for
+	// information and supervisory frames.
+	Control uint16
+	PacketBytes
+}
+
+func (l *LLC) Unmarshal(data []byte) error {
+	if len(data) < 3 {
+		return fmt.Errorf("llc header too small: %w", ErrTruncated)
+	}
+	l.DSAP = LLCSAP(data[0] &^ 0x01)
+	l.Group = data[0]&0x01 != 0
+	l.SSAP = LLCSAP(data[1] &^ 0x01)
+	l.Response = data[1]&0x01 != 0
+	hdrLen := 3
+	if data[2]&0x03 == 0x03 {
+		l.Control = uint16(data[2])
+	} else {
+		if len(data) < 4 {
+			return fmt.Errorf("llc header too small: %w", ErrTruncated)
+		}
+		l.Control = binary.BigEndian.Uint16(data[2:4])
+		hdrLen = 4
+	}
+	l.Contents = data
+	l.Payload = data[hdrLen:]
+	return nil
+}
+
+func (l LLC) Type() LayerType {
+	return LayerTypeLLC
+}
+
+func (l LLC) GetContents() []byte {
+	return l.Contents
+}
+
+func (l LLC) GetPayload() []byte {
+	return l.Payload
+}
+
+func (l LLC) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string `json:"type"`
+		DSAP     string `json:"dsap"`
+		Group    bool   `json:"group"`
+		SSAP     string `json:"ssap"`
+		Response bool   `json:"response"`
+		Control  uint16 `json:"control"`
+		Length   int    `json:"length"`
+	}{
+		Type:     l.Type().String(),
+		DSAP:     l.DSAP.String(),
+		Group:    l.Group,
+		SSAP:     l.SSAP.String(),
+		Response: l.Response,
+		Control:  l.Control,
+		Length:   len(l.Contents),
+	})
+}
+
+// SNAP is a Subnetwork Access Protocol header, carried in LLC frames with the
+// SNAP SAP. If the OUI is zero, the protocol ID is an EtherType (RFC 1042).
+type SNAP struct {
+	OUI        [3]byte
+	ProtocolID uint16
+	PacketBytes
+}
+
+func (s *SNAP) Unmarshal(data []byte) error {
+	if len(data) < 5 {
+		return fmt.Errorf("snap header too small: %w", ErrTruncated)
+	}
+	copy(s.OUI[:], data[0:3])
+	s.ProtocolID = binary.BigEndian.Uint16(data[3:5])
+	s.Contents = data
+	s.Payload = data[5:]
+	return nil
+}
+
+// EtherType returns the EtherType of the payload, and whether the protocol ID
+// is an EtherType.
+func (s SNAP) EtherType() (EtherType, bool) {
+	return EtherType(s.ProtocolID), s.OUI == [3]byte{}
+}
+
+func (s SNAP) Type() LayerType {
+	return LayerTypeSNAP
+}
+
+func (s SNAP) GetContents() []byte {
+	return s.Contents
+}
+
+func (s SNAP) GetPayload() []byte {
+	return s.Payload
+}
+
+func (s SNAP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"type"`
+		OUI        string `json:"oui"`
+		ProtocolID uint16 `json:"protocol_id"`
+		Length     int    `json:"length"`
+	}{
+		Type:       s.Type().String(),
+		OUI:        hex.EncodeToString(s.OUI[:]),
+		ProtocolID: s.ProtocolID,
+		Length:     len(s.Contents),
+	})
+}
@@ -0,0 +1,303 @@
+package packet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(MACsec)
+	_ json.Marshaler = new(MACsec)
+)
+
+// macsecICVLen is the ICV length of the GCM-AES cipher suites.
+const macsecICVLen = 16
+
+// MACsec is an IEEE 802.1AE MACsec frame, which follows the MAC addresses of
+// the protected frame. Until the frame is decrypted, the payload of encrypted
+// frames is the secure data between the SecTAG and the ICV. The payload of
+// frames which are only integrity protected is the user data following its
+// EtherType.
+type MACsec struct {
+	// The TCI flags of the SecTAG. HasSCI is the SC flag, which is set if the
+	// SecTAG carries the SCI.
+	EndStation          bool
+	HasSCI              bool
+	SingleCopyBroadcast bool
+	Encrypted           bool
+	Changed             bool
+	// AN is the association number of the secure association.
+	AN uint8
+	// ShortLength is the length of the secure data of frames with less than
+	// 48 octets of secure data, and zero otherwise.
+	ShortLength  uint8
+	PacketNumber uint32
+	// SCI is the secure channel identifier. If the SecTAG does not carry it,
+	// it is derived from the source address when decoded from a frame.
+	SCI uint64
+	ICV []byte
+
+	// AuthFailed is set if the ICV could not be verified with the key of the
+	// secure association when the frame was decoded.
+	AuthFailed bool
+
+	// Decrypted is set if the ICV was verified with the key of a secure
+	// association. EtherType and Payload then contain the plaintext user
+	// data of encrypted frames.
+	Decrypted bool
+	EtherType EtherType
+	PacketBytes
+}
+
+func (m *MACsec) Unmarshal(data []byte) error {
+	if len(data) < 6 {
+		return fmt.Errorf("macsec sectag too small: %w", ErrTruncated)
+	}
+	*m = MACsec{}
+	tci := data[0]
+	if tci&0x80 != 0 {
+		return fmt.Errorf("macsec sectag must be version 0: %w", ErrUnsupported)
+	}
+	m.EndStation = tci&0x40 != 0
+	m.HasSCI = tci&0x20 != 0
+	m.SingleCopyBroadcast = tci&0x10 != 0
+	m.Encrypted = tci&0x08 != 0
+	m.Changed = tci&0x04 != 0
+	m.AN = tci & 0x03
+	m.ShortLength = data[1] & 0x3F
+	m.PacketNumber = binary.BigEndian.Uint32(data[2:6])
+	n := m.headerLen()
+	if n > len(data) {
+		return fmt.Errorf("macsec sci exceeds frame: %w", ErrTruncated)
+	}
+	if m.HasSCI {
+		m.SCI = binary.BigEndian.Uint64(data[6:14])
+	}
+	end := len(data) - macsecICVLen
+	if m.ShortLength != 0 {
+		// Frames with a short length may be padded after the ICV
+		end = n + int(m.ShortLength)
+		if end+macsecICVLen > len(data) {
+			return fmt.Errorf("macsec short length exceeds frame: %w", ErrTruncated)
+		}
+	}
+	if end < n {
+		return fmt.Errorf("macsec frame too small: %w", ErrTruncated)
+	}
+	m.ICV = data[end : end+macsecICVLen]
+	m.Contents = data[:end+macsecICVLen]
+	m.Payload = data[n:end]
+	if !m.Encrypted && len(m.Payload) >= 2 {
+		m.EtherType = EtherType(binary.BigEndian.Uint16(m.Payload[0:2]))
+		m.Payload = m.Payload[2:]
+	}
+	return nil
+}
+
+func (m MACsec) headerLen() int {
+	if m.HasSCI {
+		return 14
+	}
+	return 6
+}
+
+// Decrypt verifies the ICV of the frame with the key of sa, and decrypts the
+// user data of encrypted frames. dst and src are the addresses of the frame,
+// which are authenticated along with the SecTAG. The plaintext is written to
+// a new buffer, so the contents of the frame are not modified. Extended packet
+// numbers and confidentiality offsets are not supported.
+func (m *MACsec) Decrypt(sa MACsecSA, dst, src net.HardwareAddr) error {
+	if err := sa.validate(); err != nil {
+		return err
+	}
+	if len(dst) != 6 || len(src) != 6 {
+		return fmt.Errorf("invalid macsec frame addresses: %w", ErrInvalid)
+	}
+	n := m.headerLen()
+	if len(m.Contents) < n+macsecICVLen {
+		return fmt.Errorf("macsec frame too small: %w", ErrTruncated)
+	}
+	block, _ := aes.NewCipher(sa.Key)
+	aead, _ := cipher.NewGCM(block)
+	var nonce [12]byte
+	binary.BigEndian.PutUint64(nonce[0:8], m.SCI)
+	binary.BigEndian.PutUint32(nonce[8:12], m.PacketNumber)
+	aad := make([]byte, 0, 14+len(m.Contents))
+	aad = append(append(aad, dst...), src...)
+	aad = append(aad, 0x88, 0xE5)
+	aad = append(aad, m.Contents[:n]...)
+
+	plain := m.Contents[n : len(m.Contents)-macsecICVLen]
+	if m.Encrypted {
+		var err error
+		plain, err = aead.Open(nil, nonce[:], m.Contents[n:], aad)
+		if err != nil {
+			return fmt.Errorf("macsec authentication failed: %w", ErrAuthFailed)
+		}
+	} else {
+		// Integrity only, where the user data is authenticated
+		aad = append(aad, plain...)
+		if _, err := aead.Open(nil, nonce[:], m.ICV, aad); err != nil {
+			return fmt.Errorf("macsec authentication failed: %w", ErrAuthFailed)
+		}
+	}
+	if len(plain) < 2 {
+		return fmt.Errorf("macsec user data too small: %w", ErrTruncated)
+	}
+	m.EtherType = EtherType(binary.BigEndian.Uint16(plain[0:2]))
+	m.Payload = plain[2:]
+	m.Decrypted = true
+	return nil
+}
+
+func (m MACsec) Type() LayerType {
+	return LayerTypeMACsec
+}
+
+func (m MACsec) GetContents() []byte {
+	return m.Contents
+}
+
+func (m MACsec) GetPayload() []byte {
+	return m.Payload
+}
+
+func (m MACsec) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type                string `json:"type"`
+		EndStation          bool   `json:"end_station"`
+		SingleCopyBroadcast bool   `json:"single_copy_broadcast"`
+		Encrypted           bool   `json:"encrypted"`
+		Changed             bool   `json:"changed"`
+		AN                  uint8  `json:"an"`
+		ShortLength         uint8  `json:"short_length"`
+		PacketNumber        uint32 `json:"packet_number"`
+		SCI                 string `json:"sci,omitempty"`
+		ICV                 string `json:"icv"`
+		AuthFailed          bool   `json:"auth_failed"`
+		Decrypted           bool   `json:"decrypted"`
+		EtherType           string `json:"ether_type,omitempty"`
+		Length              int    `json:"length"`
+	}{
+		Type:                m.Type().String(),
+		EndStation:          m.EndStation,
+		SingleCopyBroadcast: m.SingleCopyBroadcast,
+		Encrypted:           m.Encrypted,
+		Changed:             m.Changed,
+		AN:                  m.AN,
+		ShortLength:         m.ShortLength,
+		PacketNumber:        m.PacketNumber,
+		ICV:                 hex.EncodeToString(m.ICV),
+		AuthFailed:          m.AuthFailed,
+		Decrypted:           m.Decrypted,
+		Length:              len(m.Contents),
+	}
+	if m.SCI != 0 {
+		v.SCI = fmt.Sprintf("%016x", m.SCI)
+	}
+	if !m.Encrypted || m.Decrypted {
+		v.EtherType = m.EtherType.String()
+	}
+	return json.Marshal(v)
+}
+
+// MACsecSCI returns the SCI of a port of the system with the MAC address.
+// Systems which omit the SCI from the SecTAG use port 1.
+func MACsecSCI(addr net.HardwareAddr, port uint16) uint64 {
+	var b [8]byte
+	copy(b[:6], addr)
+	binary.BigEndian.PutUint16(b[6:8], port)
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// MACsecSA contains the key of a MACsec receive secure association, which is
+// identified by the SCI of the transmitting secure channel and the
+// association number.
+type MACsecSA struct {
+	SCI uint64
+	AN  uint8
+
+	// Key is the secure association key (SAK) of GCM-AES-128 or GCM-AES-256.
+	Key []byte
+}
+
+func (sa MACsecSA) validate() error {
+	if n := len(sa.Key); n != 16 && n != 32 {
+		return fmt.Errorf("invalid macsec key length %d", n)
+	}
+	if sa.AN > 3 {
+		return fmt.Errorf("invalid macsec association number %d", sa.AN)
+	}
+	return nil
+}
+
+type macsecSAKey struct {
+	sci uint64
+	an  uint8
+}
+
+// AddMACsecSA adds the key of a secure association, so that decoded MACsec
+// frames of its secure channel and association number are verified and
+// decrypted, and their user data decoded. A previously added association with
+// the same SCI and association number is replaced.
+func (d *Decoder) AddMACsecSA(sa MACsecSA) error {
+	if err := sa.validate(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.macsecSAs == nil {
+		d.macsecSAs = make(map[macsecSAKey]MACsecSA)
+	}
+	d.macsecSAs[macsecSAKey{sa.SCI, sa.AN}] = sa
+	return nil
+}
+
+// RemoveMACsecSA removes the secure association with the provided SCI and
+// association number.
+func (d *Decoder) RemoveMACsecSA(sci uint64, an uint8) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.macsecSAs, macsecSAKey{sci, an})
+}
+
+func (d *Decoder) lookupMACsecSA(sci uint64, an uint8) (MACsecSA, bool) {
+	if d == nil {
+		return MACsecSA{}, false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	sa, ok := d.macsecSAs[macsecSAKey{sci, an}]
+	return sa, ok
+}
+
+// decodeMACsec decodes a MACsec frame. If the decoder has a secure association
+// for the frame, it is verified and decrypted. The user data of decrypted and
+// integrity protected frames is decoded into the layers that follow, unless
+// the frame failed verification.
+func (p *Packet) decodeMACsec(b []byte) error {
+	m := new(MACsec)
+	if err := m.Unmarshal(b); err != nil {
+		return err
+	}
+	p.MACsec = m
+	if p.Link != nil {
+		if !m.HasSCI {
+			m.SCI = MACsecSCI(p.Link.Source, 1)
+		}
+		if sa, ok := p.dec.lookupMACsecSA(m.SCI, m.AN); ok &&
+			m.Decrypt(sa, p.Link.Destination, p.Link.Source) != nil {
+			m.AuthFailed = true
+		}
+	}
+	if m.AuthFailed || m.Encrypted && !m.Decrypted {
+		return nil
+	}
+	return p.decodeEtherType(m.EtherType, m.Payload)
+}
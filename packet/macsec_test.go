@@ -0,0 +1,152 @@
+package packet_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+var (
+	macsecDst = net.HardwareAddr{0x02, 0, 0, 0, 0, 0x02}
+	macsecSrc = net.HardwareAddr{0x02, 0, 0, 0, 0, 0x01}
+)
+
+// macsecFrame protects the user data of a frame from macsecSrc to macsecDst
+// with GCM-AES. The SCI is only carried in the SecTAG if the SC flag of tci is
+// set.
+func macsecFrame(key []byte, tci byte, pn uint32, sci uint64, user []byte) []byte {
+	frame := append(append([]byte{}, macsecDst...), macsecSrc...)
+	frame = append(frame, 0x88, 0xE5, tci, 0, 0, 0, 0, 0)
+	if len(user) < 48 {
+		frame[15] = byte(len(user))
+	}
+	binary.BigEndian.PutUint32(frame[16:20], pn)
+	var nonce [12]byte
+	binary.BigEndian.PutUint64(nonce[0:8], sci)
+	binary.BigEndian.PutUint32(nonce[8:12], pn)
+	if tci&0x20 != 0 {
+		frame = append(frame, nonce[0:8]...)
+	}
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	if tci&0x08 != 0 {
+		return aead.Seal(frame, nonce[:], user, append([]byte{}, frame...))
+	}
+	frame = append(frame, user...)
+	return aead.Seal(frame, nonce[:], nil, append([]byte{}, frame...))
+}
+
+func TestMACsecEncrypted(t *testing.T) {
+	sa := packet.MACsecSA{
+		SCI: packet.MACsecSCI(net.HardwareAddr{0x02, 0, 0, 0, 0, 0x10}, 7),
+		AN:  2,
+		Key: bytes.Repeat([]byte{0x42}, 32),
+	}
+	var d packet.Decoder
+	if err := d.AddMACsecSA(sa); err != nil {
+		t.Fatal(err)
+	}
+
+	ip := ipv4Packet(netip.MustParseAddr("192.0.2.1"), netip.MustParseAddr("192.0.2.2"), 17,
+		[]byte{0x30, 0x39, 0, 9, 0, 12, 0, 0, 'p', 'i', 'n', 'g'}, 6)
+	user := append([]byte{0x08, 0x00}, ip...)
+	// SC, E and C flags, with association number 2
+	frame := macsecFrame(sa.Key, 0x2E, 1000, sa.SCI, user)
+
+	p, err := d.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := p.MACsec
+	if m == nil || !m.Encrypted || !m.Changed || !m.HasSCI || m.AN != 2 || m.PacketNumber != 1000 ||
+		m.SCI != 0x020000000010_0007 || m.ShortLength != 34 || len(m.ICV) != 16 {
+		t.Fatalf("unexpected sectag %+v", m)
+	}
+	if !m.Decrypted || m.EtherType != packet.EthernetTypeIPv4 {
+		t.Fatalf("frame was not decrypted, %+v", m)
+	}
+	u, ok := p.Transport.(*packet.UDP)
+	if !ok || u.DestinationPort != 9 || string(u.Payload) != "ping" {
+		t.Fatalf("unexpected transport %+v", p.Transport)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"sci":"0200000000100007","icv":`) ||
+		!strings.Contains(string(data), `"decrypted":true,"ether_type":"EthernetTypeIPv4"`) {
+		t.Errorf("unexpected json %s", data)
+	}
+
+	// Tampered frames fail authentication, which is reported on the MACsec
+	// layer
+	tampered := append([]byte{}, frame...)
+	tampered[30] ^= 1
+	p, err = d.Decode(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Link == nil || p.MACsec == nil || !p.MACsec.AuthFailed || p.MACsec.Decrypted || p.Network != nil {
+		t.Errorf("unexpected tampered frame %+v", p.MACsec)
+	}
+
+	// Without the key, only the SecTAG is decoded
+	d.RemoveMACsecSA(sa.SCI, sa.AN)
+	p, err = d.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MACsec == nil || p.MACsec.Decrypted || p.MACsec.AuthFailed || p.Network != nil ||
+		len(p.MACsec.Payload) != len(user) {
+		t.Errorf("unexpected encrypted frame %+v", p)
+	}
+}
+
+func TestMACsecIntegrityOnly(t *testing.T) {
+	arp := []byte{0, 1, 0x08, 0x00, 6, 4, 0, 1}
+	arp = append(append(arp, macsecSrc...), 192, 0, 2, 1)
+	arp = append(append(arp, make([]byte, 6)...), 192, 0, 2, 2)
+	user := append([]byte{0x08, 0x06}, arp...)
+	// End station without an SCI, and with a short length and padding
+	key := bytes.Repeat([]byte{0x17}, 16)
+	sci := packet.MACsecSCI(macsecSrc, 1)
+	frame := macsecFrame(key, 0x40, 5, sci, user)
+	frame = append(frame, make([]byte, 10)...)
+
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := p.MACsec
+	if m == nil || m.Encrypted || m.HasSCI || !m.EndStation || m.ShortLength != 30 || m.SCI != sci || m.Decrypted {
+		t.Fatalf("unexpected sectag %+v", m)
+	}
+	if len(m.Contents) != 6+30+16 {
+		t.Errorf("got %d bytes, want padding to be stripped", len(m.Contents))
+	}
+	if a, ok := p.Network.(*packet.ARP); !ok || a.DestIP != netip.MustParseAddr("192.0.2.2") {
+		t.Fatalf("unexpected network layer %+v", p.Network)
+	}
+
+	// The ICV is verified once the key is known
+	var d packet.Decoder
+	if err := d.AddMACsecSA(packet.MACsecSA{SCI: sci, Key: key}); err != nil {
+		t.Fatal(err)
+	}
+	if p, err = d.Decode(frame); err != nil || !p.MACsec.Decrypted || p.Network == nil {
+		t.Errorf("verification failed, %v", err)
+	}
+	// The user data of frames which fail verification is not decoded
+	frame[20] ^= 1
+	if p, err = d.Decode(frame); err != nil || !p.MACsec.AuthFailed || p.Network != nil {
+		t.Errorf("unexpected tampered frame %+v, %v", p.MACsec, err)
+	}
+}
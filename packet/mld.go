@@ -0,0 +1,187 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(MLD)
+	_ json.Marshaler = new(MLD)
+)
+
+// MLD is a Multicast Listener Discovery message of version 1 (RFC 2710) or 2
+// (RFC 3810), carried over ICMPv6.
+type MLD struct {
+	Version          uint8
+	ICMPType         ICMPv6Type
+	Code             uint8
+	Checksum         uint16
+	MaxResponseDelay time.Duration
+	MulticastAddress netip.Addr
+
+	// MLDv2 query fields
+	SuppressRouterProcessing bool
+	RobustnessValue          uint8
+	QueryInterval            time.Duration
+	Sources                  []netip.Addr
+
+	// MLDv2 report fields
+	GroupRecords []GroupRecord
+
+	PacketBytes
+}
+
+func (m *MLD) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("mld message too small: %w", ErrTruncated)
+	}
+	m.ICMPType = ICMPv6Type(data[0])
+	m.Code = data[1]
+	m.Checksum = binary.BigEndian.Uint16(data[2:4])
+	m.MaxResponseDelay = 0
+	m.MulticastAddress = netip.Addr{}
+	m.SuppressRouterProcessing = false
+	m.RobustnessValue = 0
+	m.QueryInterval = 0
+	m.Sources = nil
+	m.GroupRecords = nil
+	m.Contents = data
+	m.Payload = nil
+
+	switch m.ICMPType {
+	case ICMPv6TypeMLDv2Report:
+		m.Version = 2
+		var err error
+		m.GroupRecords, err = parseGroupRecords(data[8:], int(binary.BigEndian.Uint16(data[6:8])), 16)
+		return err
+	case ICMPv6TypeMLDQuery, ICMPv6TypeMLDv1Report, ICMPv6TypeMLDv1Done:
+	default:
+		return fmt.Errorf("icmpv6 type %d is not an mld message: %w", data[0], ErrInvalid)
+	}
+
+	if len(data) < 24 {
+		return fmt.Errorf("mld message too small: %w", ErrTruncated)
+	}
+	code := uint32(binary.BigEndian.Uint16(data[4:6]))
+	m.MulticastAddress = netip.AddrFrom16(*(*[16]byte)(data[8:24]))
+	if m.ICMPType != ICMPv6TypeMLDQuery || len(data) < 28 {
+		m.Version = 1
+		m.MaxResponseDelay = time.Duration(code) * time.Millisecond
+		return nil
+	}
+	m.Version = 2
+	m.MaxResponseDelay = time.Duration(decodeExpCode(code, 12)) * time.Millisecond
+	m.SuppressRouterProcessing = data[24]&0x08 != 0
+	m.RobustnessValue = data[24] & 0x07
+	m.QueryInterval = time.Duration(decodeExpCode(uint32(data[25]), 4)) * time.Second
+	var err error
+	m.Sources, _, err = parseAddrs(data[28:], int(binary.BigEndian.Uint16(data[26:28])), 16)
+	return err
+}
+
+// Marshal encodes the message according to its type and version, and fills
+// in the checksum. The checksum of ICMPv6 messages covers the IPv6
+// pseudo-header, hence the source and destination addresses of the enclosing
+// packet are required.
+func (m MLD) Marshal(src, dst netip.Addr) ([]byte, error) {
+	var b []byte
+	switch {
+	case m.ICMPType == ICMPv6TypeMLDv2Report:
+		b = make([]byte, 8)
+		binary.BigEndian.PutUint16(b[6:8], uint16(len(m.GroupRecords)))
+		var err error
+		b, err = appendGroupRecords(b, m.GroupRecords, 16)
+		if err != nil {
+			return nil, err
+		}
+	case m.ICMPType == ICMPv6TypeMLDQuery && m.Version == 2:
+		b = make([]byte, 28, 28+16*len(m.Sources))
+		code := encodeExpCode(uint32(m.MaxResponseDelay.Milliseconds()), 12)
+		binary.BigEndian.PutUint16(b[4:6], uint16(code))
+		if m.SuppressRouterProcessing {
+			b[24] |= 0x08
+		}
+		b[24] |= m.RobustnessValue & 0x07
+		b[25] = uint8(encodeExpCode(uint32(m.QueryInterval/time.Second), 4))
+		binary.BigEndian.PutUint16(b[26:28], uint16(len(m.Sources)))
+		var err error
+		b, err = appendAddrs(b, m.Sources, 16)
+		if err != nil {
+			return nil, err
+		}
+	case m.ICMPType == ICMPv6TypeMLDQuery || m.ICMPType == ICMPv6TypeMLDv1Report ||
+		m.ICMPType == ICMPv6TypeMLDv1Done:
+		b = make([]byte, 24)
+		delay := m.MaxResponseDelay.Milliseconds()
+		if delay > 0xFFFF {
+			delay = 0xFFFF
+		}
+		binary.BigEndian.PutUint16(b[4:6], uint16(delay))
+	default:
+		return nil, fmt.Errorf("icmpv6 type %d is not an mld message", m.ICMPType)
+	}
+	b[0] = uint8(m.ICMPType)
+	b[1] = m.Code
+	if m.ICMPType != ICMPv6TypeMLDv2Report && m.MulticastAddress.IsValid() {
+		if !m.MulticastAddress.Is6() {
+			return nil, errors.New("mld multicast address must be IPv6")
+		}
+		copy(b[8:24], m.MulticastAddress.AsSlice())
+	}
+	if !src.Is6() || !dst.Is6() {
+		return nil, errors.New("mld pseudo-header addresses must be IPv6")
+	}
+	sum := pseudoHeaderSum(src, dst, IPProtocolICMPv6, len(b))
+	binary.BigEndian.PutUint16(b[2:4], checksum(b, sum))
+	return b, nil
+}
+
+func (m MLD) Type() LayerType {
+	return LayerTypeMLD
+}
+
+func (m MLD) GetContents() []byte {
+	return m.Contents
+}
+
+func (m MLD) GetPayload() []byte {
+	return m.Payload
+}
+
+func (m MLD) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type                     string            `json:"type"`
+		Version                  uint8             `json:"version"`
+		ICMPType                 string            `json:"icmp_type"`
+		Code                     uint8             `json:"code"`
+		Checksum                 uint16            `json:"checksum"`
+		MaxResponseDelayMS       int64             `json:"max_response_delay_ms"`
+		MulticastAddress         netip.Addr        `json:"multicast_address"`
+		SuppressRouterProcessing bool              `json:"suppress_router_processing"`
+		RobustnessValue          uint8             `json:"robustness_value"`
+		QueryIntervalMS          int64             `json:"query_interval_ms"`
+		Sources                  []netip.Addr      `json:"sources"`
+		GroupRecords             []groupRecordJSON `json:"group_records"`
+		Length                   int               `json:"length"`
+	}{
+		Type:                     m.Type().String(),
+		Version:                  m.Version,
+		ICMPType:                 m.ICMPType.String(),
+		Code:                     m.Code,
+		Checksum:                 m.Checksum,
+		MaxResponseDelayMS:       m.MaxResponseDelay.Milliseconds(),
+		MulticastAddress:         m.MulticastAddress,
+		SuppressRouterProcessing: m.SuppressRouterProcessing,
+		RobustnessValue:          m.RobustnessValue,
+		QueryIntervalMS:          m.QueryInterval.Milliseconds(),
+		Sources:                  nonNilAddrs(m.Sources),
+		GroupRecords:             groupRecordsJSON(m.GroupRecords),
+		Length:                   len(m.Contents),
+	})
+}
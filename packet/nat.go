@@ -0,0 +1,350 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"net/netip"
+)
+
+// adjustChecksum updates the Internet checksum stored in field for the change
+// of the covered bytes from old to new (RFC 1624, eqn. 3). The changed bytes
+// must be 16-bit aligned within the checksummed data.
+func adjustChecksum(field, old, new []byte) uint16 {
+	sum := uint32(^binary.BigEndian.Uint16(field))
+	for i := 0; i+1 < len(old); i += 2 {
+		sum += uint32(^binary.BigEndian.Uint16(old[i:]))
+		sum += uint32(binary.BigEndian.Uint16(new[i:]))
+	}
+	for sum > 0xFFFF {
+		sum = sum&0xFFFF + sum>>16
+	}
+	res := ^uint16(sum)
+	binary.BigEndian.PutUint16(field, res)
+	return res
+}
+
+// adjustUDPChecksum is adjustChecksum for UDP, where a zero checksum means
+// that no checksum was computed, which is not allowed over IPv6.
+func adjustUDPChecksum(field, old, new []byte) uint16 {
+	if binary.BigEndian.Uint16(field) == 0 {
+		return 0
+	}
+	if res := adjustChecksum(field, old, new); res != 0 {
+		return res
+	}
+	binary.BigEndian.PutUint16(field, 0xFFFF)
+	return 0xFFFF
+}
+
+// SetSource rewrites the source address of the packet in place. The
+// checksums of the IPv4 header and the transport layer are updated
+// incrementally. For ICMP errors, the destination address of the quoted packet
+// is rewritten as well if it equals the old source address.
+//
+// The address family cannot be changed.
+func (p *Packet) SetSource(addr netip.Addr) error {
+	return p.setAddr(addr, true)
+}
+
+// SetDestination rewrites the destination address of the packet in place, as
+// described for SetSource. For ICMP errors, the source address of the quoted
+// packet is rewritten if it equals the old destination address.
+func (p *Packet) SetDestination(addr netip.Addr) error {
+	return p.setAddr(addr, false)
+}
+
+func (p *Packet) setAddr(addr netip.Addr, src bool) error {
+	new := addr.AsSlice()
+	var field []byte
+	switch ip := p.Network.(type) {
+	case *IPv4:
+		if !addr.Is4() {
+			return errors.New("address must be IPv4")
+		}
+		off := 16
+		if src {
+			off, ip.Source = 12, addr
+		} else {
+			ip.Destination = addr
+		}
+		field = ip.Contents[off : off+4]
+		ip.HeaderChecksum = adjustChecksum(ip.Contents[10:12], field, new)
+	case *IPv6:
+		if !addr.Is6() {
+			return errors.New("address must be IPv6")
+		}
+		off := 24
+		if src {
+			off, ip.Source = 8, addr
+		} else {
+			ip.Destination = addr
+		}
+		field = ip.Contents[off : off+16]
+	default:
+		return errors.New("packet has no ip layer")
+	}
+	old := append([]byte{}, field...)
+	copy(field, new)
+
+	// Adjust for the change of the pseudo-header
+	switch t := p.Transport.(type) {
+	case *TCP:
+		t.Checksum = adjustChecksum(t.Contents[16:18], old, new)
+	case *UDP:
+		t.Checksum = adjustUDPChecksum(t.Contents[6:8], old, new)
+	case *ICMPv6:
+		t.Checksum = adjustChecksum(t.Contents[2:4], old, new)
+	case *MLD:
+		t.Checksum = adjustChecksum(t.Contents[2:4], old, new)
+	case *OSPF:
+		// Only the OSPFv3 checksum covers a pseudo-header
+		if t.Version == 3 {
+			t.Checksum = adjustChecksum(t.Contents[12:14], old, new)
+		}
+	}
+
+	if msg := p.icmpError(); msg != nil {
+		q := msg[8:]
+		var off int
+		switch {
+		case len(old) == 4 && len(q) >= 20 && q[0]>>4 == 4:
+			off = 12
+			if src {
+				off = 16
+			}
+		case len(old) == 16 && len(q) >= 40 && q[0]>>4 == 6:
+			off = 8
+			if src {
+				off = 24
+			}
+		default:
+			return nil
+		}
+		if !bytes.Equal(q[off:off+len(old)], old) {
+			return nil
+		}
+		var sums []innerChecksum
+		if len(old) == 4 {
+			// The IPv4 header checksum
+			sums = append(sums, innerChecksum{8 + 10, adjustChecksum})
+		}
+		if t, proto := quotedTransport(q); t >= 0 {
+			sums = append(sums, quotedChecksums(msg, 8+t, proto)...)
+		}
+		icmpEdit(msg, 8+off, new, sums...)
+		p.refreshICMPChecksum(msg)
+	}
+	return nil
+}
+
+// SetSourcePort rewrites the source port of a TCP, UDP or SCTP packet in
+// place. The TCP and UDP checksums are updated incrementally, and the CRC32c
+// of SCTP packets is recomputed if it was valid. For ICMP errors, the
+// destination port of the quoted packet is rewritten.
+func (p *Packet) SetSourcePort(port uint16) error {
+	return p.setPort(port, true)
+}
+
+// SetDestinationPort rewrites the destination port of the packet in place, as
+// described for SetSourcePort. For ICMP errors, the source port of the quoted
+// packet is rewritten.
+func (p *Packet) SetDestinationPort(port uint16) error {
+	return p.setPort(port, false)
+}
+
+func (p *Packet) setPort(port uint16, src bool) error {
+	off := 2
+	if src {
+		off = 0
+	}
+	var new [2]byte
+	binary.BigEndian.PutUint16(new[:], port)
+
+	switch t := p.Transport.(type) {
+	case *TCP:
+		t.Checksum = adjustChecksum(t.Contents[16:18], t.Contents[off:off+2], new[:])
+		copy(t.Contents[off:], new[:])
+		if src {
+			t.SourcePort = port
+		} else {
+			t.DestinationPort = port
+		}
+		return nil
+	case *UDP:
+		t.Checksum = adjustUDPChecksum(t.Contents[6:8], t.Contents[off:off+2], new[:])
+		copy(t.Contents[off:], new[:])
+		if src {
+			t.SourcePort = port
+		} else {
+			t.DestinationPort = port
+		}
+		return nil
+	case *SCTP:
+		valid := t.VerifyChecksum()
+		copy(t.Contents[off:], new[:])
+		if src {
+			t.SourcePort = port
+		} else {
+			t.DestinationPort = port
+		}
+		if valid {
+			crc := crc32.Update(0, castagnoli, t.Contents[:8])
+			crc = crc32.Update(crc, castagnoli, []byte{0, 0, 0, 0})
+			crc = crc32.Update(crc, castagnoli, t.Contents[12:])
+			t.Checksum = crc
+			binary.LittleEndian.PutUint32(t.Contents[8:12], crc)
+		}
+		return nil
+	}
+
+	msg := p.icmpError()
+	if msg == nil {
+		return errors.New("packet has no ports")
+	}
+	t, proto := quotedTransport(msg[8:])
+	if t < 0 || len(msg) < 8+t+4 {
+		return errors.New("icmp error does not quote ports")
+	}
+	// The quoted packet travelled in the opposite direction
+	off = 2 - off
+	icmpEdit(msg, 8+t+off, new[:], quotedChecksums(msg, 8+t, proto)...)
+	p.refreshICMPChecksum(msg)
+	return nil
+}
+
+// SetEchoID rewrites the identifier of an ICMP or ICMPv6 echo request or reply
+// in place. The checksum is updated incrementally.
+func (p *Packet) SetEchoID(id uint16) error {
+	if _, ok := p.EchoFlow(); !ok {
+		return errors.New("packet is not an echo message")
+	}
+	var new [2]byte
+	binary.BigEndian.PutUint16(new[:], id)
+	switch t := p.Transport.(type) {
+	case *ICMP:
+		t.Checksum = adjustChecksum(t.Contents[2:4], t.Contents[4:6], new[:])
+		copy(t.Data[0:2], new[:])
+		copy(t.Contents[4:6], new[:])
+	case *ICMPv6:
+		t.Checksum = adjustChecksum(t.Contents[2:4], t.Contents[4:6], new[:])
+		copy(t.Contents[4:6], new[:])
+	}
+	return nil
+}
+
+// icmpError returns the ICMP or ICMPv6 message if the packet is an error
+// message which quotes another packet.
+func (p *Packet) icmpError() []byte {
+	switch t := p.Transport.(type) {
+	case *ICMP:
+		if t.ICMPType.IsError() {
+			return t.Contents
+		}
+	case *ICMPv6:
+		if t.ICMPType.IsError() && len(t.Contents) >= 8 {
+			return t.Contents
+		}
+	}
+	return nil
+}
+
+func (p *Packet) refreshICMPChecksum(msg []byte) {
+	sum := binary.BigEndian.Uint16(msg[2:4])
+	switch t := p.Transport.(type) {
+	case *ICMP:
+		t.Checksum = sum
+	case *ICMPv6:
+		t.Checksum = sum
+	}
+}
+
+// quotedTransport returns the offset and protocol of the transport header of
+// a quoted packet, or -1 if it does not start with a TCP, UDP or SCTP header.
+func quotedTransport(q []byte) (int, IPProtocol) {
+	var off int
+	var proto IPProtocol
+	switch {
+	case len(q) >= 20 && q[0]>>4 == 4:
+		// Only the first fragment carries the transport header
+		if binary.BigEndian.Uint16(q[6:8])&0x1FFF != 0 {
+			return -1, 0
+		}
+		off, proto = int(q[0]&0x0F)*4, IPProtocol(q[9])
+	case len(q) >= 40 && q[0]>>4 == 6:
+		off, proto = 40, IPProtocol(q[6])
+	default:
+		return -1, 0
+	}
+	switch proto {
+	case IPProtocolTCP, IPProtocolUDP, IPProtocolSCTP:
+		return off, proto
+	}
+	return -1, 0
+}
+
+// innerChecksum is a checksum within an ICMP message, along with the function
+// which adjusts it.
+type innerChecksum struct {
+	off    int
+	adjust func(field, old, new []byte) uint16
+}
+
+// quotedChecksums returns the quoted transport checksum in msg which covers
+// the pseudo-header and ports, if it is present in the quote.
+func quotedChecksums(msg []byte, t int, proto IPProtocol) []innerChecksum {
+	switch proto {
+	case IPProtocolTCP:
+		return []innerChecksum{{t + 16, adjustChecksum}}
+	case IPProtocolUDP:
+		return []innerChecksum{{t + 6, adjustUDPChecksum}}
+	}
+	return nil
+}
+
+// icmpEdit replaces the bytes at off in an ICMP message with new. The inner
+// checksums, which cover the changed bytes, are adjusted, and so is the ICMP
+// checksum, which covers both the bytes and the inner checksums. Inner
+// checksums beyond the end of the message are ignored.
+func icmpEdit(msg []byte, off int, new []byte, inner ...innerChecksum) {
+	old := append([]byte{}, msg[off:off+len(new)]...)
+	for _, c := range inner {
+		if c.off+2 > len(msg) {
+			continue
+		}
+		oldSum := append([]byte{}, msg[c.off:c.off+2]...)
+		c.adjust(msg[c.off:c.off+2], old, new)
+		adjustChecksum(msg[2:4], oldSum, msg[c.off:c.off+2])
+	}
+	adjustChecksum(msg[2:4], old, new)
+	copy(msg[off:], new)
+}
+
+// QuotedFlow returns the flow of the packet quoted by an ICMP or ICMPv6 error
+// message. It reports false if the packet is not an error message, or if the
+// quote does not include the ports.
+func (p Packet) QuotedFlow() (Flow, bool) {
+	msg := p.icmpError()
+	if msg == nil {
+		return Flow{}, false
+	}
+	q := msg[8:]
+	t, proto := quotedTransport(q)
+	if t < 0 || len(q) < t+4 {
+		return Flow{}, false
+	}
+	var src, dst netip.Addr
+	if q[0]>>4 == 4 {
+		src, _ = netip.AddrFromSlice(q[12:16])
+		dst, _ = netip.AddrFromSlice(q[16:20])
+	} else {
+		src, _ = netip.AddrFromSlice(q[8:24])
+		dst, _ = netip.AddrFromSlice(q[24:40])
+	}
+	return Flow{
+		Proto: proto,
+		Src:   netip.AddrPortFrom(src, binary.BigEndian.Uint16(q[t:])),
+		Dst:   netip.AddrPortFrom(dst, binary.BigEndian.Uint16(q[t+2:])),
+	}, true
+}
@@ -0,0 +1,229 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// onesSum returns the ones' complement sum of data, which is 0xFFFF for data
+// with a valid Internet checksum.
+func onesSum(data []byte, sum uint32) uint16 {
+	for ; len(data) >= 2; data = data[2:] {
+		sum += uint32(binary.BigEndian.Uint16(data))
+	}
+	if len(data) == 1 {
+		sum += uint32(data[0]) << 8
+	}
+	for sum > 0xFFFF {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return uint16(sum)
+}
+
+func pseudoSum(src, dst netip.Addr, proto byte, n int) uint32 {
+	var sum uint32
+	for _, b := range [][]byte{src.AsSlice(), dst.AsSlice()} {
+		sum += uint32(onesSum(b, 0))
+	}
+	return sum + uint32(proto) + uint32(n)
+}
+
+// ipv4Packet returns an IPv4 packet with valid checksums, carrying a transport
+// segment whose checksum is at csumOff.
+func ipv4Packet(src, dst netip.Addr, proto byte, seg []byte, csumOff int) []byte {
+	if csumOff >= 0 {
+		binary.BigEndian.PutUint16(seg[csumOff:], 0)
+		binary.BigEndian.PutUint16(seg[csumOff:],
+			^onesSum(seg, pseudoSum(src, dst, proto, len(seg))))
+	}
+	ip := make([]byte, 20, 20+len(seg))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(seg)))
+	ip[8] = 64
+	ip[9] = proto
+	copy(ip[12:16], src.AsSlice())
+	copy(ip[16:20], dst.AsSlice())
+	binary.BigEndian.PutUint16(ip[10:12], ^onesSum(ip, 0))
+	return append(ip, seg...)
+}
+
+func decodeIPv4(t *testing.T, ip []byte) packet.Packet {
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip...)
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	return p
+}
+
+func TestNATRewriteTCP(t *testing.T) {
+	var (
+		inside  = netip.MustParseAddr("192.168.1.10")
+		remote  = netip.MustParseAddr("93.184.216.34")
+		outside = netip.MustParseAddr("203.0.113.7")
+	)
+	seg := make([]byte, 25)
+	binary.BigEndian.PutUint16(seg[0:2], 40000)
+	binary.BigEndian.PutUint16(seg[2:4], 443)
+	seg[12] = 5 << 4
+	copy(seg[20:], "hello")
+	p := decodeIPv4(t, ipv4Packet(inside, remote, 6, seg, 16))
+
+	if err := p.SetSource(outside); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetSourcePort(20000); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetSource(netip.MustParseAddr("::1")); err == nil {
+		t.Errorf("expected error changing the address family")
+	}
+
+	ip := p.Network.(*packet.IPv4)
+	if s := onesSum(ip.Contents[:20], 0); s != 0xFFFF {
+		t.Errorf("invalid ip header checksum")
+	}
+	tcp := p.Transport.(*packet.TCP)
+	if s := onesSum(tcp.Contents, pseudoSum(outside, remote, 6, len(tcp.Contents))); s != 0xFFFF {
+		t.Errorf("invalid tcp checksum")
+	}
+
+	// The frame bytes and decoded fields must agree
+	again, err := packet.Decode(p.Link.Contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flow, _ := again.Flow()
+	if flow.Src != netip.AddrPortFrom(outside, 20000) || flow.Dst != netip.AddrPortFrom(remote, 443) {
+		t.Errorf("unexpected flow %v", flow)
+	}
+	if ip.Source != outside || tcp.SourcePort != 20000 ||
+		again.Transport.(*packet.TCP).Checksum != tcp.Checksum {
+		t.Errorf("decoded fields do not match contents")
+	}
+}
+
+func TestNATRewriteICMPError(t *testing.T) {
+	var (
+		inside  = netip.MustParseAddr("192.168.1.10")
+		remote  = netip.MustParseAddr("93.184.216.34")
+		outside = netip.MustParseAddr("203.0.113.7")
+		router  = netip.MustParseAddr("198.51.100.1")
+	)
+	// The translated UDP datagram, quoted in full by a router
+	udp := make([]byte, 12)
+	binary.BigEndian.PutUint16(udp[0:2], 20000)
+	binary.BigEndian.PutUint16(udp[2:4], 53)
+	binary.BigEndian.PutUint16(udp[4:6], 12)
+	quoted := ipv4Packet(outside, remote, 17, udp, 6)
+
+	icmp := append([]byte{11, 0, 0, 0, 0, 0, 0, 0}, quoted...)
+	binary.BigEndian.PutUint16(icmp[2:4], ^onesSum(icmp, 0))
+	p := decodeIPv4(t, ipv4Packet(router, outside, 1, icmp, -1))
+
+	if err := p.SetDestination(inside); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetDestinationPort(40000); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := p.Transport.(*packet.ICMP)
+	if s := onesSum(msg.Contents, 0); s != 0xFFFF {
+		t.Errorf("invalid icmp checksum")
+	}
+	q := msg.Payload
+	if s := onesSum(q[:20], 0); s != 0xFFFF {
+		t.Errorf("invalid quoted ip header checksum")
+	}
+	if s := onesSum(q[20:], pseudoSum(inside, remote, 17, 12)); s != 0xFFFF {
+		t.Errorf("invalid quoted udp checksum")
+	}
+	flow, ok := p.QuotedFlow()
+	if !ok || flow.Src != netip.AddrPortFrom(inside, 40000) || flow.Dst != netip.AddrPortFrom(remote, 53) {
+		t.Errorf("unexpected quoted flow %v", flow)
+	}
+}
+
+func TestNATRewriteICMPErrorUDPZeroChecksum(t *testing.T) {
+	var (
+		inside = netip.MustParseAddr("192.168.1.10")
+		remote = netip.MustParseAddr("93.184.216.34")
+	)
+	udp := make([]byte, 12)
+	binary.BigEndian.PutUint16(udp[0:2], 20000)
+	binary.BigEndian.PutUint16(udp[2:4], 53)
+	binary.BigEndian.PutUint16(udp[4:6], 12)
+	quoted := ipv4Packet(inside, remote, 17, udp, 6)
+
+	icmp := append([]byte{3, 3, 0, 0, 0, 0, 0, 0}, quoted...)
+	binary.BigEndian.PutUint16(icmp[2:4], ^onesSum(icmp, 0))
+	p := decodeIPv4(t, ipv4Packet(remote, inside, 1, icmp, -1))
+
+	// Choose the port for which the adjusted checksum is zero, which must be
+	// sent as 0xFFFF since zero means that there is no checksum
+	var b [4]byte
+	binary.BigEndian.PutUint16(b[0:2], ^binary.BigEndian.Uint16(udp[6:8]))
+	binary.BigEndian.PutUint16(b[2:4], ^uint16(20000))
+	if err := p.SetDestinationPort(^onesSum(b[:], 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := p.Transport.(*packet.ICMP)
+	q := msg.Payload
+	if sum := binary.BigEndian.Uint16(q[20+6:]); sum != 0xFFFF {
+		t.Errorf("quoted udp checksum = %#x, want 0xffff", sum)
+	}
+	if s := onesSum(msg.Contents, 0); s != 0xFFFF {
+		t.Errorf("invalid icmp checksum")
+	}
+}
+
+func TestSetEchoID(t *testing.T) {
+	var (
+		src = netip.MustParseAddr("2001:db8::1")
+		dst = netip.MustParseAddr("2001:db8::2")
+	)
+	icmp := []byte{128, 0, 0, 0, 0x04, 0xD2, 0, 1, 'p', 'i', 'n', 'g'}
+	binary.BigEndian.PutUint16(icmp[2:4], ^onesSum(icmp, pseudoSum(src, dst, 58, len(icmp))))
+	ip := make([]byte, 40, 40+len(icmp))
+	ip[0] = 0x60
+	binary.BigEndian.PutUint16(ip[4:6], uint16(len(icmp)))
+	ip[6], ip[7] = 58, 64
+	copy(ip[8:24], src.AsSlice())
+	copy(ip[24:40], dst.AsSlice())
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x86, 0xDD}, append(ip, icmp...)...)
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The identifier of requests takes the place of the source port
+	flow, ok := p.EchoFlow()
+	if !ok || flow.Proto != packet.IPProtocolICMPv6 || flow.Src != netip.AddrPortFrom(src, 1234) ||
+		flow.Dst != netip.AddrPortFrom(dst, 0) {
+		t.Fatalf("unexpected echo flow %v", flow)
+	}
+	if err := p.SetEchoID(20000); err != nil {
+		t.Fatal(err)
+	}
+	msg := p.Transport.(*packet.ICMPv6)
+	if s := onesSum(msg.Contents, pseudoSum(src, dst, 58, len(msg.Contents))); s != 0xFFFF {
+		t.Errorf("invalid icmpv6 checksum")
+	}
+	if flow, _ = p.EchoFlow(); flow.Src.Port() != 20000 {
+		t.Errorf("identifier = %d, want 20000", flow.Src.Port())
+	}
+
+	// Other messages have no identifier
+	p = decodeUDP(t, 5000, 53, nil)
+	if _, ok := p.EchoFlow(); ok {
+		t.Error("udp datagram has an echo flow")
+	}
+	if err := p.SetEchoID(1); err == nil {
+		t.Error("expected error setting the identifier of a udp datagram")
+	}
+}
@@ -0,0 +1,304 @@
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(NTP)
+	_ json.Marshaler = new(NTP)
+)
+
+type NTPMode uint8
+
+const (
+	NTPModeReserved         NTPMode = 0
+	NTPModeSymmetricActive  NTPMode = 1
+	NTPModeSymmetricPassive NTPMode = 2
+	NTPModeClient           NTPMode = 3
+	NTPModeServer           NTPMode = 4
+	NTPModeBroadcast        NTPMode = 5
+	NTPModeControl          NTPMode = 6
+	NTPModePrivate          NTPMode = 7
+)
+
+// NTPTimestamp is a 64-bit NTP timestamp, with the seconds since 1900 in the
+// upper 32 bits and the fraction of a second in the lower 32 bits.
+type NTPTimestamp uint64
+
+// ntpEpochOffset is the number of seconds from 1900 to 1970.
+const ntpEpochOffset = 2208988800
+
+// Time returns the time of the timestamp, or the zero time for a zero
+// timestamp. Timestamps whose most significant bit is clear are assumed to
+// belong to the era starting in 2036 (RFC 4330, section 3).
+func (t NTPTimestamp) Time() time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	sec := int64(t >> 32)
+	if sec&0x80000000 == 0 {
+		sec += 1 << 32
+	}
+	nsec := int64((uint64(t&0xFFFFFFFF)*1e9 + 1<<31) >> 32)
+	return time.Unix(sec-ntpEpochOffset, nsec).UTC()
+}
+
+// ntpShort decodes the 32-bit short format, with 16 bits of seconds and 16
+// bits of fraction.
+func ntpShort(b []byte) time.Duration {
+	return time.Duration(int64(binary.BigEndian.Uint32(b)) * int64(time.Second) >> 16)
+}
+
+// NTPExtension is an NTPv4 extension field (RFC 7822).
+type NTPExtension struct {
+	Type  uint16
+	Value []byte
+}
+
+// NTPControl is the header of an NTP control message (mode 6).
+type NTPControl struct {
+	Response      bool
+	Error         bool
+	More          bool
+	OpCode        uint8
+	Sequence      uint16
+	Status        uint16
+	AssociationID uint16
+	Offset        uint16
+	Count         uint16
+	Data          []byte
+}
+
+// NTP is a Network Time Protocol packet (RFC 5905, RFC 1305). The fields of
+// the time header are not set for control messages, which are decoded into
+// Control instead.
+type NTP struct {
+	LeapIndicator  uint8
+	Version        uint8
+	Mode           NTPMode
+	Stratum        uint8
+	Poll           int8
+	Precision      int8
+	RootDelay      time.Duration
+	RootDispersion time.Duration
+	ReferenceID    [4]byte
+	ReferenceTime  NTPTimestamp
+	OriginTime     NTPTimestamp
+	ReceiveTime    NTPTimestamp
+	TransmitTime   NTPTimestamp
+	Extensions     []NTPExtension
+	Control        *NTPControl
+	// KeyID and MAC contain the message authentication code, if present.
+	KeyID uint32
+	MAC   []byte
+	PacketBytes
+}
+
+func (n *NTP) Unmarshal(data []byte) error {
+	if len(data) < 1 {
+		return fmt.Errorf("ntp packet too small: %w", ErrTruncated)
+	}
+	*n = NTP{Extensions: n.Extensions[:0]}
+	n.LeapIndicator = data[0] >> 6
+	n.Version = data[0] >> 3 & 0x07
+	n.Mode = NTPMode(data[0] & 0x07)
+	n.Contents = data
+	if n.Mode == NTPModeControl {
+		return n.unmarshalControl(data)
+	}
+
+	if len(data) < 48 {
+		return fmt.Errorf("ntp packet too small: %w", ErrTruncated)
+	}
+	n.Stratum = data[1]
+	n.Poll = int8(data[2])
+	n.Precision = int8(data[3])
+	n.RootDelay = ntpShort(data[4:8])
+	n.RootDispersion = ntpShort(data[8:12])
+	copy(n.ReferenceID[:], data[12:16])
+	n.ReferenceTime = NTPTimestamp(binary.BigEndian.Uint64(data[16:24]))
+	n.OriginTime = NTPTimestamp(binary.BigEndian.Uint64(data[24:32]))
+	n.ReceiveTime = NTPTimestamp(binary.BigEndian.Uint64(data[32:40]))
+	n.TransmitTime = NTPTimestamp(binary.BigEndian.Uint64(data[40:48]))
+
+	// Extension fields are at least 16 bytes long, so a trailer of 4 (crypto
+	// NAK), 20 or 24 bytes is a MAC
+	rest := data[48:]
+	for len(rest) > 0 && n.Version >= 4 && !isNTPMAC(rest) {
+		if len(rest) < 16 {
+			return fmt.Errorf("ntp extension field too small: %w", ErrTruncated)
+		}
+		l := int(binary.BigEndian.Uint16(rest[2:4]))
+		if l < 16 || l%4 != 0 || l > len(rest) {
+			return fmt.Errorf("invalid ntp extension field length %d: %w", l, ErrInvalid)
+		}
+		n.Extensions = append(n.Extensions, NTPExtension{
+			Type:  binary.BigEndian.Uint16(rest[0:2]),
+			Value: rest[4:l],
+		})
+		rest = rest[l:]
+	}
+	if err := n.unmarshalMAC(rest); err != nil {
+		return err
+	}
+	return nil
+}
+
+func isNTPMAC(b []byte) bool {
+	return len(b) == 4 || len(b) == 20 || len(b) == 24
+}
+
+func (n *NTP) unmarshalMAC(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	if !isNTPMAC(b) {
+		return fmt.Errorf("invalid ntp mac length %d: %w", len(b), ErrInvalid)
+	}
+	n.KeyID = binary.BigEndian.Uint32(b[0:4])
+	n.MAC = b[4:]
+	return nil
+}
+
+func (n *NTP) unmarshalControl(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("ntp control message too small: %w", ErrTruncated)
+	}
+	c := &NTPControl{
+		Response:      data[1]&0x80 != 0,
+		Error:         data[1]&0x40 != 0,
+		More:          data[1]&0x20 != 0,
+		OpCode:        data[1] & 0x1F,
+		Sequence:      binary.BigEndian.Uint16(data[2:4]),
+		Status:        binary.BigEndian.Uint16(data[4:6]),
+		AssociationID: binary.BigEndian.Uint16(data[6:8]),
+		Offset:        binary.BigEndian.Uint16(data[8:10]),
+		Count:         binary.BigEndian.Uint16(data[10:12]),
+	}
+	end := 12 + int(c.Count)
+	if end > len(data) {
+		return fmt.Errorf("ntp control data exceeds packet: %w", ErrTruncated)
+	}
+	c.Data = data[12:end]
+	n.Control = c
+
+	// Data is padded to 32 bits, and may be followed by a MAC
+	if pad := end % 4; pad != 0 && end+4-pad <= len(data) {
+		end += 4 - pad
+	}
+	return n.unmarshalMAC(data[end:])
+}
+
+func (n NTP) Type() LayerType {
+	return LayerTypeNTP
+}
+
+func (n NTP) GetContents() []byte {
+	return n.Contents
+}
+
+func (n NTP) GetPayload() []byte {
+	return n.Payload
+}
+
+// ReferenceIDString returns the reference ID as a string. For stratum 0 and 1,
+// it is a kiss code or the ASCII name of a reference clock, and otherwise the
+// IPv4 address of the reference, or the hash of its IPv6 address.
+func (n NTP) ReferenceIDString() string {
+	if n.Stratum <= 1 {
+		return string(bytes.TrimRight(n.ReferenceID[:], "\x00"))
+	}
+	return netip.AddrFrom4(n.ReferenceID).String()
+}
+
+func ntpTimeJSON(t NTPTimestamp) string {
+	if t == 0 {
+		return ""
+	}
+	return t.Time().Format(time.RFC3339Nano)
+}
+
+func (n NTP) MarshalJSON() ([]byte, error) {
+	type extension struct {
+		Type  uint16 `json:"type"`
+		Value string `json:"value"`
+	}
+	type control struct {
+		Response      bool   `json:"response"`
+		Error         bool   `json:"error"`
+		More          bool   `json:"more"`
+		OpCode        uint8  `json:"opcode"`
+		Sequence      uint16 `json:"sequence"`
+		Status        uint16 `json:"status"`
+		AssociationID uint16 `json:"association_id"`
+		Offset        uint16 `json:"offset"`
+		Count         uint16 `json:"count"`
+		Data          string `json:"data"`
+	}
+	v := struct {
+		Type             string      `json:"type"`
+		LeapIndicator    uint8       `json:"leap_indicator"`
+		Version          uint8       `json:"version"`
+		Mode             string      `json:"mode"`
+		Stratum          uint8       `json:"stratum"`
+		Poll             int8        `json:"poll"`
+		Precision        int8        `json:"precision"`
+		RootDelayNS      int64       `json:"root_delay_ns"`
+		RootDispersionNS int64       `json:"root_dispersion_ns"`
+		ReferenceID      string      `json:"reference_id"`
+		ReferenceTime    string      `json:"reference_time,omitempty"`
+		OriginTime       string      `json:"origin_time,omitempty"`
+		ReceiveTime      string      `json:"receive_time,omitempty"`
+		TransmitTime     string      `json:"transmit_time,omitempty"`
+		Extensions       []extension `json:"extensions"`
+		Control          *control    `json:"control,omitempty"`
+		KeyID            uint32      `json:"key_id,omitempty"`
+		MAC              string      `json:"mac,omitempty"`
+		Length           int         `json:"length"`
+	}{
+		Type:             n.Type().String(),
+		LeapIndicator:    n.LeapIndicator,
+		Version:          n.Version,
+		Mode:             n.Mode.String(),
+		Stratum:          n.Stratum,
+		Poll:             n.Poll,
+		Precision:        n.Precision,
+		RootDelayNS:      int64(n.RootDelay),
+		RootDispersionNS: int64(n.RootDispersion),
+		ReferenceID:      n.ReferenceIDString(),
+		ReferenceTime:    ntpTimeJSON(n.ReferenceTime),
+		OriginTime:       ntpTimeJSON(n.OriginTime),
+		ReceiveTime:      ntpTimeJSON(n.ReceiveTime),
+		TransmitTime:     ntpTimeJSON(n.TransmitTime),
+		Extensions:       make([]extension, len(n.Extensions)),
+		KeyID:            n.KeyID,
+		MAC:              hex.EncodeToString(n.MAC),
+		Length:           len(n.Contents),
+	}
+	for i, e := range n.Extensions {
+		v.Extensions[i] = extension{Type: e.Type, Value: hex.EncodeToString(e.Value)}
+	}
+	if c := n.Control; c != nil {
+		v.Control = &control{
+			Response:      c.Response,
+			Error:         c.Error,
+			More:          c.More,
+			OpCode:        c.OpCode,
+			Sequence:      c.Sequence,
+			Status:        c.Status,
+			AssociationID: c.AssociationID,
+			Offset:        c.Offset,
+			Count:         c.Count,
+			Data:          hex.EncodeToString(c.Data),
+		}
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,83 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestNTPServerResponse(t *testing.T) {
+	ntp := make([]byte, 48)
+	ntp[0] = 0<<6 | 4<<3 | 4 // no leap warning, v4, server
+	ntp[1] = 2
+	ntp[2] = 6
+	ntp[3] = 0xEC // -20
+	// Root delay of 0.5s
+	binary.BigEndian.PutUint32(ntp[4:8], 0x00008000)
+	copy(ntp[12:16], []byte{192, 0, 2, 1})
+	// 2024-01-01T00:00:00.5Z
+	ts := uint64(3913056000)<<32 | 1<<31
+	binary.BigEndian.PutUint64(ntp[40:48], ts)
+
+	// An extension field followed by a MAC with a 16 byte digest
+	ext := make([]byte, 16)
+	binary.BigEndian.PutUint16(ext[0:2], 0x0104)
+	binary.BigEndian.PutUint16(ext[2:4], 16)
+	ntp = append(ntp, ext...)
+	mac := make([]byte, 20)
+	binary.BigEndian.PutUint32(mac[0:4], 42)
+	ntp = append(ntp, mac...)
+
+	udp := make([]byte, 8, 8+len(ntp))
+	binary.BigEndian.PutUint16(udp[0:2], 123)
+	binary.BigEndian.PutUint16(udp[2:4], 123)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(ntp)))
+	udp = append(udp, ntp...)
+	p := decodeIPv4(t, ipv4Packet(netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"), 17, udp, 6))
+
+	n, ok := p.Application.(*packet.NTP)
+	if !ok {
+		t.Fatalf("expected ntp, got %T", p.Application)
+	}
+	if n.Version != 4 || n.Mode != packet.NTPModeServer || n.Stratum != 2 || n.Precision != -20 {
+		t.Errorf("unexpected header %+v", n)
+	}
+	if n.RootDelay != 500*time.Millisecond {
+		t.Errorf("unexpected root delay %v", n.RootDelay)
+	}
+	if got := n.ReferenceIDString(); got != "192.0.2.1" {
+		t.Errorf("unexpected reference id %v", got)
+	}
+	want := time.Date(2024, 1, 1, 0, 0, 0, 5e8, time.UTC)
+	if got := n.TransmitTime.Time(); !got.Equal(want) {
+		t.Errorf("unexpected transmit time %v", got)
+	}
+	if len(n.Extensions) != 1 || n.Extensions[0].Type != 0x0104 || len(n.Extensions[0].Value) != 12 {
+		t.Errorf("unexpected extensions %+v", n.Extensions)
+	}
+	if n.KeyID != 42 || len(n.MAC) != 16 {
+		t.Errorf("unexpected mac %d %x", n.KeyID, n.MAC)
+	}
+	if _, err := n.MarshalJSON(); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestNTPControl(t *testing.T) {
+	// A read variables response, with data padded to 32 bits
+	msg := []byte{0<<6 | 2<<3 | 6, 0x82, 0, 1, 0x06, 0x15, 0, 0, 0, 0, 0, 5}
+	msg = append(msg, "stratum"[:5]...)
+	msg = append(msg, 0, 0, 0)
+	var n packet.NTP
+	if err := n.Unmarshal(msg); err != nil {
+		t.Fatal(err)
+	}
+	c := n.Control
+	if c == nil || !c.Response || c.OpCode != 2 || c.Sequence != 1 || string(c.Data) != "strat" {
+		t.Errorf("unexpected control message %+v", c)
+	}
+}
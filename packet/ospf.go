@@ -0,0 +1,817 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(OSPF)
+	_ json.Marshaler = new(OSPF)
+)
+
+type OSPFType uint8
+
+const (
+	OSPFTypeHello OSPFType = 1
+	OSPFTypeDBD   OSPFType = 2
+	OSPFTypeLSR   OSPFType = 3
+	OSPFTypeLSU   OSPFType = 4
+	OSPFTypeLSAck OSPFType = 5
+)
+
+// OSPFLSType is the type of an LSA. OSPFv3 types include the U-bit and the
+// flooding scope in their high-order bits.
+type OSPFLSType uint16
+
+const (
+	OSPFLSTypeRouter      OSPFLSType = 1
+	OSPFLSTypeNetwork     OSPFLSType = 2
+	OSPFLSTypeSummary     OSPFLSType = 3
+	OSPFLSTypeASBRSummary OSPFLSType = 4
+	OSPFLSTypeASExternal  OSPFLSType = 5
+	OSPFLSTypeNSSA        OSPFLSType = 7
+
+	OSPFLSTypeV3Router          OSPFLSType = 0x2001
+	OSPFLSTypeV3Network         OSPFLSType = 0x2002
+	OSPFLSTypeV3InterAreaPrefix OSPFLSType = 0x2003
+	OSPFLSTypeV3InterAreaRouter OSPFLSType = 0x2004
+	OSPFLSTypeV3ASExternal      OSPFLSType = 0x4005
+	OSPFLSTypeV3NSSA            OSPFLSType = 0x2007
+	OSPFLSTypeV3Link            OSPFLSType = 0x0008
+	OSPFLSTypeV3IntraAreaPrefix OSPFLSType = 0x2009
+)
+
+// OSPF is an OSPFv2 (RFC 2328) or OSPFv3 (RFC 5340) packet. The body of the
+// packet is decoded into the field of its type. Router, area and LSA
+// identifiers are 32-bit values, which are represented as IPv4 addresses.
+type OSPF struct {
+	Version  uint8
+	OSPFType OSPFType
+	Length   uint16
+	RouterID netip.Addr
+	AreaID   netip.Addr
+	Checksum uint16
+	// AuType and Authentication are set for OSPFv2.
+	AuType         uint16
+	Authentication []byte
+	// InstanceID is set for OSPFv3.
+	InstanceID uint8
+
+	Hello *OSPFHello
+	DBD   *OSPFDBD
+	// LSRequests is set for link state requests.
+	LSRequests []OSPFLSRequest
+	// LSAs is set for link state updates.
+	LSAs []OSPFLSA
+	// LSAHeaders is set for link state acknowledgements.
+	LSAHeaders []OSPFLSAHeader
+	PacketBytes
+}
+
+func (o *OSPF) Unmarshal(data []byte) error {
+	if len(data) < 16 {
+		return fmt.Errorf("ospf packet too small: %w", ErrTruncated)
+	}
+	*o = OSPF{}
+	o.Version = data[0]
+	o.OSPFType = OSPFType(data[1])
+	o.Length = binary.BigEndian.Uint16(data[2:4])
+	o.RouterID = netip.AddrFrom4(*(*[4]byte)(data[4:8]))
+	o.AreaID = netip.AddrFrom4(*(*[4]byte)(data[8:12]))
+	o.Checksum = binary.BigEndian.Uint16(data[12:14])
+	hdrLen := 16
+	switch o.Version {
+	case 2:
+		hdrLen = 24
+		if len(data) < hdrLen {
+			return fmt.Errorf("ospf packet too small: %w", ErrTruncated)
+		}
+		o.AuType = binary.BigEndian.Uint16(data[14:16])
+		o.Authentication = data[16:24]
+	case 3:
+		o.InstanceID = data[14]
+	default:
+		return fmt.Errorf("unsupported ospf version %d: %w", o.Version, ErrUnsupported)
+	}
+	// Cryptographic authentication data follows the packet
+	if int(o.Length) < hdrLen || int(o.Length) > len(data) {
+		return fmt.Errorf("invalid ospf packet length: %w", ErrInvalid)
+	}
+	o.Contents = data[:o.Length]
+	o.Payload = data[hdrLen:o.Length]
+
+	body := o.Payload
+	var err error
+	switch o.OSPFType {
+	case OSPFTypeHello:
+		o.Hello = new(OSPFHello)
+		err = o.Hello.unmarshal(body, o.Version)
+	case OSPFTypeDBD:
+		o.DBD = new(OSPFDBD)
+		err = o.DBD.unmarshal(body, o.Version)
+	case OSPFTypeLSR:
+		if len(body)%12 != 0 {
+			return fmt.Errorf("invalid ospf link state request length: %w", ErrInvalid)
+		}
+		o.LSRequests = make([]OSPFLSRequest, 0, len(body)/12)
+		for ; len(body) > 0; body = body[12:] {
+			o.LSRequests = append(o.LSRequests, OSPFLSRequest{
+				LSType:            OSPFLSType(binary.BigEndian.Uint16(body[2:4])),
+				LinkStateID:       netip.AddrFrom4(*(*[4]byte)(body[4:8])),
+				AdvertisingRouter: netip.AddrFrom4(*(*[4]byte)(body[8:12])),
+			})
+		}
+	case OSPFTypeLSU:
+		if len(body) < 4 {
+			return fmt.Errorf("ospf link state update too small: %w", ErrTruncated)
+		}
+		n := binary.BigEndian.Uint32(body[0:4])
+		body = body[4:]
+		o.LSAs = []OSPFLSA{}
+		for i := uint32(0); i < n; i++ {
+			var lsa OSPFLSA
+			if err := lsa.unmarshal(body, o.Version); err != nil {
+				return err
+			}
+			o.LSAs = append(o.LSAs, lsa)
+			body = body[lsa.Length:]
+		}
+	case OSPFTypeLSAck:
+		o.LSAHeaders, err = parseOSPFLSAHeaders(body, o.Version)
+	}
+	return err
+}
+
+func (o OSPF) Type() LayerType {
+	return LayerTypeOSPF
+}
+
+func (o OSPF) GetContents() []byte {
+	return o.Contents
+}
+
+func (o OSPF) GetPayload() []byte {
+	return o.Payload
+}
+
+func (o OSPF) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Type       string          `json:"type"`
+		Version    uint8           `json:"version"`
+		OSPFType   string          `json:"ospf_type"`
+		RouterID   netip.Addr      `json:"router_id"`
+		AreaID     netip.Addr      `json:"area_id"`
+		Checksum   uint16          `json:"checksum"`
+		AuType     *uint16         `json:"au_type,omitempty"`
+		InstanceID *uint8          `json:"instance_id,omitempty"`
+		Hello      *OSPFHello      `json:"hello,omitempty"`
+		DBD        *OSPFDBD        `json:"dbd,omitempty"`
+		LSRequests []OSPFLSRequest `json:"ls_requests,omitempty"`
+		LSAs       []OSPFLSA       `json:"lsas,omitempty"`
+		LSAHeaders []OSPFLSAHeader `json:"lsa_headers,omitempty"`
+		Length     int             `json:"length"`
+	}{
+		Type:       o.Type().String(),
+		Version:    o.Version,
+		OSPFType:   o.OSPFType.String(),
+		RouterID:   o.RouterID,
+		AreaID:     o.AreaID,
+		Checksum:   o.Checksum,
+		Hello:      o.Hello,
+		DBD:        o.DBD,
+		LSRequests: o.LSRequests,
+		LSAs:       o.LSAs,
+		LSAHeaders: o.LSAHeaders,
+		Length:     len(o.Contents),
+	}
+	if o.Version == 2 {
+		v.AuType = &o.AuType
+	} else {
+		v.InstanceID = &o.InstanceID
+	}
+	return json.Marshal(v)
+}
+
+// OSPFHello is the body of a Hello packet.
+type OSPFHello struct {
+	// NetworkMask is set for OSPFv2, and InterfaceID for OSPFv3.
+	NetworkMask            netip.Addr
+	InterfaceID            uint32
+	HelloInterval          time.Duration
+	RouterDeadInterval     time.Duration
+	Options                uint32
+	Priority               uint8
+	DesignatedRouter       netip.Addr
+	BackupDesignatedRouter netip.Addr
+	Neighbors              []netip.Addr
+}
+
+func (h *OSPFHello) unmarshal(data []byte, version uint8) error {
+	if len(data) < 20 {
+		return fmt.Errorf("ospf hello too small: %w", ErrTruncated)
+	}
+	if version == 2 {
+		h.NetworkMask = netip.AddrFrom4(*(*[4]byte)(data[0:4]))
+		h.HelloInterval = time.Duration(binary.BigEndian.Uint16(data[4:6])) * time.Second
+		h.Options = uint32(data[6])
+		h.Priority = data[7]
+		h.RouterDeadInterval = time.Duration(binary.BigEndian.Uint32(data[8:12])) * time.Second
+	} else {
+		h.InterfaceID = binary.BigEndian.Uint32(data[0:4])
+		h.Priority = data[4]
+		h.Options = binary.BigEndian.Uint32(data[4:8]) & 0xFFFFFF
+		h.HelloInterval = time.Duration(binary.BigEndian.Uint16(data[8:10])) * time.Second
+		h.RouterDeadInterval = time.Duration(binary.BigEndian.Uint16(data[10:12])) * time.Second
+	}
+	h.DesignatedRouter = netip.AddrFrom4(*(*[4]byte)(data[12:16]))
+	h.BackupDesignatedRouter = netip.AddrFrom4(*(*[4]byte)(data[16:20]))
+	data = data[20:]
+	if len(data)%4 != 0 {
+		return fmt.Errorf("invalid ospf hello length: %w", ErrInvalid)
+	}
+	h.Neighbors = make([]netip.Addr, 0, len(data)/4)
+	for ; len(data) > 0; data = data[4:] {
+		h.Neighbors = append(h.Neighbors, netip.AddrFrom4(*(*[4]byte)(data)))
+	}
+	return nil
+}
+
+func (h OSPFHello) MarshalJSON() ([]byte, error) {
+	v := struct {
+		NetworkMask            *netip.Addr  `json:"network_mask,omitempty"`
+		InterfaceID            *uint32      `json:"interface_id,omitempty"`
+		HelloInterval          float64      `json:"hello_interval"`
+		RouterDeadInterval     float64      `json:"router_dead_interval"`
+		Options                uint32       `json:"options"`
+		Priority               uint8        `json:"priority"`
+		DesignatedRouter       netip.Addr   `json:"designated_router"`
+		BackupDesignatedRouter netip.Addr   `json:"backup_designated_router"`
+		Neighbors              []netip.Addr `json:"neighbors"`
+	}{
+		HelloInterval:          h.HelloInterval.Seconds(),
+		RouterDeadInterval:     h.RouterDeadInterval.Seconds(),
+		Options:                h.Options,
+		Priority:               h.Priority,
+		DesignatedRouter:       h.DesignatedRouter,
+		BackupDesignatedRouter: h.BackupDesignatedRouter,
+		Neighbors:              h.Neighbors,
+	}
+	if h.NetworkMask.IsValid() {
+		v.NetworkMask = &h.NetworkMask
+	} else {
+		v.InterfaceID = &h.InterfaceID
+	}
+	if v.Neighbors == nil {
+		v.Neighbors = []netip.Addr{}
+	}
+	return json.Marshal(v)
+}
+
+// OSPFDBDFlags are the flags of a Database Description packet.
+type OSPFDBDFlags uint8
+
+func (f OSPFDBDFlags) Init() bool   { return f&0x04 != 0 }
+func (f OSPFDBDFlags) More() bool   { return f&0x02 != 0 }
+func (f OSPFDBDFlags) Master() bool { return f&0x01 != 0 }
+
+// OSPFDBD is the body of a Database Description packet.
+type OSPFDBD struct {
+	InterfaceMTU uint16
+	Options      uint32
+	Flags        OSPFDBDFlags
+	Sequence     uint32
+	LSAHeaders   []OSPFLSAHeader
+}
+
+func (d *OSPFDBD) unmarshal(data []byte, version uint8) error {
+	hdrLen := 8
+	if version == 3 {
+		hdrLen = 12
+	}
+	if len(data) < hdrLen {
+		return fmt.Errorf("ospf database description too small: %w", ErrTruncated)
+	}
+	if version == 2 {
+		d.InterfaceMTU = binary.BigEndian.Uint16(data[0:2])
+		d.Options = uint32(data[2])
+		d.Flags = OSPFDBDFlags(data[3])
+	} else {
+		d.Options = binary.BigEndian.Uint32(data[0:4]) & 0xFFFFFF
+		d.InterfaceMTU = binary.BigEndian.Uint16(data[4:6])
+		d.Flags = OSPFDBDFlags(data[7])
+	}
+	d.Sequence = binary.BigEndian.Uint32(data[hdrLen-4 : hdrLen])
+	var err error
+	d.LSAHeaders, err = parseOSPFLSAHeaders(data[hdrLen:], version)
+	return err
+}
+
+func (d OSPFDBD) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		InterfaceMTU uint16          `json:"interface_mtu"`
+		Options      uint32          `json:"options"`
+		Init         bool            `json:"init"`
+		More         bool            `json:"more"`
+		Master       bool            `json:"master"`
+		Sequence     uint32          `json:"sequence"`
+		LSAHeaders   []OSPFLSAHeader `json:"lsa_headers"`
+	}{
+		InterfaceMTU: d.InterfaceMTU,
+		Options:      d.Options,
+		Init:         d.Flags.Init(),
+		More:         d.Flags.More(),
+		Master:       d.Flags.Master(),
+		Sequence:     d.Sequence,
+		LSAHeaders:   d.LSAHeaders,
+	})
+}
+
+// OSPFLSRequest identifies an LSA in a Link State Request packet.
+type OSPFLSRequest struct {
+	LSType            OSPFLSType
+	LinkStateID       netip.Addr
+	AdvertisingRouter netip.Addr
+}
+
+func (r OSPFLSRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		LSType            string     `json:"ls_type"`
+		LinkStateID       netip.Addr `json:"link_state_id"`
+		AdvertisingRouter netip.Addr `json:"advertising_router"`
+	}{r.LSType.String(), r.LinkStateID, r.AdvertisingRouter})
+}
+
+// OSPFLSAHeaderLen is the length of LSA headers of both versions.
+const OSPFLSAHeaderLen = 20
+
+// OSPFLSAHeader is the header of an LSA.
+type OSPFLSAHeader struct {
+	Age time.Duration
+	// Options is set for OSPFv2, where it is part of the header.
+	Options           uint8
+	LSType            OSPFLSType
+	LinkStateID       netip.Addr
+	AdvertisingRouter netip.Addr
+	Sequence          uint32
+	Checksum          uint16
+	Length            uint16
+}
+
+func (h *OSPFLSAHeader) unmarshal(data []byte, version uint8) error {
+	if len(data) < OSPFLSAHeaderLen {
+		return fmt.Errorf("ospf lsa header too small: %w", ErrTruncated)
+	}
+	h.Age = time.Duration(binary.BigEndian.Uint16(data[0:2])) * time.Second
+	if version == 2 {
+		h.Options = data[2]
+		h.LSType = OSPFLSType(data[3])
+	} else {
+		h.LSType = OSPFLSType(binary.BigEndian.Uint16(data[2:4]))
+	}
+	h.LinkStateID = netip.AddrFrom4(*(*[4]byte)(data[4:8]))
+	h.AdvertisingRouter = netip.AddrFrom4(*(*[4]byte)(data[8:12]))
+	h.Sequence = binary.BigEndian.Uint32(data[12:16])
+	h.Checksum = binary.BigEndian.Uint16(data[16:18])
+	h.Length = binary.BigEndian.Uint16(data[18:20])
+	return nil
+}
+
+func (h OSPFLSAHeader) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.jsonValue())
+}
+
+type ospfLSAHeaderJSON struct {
+	Age               float64    `json:"age"`
+	Options           uint8      `json:"options"`
+	LSType            string     `json:"ls_type"`
+	LinkStateID       netip.Addr `json:"link_state_id"`
+	AdvertisingRouter netip.Addr `json:"advertising_router"`
+	Sequence          uint32     `json:"sequence"`
+	Checksum          uint16     `json:"checksum"`
+	Length            uint16     `json:"length"`
+}
+
+func (h OSPFLSAHeader) jsonValue() ospfLSAHeaderJSON {
+	return ospfLSAHeaderJSON{
+		Age:               h.Age.Seconds(),
+		Options:           h.Options,
+		LSType:            h.LSType.String(),
+		LinkStateID:       h.LinkStateID,
+		AdvertisingRouter: h.AdvertisingRouter,
+		Sequence:          h.Sequence,
+		Checksum:          h.Checksum,
+		Length:            h.Length,
+	}
+}
+
+func parseOSPFLSAHeaders(data []byte, version uint8) ([]OSPFLSAHeader, error) {
+	if len(data)%OSPFLSAHeaderLen != 0 {
+		return nil, fmt.Errorf("invalid length of ospf lsa headers: %w", ErrInvalid)
+	}
+	res := make([]OSPFLSAHeader, len(data)/OSPFLSAHeaderLen)
+	for i := range res {
+		res[i].unmarshal(data[i*OSPFLSAHeaderLen:], version)
+	}
+	return res, nil
+}
+
+// OSPFLSA is an LSA of a Link State Update packet. The body of router,
+// network, summary, AS-external and NSSA LSAs, and of the OSPFv3
+// intra-area-prefix LSA, is decoded into the field of its type. The OSPFv3
+// inter-area-prefix LSA is decoded as a summary LSA.
+type OSPFLSA struct {
+	OSPFLSAHeader
+	Router          *OSPFRouterLSA
+	Network         *OSPFNetworkLSA
+	Summary         *OSPFSummaryLSA
+	ASExternal      *OSPFASExternalLSA
+	IntraAreaPrefix *OSPFIntraAreaPrefixLSA
+	// Body contains the LSA without its header.
+	Body []byte
+}
+
+func (l *OSPFLSA) unmarshal(data []byte, version uint8) error {
+	if err := l.OSPFLSAHeader.unmarshal(data, version); err != nil {
+		return err
+	}
+	if l.Length < OSPFLSAHeaderLen || int(l.Length) > len(data) {
+		return fmt.Errorf("invalid ospf lsa length: %w", ErrInvalid)
+	}
+	l.Body = data[OSPFLSAHeaderLen:l.Length]
+	b := l.Body
+	var err error
+	switch l.LSType {
+	case OSPFLSTypeRouter, OSPFLSTypeV3Router:
+		l.Router = new(OSPFRouterLSA)
+		err = l.Router.unmarshal(b, version)
+	case OSPFLSTypeNetwork, OSPFLSTypeV3Network:
+		if len(b) < 4 || len(b)%4 != 0 {
+			return fmt.Errorf("invalid ospf network lsa length: %w", ErrInvalid)
+		}
+		n := new(OSPFNetworkLSA)
+		if version == 2 {
+			n.NetworkMask = netip.AddrFrom4(*(*[4]byte)(b[0:4]))
+		} else {
+			n.Options = binary.BigEndian.Uint32(b[0:4]) & 0xFFFFFF
+		}
+		for b = b[4:]; len(b) > 0; b = b[4:] {
+			n.AttachedRouters = append(n.AttachedRouters, netip.AddrFrom4(*(*[4]byte)(b)))
+		}
+		l.Network = n
+	case OSPFLSTypeSummary, OSPFLSTypeASBRSummary:
+		if len(b) < 8 {
+			return fmt.Errorf("ospf summary lsa too small: %w", ErrTruncated)
+		}
+		l.Summary = &OSPFSummaryLSA{
+			Prefix: ospfPrefix(l.LinkStateID, b[0:4]),
+			Metric: binary.BigEndian.Uint32(b[4:8]) & 0xFFFFFF,
+		}
+		if l.LSType == OSPFLSTypeASBRSummary {
+			l.Summary.Prefix = netip.PrefixFrom(l.LinkStateID, 32)
+		}
+	case OSPFLSTypeV3InterAreaPrefix:
+		if len(b) < 4 {
+			return fmt.Errorf("ospf inter-area-prefix lsa too small: %w", ErrTruncated)
+		}
+		s := &OSPFSummaryLSA{Metric: binary.BigEndian.Uint32(b[0:4]) & 0xFFFFFF}
+		s.Prefix, s.PrefixOptions, _, err = parseOSPFv3Prefix(b[4:])
+		l.Summary = s
+	case OSPFLSTypeASExternal, OSPFLSTypeNSSA:
+		if len(b) < 16 {
+			return fmt.Errorf("ospf as-external lsa too small: %w", ErrTruncated)
+		}
+		l.ASExternal = &OSPFASExternalLSA{
+			Prefix:            ospfPrefix(l.LinkStateID, b[0:4]),
+			ExternalType2:     b[4]&0x80 != 0,
+			Metric:            binary.BigEndian.Uint32(b[4:8]) & 0xFFFFFF,
+			ForwardingAddress: netip.AddrFrom4(*(*[4]byte)(b[8:12])),
+			RouteTag:          binary.BigEndian.Uint32(b[12:16]),
+		}
+	case OSPFLSTypeV3ASExternal, OSPFLSTypeV3NSSA:
+		l.ASExternal = new(OSPFASExternalLSA)
+		err = l.ASExternal.unmarshalV3(b)
+	case OSPFLSTypeV3IntraAreaPrefix:
+		l.IntraAreaPrefix = new(OSPFIntraAreaPrefixLSA)
+		err = l.IntraAreaPrefix.unmarshal(b)
+	}
+	return err
+}
+
+func (l OSPFLSA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		ospfLSAHeaderJSON
+		Router          *OSPFRouterLSA          `json:"router,omitempty"`
+		Network         *OSPFNetworkLSA         `json:"network,omitempty"`
+		Summary         *OSPFSummaryLSA         `json:"summary,omitempty"`
+		ASExternal      *OSPFASExternalLSA      `json:"as_external,omitempty"`
+		IntraAreaPrefix *OSPFIntraAreaPrefixLSA `json:"intra_area_prefix,omitempty"`
+		Body            string                  `json:"body"`
+	}{
+		ospfLSAHeaderJSON: l.OSPFLSAHeader.jsonValue(),
+		Router:            l.Router,
+		Network:           l.Network,
+		Summary:           l.Summary,
+		ASExternal:        l.ASExternal,
+		IntraAreaPrefix:   l.IntraAreaPrefix,
+		Body:              hex.EncodeToString(l.Body),
+	})
+}
+
+// ospfPrefix returns the prefix of an OSPFv2 LSA whose link state ID is the
+// address of a network with the provided mask.
+func ospfPrefix(id netip.Addr, mask []byte) netip.Prefix {
+	bits := 0
+	for _, b := range mask {
+		for ; b&0x80 != 0; b <<= 1 {
+			bits++
+		}
+	}
+	return netip.PrefixFrom(id, bits).Masked()
+}
+
+// parseOSPFv3Prefix parses a prefix of an OSPFv3 LSA, whose address is
+// padded to a multiple of 4 octets. It returns the prefix, its options, and
+// the remaining data.
+func parseOSPFv3Prefix(data []byte) (netip.Prefix, uint8, []byte, error) {
+	if len(data) < 4 {
+		return netip.Prefix{}, 0, nil, fmt.Errorf("ospf prefix too small: %w", ErrTruncated)
+	}
+	bits := int(data[0])
+	n := (bits + 31) / 32 * 4
+	if bits > 128 || 4+n > len(data) {
+		return netip.Prefix{}, 0, nil, fmt.Errorf("invalid ospf prefix length: %w", ErrInvalid)
+	}
+	var addr [16]byte
+	copy(addr[:], data[4:4+n])
+	return netip.PrefixFrom(netip.AddrFrom16(addr), bits).Masked(), data[1], data[4+n:], nil
+}
+
+// OSPFRouterLink is a link of a router LSA. LinkID and LinkData are set for
+// OSPFv2, and the interface and neighbor fields for OSPFv3.
+type OSPFRouterLink struct {
+	LinkType            uint8
+	Metric              uint16
+	LinkID              netip.Addr
+	LinkData            netip.Addr
+	InterfaceID         uint32
+	NeighborInterfaceID uint32
+	NeighborRouterID    netip.Addr
+}
+
+// OSPFRouterLSA is the body of a router LSA. Type of service metrics of
+// OSPFv2 links are skipped.
+type OSPFRouterLSA struct {
+	// Flags contains the V, E and B bits.
+	Flags uint8
+	// Options is set for OSPFv3.
+	Options uint32
+	Links   []OSPFRouterLink
+}
+
+func (r *OSPFRouterLSA) unmarshal(data []byte, version uint8) error {
+	if len(data) < 4 {
+		return fmt.Errorf("ospf router lsa too small: %w", ErrTruncated)
+	}
+	r.Flags = data[0]
+	r.Links = []OSPFRouterLink{}
+	if version == 3 {
+		r.Options = binary.BigEndian.Uint32(data[0:4]) & 0xFFFFFF
+		data = data[4:]
+		if len(data)%16 != 0 {
+			return fmt.Errorf("invalid ospf router lsa length: %w", ErrInvalid)
+		}
+		for ; len(data) > 0; data = data[16:] {
+			r.Links = append(r.Links, OSPFRouterLink{
+				LinkType:            data[0],
+				Metric:              binary.BigEndian.Uint16(data[2:4]),
+				InterfaceID:         binary.BigEndian.Uint32(data[4:8]),
+				NeighborInterfaceID: binary.BigEndian.Uint32(data[8:12]),
+				NeighborRouterID:    netip.AddrFrom4(*(*[4]byte)(data[12:16])),
+			})
+		}
+		return nil
+	}
+	n := int(binary.BigEndian.Uint16(data[2:4]))
+	data = data[4:]
+	for i := 0; i < n; i++ {
+		if len(data) < 12 || 12+4*int(data[9]) > len(data) {
+			return fmt.Errorf("invalid ospf router link length: %w", ErrInvalid)
+		}
+		r.Links = append(r.Links, OSPFRouterLink{
+			LinkID:   netip.AddrFrom4(*(*[4]byte)(data[0:4])),
+			LinkData: netip.AddrFrom4(*(*[4]byte)(data[4:8])),
+			LinkType: data[8],
+			Metric:   binary.BigEndian.Uint16(data[10:12]),
+		})
+		data = data[12+4*int(data[9]):]
+	}
+	return nil
+}
+
+func (r OSPFRouterLSA) MarshalJSON() ([]byte, error) {
+	type link struct {
+		LinkType            uint8       `json:"link_type"`
+		Metric              uint16      `json:"metric"`
+		LinkID              *netip.Addr `json:"link_id,omitempty"`
+		LinkData            *netip.Addr `json:"link_data,omitempty"`
+		InterfaceID         *uint32     `json:"interface_id,omitempty"`
+		NeighborInterfaceID *uint32     `json:"neighbor_interface_id,omitempty"`
+		NeighborRouterID    *netip.Addr `json:"neighbor_router_id,omitempty"`
+	}
+	links := make([]link, len(r.Links))
+	for i := range r.Links {
+		l := &r.Links[i]
+		links[i] = link{LinkType: l.LinkType, Metric: l.Metric}
+		if l.LinkID.IsValid() {
+			links[i].LinkID, links[i].LinkData = &l.LinkID, &l.LinkData
+		} else {
+			links[i].InterfaceID = &l.InterfaceID
+			links[i].NeighborInterfaceID = &l.NeighborInterfaceID
+			links[i].NeighborRouterID = &l.NeighborRouterID
+		}
+	}
+	return json.Marshal(struct {
+		Flags   uint8  `json:"flags"`
+		Options uint32 `json:"options"`
+		Links   []link `json:"links"`
+	}{r.Flags, r.Options, links})
+}
+
+// OSPFNetworkLSA is the body of a network LSA. NetworkMask is set for OSPFv2,
+// and Options for OSPFv3.
+type OSPFNetworkLSA struct {
+	NetworkMask     netip.Addr
+	Options         uint32
+	AttachedRouters []netip.Addr
+}
+
+func (n OSPFNetworkLSA) MarshalJSON() ([]byte, error) {
+	v := struct {
+		NetworkMask     *netip.Addr  `json:"network_mask,omitempty"`
+		Options         uint32       `json:"options"`
+		AttachedRouters []netip.Addr `json:"attached_routers"`
+	}{Options: n.Options, AttachedRouters: n.AttachedRouters}
+	if n.NetworkMask.IsValid() {
+		v.NetworkMask = &n.NetworkMask
+	}
+	if v.AttachedRouters == nil {
+		v.AttachedRouters = []netip.Addr{}
+	}
+	return json.Marshal(v)
+}
+
+// OSPFSummaryLSA is the body of an OSPFv2 summary LSA, or of an OSPFv3
+// inter-area-prefix LSA. The prefix of ASBR summary LSAs is the host route of
+// the AS boundary router.
+type OSPFSummaryLSA struct {
+	Prefix netip.Prefix
+	Metric uint32
+	// PrefixOptions is set for OSPFv3.
+	PrefixOptions uint8
+}
+
+func (s OSPFSummaryLSA) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Prefix        netip.Prefix `json:"prefix"`
+		Metric        uint32       `json:"metric"`
+		PrefixOptions uint8        `json:"prefix_options"`
+	}{s.Prefix, s.Metric, s.PrefixOptions})
+}
+
+// OSPFASExternalLSA is the body of an AS-external or NSSA LSA.
+type OSPFASExternalLSA struct {
+	Prefix netip.Prefix
+	// ExternalType2 is set if the metric is a type 2 external metric.
+	ExternalType2     bool
+	Metric            uint32
+	ForwardingAddress netip.Addr
+	RouteTag          uint32
+	// PrefixOptions is set for OSPFv3.
+	PrefixOptions uint8
+}
+
+func (e *OSPFASExternalLSA) unmarshalV3(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("ospf as-external lsa too small: %w", ErrTruncated)
+	}
+	flags := data[0]
+	e.ExternalType2 = flags&0x04 != 0
+	e.Metric = binary.BigEndian.Uint32(data[0:4]) & 0xFFFFFF
+	// The referenced LS type takes the place of the metric of other
+	// prefixes
+	refType := binary.BigEndian.Uint16(data[6:8])
+	var err error
+	e.Prefix, e.PrefixOptions, data, err = parseOSPFv3Prefix(data[4:])
+	if err != nil {
+		return err
+	}
+	if flags&0x02 != 0 {
+		if len(data) < 16 {
+			return fmt.Errorf("ospf as-external lsa too small: %w", ErrTruncated)
+		}
+		e.ForwardingAddress = netip.AddrFrom16(*(*[16]byte)(data[0:16]))
+		data = data[16:]
+	}
+	if flags&0x01 != 0 {
+		if len(data) < 4 {
+			return fmt.Errorf("ospf as-external lsa too small: %w", ErrTruncated)
+		}
+		e.RouteTag = binary.BigEndian.Uint32(data[0:4])
+		data = data[4:]
+	}
+	if refType != 0 && len(data) < 4 {
+		return fmt.Errorf("ospf as-external lsa too small: %w", ErrTruncated)
+	}
+	return nil
+}
+
+func (e OSPFASExternalLSA) MarshalJSON() ([]byte, error) {
+	v := struct {
+		Prefix            netip.Prefix `json:"prefix"`
+		ExternalType2     bool         `json:"external_type2"`
+		Metric            uint32       `json:"metric"`
+		ForwardingAddress *netip.Addr  `json:"forwarding_address,omitempty"`
+		RouteTag          uint32       `json:"route_tag"`
+		PrefixOptions     uint8        `json:"prefix_options"`
+	}{
+		Prefix:        e.Prefix,
+		ExternalType2: e.ExternalType2,
+		Metric:        e.Metric,
+		RouteTag:      e.RouteTag,
+		PrefixOptions: e.PrefixOptions,
+	}
+	if e.ForwardingAddress.IsValid() {
+		v.ForwardingAddress = &e.ForwardingAddress
+	}
+	return json.Marshal(v)
+}
+
+// OSPFPrefix is a prefix of an OSPFv3 intra-area-prefix LSA.
+type OSPFPrefix struct {
+	Prefix  netip.Prefix
+	Options uint8
+	Metric  uint16
+}
+
+// OSPFIntraAreaPrefixLSA is the body of an OSPFv3 intra-area-prefix LSA, which
+// associates prefixes with a router or network LSA.
+type OSPFIntraAreaPrefixLSA struct {
+	ReferencedLSType            OSPFLSType
+	ReferencedLinkStateID       netip.Addr
+	ReferencedAdvertisingRouter netip.Addr
+	Prefixes                    []OSPFPrefix
+}
+
+func (p *OSPFIntraAreaPrefixLSA) unmarshal(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("ospf intra-area-prefix lsa too small: %w", ErrTruncated)
+	}
+	n := int(binary.BigEndian.Uint16(data[0:2]))
+	p.ReferencedLSType = OSPFLSType(binary.BigEndian.Uint16(data[2:4]))
+	p.ReferencedLinkStateID = netip.AddrFrom4(*(*[4]byte)(data[4:8]))
+	p.ReferencedAdvertisingRouter = netip.AddrFrom4(*(*[4]byte)(data[8:12]))
+	data = data[12:]
+	p.Prefixes = make([]OSPFPrefix, 0, n)
+	for i := 0; i < n; i++ {
+		if len(data) < 4 {
+			return fmt.Errorf("ospf prefix too small: %w", ErrTruncated)
+		}
+		metric := binary.BigEndian.Uint16(data[2:4])
+		pfx, opts, rest, err := parseOSPFv3Prefix(data)
+		if err != nil {
+			return err
+		}
+		p.Prefixes = append(p.Prefixes, OSPFPrefix{Prefix: pfx, Options: opts, Metric: metric})
+		data = rest
+	}
+	return nil
+}
+
+func (p OSPFIntraAreaPrefixLSA) MarshalJSON() ([]byte, error) {
+	type prefix struct {
+		Prefix  netip.Prefix `json:"prefix"`
+		Options uint8        `json:"options"`
+		Metric  uint16       `json:"metric"`
+	}
+	prefixes := make([]prefix, len(p.Prefixes))
+	for i, x := range p.Prefixes {
+		prefixes[i] = prefix(x)
+	}
+	return json.Marshal(struct {
+		ReferencedLSType            string     `json:"referenced_ls_type"`
+		ReferencedLinkStateID       netip.Addr `json:"referenced_link_state_id"`
+		ReferencedAdvertisingRouter netip.Addr `json:"referenced_advertising_router"`
+		Prefixes                    []prefix   `json:"prefixes"`
+	}{
+		ReferencedLSType:            p.ReferencedLSType.String(),
+		ReferencedLinkStateID:       p.ReferencedLinkStateID,
+		ReferencedAdvertisingRouter: p.ReferencedAdvertisingRouter,
+		Prefixes:                    prefixes,
+	})
+}
@@ -0,0 +1,131 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// ospfPacket returns an OSPF packet with the provided header and body, with
+// the length field set.
+func ospfPacket(hdr, body []byte) []byte {
+	b := append(append([]byte{}, hdr...), body...)
+	binary.BigEndian.PutUint16(b[2:4], uint16(len(b)))
+	return b
+}
+
+// lsa returns an LSA with the provided header fields and body.
+func lsa(typ []byte, id, adv string, body []byte) []byte {
+	b := append([]byte{0, 10}, typ...)
+	b = append(b, netip.MustParseAddr(id).AsSlice()...)
+	b = append(b, netip.MustParseAddr(adv).AsSlice()...)
+	b = append(b, 0x80, 0, 0, 1, 0, 0, 0, 0)
+	binary.BigEndian.PutUint16(b[18:20], uint16(20+len(body)))
+	return append(b, body...)
+}
+
+func TestOSPFv2Hello(t *testing.T) {
+	hdr := []byte{2, 1, 0, 0, 10, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	body := []byte{
+		255, 255, 255, 0, 0, 10, 0x02, 1, 0, 0, 0, 40,
+		10, 0, 0, 1, 0, 0, 0, 0,
+		10, 0, 0, 2,
+	}
+	p := decodeIPv4(t, ipv4Packet(netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("224.0.0.5"),
+		89, ospfPacket(hdr, body), -1))
+	o, ok := p.Transport.(*packet.OSPF)
+	if !ok || o.Version != 2 || o.OSPFType != packet.OSPFTypeHello || o.RouterID != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("unexpected transport %+v", p.Transport)
+	}
+	h := o.Hello
+	if h.HelloInterval != 10*time.Second || h.RouterDeadInterval != 40*time.Second || h.Priority != 1 ||
+		h.DesignatedRouter != netip.MustParseAddr("10.0.0.1") || len(h.Neighbors) != 1 {
+		t.Errorf("unexpected hello %+v", h)
+	}
+	if _, err := json.Marshal(p); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestOSPFv2LSU(t *testing.T) {
+	hdr := []byte{2, 4, 0, 0, 10, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	router := []byte{
+		0x02, 0, 0, 2,
+		10, 0, 0, 2, 10, 0, 0, 1, 1, 0, 0, 10, // point-to-point
+		192, 168, 1, 0, 255, 255, 255, 0, 3, 1, 0, 1, 0, 0, 0, 5, // stub, one tos metric
+	}
+	external := []byte{255, 255, 0, 0, 0x80, 0, 0, 20, 0, 0, 0, 0, 0, 0, 0, 7}
+	body := []byte{0, 0, 0, 2}
+	body = append(body, lsa([]byte{0x22, 1}, "10.0.0.1", "10.0.0.1", router)...)
+	body = append(body, lsa([]byte{0x22, 5}, "172.16.0.0", "10.0.0.1", external)...)
+
+	var o packet.OSPF
+	if err := o.Unmarshal(ospfPacket(hdr, body)); err != nil {
+		t.Fatalf("unmarshal failed, %v", err)
+	}
+	if len(o.LSAs) != 2 {
+		t.Fatalf("expected 2 lsas, got %d", len(o.LSAs))
+	}
+	r := o.LSAs[0]
+	if r.LSType != packet.OSPFLSTypeRouter || r.Router == nil || len(r.Router.Links) != 2 ||
+		r.Router.Links[1].LinkType != 3 || r.Router.Links[1].Metric != 1 || r.Age != 10*time.Second {
+		t.Errorf("unexpected router lsa %+v", r)
+	}
+	e := o.LSAs[1].ASExternal
+	if e == nil || e.Prefix != netip.MustParsePrefix("172.16.0.0/16") || !e.ExternalType2 ||
+		e.Metric != 20 || e.RouteTag != 7 {
+		t.Errorf("unexpected as-external lsa %+v", e)
+	}
+	if _, err := json.Marshal(o); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestOSPFv3LSU(t *testing.T) {
+	hdr := []byte{3, 4, 0, 0, 1, 1, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0}
+	router := []byte{
+		0x01, 0, 0, 0x13,
+		2, 0, 0, 10, 0, 0, 0, 5, 0, 0, 0, 7, 2, 2, 2, 2, // transit
+	}
+	prefixes := []byte{
+		0, 2, 0x20, 0x01, 0, 0, 0, 0, 1, 1, 1, 1,
+		64, 0, 0, 10, 0x20, 0x01, 0x0D, 0xB8, 0, 0, 0, 1, // 2001:db8:0:1::/64
+		128, 0x02, 0, 0, 0x20, 0x01, 0x0D, 0xB8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1,
+	}
+	external := []byte{0x05, 0, 0, 30, 48, 0, 0, 0, 0x20, 0x01, 0x0D, 0xB8, 0, 2, 0, 0, 0, 0, 0, 9}
+	body := []byte{0, 0, 0, 3}
+	body = append(body, lsa([]byte{0x20, 0x01}, "0.0.0.0", "1.1.1.1", router)...)
+	body = append(body, lsa([]byte{0x20, 0x09}, "0.0.0.1", "1.1.1.1", prefixes)...)
+	body = append(body, lsa([]byte{0x40, 0x05}, "0.0.0.2", "1.1.1.1", external)...)
+
+	var o packet.OSPF
+	if err := o.Unmarshal(ospfPacket(hdr, body)); err != nil {
+		t.Fatalf("unmarshal failed, %v", err)
+	}
+	if len(o.LSAs) != 3 {
+		t.Fatalf("expected 3 lsas, got %d", len(o.LSAs))
+	}
+	r := o.LSAs[0].Router
+	if r == nil || r.Options != 0x13 || len(r.Links) != 1 || r.Links[0].NeighborInterfaceID != 7 ||
+		r.Links[0].NeighborRouterID != netip.MustParseAddr("2.2.2.2") {
+		t.Errorf("unexpected router lsa %+v", r)
+	}
+	p := o.LSAs[1].IntraAreaPrefix
+	if p == nil || p.ReferencedLSType != packet.OSPFLSTypeV3Router || len(p.Prefixes) != 2 ||
+		p.Prefixes[0].Prefix != netip.MustParsePrefix("2001:db8:0:1::/64") || p.Prefixes[0].Metric != 10 ||
+		p.Prefixes[1].Prefix != netip.MustParsePrefix("2001:db8::1/128") {
+		t.Errorf("unexpected intra-area-prefix lsa %+v", p)
+	}
+	e := o.LSAs[2].ASExternal
+	if e == nil || !e.ExternalType2 || e.Metric != 30 || e.RouteTag != 9 ||
+		e.Prefix != netip.MustParsePrefix("2001:db8:2::/48") {
+		t.Errorf("unexpected as-external lsa %+v", e)
+	}
+	if _, err := json.Marshal(o); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
@@ -1,8 +1,11 @@
 package packet
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
+	"sync"
+
+	"github.com/sebnyberg/net/pcap"
 )
 
 // PacketBytes ensures a coherent naming scheme for packets' internal byte slice
@@ -18,52 +21,611 @@ type PacketBytes struct {
 
 // Packet represents a raw packet flowing through the network.
 type Packet struct {
+	// Radiotap and Dot11 contain the radio information and MAC header of
+	// 802.11 frames. Link is not set for such frames.
+	Radiotap *Radiotap
+	Dot11    *Dot11
+
 	// Link contains the link-layer representation of the packet.
 	Link *Ethernet
 
+	// MACsec contains the SecTAG and ICV of MACsec frames. If the frame was
+	// decrypted or is only integrity protected, the layers that follow are
+	// decoded from its user data.
+	MACsec *MACsec
+
+	// LLC and SNAP contain the Logical Link Control sublayer of 802.3 frames.
+	LLC  *LLC
+	SNAP *SNAP
+
+	// PPPoE and PPP contain the PPP over Ethernet header and the PPP frame of
+	// PPPoE packets.
+	PPPoE *PPPoE
+	PPP   *PPP
+
 	// Network contains the network-layer representation of the packet.
 	Network Layer
+
+	// AH and ESP contain the IPsec headers of the packet. If the ESP payload
+	// was decrypted, the layers that follow are decoded from the plaintext.
+	AH  *AH
+	ESP *ESP
+
+	// Transport contains the transport-layer representation of the packet.
+	Transport Layer
+
+	// Application contains the application-layer representation of the
+	// packet, if its transport payload could be decoded.
+	Application Layer
+
+	// Inner contains the packet carried by a tunnel, such as IP in IP or
+	// tunnel mode ESP.
+	Inner *Packet
+
+	// dec provides the keys of the security associations while the packet is
+	// decoded.
+	dec *Decoder
+}
+
+// MarshalJSON encodes the packet as an object with its layers in the
+// "radiotap", "dot11", "link", "macsec", "llc", "snap", "pppoe", "ppp",
+// "network", "ah", "esp", "transport", "application" and "inner" fields.
+// Layers which were not decoded are omitted.
+//
+// Layers are encoded to JSON objects with a "type" field containing the name
+// of the layer type. Addresses are encoded as strings, and enums by name.
+// Layers which do not implement json.Marshaler are encoded with their type and
+// length only.
+func (p Packet) MarshalJSON() ([]byte, error) {
+	var v struct {
+		Radiotap    any     `json:"radiotap,omitempty"`
+		Dot11       any     `json:"dot11,omitempty"`
+		Link        any     `json:"link,omitempty"`
+		MACsec      any     `json:"macsec,omitempty"`
+		LLC         any     `json:"llc,omitempty"`
+		SNAP        any     `json:"snap,omitempty"`
+		PPPoE       any     `json:"pppoe,omitempty"`
+		PPP         any     `json:"ppp,omitempty"`
+		Network     any     `json:"network,omitempty"`
+		AH          any     `json:"ah,omitempty"`
+		ESP         any     `json:"esp,omitempty"`
+		Transport   any     `json:"transport,omitempty"`
+		Application any     `json:"application,omitempty"`
+		Inner       *Packet `json:"inner,omitempty"`
+	}
+	if p.Radiotap != nil {
+		v.Radiotap = p.Radiotap
+	}
+	if p.Dot11 != nil {
+		v.Dot11 = p.Dot11
+	}
+	if p.Link != nil {
+		v.Link = p.Link
+	}
+	if p.MACsec != nil {
+		v.MACsec = p.MACsec
+	}
+	if p.LLC != nil {
+		v.LLC = p.LLC
+	}
+	if p.SNAP != nil {
+		v.SNAP = p.SNAP
+	}
+	if p.PPPoE != nil {
+		v.PPPoE = p.PPPoE
+	}
+	if p.PPP != nil {
+		v.PPP = p.PPP
+	}
+	v.Network = layerJSON(p.Network)
+	if p.AH != nil {
+		v.AH = p.AH
+	}
+	if p.ESP != nil {
+		v.ESP = p.ESP
+	}
+	v.Transport = layerJSON(p.Transport)
+	v.Application = layerJSON(p.Application)
+	v.Inner = p.Inner
+	return json.Marshal(v)
+}
+
+// layerJSON returns the value to encode for the layer l, which is l itself if
+// it implements json.Marshaler.
+func layerJSON(l Layer) any {
+	if l == nil {
+		return nil
+	}
+	if m, ok := l.(json.Marshaler); ok {
+		return m
+	}
+	return struct {
+		Type   string `json:"type"`
+		Length int    `json:"length"`
+	}{l.Type().String(), len(l.GetContents())}
+}
+
+// A Decoder decodes packets with the keys of IPsec and MACsec security
+// associations, which are used to verify and decrypt ESP packets and MACsec
+// frames. The zero value decodes packets without keys. A Decoder is safe for
+// concurrent use.
+type Decoder struct {
+	mu        sync.RWMutex
+	sas       map[uint32]SecurityAssociation
+	macsecSAs map[macsecSAKey]MACsecSA
 }
 
 // Decode copies the input bytes, and eagerly decodes the provided byte slice.
 func Decode(b []byte) (Packet, error) {
+	return new(Decoder).Decode(b)
+}
+
+// Decode copies and decodes an Ethernet frame.
+func (d *Decoder) Decode(b []byte) (p Packet, err error) {
 	// Copy input bytes
 	cpy := make([]byte, len(b))
 	copy(cpy, b)
 	b = cpy
 
-	var p Packet
+	p.dec = d
+	defer p.release()
 	eth := new(Ethernet)
-	if err := eth.Unmarshal(b); err != nil {
+	if err = eth.Unmarshal(b); err != nil {
 		return p, err
 	}
 
 	p.Link = eth
-	if err := p.decodeEthernetFrame(eth); err != nil {
+	if err = p.decodeEthernetFrame(eth); err != nil {
 		return p, err
 	}
 
 	return p, nil
 }
 
+// release drops the references to the decoder once the packet is decoded.
+func (p *Packet) release() {
+	for q := p; q != nil; q = q.Inner {
+		q.dec = nil
+	}
+}
+
+// Layers returns the decoded layers of the packet, outermost first, followed
+// by the layers of the inner packet.
+func (p Packet) Layers() []Layer {
+	var res []Layer
+	if p.Radiotap != nil {
+		res = append(res, p.Radiotap)
+	}
+	if p.Dot11 != nil {
+		res = append(res, p.Dot11)
+	}
+	if p.Link != nil {
+		res = append(res, p.Link)
+	}
+	if p.MACsec != nil {
+		res = append(res, p.MACsec)
+	}
+	if p.LLC != nil {
+		res = append(res, p.LLC)
+	}
+	if p.SNAP != nil {
+		res = append(res, p.SNAP)
+	}
+	if p.PPPoE != nil {
+		res = append(res, p.PPPoE)
+	}
+	if p.PPP != nil {
+		res = append(res, p.PPP)
+	}
+	if p.Network != nil {
+		res = append(res, p.Network)
+	}
+	if p.AH != nil {
+		res = append(res, p.AH)
+	}
+	if p.ESP != nil {
+		res = append(res, p.ESP)
+	}
+	for _, l := range []Layer{p.Transport, p.Application} {
+		if l != nil {
+			res = append(res, l)
+		}
+	}
+	if p.Inner != nil {
+		res = append(res, p.Inner.Layers()...)
+	}
+	return res
+}
+
+// DecodeLinkType copies and decodes a frame with the link-layer header type
+// of a capture file. Ethernet, 802.11 and Radiotap frames are supported.
+func DecodeLinkType(t pcap.LinkType, b []byte) (Packet, error) {
+	return new(Decoder).DecodeLinkType(t, b)
+}
+
+// DecodeLinkType copies and decodes a frame with the link-layer header type
+// of a capture file.
+func (d *Decoder) DecodeLinkType(t pcap.LinkType, b []byte) (Packet, error) {
+	switch t {
+	case pcap.LinkTypeEthernet:
+		return d.Decode(b)
+	case pcap.LinkTypeIEEE80211:
+		return d.DecodeDot11(b)
+	case pcap.LinkTypeIEEE80211Radiotap:
+		return d.DecodeRadiotap(b)
+	}
+	return Packet{}, fmt.Errorf("unsupported link type %d: %w", t, ErrUnsupported)
+}
+
+// DecodeRadiotap copies and decodes an 802.11 frame with a Radiotap header.
+func DecodeRadiotap(b []byte) (Packet, error) {
+	return new(Decoder).DecodeRadiotap(b)
+}
+
+// DecodeRadiotap copies and decodes an 802.11 frame with a Radiotap header.
+func (d *Decoder) DecodeRadiotap(b []byte) (p Packet, err error) {
+	cpy := make([]byte, len(b))
+	copy(cpy, b)
+
+	p.dec = d
+	defer p.release()
+	rt := new(Radiotap)
+	if err = rt.Unmarshal(cpy); err != nil {
+		return p, err
+	}
+	p.Radiotap = rt
+	err = p.decodeDot11(rt.Payload)
+	return p, err
+}
+
+// DecodeDot11 copies and decodes an 802.11 frame without a frame check
+// sequence.
+func DecodeDot11(b []byte) (Packet, error) {
+	return new(Decoder).DecodeDot11(b)
+}
+
+// DecodeDot11 copies and decodes an 802.11 frame without a frame check
+// sequence.
+func (d *Decoder) DecodeDot11(b []byte) (p Packet, err error) {
+	cpy := make([]byte, len(b))
+	copy(cpy, b)
+
+	p.dec = d
+	defer p.release()
+	err = p.decodeDot11(cpy)
+	return p, err
+}
+
+// decodeDot11 decodes an 802.11 frame. The body of management frames is
+// decoded into Network, and the body of data frames is an LLC frame. The
+// bodies of protected frames are encrypted and left undecoded.
+func (p *Packet) decodeDot11(data []byte) error {
+	d := new(Dot11)
+	if err := d.Unmarshal(data); err != nil {
+		return err
+	}
+	p.Dot11 = d
+	if d.Flags.Protected() {
+		return nil
+	}
+	switch {
+	case d.Dot11Type.IsManagement():
+		m := &Dot11Mgmt{Dot11Type: d.Dot11Type}
+		if err := m.Unmarshal(d.Payload); err != nil {
+			return err
+		}
+		p.Network = m
+	case d.Dot11Type.IsData() && len(d.Payload) > 0:
+		return p.decodeLLC(d.Payload)
+	}
+	return nil
+}
+
 func (p *Packet) decodeEthernetFrame(eth *Ethernet) error {
-	switch eth.EthernetType {
+	if eth.EthernetType == EthernetTypeLLC {
+		return p.decodeLLC(eth.Payload)
+	}
+	return p.decodeEtherType(eth.EthernetType, eth.Payload)
+}
+
+// decodeLLC decodes an LLC frame, and the SNAP header and payload of frames
+// which carry an EtherType. Spanning tree BPDUs are decoded into Network.
+func (p *Packet) decodeLLC(data []byte) error {
+	llc := new(LLC)
+	if err := llc.Unmarshal(data); err != nil {
+		return err
+	}
+	p.LLC = llc
+	if llc.DSAP == LLCSAPSTP && llc.SSAP == LLCSAPSTP {
+		return p.decodeBPDU(llc.Payload)
+	}
+	if llc.DSAP != LLCSAPSNAP || llc.SSAP != LLCSAPSNAP {
+		return nil
+	}
+	snap := new(SNAP)
+	if err := snap.Unmarshal(llc.Payload); err != nil {
+		return err
+	}
+	p.SNAP = snap
+	if t, ok := snap.EtherType(); ok {
+		return p.decodeEtherType(t, snap.Payload)
+	}
+	// Cisco PVST+ sends a BPDU per VLAN with its own SNAP protocol ID
+	if snap.OUI == [3]byte{0x00, 0x00, 0x0C} && snap.ProtocolID == 0x010B {
+		return p.decodeBPDU(snap.Payload)
+	}
+	return nil
+}
+
+func (p *Packet) decodeBPDU(data []byte) error {
+	bpdu := new(BPDU)
+	if err := bpdu.Unmarshal(data); err != nil {
+		return err
+	}
+	p.Network = bpdu
+	return nil
+}
+
+// decodePPP decodes a PPP frame. IP packets continue into the network layer,
+// and the negotiation and authentication protocols are decoded into Network.
+func (p *Packet) decodePPP(data []byte) error {
+	ppp := new(PPP)
+	if err := ppp.Unmarshal(data); err != nil {
+		return err
+	}
+	p.PPP = ppp
+	switch ppp.Protocol {
+	case PPPProtocolIPv4:
+		return p.decodeEtherType(EthernetTypeIPv4, ppp.Payload)
+	case PPPProtocolIPv6:
+		return p.decodeEtherType(EthernetTypeIPv6, ppp.Payload)
+	case PPPProtocolLCP, PPPProtocolIPCP, PPPProtocolIPV6CP:
+		c := &PPPControl{Protocol: ppp.Protocol}
+		if err := c.Unmarshal(ppp.Payload); err != nil {
+			return err
+		}
+		p.Network = c
+	case PPPProtocolPAP:
+		pap := new(PAP)
+		if err := pap.Unmarshal(ppp.Payload); err != nil {
+			return err
+		}
+		p.Network = pap
+	case PPPProtocolCHAP:
+		chap := new(CHAP)
+		if err := chap.Unmarshal(ppp.Payload); err != nil {
+			return err
+		}
+		p.Network = chap
+	}
+	return nil
+}
+
+func (p *Packet) decodeEtherType(t EtherType, payload []byte) error {
+	switch t {
 	case EthernetTypeARP:
 		arp := new(ARP)
-		if err := arp.Unmarshal(eth.Payload); err != nil {
+		if err := arp.Unmarshal(payload); err != nil {
 			return err
 		}
 		p.Network = arp
 	case EthernetTypeIPv4:
 		ip := new(IPv4)
-		if err := ip.Unmarshal(eth.Payload); err != nil {
+		if err := ip.Unmarshal(payload); err != nil {
 			return err
 		}
 		p.Network = ip
+		if err := p.decodeIPv4(ip); err != nil {
+			return err
+		}
 	case EthernetTypeIPv6:
-		return errors.New("IPv6 not supported")
+		ip := new(IPv6)
+		if err := ip.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Network = ip
+		if err := p.decodeIPv6(ip); err != nil {
+			return err
+		}
+	case EthernetTypePPPoEDiscovery, EthernetTypePPPoESession:
+		pppoe := new(PPPoE)
+		if err := pppoe.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.PPPoE = pppoe
+		if pppoe.Code == PPPoECodeSession {
+			return p.decodePPP(pppoe.Payload)
+		}
+	case EthernetTypeMACsec:
+		return p.decodeMACsec(payload)
+	case EthernetTypePTP:
+		ptp := new(PTP)
+		if err := ptp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Network = ptp
 	default:
-		fmt.Printf("unknown network protocol %d\n", eth.EthernetType)
+		// Other EtherTypes, such as LLDP, are not decoded, and leave
+		// Network nil
 	}
 	return nil
 }
+
+func (p *Packet) decodeIPv4(ip *IPv4) error {
+	// Only the first fragment carries the transport header
+	if ip.FragOffset != 0 {
+		return nil
+	}
+	return p.decodeIPPayload(ip.Proto, ip.Payload)
+}
+
+func (p *Packet) decodeIPv6(ip *IPv6) error {
+	if ip.FragOffset != 0 {
+		return nil
+	}
+	return p.decodeIPPayload(ip.Proto, ip.Payload)
+}
+
+// decodeIPPayload decodes the payload of an IP packet, or of an IPsec header,
+// whose protocol is proto.
+func (p *Packet) decodeIPPayload(proto IPProtocol, payload []byte) error {
+	switch proto {
+	case IPProtocolTCP:
+		tcp := new(TCP)
+		if err := tcp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = tcp
+		p.decodeTCPPayload(tcp)
+	case IPProtocolUDP:
+		udp := new(UDP)
+		if err := udp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = udp
+		p.decodeUDPPayload(udp)
+	case IPProtocolSCTP:
+		sctp := new(SCTP)
+		if err := sctp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = sctp
+	case IPProtocolICMP:
+		icmp := new(ICMP)
+		if err := icmp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = icmp
+	case IPProtocolIGMP:
+		igmp := new(IGMP)
+		if err := igmp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = igmp
+	case IPProtocolICMPv6:
+		if len(payload) > 0 {
+			switch ICMPv6Type(payload[0]) {
+			case ICMPv6TypeMLDQuery, ICMPv6TypeMLDv1Report, ICMPv6TypeMLDv1Done,
+				ICMPv6TypeMLDv2Report:
+				mld := new(MLD)
+				if err := mld.Unmarshal(payload); err != nil {
+					return err
+				}
+				p.Transport = mld
+				return nil
+			}
+		}
+		icmp := new(ICMPv6)
+		if err := icmp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = icmp
+	case IPProtocolOSPF:
+		ospf := new(OSPF)
+		if err := ospf.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Transport = ospf
+	case IPProtocolAH:
+		ah := new(AH)
+		if err := ah.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.AH = ah
+		return p.decodeIPPayload(ah.NextHeader, ah.Payload)
+	case IPProtocolESP:
+		esp := new(ESP)
+		if err := esp.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.ESP = esp
+		sa, ok := p.dec.lookupSA(esp.SPI)
+		if !ok {
+			return nil
+		}
+		if esp.Decrypt(sa) != nil {
+			// The packet is left encrypted, as without the keys
+			esp.AuthFailed = true
+			return nil
+		}
+		return p.decodeIPPayload(esp.NextHeader, esp.Payload)
+	case IPProtocolIPv4:
+		ip := new(IPv4)
+		if err := ip.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Inner = &Packet{Network: ip, dec: p.dec}
+		return p.Inner.decodeIPv4(ip)
+	case IPProtocolIPv6:
+		ip := new(IPv6)
+		if err := ip.Unmarshal(payload); err != nil {
+			return err
+		}
+		p.Inner = &Packet{Network: ip, dec: p.dec}
+		return p.Inner.decodeIPv6(ip)
+	}
+	return nil
+}
+
+// decodeTCPPayload decodes the application layer of a TCP segment. Since a
+// segment need not start at a message boundary, protocols are detected by
+// their contents, and failure to decode the payload is not an error.
+func (p *Packet) decodeTCPPayload(tcp *TCP) {
+	b := tcp.Payload
+	switch {
+	// TLS handshake record
+	case len(b) >= 5 && TLSContentType(b[0]) == TLSContentTypeHandshake && b[1] == 3:
+		tls := new(TLS)
+		if tls.Unmarshal(b) == nil {
+			p.Application = tls
+		}
+	case tcp.SourcePort == 179 || tcp.DestinationPort == 179:
+		bgp := new(BGP)
+		if bgp.Unmarshal(b) == nil {
+			p.Application = bgp
+		}
+	}
+}
+
+// decodeUDPPayload decodes the application layer of a UDP datagram, selected
+// by well-known ports. As for TCP, failure to decode the payload is not an
+// error.
+func (p *Packet) decodeUDPPayload(udp *UDP) {
+	b := udp.Payload
+	switch {
+	// QUIC packets have the fixed bit set
+	case (udp.SourcePort == 443 || udp.DestinationPort == 443) && len(b) > 0 && b[0]&0x40 != 0:
+		quic := new(QUIC)
+		if quic.Unmarshal(b) == nil {
+			p.Application = quic
+		}
+	case udp.SourcePort == 123 || udp.DestinationPort == 123:
+		ntp := new(NTP)
+		if ntp.Unmarshal(b) == nil {
+			p.Application = ntp
+		}
+	// PTP event and general messages
+	case udp.SourcePort == 319 || udp.DestinationPort == 319,
+		udp.SourcePort == 320 || udp.DestinationPort == 320:
+		ptp := new(PTP)
+		if ptp.Unmarshal(b) == nil {
+			p.Application = ptp
+		}
+	// SNMP requests and responses, and notifications
+	case udp.SourcePort == 161 || udp.DestinationPort == 161,
+		udp.SourcePort == 162 || udp.DestinationPort == 162:
+		snmp := new(SNMP)
+		if snmp.Unmarshal(b) == nil {
+			p.Application = snmp
+		}
+	// GTP-U user plane packets
+	case udp.SourcePort == 2152 || udp.DestinationPort == 2152:
+		p.decodeGTPU(b)
+	// GTP-C control plane messages, of which only GTPv2 is decoded
+	case (udp.SourcePort == 2123 || udp.DestinationPort == 2123) && len(b) > 0 && b[0]>>5 == 2:
+		gtp := new(GTPv2)
+		if gtp.Unmarshal(b) == nil {
+			p.Application = gtp
+		}
+	}
+}
@@ -0,0 +1,446 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(PPP)
+	_ Layer          = new(PPPControl)
+	_ Layer          = new(PAP)
+	_ Layer          = new(CHAP)
+	_ json.Marshaler = new(PPP)
+	_ json.Marshaler = new(PPPControl)
+	_ json.Marshaler = new(PAP)
+	_ json.Marshaler = new(CHAP)
+)
+
+type PPPProtocol uint16
+
+const (
+	PPPProtocolIPv4   PPPProtocol = 0x0021
+	PPPProtocolIPv6   PPPProtocol = 0x0057
+	PPPProtocolIPCP   PPPProtocol = 0x8021
+	PPPProtocolIPV6CP PPPProtocol = 0x8057
+	PPPProtocolLCP    PPPProtocol = 0xC021
+	PPPProtocolPAP    PPPProtocol = 0xC023
+	PPPProtocolCHAP   PPPProtocol = 0xC223
+)
+
+// PPP is the header of a PPP frame (RFC 1661). The address and control
+// fields are skipped if present, and the protocol field may be compressed to
+// one byte.
+type PPP struct {
+	Protocol PPPProtocol
+	PacketBytes
+}
+
+func (p *PPP) Unmarshal(data []byte) error {
+	hdrLen := 0
+	if len(data) >= 2 && data[0] == 0xFF && data[1] == 0x03 {
+		hdrLen = 2
+	}
+	if len(data) < hdrLen+1 {
+		return fmt.Errorf("ppp header too small: %w", ErrTruncated)
+	}
+	// Protocol numbers are odd, so an odd first byte is a compressed field
+	if data[hdrLen]&0x01 != 0 {
+		p.Protocol = PPPProtocol(data[hdrLen])
+		hdrLen++
+	} else {
+		if len(data) < hdrLen+2 {
+			return fmt.Errorf("ppp header too small: %w", ErrTruncated)
+		}
+		p.Protocol = PPPProtocol(binary.BigEndian.Uint16(data[hdrLen:]))
+		hdrLen += 2
+	}
+	p.Contents = data
+	p.Payload = data[hdrLen:]
+	return nil
+}
+
+func (p PPP) Type() LayerType {
+	return LayerTypePPP
+}
+
+func (p PPP) GetContents() []byte {
+	return p.Contents
+}
+
+func (p PPP) GetPayload() []byte {
+	return p.Payload
+}
+
+func (p PPP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string `json:"type"`
+		Protocol string `json:"protocol"`
+		Length   int    `json:"length"`
+	}{
+		Type:     p.Type().String(),
+		Protocol: p.Protocol.String(),
+		Length:   len(p.Contents),
+	})
+}
+
+type PPPControlCode uint8
+
+const (
+	PPPControlCodeConfigureRequest PPPControlCode = 1
+	PPPControlCodeConfigureAck     PPPControlCode = 2
+	PPPControlCodeConfigureNak     PPPControlCode = 3
+	PPPControlCodeConfigureReject  PPPControlCode = 4
+	PPPControlCodeTerminateRequest PPPControlCode = 5
+	PPPControlCodeTerminateAck     PPPControlCode = 6
+	PPPControlCodeCodeReject       PPPControlCode = 7
+	PPPControlCodeProtocolReject   PPPControlCode = 8
+	PPPControlCodeEchoRequest      PPPControlCode = 9
+	PPPControlCodeEchoReply        PPPControlCode = 10
+	PPPControlCodeDiscardRequest   PPPControlCode = 11
+)
+
+// PPPOption is a configuration option of a PPP control protocol. The meaning
+// of the type depends on the protocol.
+type PPPOption struct {
+	Type uint8
+	Data []byte
+}
+
+// PPPControl is a packet of the Link Control Protocol (RFC 1661), or of the
+// network control protocols IPCP (RFC 1332) and IPV6CP (RFC 5072), which share
+// its format. Options are decoded for the Configure codes.
+type PPPControl struct {
+	Protocol   PPPProtocol
+	Code       PPPControlCode
+	Identifier uint8
+	Length     uint16
+	Options    []PPPOption
+	// MagicNumber is set for LCP echo and discard packets.
+	MagicNumber uint32
+	// RejectedProtocol is set for LCP Protocol-Reject packets.
+	RejectedProtocol PPPProtocol
+	PacketBytes
+}
+
+// Unmarshal decodes a control packet. The Protocol field must be set by the
+// caller.
+func (c *PPPControl) Unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("ppp control packet too small: %w", ErrTruncated)
+	}
+	c.Code = PPPControlCode(data[0])
+	c.Identifier = data[1]
+	c.Length = binary.BigEndian.Uint16(data[2:4])
+	c.Options = c.Options[:0]
+	c.MagicNumber = 0
+	c.RejectedProtocol = 0
+	if c.Length < 4 || int(c.Length) > len(data) {
+		return fmt.Errorf("invalid ppp control packet length: %w", ErrInvalid)
+	}
+	c.Contents = data[:c.Length]
+	c.Payload = data[4:c.Length]
+
+	body := c.Payload
+	switch c.Code {
+	case PPPControlCodeConfigureRequest, PPPControlCodeConfigureAck,
+		PPPControlCodeConfigureNak, PPPControlCodeConfigureReject:
+		for len(body) > 0 {
+			if len(body) < 2 || body[1] < 2 || int(body[1]) > len(body) {
+				return fmt.Errorf("invalid ppp option length: %w", ErrInvalid)
+			}
+			c.Options = append(c.Options, PPPOption{Type: body[0], Data: body[2:body[1]]})
+			body = body[body[1]:]
+		}
+	case PPPControlCodeEchoRequest, PPPControlCodeEchoReply, PPPControlCodeDiscardRequest:
+		if len(body) < 4 {
+			return fmt.Errorf("%v packet too small: %w", c.Code, ErrTruncated)
+		}
+		c.MagicNumber = binary.BigEndian.Uint32(body[0:4])
+	case PPPControlCodeProtocolReject:
+		if len(body) < 2 {
+			return fmt.Errorf("%v packet too small: %w", c.Code, ErrTruncated)
+		}
+		c.RejectedProtocol = PPPProtocol(binary.BigEndian.Uint16(body[0:2]))
+	}
+	return nil
+}
+
+// Option returns the data of the first option of type t.
+func (c PPPControl) Option(t uint8) ([]byte, bool) {
+	for _, o := range c.Options {
+		if o.Type == t {
+			return o.Data, true
+		}
+	}
+	return nil, false
+}
+
+// MRU returns the Maximum-Receive-Unit option of an LCP packet.
+func (c PPPControl) MRU() (uint16, bool) {
+	b, ok := c.Option(1)
+	if c.Protocol != PPPProtocolLCP || !ok || len(b) != 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(b), true
+}
+
+// AuthProtocol returns the Authentication-Protocol option of an LCP packet.
+func (c PPPControl) AuthProtocol() (PPPProtocol, bool) {
+	b, ok := c.Option(3)
+	if c.Protocol != PPPProtocolLCP || !ok || len(b) < 2 {
+		return 0, false
+	}
+	return PPPProtocol(binary.BigEndian.Uint16(b)), true
+}
+
+// IPAddress returns the IP-Address option of an IPCP packet.
+func (c PPPControl) IPAddress() (netip.Addr, bool) {
+	return c.ipcpAddr(3)
+}
+
+// PrimaryDNS returns the Primary-DNS-Address option of an IPCP packet
+// (RFC 1877).
+func (c PPPControl) PrimaryDNS() (netip.Addr, bool) {
+	return c.ipcpAddr(129)
+}
+
+// SecondaryDNS returns the Secondary-DNS-Address option of an IPCP packet.
+func (c PPPControl) SecondaryDNS() (netip.Addr, bool) {
+	return c.ipcpAddr(131)
+}
+
+func (c PPPControl) ipcpAddr(t uint8) (netip.Addr, bool) {
+	b, ok := c.Option(t)
+	if c.Protocol != PPPProtocolIPCP || !ok || len(b) != 4 {
+		return netip.Addr{}, false
+	}
+	return netip.AddrFrom4(*(*[4]byte)(b)), true
+}
+
+// InterfaceID returns the Interface-Identifier option of an IPV6CP packet.
+func (c PPPControl) InterfaceID() ([8]byte, bool) {
+	var id [8]byte
+	b, ok := c.Option(1)
+	if c.Protocol != PPPProtocolIPV6CP || !ok || len(b) != 8 {
+		return id, false
+	}
+	copy(id[:], b)
+	return id, true
+}
+
+func (c PPPControl) Type() LayerType {
+	return LayerTypePPPControl
+}
+
+func (c PPPControl) GetContents() []byte {
+	return c.Contents
+}
+
+func (c PPPControl) GetPayload() []byte {
+	return c.Payload
+}
+
+func (c PPPControl) MarshalJSON() ([]byte, error) {
+	type option struct {
+		Type uint8  `json:"type"`
+		Data string `json:"data"`
+	}
+	v := struct {
+		Type             string   `json:"type"`
+		Protocol         string   `json:"protocol"`
+		Code             string   `json:"code"`
+		Identifier       uint8    `json:"identifier"`
+		Options          []option `json:"options"`
+		MagicNumber      uint32   `json:"magic_number,omitempty"`
+		RejectedProtocol string   `json:"rejected_protocol,omitempty"`
+		Length           int      `json:"length"`
+	}{
+		Type:        c.Type().String(),
+		Protocol:    c.Protocol.String(),
+		Code:        c.Code.String(),
+		Identifier:  c.Identifier,
+		Options:     make([]option, len(c.Options)),
+		MagicNumber: c.MagicNumber,
+		Length:      len(c.Contents),
+	}
+	for i, o := range c.Options {
+		v.Options[i] = option{Type: o.Type, Data: hex.EncodeToString(o.Data)}
+	}
+	if c.Code == PPPControlCodeProtocolReject {
+		v.RejectedProtocol = c.RejectedProtocol.String()
+	}
+	return json.Marshal(v)
+}
+
+type PAPCode uint8
+
+const (
+	PAPCodeAuthenticateRequest PAPCode = 1
+	PAPCodeAuthenticateAck     PAPCode = 2
+	PAPCodeAuthenticateNak     PAPCode = 3
+)
+
+// PAP is a Password Authentication Protocol packet (RFC 1334). Requests carry
+// the peer ID and password, and replies carry a message.
+type PAP struct {
+	Code       PAPCode
+	Identifier uint8
+	PeerID     string
+	Password   string
+	Message    string
+	PacketBytes
+}
+
+func (a *PAP) Unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("pap packet too small: %w", ErrTruncated)
+	}
+	a.Code = PAPCode(data[0])
+	a.Identifier = data[1]
+	l := int(binary.BigEndian.Uint16(data[2:4]))
+	if l < 4 || l > len(data) {
+		return fmt.Errorf("invalid pap packet length: %w", ErrInvalid)
+	}
+	a.Contents = data[:l]
+	a.Payload = data[4:l]
+	a.PeerID, a.Password, a.Message = "", "", ""
+
+	var fields []*string
+	switch a.Code {
+	case PAPCodeAuthenticateRequest:
+		fields = []*string{&a.PeerID, &a.Password}
+	case PAPCodeAuthenticateAck, PAPCodeAuthenticateNak:
+		fields = []*string{&a.Message}
+	default:
+		return fmt.Errorf("unknown pap code %d: %w", a.Code, ErrUnsupported)
+	}
+	b := a.Payload
+	for _, f := range fields {
+		if len(b) < 1 || 1+int(b[0]) > len(b) {
+			return fmt.Errorf("pap field exceeds packet: %w", ErrTruncated)
+		}
+		*f = string(b[1 : 1+b[0]])
+		b = b[1+b[0]:]
+	}
+	return nil
+}
+
+func (a PAP) Type() LayerType {
+	return LayerTypePAP
+}
+
+func (a PAP) GetContents() []byte {
+	return a.Contents
+}
+
+func (a PAP) GetPayload() []byte {
+	return a.Payload
+}
+
+func (a PAP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"type"`
+		Code       string `json:"code"`
+		Identifier uint8  `json:"identifier"`
+		PeerID     string `json:"peer_id,omitempty"`
+		Password   string `json:"password,omitempty"`
+		Message    string `json:"message,omitempty"`
+		Length     int    `json:"length"`
+	}{
+		Type:       a.Type().String(),
+		Code:       a.Code.String(),
+		Identifier: a.Identifier,
+		PeerID:     a.PeerID,
+		Password:   a.Password,
+		Message:    a.Message,
+		Length:     len(a.Contents),
+	})
+}
+
+type CHAPCode uint8
+
+const (
+	CHAPCodeChallenge CHAPCode = 1
+	CHAPCodeResponse  CHAPCode = 2
+	CHAPCodeSuccess   CHAPCode = 3
+	CHAPCodeFailure   CHAPCode = 4
+)
+
+// CHAP is a Challenge Handshake Authentication Protocol packet (RFC 1994).
+// Challenges and responses carry a value and the name of the sender, and
+// success and failure packets carry a message.
+type CHAP struct {
+	Code       CHAPCode
+	Identifier uint8
+	Value      []byte
+	Name       string
+	Message    string
+	PacketBytes
+}
+
+func (c *CHAP) Unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("chap packet too small: %w", ErrTruncated)
+	}
+	c.Code = CHAPCode(data[0])
+	c.Identifier = data[1]
+	l := int(binary.BigEndian.Uint16(data[2:4]))
+	if l < 4 || l > len(data) {
+		return fmt.Errorf("invalid chap packet length: %w", ErrInvalid)
+	}
+	c.Contents = data[:l]
+	c.Payload = data[4:l]
+	c.Value, c.Name, c.Message = nil, "", ""
+
+	b := c.Payload
+	switch c.Code {
+	case CHAPCodeChallenge, CHAPCodeResponse:
+		if len(b) < 1 || 1+int(b[0]) > len(b) {
+			return fmt.Errorf("chap value exceeds packet: %w", ErrTruncated)
+		}
+		c.Value = b[1 : 1+b[0]]
+		c.Name = string(b[1+b[0]:])
+	case CHAPCodeSuccess, CHAPCodeFailure:
+		c.Message = string(b)
+	default:
+		return fmt.Errorf("unknown chap code %d: %w", c.Code, ErrUnsupported)
+	}
+	return nil
+}
+
+func (c CHAP) Type() LayerType {
+	return LayerTypeCHAP
+}
+
+func (c CHAP) GetContents() []byte {
+	return c.Contents
+}
+
+func (c CHAP) GetPayload() []byte {
+	return c.Payload
+}
+
+func (c CHAP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string `json:"type"`
+		Code       string `json:"code"`
+		Identifier uint8  `json:"identifier"`
+		Value      string `json:"value,omitempty"`
+		Name       string `json:"name,omitempty"`
+		Message    string `json:"message,omitempty"`
+		Length     int    `json:"length"`
+	}{
+		Type:       c.Type().String(),
+		Code:       c.Code.String(),
+		Identifier: c.Identifier,
+		Value:      hex.EncodeToString(c.Value),
+		Name:       c.Name,
+		Message:    c.Message,
+		Length:     len(c.Contents),
+	})
+}
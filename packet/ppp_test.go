@@ -0,0 +1,151 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// pppoeFrame returns an Ethernet frame with a PPPoE packet and a minimum size
+// padding.
+func pppoeFrame(t packet.EtherType, code packet.PPPoECode, session uint16, payload []byte) []byte {
+	frame := []byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0, 0, 0x11, byte(code), 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(frame[12:14], uint16(t))
+	binary.BigEndian.PutUint16(frame[16:18], session)
+	binary.BigEndian.PutUint16(frame[18:20], uint16(len(payload)))
+	frame = append(frame, payload...)
+	for len(frame) < 60 {
+		frame = append(frame, 0)
+	}
+	return frame
+}
+
+func TestPPPoEDiscovery(t *testing.T) {
+	tags := []byte{
+		0x01, 0x01, 0x00, 0x00, // service name, any
+		0x01, 0x03, 0x00, 0x04, 0xDE, 0xAD, 0xBE, 0xEF, // host uniq
+	}
+	p, err := packet.Decode(pppoeFrame(packet.EthernetTypePPPoEDiscovery, packet.PPPoECodePADI, 0, tags))
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	if p.PPPoE == nil || p.PPPoE.Code != packet.PPPoECodePADI || len(p.PPPoE.Tags) != 2 {
+		t.Fatalf("unexpected pppoe %+v", p.PPPoE)
+	}
+	if v, ok := p.PPPoE.Tag(packet.PPPoETagTypeHostUniq); !ok || len(v) != 4 {
+		t.Errorf("unexpected host uniq %x", v)
+	}
+	if p.PPP != nil || p.Network != nil {
+		t.Errorf("discovery packet has ppp layers")
+	}
+}
+
+func TestPPPoESession(t *testing.T) {
+	ppp := func(proto packet.PPPProtocol, b []byte) []byte {
+		return append([]byte{byte(proto >> 8), byte(proto)}, b...)
+	}
+	for _, tc := range []struct {
+		name  string
+		ppp   []byte
+		check func(t *testing.T, p packet.Packet)
+	}{
+		{
+			name: "lcp",
+			ppp: ppp(packet.PPPProtocolLCP, []byte{
+				1, 1, 0, 18,
+				1, 4, 0x05, 0xD4, // mru 1492
+				3, 4, 0xC0, 0x23, // pap
+				5, 6, 0x12, 0x34, 0x56, 0x78, // magic number
+			}),
+			check: func(t *testing.T, p packet.Packet) {
+				c := p.Network.(*packet.PPPControl)
+				if c.Code != packet.PPPControlCodeConfigureRequest || len(c.Options) != 3 {
+					t.Errorf("unexpected lcp %+v", c)
+				}
+				if mru, ok := c.MRU(); !ok || mru != 1492 {
+					t.Errorf("unexpected mru %d", mru)
+				}
+				if auth, ok := c.AuthProtocol(); !ok || auth != packet.PPPProtocolPAP {
+					t.Errorf("unexpected auth protocol %v", auth)
+				}
+			},
+		},
+		{
+			name: "ipcp",
+			ppp:  ppp(packet.PPPProtocolIPCP, []byte{3, 2, 0, 10, 3, 6, 100, 64, 0, 1}),
+			check: func(t *testing.T, p packet.Packet) {
+				c := p.Network.(*packet.PPPControl)
+				if addr, ok := c.IPAddress(); !ok || addr != netip.MustParseAddr("100.64.0.1") {
+					t.Errorf("unexpected ip address %v", addr)
+				}
+			},
+		},
+		{
+			name: "pap",
+			ppp:  ppp(packet.PPPProtocolPAP, []byte{1, 3, 0, 14, 4, 'u', 's', 'e', 'r', 4, 'p', 'a', 's', 's'}),
+			check: func(t *testing.T, p packet.Packet) {
+				a := p.Network.(*packet.PAP)
+				if a.PeerID != "user" || a.Password != "pass" {
+					t.Errorf("unexpected pap %+v", a)
+				}
+			},
+		},
+		{
+			name: "chap",
+			ppp:  ppp(packet.PPPProtocolCHAP, []byte{1, 4, 0, 11, 2, 0xAB, 0xCD, 'b', 'r', 'a', 's'}),
+			check: func(t *testing.T, p packet.Packet) {
+				c := p.Network.(*packet.CHAP)
+				if c.Code != packet.CHAPCodeChallenge || len(c.Value) != 2 || c.Name != "bras" {
+					t.Errorf("unexpected chap %+v", c)
+				}
+			},
+		},
+		{
+			name: "ipv4",
+			ppp: ppp(packet.PPPProtocolIPv4, ipv4Packet(netip.MustParseAddr("100.64.0.1"),
+				netip.MustParseAddr("192.0.2.1"), 17, []byte{0, 53, 0, 53, 0, 8, 0, 0}, -1)),
+			check: func(t *testing.T, p packet.Packet) {
+				if _, ok := p.Transport.(*packet.UDP); !ok {
+					t.Errorf("expected udp, got %T", p.Transport)
+				}
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			frame := pppoeFrame(packet.EthernetTypePPPoESession, packet.PPPoECodeSession, 0x1234, tc.ppp)
+			p, err := packet.Decode(frame)
+			if err != nil {
+				t.Fatalf("decode failed, %v", err)
+			}
+			if p.PPPoE == nil || p.PPPoE.SessionID != 0x1234 || p.PPP == nil {
+				t.Fatalf("missing pppoe session layers")
+			}
+			tc.check(t, p)
+			if _, err := json.Marshal(p); err != nil {
+				t.Errorf("marshal failed, %v", err)
+			}
+		})
+	}
+}
+
+func TestPPPoELongLength(t *testing.T) {
+	// A session packet with the largest length, within a large capture
+	data := make([]byte, 70000)
+	data[0], data[1] = 0x11, byte(packet.PPPoECodeSession)
+	binary.BigEndian.PutUint16(data[4:6], 0xFFFC)
+	p := new(packet.PPPoE)
+	if err := p.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Contents) != 6+0xFFFC || len(p.Payload) != 0xFFFC {
+		t.Errorf("got %d and %d bytes", len(p.Contents), len(p.Payload))
+	}
+
+	// The length must be within the packet
+	if err := p.Unmarshal(data[:0xFFFC]); err == nil {
+		t.Error("invalid length decoded without error")
+	}
+}
@@ -0,0 +1,148 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(PPPoE)
+	_ json.Marshaler = new(PPPoE)
+)
+
+type PPPoECode uint8
+
+const (
+	PPPoECodeSession PPPoECode = 0x00
+	PPPoECodePADO    PPPoECode = 0x07
+	PPPoECodePADI    PPPoECode = 0x09
+	PPPoECodePADR    PPPoECode = 0x19
+	PPPoECodePADS    PPPoECode = 0x65
+	PPPoECodePADT    PPPoECode = 0xA7
+)
+
+type PPPoETagType uint16
+
+const (
+	PPPoETagTypeEndOfList        PPPoETagType = 0x0000
+	PPPoETagTypeServiceName      PPPoETagType = 0x0101
+	PPPoETagTypeACName           PPPoETagType = 0x0102
+	PPPoETagTypeHostUniq         PPPoETagType = 0x0103
+	PPPoETagTypeACCookie         PPPoETagType = 0x0104
+	PPPoETagTypeVendorSpecific   PPPoETagType = 0x0105
+	PPPoETagTypeRelaySessionID   PPPoETagType = 0x0110
+	PPPoETagTypePPPMaxPayload    PPPoETagType = 0x0120
+	PPPoETagTypeServiceNameError PPPoETagType = 0x0201
+	PPPoETagTypeACSystemError    PPPoETagType = 0x0202
+	PPPoETagTypeGenericError     PPPoETagType = 0x0203
+)
+
+// PPPoETag is a tag of a PPPoE discovery packet.
+type PPPoETag struct {
+	Type  PPPoETagType
+	Value []byte
+}
+
+// PPPoE is a PPP over Ethernet header (RFC 2516). Discovery packets carry
+// tags, and session packets carry a PPP frame in the payload.
+type PPPoE struct {
+	Version   uint8
+	TypeField uint8
+	Code      PPPoECode
+	SessionID uint16
+	Length    uint16
+	Tags      []PPPoETag
+	PacketBytes
+}
+
+func (p *PPPoE) Unmarshal(data []byte) error {
+	if len(data) < 6 {
+		return fmt.Errorf("pppoe header too small: %w", ErrTruncated)
+	}
+	p.Version = data[0] >> 4
+	p.TypeField = data[0] & 0x0F
+	p.Code = PPPoECode(data[1])
+	p.SessionID = binary.BigEndian.Uint16(data[2:4])
+	p.Length = binary.BigEndian.Uint16(data[4:6])
+	p.Tags = p.Tags[:0]
+	end := 6 + int(p.Length)
+	if end > len(data) {
+		return fmt.Errorf("pppoe length exceeds packet: %w", ErrTruncated)
+	}
+	// Strip padding
+	p.Contents = data[:end]
+	p.Payload = data[6:end]
+	if p.Code == PPPoECodeSession {
+		return nil
+	}
+
+	for b := p.Payload; len(b) > 0; {
+		if len(b) < 4 {
+			return fmt.Errorf("pppoe tag too small: %w", ErrTruncated)
+		}
+		t := PPPoETagType(binary.BigEndian.Uint16(b[0:2]))
+		l := int(binary.BigEndian.Uint16(b[2:4]))
+		if 4+l > len(b) {
+			return fmt.Errorf("pppoe tag exceeds packet: %w", ErrTruncated)
+		}
+		if t == PPPoETagTypeEndOfList {
+			break
+		}
+		p.Tags = append(p.Tags, PPPoETag{Type: t, Value: b[4 : 4+l]})
+		b = b[4+l:]
+	}
+	return nil
+}
+
+// Tag returns the value of the first tag of type t.
+func (p PPPoE) Tag(t PPPoETagType) ([]byte, bool) {
+	for _, tag := range p.Tags {
+		if tag.Type == t {
+			return tag.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (p PPPoE) Type() LayerType {
+	return LayerTypePPPoE
+}
+
+func (p PPPoE) GetContents() []byte {
+	return p.Contents
+}
+
+func (p PPPoE) GetPayload() []byte {
+	return p.Payload
+}
+
+func (p PPPoE) MarshalJSON() ([]byte, error) {
+	type tag struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	tags := make([]tag, len(p.Tags))
+	for i, t := range p.Tags {
+		tags[i] = tag{Type: t.Type.String(), Value: hex.EncodeToString(t.Value)}
+	}
+	return json.Marshal(struct {
+		Type      string `json:"type"`
+		Version   uint8  `json:"version"`
+		TypeField uint8  `json:"type_field"`
+		Code      string `json:"code"`
+		SessionID uint16 `json:"session_id"`
+		Tags      []tag  `json:"tags"`
+		Length    int    `json:"length"`
+	}{
+		Type:      p.Type().String(),
+		Version:   p.Version,
+		TypeField: p.TypeField,
+		Code:      p.Code.String(),
+		SessionID: p.SessionID,
+		Tags:      tags,
+		Length:    len(p.Contents),
+	})
+}
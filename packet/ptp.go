@@ -0,0 +1,290 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(PTP)
+	_ json.Marshaler = new(PTP)
+)
+
+type PTPMessageType uint8
+
+const (
+	PTPMessageTypeSync               PTPMessageType = 0x0
+	PTPMessageTypeDelayReq           PTPMessageType = 0x1
+	PTPMessageTypePdelayReq          PTPMessageType = 0x2
+	PTPMessageTypePdelayResp         PTPMessageType = 0x3
+	PTPMessageTypeFollowUp           PTPMessageType = 0x8
+	PTPMessageTypeDelayResp          PTPMessageType = 0x9
+	PTPMessageTypePdelayRespFollowUp PTPMessageType = 0xA
+	PTPMessageTypeAnnounce           PTPMessageType = 0xB
+	PTPMessageTypeSignaling          PTPMessageType = 0xC
+	PTPMessageTypeManagement         PTPMessageType = 0xD
+)
+
+// PTPFlags is the flag field of the PTP header.
+type PTPFlags uint16
+
+func (f PTPFlags) AlternateMaster() bool    { return f&0x0100 != 0 }
+func (f PTPFlags) TwoStep() bool            { return f&0x0200 != 0 }
+func (f PTPFlags) Unicast() bool            { return f&0x0400 != 0 }
+func (f PTPFlags) Leap61() bool             { return f&0x0001 != 0 }
+func (f PTPFlags) Leap59() bool             { return f&0x0002 != 0 }
+func (f PTPFlags) UTCOffsetValid() bool     { return f&0x0004 != 0 }
+func (f PTPFlags) PTPTimescale() bool       { return f&0x0008 != 0 }
+func (f PTPFlags) TimeTraceable() bool      { return f&0x0010 != 0 }
+func (f PTPFlags) FrequencyTraceable() bool { return f&0x0020 != 0 }
+
+// PTPTimestamp is a PTP timestamp, with 48 bits of seconds.
+type PTPTimestamp struct {
+	Seconds     uint64
+	Nanoseconds uint32
+}
+
+func parsePTPTimestamp(b []byte) PTPTimestamp {
+	return PTPTimestamp{
+		Seconds:     uint64(binary.BigEndian.Uint16(b[0:2]))<<32 | uint64(binary.BigEndian.Uint32(b[2:6])),
+		Nanoseconds: binary.BigEndian.Uint32(b[6:10]),
+	}
+}
+
+// Time returns the timestamp as a time. With the PTP timescale, it is TAI
+// rather than UTC.
+func (t PTPTimestamp) Time() time.Time {
+	return time.Unix(int64(t.Seconds), int64(t.Nanoseconds)).UTC()
+}
+
+// PTPPortIdentity identifies a PTP port by the clock and the port number.
+type PTPPortIdentity struct {
+	ClockIdentity [8]byte
+	PortNumber    uint16
+}
+
+func parsePTPPortIdentity(b []byte) PTPPortIdentity {
+	var id PTPPortIdentity
+	copy(id.ClockIdentity[:], b[0:8])
+	id.PortNumber = binary.BigEndian.Uint16(b[8:10])
+	return id
+}
+
+func (id PTPPortIdentity) String() string {
+	return fmt.Sprintf("%x.%d", id.ClockIdentity, id.PortNumber)
+}
+
+// PTPAnnounce is the body of an Announce message, which describes the
+// grandmaster of a PTP domain.
+type PTPAnnounce struct {
+	CurrentUTCOffset         int16
+	GrandmasterPriority1     uint8
+	GrandmasterClockClass    uint8
+	GrandmasterClockAccuracy uint8
+	GrandmasterClockVariance uint16
+	GrandmasterPriority2     uint8
+	GrandmasterIdentity      [8]byte
+	StepsRemoved             uint16
+	TimeSource               uint8
+}
+
+// PTP is an IEEE 1588-2008 (PTPv2) message. Timestamp contains the origin
+// timestamp of Sync, Delay_Req, Pdelay_Req and Announce messages, the precise
+// origin timestamp of Follow_Up messages, and the receive or response
+// timestamp of the others. RequestingPortIdentity is set for Delay_Resp,
+// Pdelay_Resp and Pdelay_Resp_Follow_Up messages.
+type PTP struct {
+	TransportSpecific uint8
+	MessageType       PTPMessageType
+	Version           uint8
+	MessageLength     uint16
+	Domain            uint8
+	Flags             PTPFlags
+	// CorrectionField is the correction in nanoseconds, multiplied by 2^16.
+	CorrectionField        int64
+	SourcePortIdentity     PTPPortIdentity
+	SequenceID             uint16
+	ControlField           uint8
+	LogMessageInterval     int8
+	Timestamp              PTPTimestamp
+	RequestingPortIdentity PTPPortIdentity
+	Announce               *PTPAnnounce
+	PacketBytes
+}
+
+func (p *PTP) Unmarshal(data []byte) error {
+	if len(data) < 34 {
+		return fmt.Errorf("ptp header too small: %w", ErrTruncated)
+	}
+	*p = PTP{}
+	p.TransportSpecific = data[0] >> 4
+	p.MessageType = PTPMessageType(data[0] & 0x0F)
+	p.Version = data[1] & 0x0F
+	if p.Version != 2 {
+		return fmt.Errorf("unsupported ptp version %d: %w", p.Version, ErrUnsupported)
+	}
+	p.MessageLength = binary.BigEndian.Uint16(data[2:4])
+	if int(p.MessageLength) < 34 || int(p.MessageLength) > len(data) {
+		return fmt.Errorf("invalid ptp message length: %w", ErrInvalid)
+	}
+	data = data[:p.MessageLength]
+	p.Domain = data[4]
+	p.Flags = PTPFlags(binary.BigEndian.Uint16(data[6:8]))
+	p.CorrectionField = int64(binary.BigEndian.Uint64(data[8:16]))
+	p.SourcePortIdentity = parsePTPPortIdentity(data[20:30])
+	p.SequenceID = binary.BigEndian.Uint16(data[30:32])
+	p.ControlField = data[32]
+	p.LogMessageInterval = int8(data[33])
+	p.Contents = data
+	p.Payload = data[34:]
+
+	body := data[34:]
+	var n int
+	switch p.MessageType {
+	case PTPMessageTypeSync, PTPMessageTypeDelayReq, PTPMessageTypeFollowUp:
+		n = 10
+	case PTPMessageTypePdelayReq:
+		n = 20
+	case PTPMessageTypeDelayResp, PTPMessageTypePdelayResp, PTPMessageTypePdelayRespFollowUp:
+		n = 20
+		if len(body) >= n {
+			p.RequestingPortIdentity = parsePTPPortIdentity(body[10:20])
+		}
+	case PTPMessageTypeAnnounce:
+		n = 30
+		if len(body) >= n {
+			a := &PTPAnnounce{
+				CurrentUTCOffset:         int16(binary.BigEndian.Uint16(body[10:12])),
+				GrandmasterPriority1:     body[13],
+				GrandmasterClockClass:    body[14],
+				GrandmasterClockAccuracy: body[15],
+				GrandmasterClockVariance: binary.BigEndian.Uint16(body[16:18]),
+				GrandmasterPriority2:     body[18],
+				StepsRemoved:             binary.BigEndian.Uint16(body[27:29]),
+				TimeSource:               body[29],
+			}
+			copy(a.GrandmasterIdentity[:], body[19:27])
+			p.Announce = a
+		}
+	default:
+		// Signaling and management messages consist of TLVs, which are left
+		// in the payload
+		return nil
+	}
+	if len(body) < n {
+		return fmt.Errorf("%v message too small: %w", p.MessageType, ErrTruncated)
+	}
+	p.Timestamp = parsePTPTimestamp(body[0:10])
+	p.Payload = body[n:]
+	return nil
+}
+
+// Correction returns the correction field as a duration, truncated to whole
+// nanoseconds.
+func (p PTP) Correction() time.Duration {
+	return time.Duration(p.CorrectionField >> 16)
+}
+
+func (p PTP) Type() LayerType {
+	return LayerTypePTP
+}
+
+func (p PTP) GetContents() []byte {
+	return p.Contents
+}
+
+func (p PTP) GetPayload() []byte {
+	return p.Payload
+}
+
+func (p PTP) MarshalJSON() ([]byte, error) {
+	type flags struct {
+		AlternateMaster    bool `json:"alternate_master"`
+		TwoStep            bool `json:"two_step"`
+		Unicast            bool `json:"unicast"`
+		Leap61             bool `json:"leap61"`
+		Leap59             bool `json:"leap59"`
+		UTCOffsetValid     bool `json:"utc_offset_valid"`
+		PTPTimescale       bool `json:"ptp_timescale"`
+		TimeTraceable      bool `json:"time_traceable"`
+		FrequencyTraceable bool `json:"frequency_traceable"`
+	}
+	type announce struct {
+		CurrentUTCOffset         int16  `json:"current_utc_offset"`
+		GrandmasterPriority1     uint8  `json:"grandmaster_priority1"`
+		GrandmasterClockClass    uint8  `json:"grandmaster_clock_class"`
+		GrandmasterClockAccuracy uint8  `json:"grandmaster_clock_accuracy"`
+		GrandmasterClockVariance uint16 `json:"grandmaster_clock_variance"`
+		GrandmasterPriority2     uint8  `json:"grandmaster_priority2"`
+		GrandmasterIdentity      string `json:"grandmaster_identity"`
+		StepsRemoved             uint16 `json:"steps_removed"`
+		TimeSource               uint8  `json:"time_source"`
+	}
+	v := struct {
+		Type                   string    `json:"type"`
+		TransportSpecific      uint8     `json:"transport_specific"`
+		MessageType            string    `json:"message_type"`
+		Version                uint8     `json:"version"`
+		MessageLength          uint16    `json:"message_length"`
+		Domain                 uint8     `json:"domain"`
+		Flags                  flags     `json:"flags"`
+		CorrectionNS           int64     `json:"correction_ns"`
+		SourcePortIdentity     string    `json:"source_port_identity"`
+		SequenceID             uint16    `json:"sequence_id"`
+		LogMessageInterval     int8      `json:"log_message_interval"`
+		Timestamp              string    `json:"timestamp,omitempty"`
+		RequestingPortIdentity string    `json:"requesting_port_identity,omitempty"`
+		Announce               *announce `json:"announce,omitempty"`
+		Length                 int       `json:"length"`
+	}{
+		Type:              p.Type().String(),
+		TransportSpecific: p.TransportSpecific,
+		MessageType:       p.MessageType.String(),
+		Version:           p.Version,
+		MessageLength:     p.MessageLength,
+		Domain:            p.Domain,
+		Flags: flags{
+			AlternateMaster:    p.Flags.AlternateMaster(),
+			TwoStep:            p.Flags.TwoStep(),
+			Unicast:            p.Flags.Unicast(),
+			Leap61:             p.Flags.Leap61(),
+			Leap59:             p.Flags.Leap59(),
+			UTCOffsetValid:     p.Flags.UTCOffsetValid(),
+			PTPTimescale:       p.Flags.PTPTimescale(),
+			TimeTraceable:      p.Flags.TimeTraceable(),
+			FrequencyTraceable: p.Flags.FrequencyTraceable(),
+		},
+		CorrectionNS:       int64(p.Correction()),
+		SourcePortIdentity: p.SourcePortIdentity.String(),
+		SequenceID:         p.SequenceID,
+		LogMessageInterval: p.LogMessageInterval,
+		Length:             len(p.Contents),
+	}
+	switch p.MessageType {
+	case PTPMessageTypeSignaling, PTPMessageTypeManagement:
+	default:
+		v.Timestamp = fmt.Sprintf("%d.%09d", p.Timestamp.Seconds, p.Timestamp.Nanoseconds)
+	}
+	switch p.MessageType {
+	case PTPMessageTypeDelayResp, PTPMessageTypePdelayResp, PTPMessageTypePdelayRespFollowUp:
+		v.RequestingPortIdentity = p.RequestingPortIdentity.String()
+	}
+	if a := p.Announce; a != nil {
+		v.Announce = &announce{
+			CurrentUTCOffset:         a.CurrentUTCOffset,
+			GrandmasterPriority1:     a.GrandmasterPriority1,
+			GrandmasterClockClass:    a.GrandmasterClockClass,
+			GrandmasterClockAccuracy: a.GrandmasterClockAccuracy,
+			GrandmasterClockVariance: a.GrandmasterClockVariance,
+			GrandmasterPriority2:     a.GrandmasterPriority2,
+			GrandmasterIdentity:      hex.EncodeToString(a.GrandmasterIdentity[:]),
+			StepsRemoved:             a.StepsRemoved,
+			TimeSource:               a.TimeSource,
+		}
+	}
+	return json.Marshal(v)
+}
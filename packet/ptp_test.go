@@ -0,0 +1,100 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// ptpHeader returns a PTPv2 message of type t with a body of n bytes.
+func ptpHeader(t packet.PTPMessageType, n int) []byte {
+	msg := make([]byte, 34+n)
+	msg[0] = byte(t)
+	msg[1] = 2
+	binary.BigEndian.PutUint16(msg[2:4], uint16(len(msg)))
+	msg[4] = 24
+	copy(msg[20:28], []byte{0x00, 0x1B, 0x19, 0xFF, 0xFE, 0x00, 0x00, 0x01})
+	binary.BigEndian.PutUint16(msg[28:30], 1)
+	binary.BigEndian.PutUint16(msg[30:32], 7)
+	return msg
+}
+
+func TestPTPAnnounceEthernet(t *testing.T) {
+	msg := ptpHeader(packet.PTPMessageTypeAnnounce, 30)
+	binary.BigEndian.PutUint16(msg[6:8], 0x000C) // utc offset valid, ptp timescale
+	body := msg[34:]
+	binary.BigEndian.PutUint16(body[10:12], 37)
+	body[13] = 128
+	body[14] = 6
+	body[15] = 0x21
+	body[18] = 128
+	copy(body[19:27], msg[20:28])
+	body[29] = 0x20 // GPS
+
+	frame := append([]byte{0x01, 0x1B, 0x19, 0, 0, 0, 2, 0, 0, 0, 0, 1, 0x88, 0xF7}, msg...)
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	ptp, ok := p.Network.(*packet.PTP)
+	if !ok {
+		t.Fatalf("expected ptp, got %T", p.Network)
+	}
+	if ptp.Domain != 24 || ptp.SequenceID != 7 || !ptp.Flags.UTCOffsetValid() || !ptp.Flags.PTPTimescale() {
+		t.Errorf("unexpected header %+v", ptp)
+	}
+	if got := ptp.SourcePortIdentity.String(); got != "001b19fffe000001.1" {
+		t.Errorf("unexpected source port identity %v", got)
+	}
+	a := ptp.Announce
+	if a == nil || a.CurrentUTCOffset != 37 || a.GrandmasterClockClass != 6 ||
+		a.GrandmasterPriority1 != 128 || a.TimeSource != 0x20 {
+		t.Errorf("unexpected announce %+v", a)
+	}
+	if _, err := json.Marshal(p); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestPTPFollowUp(t *testing.T) {
+	msg := ptpHeader(packet.PTPMessageTypeFollowUp, 10)
+	// 1.5 ns, scaled by 2^16
+	binary.BigEndian.PutUint64(msg[8:16], 3<<15)
+	copy(msg[34:44], []byte{0, 0, 0x65, 0x92, 0x00, 0x80, 0x00, 0x00, 0x00, 0x64})
+	var ptp packet.PTP
+	if err := ptp.Unmarshal(msg); err != nil {
+		t.Fatal(err)
+	}
+	if ptp.Correction() != time.Nanosecond || ptp.CorrectionField != 3<<15 {
+		t.Errorf("unexpected correction %v", ptp.Correction())
+	}
+	want := packet.PTPTimestamp{Seconds: 0x65920080, Nanoseconds: 100}
+	if ptp.Timestamp != want {
+		t.Errorf("unexpected timestamp %+v", ptp.Timestamp)
+	}
+	if err := ptp.Unmarshal(msg[:40]); err == nil {
+		t.Errorf("expected error for truncated message")
+	}
+}
+
+func TestUnknownEtherType(t *testing.T) {
+	// LLDP frames are not decoded beyond the link layer
+	frame := []byte{0x01, 0x80, 0xC2, 0, 0, 0x0E, 2, 0, 0, 0, 0, 1, 0x88, 0xCC, 0x02, 0x07, 0x04}
+	frame = append(frame, make([]byte, 43)...)
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	if p.Link.EthernetType != 0x88CC || p.Network != nil {
+		t.Errorf("unexpected packet %+v", p)
+	}
+
+	// Type values between the largest length and 0x0600 are invalid
+	frame[12], frame[13] = 0x05, 0xFF
+	if _, err := packet.Decode(frame); err == nil {
+		t.Error("invalid ether type decoded without error")
+	}
+}
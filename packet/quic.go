@@ -0,0 +1,592 @@
+package packet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(QUIC)
+	_ json.Marshaler = new(QUIC)
+)
+
+// QUICPacketType is the type of a QUIC packet. The values do not match the
+// wire encoding, which differs between QUIC versions.
+type QUICPacketType uint8
+
+const (
+	QUICPacketTypeInitial            QUICPacketType = 0
+	QUICPacketTypeZeroRTT            QUICPacketType = 1
+	QUICPacketTypeHandshake          QUICPacketType = 2
+	QUICPacketTypeRetry              QUICPacketType = 3
+	QUICPacketTypeVersionNegotiation QUICPacketType = 4
+	QUICPacketTypeOneRTT             QUICPacketType = 5
+)
+
+type QUICFrameType uint8
+
+const (
+	QUICFrameTypePadding          QUICFrameType = 0x00
+	QUICFrameTypePing             QUICFrameType = 0x01
+	QUICFrameTypeACK              QUICFrameType = 0x02
+	QUICFrameTypeACKECN           QUICFrameType = 0x03
+	QUICFrameTypeCrypto           QUICFrameType = 0x06
+	QUICFrameTypeConnectionClose  QUICFrameType = 0x1c
+	QUICFrameTypeApplicationClose QUICFrameType = 0x1d
+)
+
+const (
+	QUICVersion1       uint32 = 0x00000001
+	QUICVersion2       uint32 = 0x6b3343cf
+	QUICVersionDraft29 uint32 = 0xff00001d
+)
+
+// QUICAckRange is a range of acknowledged packet numbers.
+type QUICAckRange struct {
+	Smallest, Largest uint64
+}
+
+// QUICFrame is a frame of a decrypted QUIC packet. Only the frames which may
+// appear in Initial packets are decoded.
+type QUICFrame struct {
+	FrameType QUICFrameType
+
+	// CRYPTO frame fields
+	Offset uint64
+	Data   []byte
+
+	// ACK frame fields
+	AckDelay  uint64
+	AckRanges []QUICAckRange
+
+	// CONNECTION_CLOSE frame fields
+	ErrorCode uint64
+	Reason    string
+}
+
+// QUICPacket is a single QUIC packet. Several long header packets may be
+// coalesced into one UDP datagram.
+type QUICPacket struct {
+	PacketType QUICPacketType
+	Version    uint32
+	DestConnID []byte
+	SrcConnID  []byte
+
+	// Token is the token of Initial and Retry packets.
+	Token []byte
+	// Length is the length of the packet number and payload of Initial,
+	// 0-RTT and Handshake packets.
+	Length uint64
+
+	// SupportedVersions is set for Version Negotiation packets.
+	SupportedVersions []uint32
+	// RetryIntegrityTag is set for Retry packets.
+	RetryIntegrityTag []byte
+
+	// Header contains the header of long header packets, up to the packet
+	// number.
+	Header []byte
+	// Payload contains the protected packet number and payload. For short
+	// header packets, it also contains the destination connection ID, whose
+	// length is only known to the endpoints.
+	Payload []byte
+
+	// Decrypted is set when the packet protection of an Initial packet has
+	// been removed, in which case PacketNumber and Frames are set.
+	Decrypted    bool
+	PacketNumber uint64
+	Frames       []QUICFrame
+}
+
+// QUIC contains the QUIC packets of a UDP datagram (RFC 8999, RFC 9000).
+//
+// Client Initial packets of QUIC versions 1 and 2 are decrypted with the keys
+// derived from their destination connection ID (RFC 9001 section 5.2), and the
+// ClientHello carried in their CRYPTO frames is decoded. Server Initial
+// packets use keys derived from the client's original destination connection
+// ID, and can be decrypted with DecryptInitial.
+type QUIC struct {
+	Packets     []QUICPacket
+	ClientHello *TLSClientHello
+	PacketBytes
+}
+
+func (q *QUIC) Unmarshal(data []byte) error {
+	q.Packets = q.Packets[:0]
+	q.ClientHello = nil
+	q.Contents = data
+	q.Payload = nil
+
+	for rest := data; len(rest) > 0; {
+		// Coalesced packets may be followed by padding
+		if len(q.Packets) > 0 && rest[0] == 0 {
+			break
+		}
+		var p QUICPacket
+		var err error
+		if rest, err = p.unmarshal(rest); err != nil {
+			return err
+		}
+		q.Packets = append(q.Packets, p)
+	}
+	if len(q.Packets) == 0 {
+		return fmt.Errorf("quic datagram is empty: %w", ErrTruncated)
+	}
+
+	for i := range q.Packets {
+		p := &q.Packets[i]
+		if p.PacketType == QUICPacketTypeInitial {
+			// Failure means that the packet was sent by the server, or that
+			// the version is unknown.
+			_ = p.decryptInitial(p.DestConnID, false)
+		}
+	}
+	q.decodeClientHello()
+	return nil
+}
+
+// DecryptInitial removes the packet protection of the Initial packets in the
+// datagram, using keys derived from the original destination connection ID
+// chosen by the client. The server flag selects the keys of the sender.
+func (q *QUIC) DecryptInitial(origDestConnID []byte, server bool) error {
+	var found bool
+	for i := range q.Packets {
+		p := &q.Packets[i]
+		if p.PacketType != QUICPacketTypeInitial || p.Decrypted {
+			continue
+		}
+		found = true
+		if err := p.decryptInitial(origDestConnID, server); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errors.New("no protected quic initial packets")
+	}
+	if !server {
+		q.decodeClientHello()
+	}
+	return nil
+}
+
+// decodeClientHello assembles the CRYPTO frames of the decrypted Initial
+// packets and decodes the ClientHello, if the data starts with one which is
+// complete.
+func (q *QUIC) decodeClientHello() {
+	var frames []QUICFrame
+	for _, p := range q.Packets {
+		for _, f := range p.Frames {
+			if f.FrameType == QUICFrameTypeCrypto {
+				frames = append(frames, f)
+			}
+		}
+	}
+	// Frames may be sent out of order
+	sort.Slice(frames, func(i, j int) bool { return frames[i].Offset < frames[j].Offset })
+	var stream []byte
+	for _, f := range frames {
+		if f.Offset > uint64(len(stream)) {
+			break
+		}
+		if end := f.Offset + uint64(len(f.Data)); end > uint64(len(stream)) {
+			stream = append(stream, f.Data[uint64(len(stream))-f.Offset:]...)
+		}
+	}
+	hs := parseTLSHandshakes(nil, stream)
+	if len(hs) == 0 || hs[0].HandshakeType != TLSHandshakeTypeClientHello {
+		return
+	}
+	hello := new(TLSClientHello)
+	if hello.Unmarshal(hs[0].Body) == nil {
+		q.ClientHello = hello
+	}
+}
+
+// JA4 returns the JA4 fingerprint of the ClientHello, or an empty string if
+// the datagram does not contain a complete ClientHello.
+func (q QUIC) JA4() string {
+	if q.ClientHello == nil {
+		return ""
+	}
+	return q.ClientHello.ja4('q')
+}
+
+// quicVarint decodes a variable-length integer (RFC 9000 section 16).
+func quicVarint(data []byte) (uint64, []byte, bool) {
+	if len(data) == 0 {
+		return 0, nil, false
+	}
+	n := 1 << (data[0] >> 6)
+	if len(data) < n {
+		return 0, nil, false
+	}
+	v := uint64(data[0] & 0x3f)
+	for _, b := range data[1:n] {
+		v = v<<8 | uint64(b)
+	}
+	return v, data[n:], true
+}
+
+// unmarshal decodes the packet at the start of data and returns the data of
+// any coalesced packets.
+func (p *QUICPacket) unmarshal(data []byte) ([]byte, error) {
+	if len(data) < 1 {
+		return nil, fmt.Errorf("quic packet too small: %w", ErrTruncated)
+	}
+	if data[0]&0x80 == 0 {
+		// Short header packets extend to the end of the datagram
+		p.PacketType = QUICPacketTypeOneRTT
+		p.Payload = data[1:]
+		return nil, nil
+	}
+
+	if len(data) < 7 {
+		return nil, fmt.Errorf("quic long header too small: %w", ErrTruncated)
+	}
+	p.Version = binary.BigEndian.Uint32(data[1:5])
+	var ok bool
+	rest := data[5:]
+	if p.DestConnID, rest, ok = tlsVector(rest, 1); !ok {
+		return nil, fmt.Errorf("quic destination connection id truncated: %w", ErrTruncated)
+	}
+	if p.SrcConnID, rest, ok = tlsVector(rest, 1); !ok {
+		return nil, fmt.Errorf("quic source connection id truncated: %w", ErrTruncated)
+	}
+
+	if p.Version == 0 {
+		p.PacketType = QUICPacketTypeVersionNegotiation
+		if len(rest)%4 != 0 {
+			return nil, fmt.Errorf("invalid quic version negotiation packet: %w", ErrInvalid)
+		}
+		for ; len(rest) > 0; rest = rest[4:] {
+			p.SupportedVersions = append(p.SupportedVersions, binary.BigEndian.Uint32(rest))
+		}
+		return nil, nil
+	}
+
+	p.PacketType = quicLongPacketType(p.Version, data[0]>>4&0x03)
+	switch p.PacketType {
+	case QUICPacketTypeRetry:
+		if len(rest) < 16 {
+			return nil, fmt.Errorf("quic retry packet too small: %w", ErrTruncated)
+		}
+		p.Token = rest[:len(rest)-16]
+		p.RetryIntegrityTag = rest[len(rest)-16:]
+		return nil, nil
+	case QUICPacketTypeInitial:
+		var n uint64
+		if n, rest, ok = quicVarint(rest); !ok || uint64(len(rest)) < n {
+			return nil, fmt.Errorf("quic initial token truncated: %w", ErrTruncated)
+		}
+		p.Token, rest = rest[:n], rest[n:]
+	}
+	if p.Length, rest, ok = quicVarint(rest); !ok || uint64(len(rest)) < p.Length {
+		return nil, fmt.Errorf("quic %v packet truncated: %w", p.PacketType, ErrTruncated)
+	}
+	p.Header = data[:len(data)-len(rest)]
+	p.Payload = rest[:p.Length]
+	return rest[p.Length:], nil
+}
+
+// quicLongPacketType maps the long header packet type bits to a packet type.
+// QUIC version 2 rotates the values to prevent ossification.
+func quicLongPacketType(version uint32, bits uint8) QUICPacketType {
+	if version == QUICVersion2 {
+		bits = (bits + 3) & 0x03
+	}
+	return QUICPacketType(bits)
+}
+
+type quicInitialParams struct {
+	salt              []byte
+	keyLabel, ivLabel string
+	hpLabel           string
+}
+
+func quicInitialParamsFor(version uint32) (quicInitialParams, bool) {
+	switch version {
+	case QUICVersion1:
+		salt, _ := hex.DecodeString("38762cf7f55934b34d179ae6a4c80cadccbb7f0a")
+		return quicInitialParams{salt, "quic key", "quic iv", "quic hp"}, true
+	case QUICVersion2:
+		salt, _ := hex.DecodeString("0dede3def700a6db819381be6e269dcbf9bd2ed9")
+		return quicInitialParams{salt, "quicv2 key", "quicv2 iv", "quicv2 hp"}, true
+	case QUICVersionDraft29:
+		salt, _ := hex.DecodeString("afbfec289993d24c9e9786f19c6111e04390a899")
+		return quicInitialParams{salt, "quic key", "quic iv", "quic hp"}, true
+	}
+	return quicInitialParams{}, false
+}
+
+// hkdfExpandLabel implements HKDF-Expand-Label of TLS 1.3 with SHA-256 and an
+// empty context, for lengths of at most one hash block.
+func hkdfExpandLabel(secret []byte, label string, length int) []byte {
+	label = "tls13 " + label
+	info := []byte{byte(length >> 8), byte(length), byte(len(label))}
+	info = append(info, label...)
+	info = append(info, 0, 1)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(info)
+	return mac.Sum(nil)[:length]
+}
+
+// quicInitialKeys derives the Initial packet protection keys of the client or
+// server (RFC 9001 section 5.2).
+func quicInitialKeys(version uint32, destConnID []byte, server bool) (aead cipher.AEAD, iv []byte, hp cipher.Block, err error) {
+	params, ok := quicInitialParamsFor(version)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unsupported quic version 0x%08x: %w", version, ErrUnsupported)
+	}
+	extract := hmac.New(sha256.New, params.salt)
+	extract.Write(destConnID)
+	label := "client in"
+	if server {
+		label = "server in"
+	}
+	secret := hkdfExpandLabel(extract.Sum(nil), label, 32)
+
+	block, err := aes.NewCipher(hkdfExpandLabel(secret, params.keyLabel, 16))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if aead, err = cipher.NewGCM(block); err != nil {
+		return nil, nil, nil, err
+	}
+	if hp, err = aes.NewCipher(hkdfExpandLabel(secret, params.hpLabel, 16)); err != nil {
+		return nil, nil, nil, err
+	}
+	return aead, hkdfExpandLabel(secret, params.ivLabel, 12), hp, nil
+}
+
+// decryptInitial removes header and packet protection from an Initial packet
+// and decodes its frames. The packet number is assumed to be small enough to
+// be fully encoded, which holds for the first packets of a connection.
+func (p *QUICPacket) decryptInitial(destConnID []byte, server bool) error {
+	aead, iv, hp, err := quicInitialKeys(p.Version, destConnID, server)
+	if err != nil {
+		return err
+	}
+	payload := p.Payload
+	if len(payload) < 4+16 {
+		return fmt.Errorf("quic initial packet too small: %w", ErrTruncated)
+	}
+	var mask [16]byte
+	hp.Encrypt(mask[:], payload[4:20])
+
+	// The unprotected header is authenticated as additional data
+	hdr := append([]byte{}, p.Header...)
+	first := hdr[0] ^ mask[0]&0x0f
+	hdr[0] = first
+	pnLen := int(first&0x03) + 1
+	var pn uint64
+	for i := 0; i < pnLen; i++ {
+		b := payload[i] ^ mask[1+i]
+		hdr = append(hdr, b)
+		pn = pn<<8 | uint64(b)
+	}
+
+	nonce := make([]byte, len(iv))
+	copy(nonce, iv)
+	for i := 0; i < 8; i++ {
+		nonce[len(nonce)-1-i] ^= byte(pn >> (8 * i))
+	}
+	plain, err := aead.Open(nil, nonce, payload[pnLen:], hdr)
+	if err != nil {
+		return fmt.Errorf("quic initial packet authentication failed: %w", ErrAuthFailed)
+	}
+	frames, err := parseQUICFrames(plain)
+	if err != nil {
+		return err
+	}
+	p.Decrypted = true
+	p.PacketNumber = pn
+	p.Frames = frames
+	return nil
+}
+
+func parseQUICFrames(data []byte) ([]QUICFrame, error) {
+	var frames []QUICFrame
+	for len(data) > 0 {
+		typ, rest, ok := quicVarint(data)
+		if !ok {
+			return nil, fmt.Errorf("quic frame type truncated: %w", ErrTruncated)
+		}
+		f := QUICFrame{FrameType: QUICFrameType(typ)}
+		switch f.FrameType {
+		case QUICFrameTypePadding:
+			// Coalesce runs of padding into a single frame
+			n := 0
+			for n < len(rest) && rest[n] == 0 {
+				n++
+			}
+			f.Data = data[:1+n]
+			rest = rest[n:]
+		case QUICFrameTypePing:
+		case QUICFrameTypeACK, QUICFrameTypeACKECN:
+			var largest, count, first uint64
+			if largest, rest, ok = quicVarint(rest); !ok {
+				break
+			}
+			if f.AckDelay, rest, ok = quicVarint(rest); !ok {
+				break
+			}
+			if count, rest, ok = quicVarint(rest); !ok {
+				break
+			}
+			if first, rest, ok = quicVarint(rest); !ok || first > largest {
+				ok = false
+				break
+			}
+			smallest := largest - first
+			f.AckRanges = append(f.AckRanges, QUICAckRange{smallest, largest})
+			for i := uint64(0); i < count && ok; i++ {
+				var gap, n uint64
+				if gap, rest, ok = quicVarint(rest); !ok {
+					break
+				}
+				if n, rest, ok = quicVarint(rest); !ok {
+					break
+				}
+				if gap+2+n > smallest {
+					ok = false
+					break
+				}
+				largest = smallest - gap - 2
+				smallest = largest - n
+				f.AckRanges = append(f.AckRanges, QUICAckRange{smallest, largest})
+			}
+			if f.FrameType == QUICFrameTypeACKECN {
+				for i := 0; i < 3 && ok; i++ {
+					_, rest, ok = quicVarint(rest)
+				}
+			}
+		case QUICFrameTypeCrypto:
+			var n uint64
+			if f.Offset, rest, ok = quicVarint(rest); !ok {
+				break
+			}
+			if n, rest, ok = quicVarint(rest); !ok || uint64(len(rest)) < n {
+				ok = false
+				break
+			}
+			f.Data, rest = rest[:n], rest[n:]
+		case QUICFrameTypeConnectionClose, QUICFrameTypeApplicationClose:
+			var n uint64
+			if f.ErrorCode, rest, ok = quicVarint(rest); !ok {
+				break
+			}
+			if f.FrameType == QUICFrameTypeConnectionClose {
+				if _, rest, ok = quicVarint(rest); !ok {
+					break
+				}
+			}
+			if n, rest, ok = quicVarint(rest); !ok || uint64(len(rest)) < n {
+				ok = false
+				break
+			}
+			f.Reason, rest = string(rest[:n]), rest[n:]
+		default:
+			return nil, fmt.Errorf("unexpected quic frame type %#x: %w", typ, ErrUnsupported)
+		}
+		if !ok {
+			return nil, fmt.Errorf("quic %v frame truncated: %w", f.FrameType, ErrTruncated)
+		}
+		frames = append(frames, f)
+		data = rest
+	}
+	return frames, nil
+}
+
+func (q QUIC) Type() LayerType {
+	return LayerTypeQUIC
+}
+
+func (q QUIC) GetContents() []byte {
+	return q.Contents
+}
+
+func (q QUIC) GetPayload() []byte {
+	return q.Payload
+}
+
+func (q QUIC) MarshalJSON() ([]byte, error) {
+	type ackRange struct {
+		Smallest uint64 `json:"smallest"`
+		Largest  uint64 `json:"largest"`
+	}
+	type frame struct {
+		FrameType string     `json:"frame_type"`
+		Offset    uint64     `json:"offset,omitempty"`
+		Length    int        `json:"length"`
+		AckDelay  uint64     `json:"ack_delay,omitempty"`
+		AckRanges []ackRange `json:"ack_ranges,omitempty"`
+		ErrorCode uint64     `json:"error_code,omitempty"`
+		Reason    string     `json:"reason,omitempty"`
+	}
+	type pkt struct {
+		PacketType        string   `json:"packet_type"`
+		Version           string   `json:"version"`
+		DestConnID        string   `json:"dest_conn_id"`
+		SrcConnID         string   `json:"src_conn_id"`
+		Token             string   `json:"token"`
+		SupportedVersions []string `json:"supported_versions,omitempty"`
+		Decrypted         bool     `json:"decrypted"`
+		PacketNumber      uint64   `json:"packet_number"`
+		Frames            []frame  `json:"frames"`
+		Length            int      `json:"length"`
+	}
+	pkts := make([]pkt, len(q.Packets))
+	for i, p := range q.Packets {
+		jp := pkt{
+			PacketType:   p.PacketType.String(),
+			Version:      fmt.Sprintf("0x%08x", p.Version),
+			DestConnID:   hex.EncodeToString(p.DestConnID),
+			SrcConnID:    hex.EncodeToString(p.SrcConnID),
+			Token:        hex.EncodeToString(p.Token),
+			Decrypted:    p.Decrypted,
+			PacketNumber: p.PacketNumber,
+			Frames:       []frame{},
+			Length:       len(p.Payload),
+		}
+		for _, v := range p.SupportedVersions {
+			jp.SupportedVersions = append(jp.SupportedVersions, fmt.Sprintf("0x%08x", v))
+		}
+		for _, f := range p.Frames {
+			jf := frame{
+				FrameType: f.FrameType.String(),
+				Offset:    f.Offset,
+				Length:    len(f.Data),
+				AckDelay:  f.AckDelay,
+				ErrorCode: f.ErrorCode,
+				Reason:    f.Reason,
+			}
+			for _, r := range f.AckRanges {
+				jf.AckRanges = append(jf.AckRanges, ackRange{r.Smallest, r.Largest})
+			}
+			jp.Frames = append(jp.Frames, jf)
+		}
+		pkts[i] = jp
+	}
+	return json.Marshal(struct {
+		Type        string          `json:"type"`
+		Packets     []pkt           `json:"packets"`
+		ClientHello *TLSClientHello `json:"client_hello,omitempty"`
+		JA4         string          `json:"ja4,omitempty"`
+		Length      int             `json:"length"`
+	}{
+		Type:        q.Type().String(),
+		Packets:     pkts,
+		ClientHello: q.ClientHello,
+		JA4:         q.JA4(),
+		Length:      len(q.Contents),
+	})
+}
@@ -0,0 +1,133 @@
+package packet_test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// TestQUICInitial protects a client Initial packet with the keys given in
+// RFC 9001 appendix A.1, and checks that it is decrypted using keys derived
+// from the destination connection ID.
+func TestQUICInitial(t *testing.T) {
+	var (
+		dcid = mustHex("8394c8f03e515708")
+		key  = mustHex("1f369613dd76d5467730efcbe3b1a22d")
+		iv   = mustHex("fa044b2f42a3fd3b46fb255c")
+		hp   = mustHex("9f50449e04a0e810283a1e9933adedd2")
+	)
+	hello := clientHello(t, &tls.Config{
+		ServerName: "example.com",
+		NextProtos: []string{"h3"},
+		MinVersion: tls.VersionTLS13,
+	})[5:]
+
+	// CRYPTO frames in reverse order, followed by padding
+	half := len(hello) / 2
+	var frames []byte
+	frames = append(frames, 0x06, 0x40|byte(half>>8), byte(half))
+	frames = append(frames, 0x40|byte((len(hello)-half)>>8), byte(len(hello)-half))
+	frames = append(frames, hello[half:]...)
+	frames = append(frames, 0x06, 0x00, 0x40|byte(half>>8), byte(half))
+	frames = append(frames, hello[:half]...)
+	frames = append(frames, make([]byte, 1162-len(frames))...)
+
+	pn := []byte{0, 0, 0, 2}
+	length := len(pn) + len(frames) + 16
+	hdr := []byte{0xc3, 0, 0, 0, 1, byte(len(dcid))}
+	hdr = append(hdr, dcid...)
+	hdr = append(hdr, 0, 0, 0x40|byte(length>>8), byte(length))
+	hdr = append(hdr, pn...)
+
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCM(block)
+	nonce := append([]byte{}, iv...)
+	nonce[len(nonce)-1] ^= 2
+	pkt := aead.Seal(append([]byte{}, hdr...), nonce, frames, hdr)
+
+	hpBlock, _ := aes.NewCipher(hp)
+	var mask [16]byte
+	pnOffset := len(hdr) - len(pn)
+	hpBlock.Encrypt(mask[:], pkt[pnOffset+4:pnOffset+20])
+	pkt[0] ^= mask[0] & 0x0f
+	for i := range pn {
+		pkt[pnOffset+i] ^= mask[1+i]
+	}
+
+	udp := make([]byte, 8)
+	binary.BigEndian.PutUint16(udp[0:2], 50000)
+	binary.BigEndian.PutUint16(udp[2:4], 443)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(pkt)))
+	ip := []byte{
+		0x45, 0, 0, 0, 0, 0, 0, 0, 64, 17, 0, 0,
+		10, 0, 0, 1, 10, 0, 0, 2,
+	}
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(ip)+len(udp)+len(pkt)))
+	frame := append([]byte{
+		0x02, 0, 0, 0, 0, 0x0b, 0x02, 0, 0, 0, 0, 0x0a, 0x08, 0x00,
+	}, append(ip, append(udp, pkt...)...)...)
+
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	quic, ok := p.Application.(*packet.QUIC)
+	if !ok {
+		t.Fatalf("expected quic layer, got %T", p.Application)
+	}
+	if len(quic.Packets) != 1 {
+		t.Fatalf("got %d packets, want 1", len(quic.Packets))
+	}
+	got := quic.Packets[0]
+	if got.PacketType != packet.QUICPacketTypeInitial || got.Version != packet.QUICVersion1 {
+		t.Errorf("unexpected packet %v version %#x", got.PacketType, got.Version)
+	}
+	if !got.Decrypted || got.PacketNumber != 2 {
+		t.Fatalf("decrypted = %v, packet number = %d", got.Decrypted, got.PacketNumber)
+	}
+	if len(got.Frames) != 3 || got.Frames[2].FrameType != packet.QUICFrameTypePadding {
+		t.Errorf("unexpected frames %+v", got.Frames)
+	}
+	if quic.ClientHello == nil || quic.ClientHello.ServerName != "example.com" {
+		t.Fatalf("expected client hello for example.com")
+	}
+	if ja4 := quic.JA4(); !strings.HasPrefix(ja4, "q13d") || !strings.Contains(ja4, "h3_") {
+		t.Errorf("unexpected ja4 %q", ja4)
+	}
+}
+
+func TestQUICVersionNegotiation(t *testing.T) {
+	b := mustHex("c000000000" + "04" + "01020304" + "00" + "00000001" + "6b3343cf")
+	var q packet.QUIC
+	if err := q.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	p := q.Packets[0]
+	if p.PacketType != packet.QUICPacketTypeVersionNegotiation || len(p.SupportedVersions) != 2 ||
+		p.SupportedVersions[1] != packet.QUICVersion2 {
+		t.Errorf("unexpected packet %+v", p)
+	}
+	data, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"version":"0x00000000"`) ||
+		!strings.Contains(string(data), `"supported_versions":["0x00000001","0x6b3343cf"]`) {
+		t.Errorf("unexpected json %s", data)
+	}
+}
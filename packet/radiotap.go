@@ -0,0 +1,243 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(Radiotap)
+	_ json.Marshaler = new(Radiotap)
+)
+
+// RadiotapPresent is the first present bitmap of a Radiotap header, which
+// flags the fields of the default namespace.
+type RadiotapPresent uint32
+
+const (
+	RadiotapPresentTSFT          RadiotapPresent = 1 << 0
+	RadiotapPresentFlags         RadiotapPresent = 1 << 1
+	RadiotapPresentRate          RadiotapPresent = 1 << 2
+	RadiotapPresentChannel       RadiotapPresent = 1 << 3
+	RadiotapPresentAntennaSignal RadiotapPresent = 1 << 5
+	RadiotapPresentAntennaNoise  RadiotapPresent = 1 << 6
+	RadiotapPresentAntenna       RadiotapPresent = 1 << 11
+	RadiotapPresentRXFlags       RadiotapPresent = 1 << 14
+	RadiotapPresentMCS           RadiotapPresent = 1 << 19
+	RadiotapPresentExt           RadiotapPresent = 1 << 31
+)
+
+// radiotapFields contains the alignment and size of the fields of the
+// default namespace, indexed by their bit in the present bitmap.
+var radiotapFields = [...]struct{ align, size int }{
+	{8, 8},  // TSFT
+	{1, 1},  // Flags
+	{1, 1},  // Rate
+	{2, 4},  // Channel
+	{2, 2},  // FHSS
+	{1, 1},  // Antenna signal (dBm)
+	{1, 1},  // Antenna noise (dBm)
+	{2, 2},  // Lock quality
+	{2, 2},  // TX attenuation
+	{2, 2},  // TX attenuation (dB)
+	{1, 1},  // TX power (dBm)
+	{1, 1},  // Antenna
+	{1, 1},  // Antenna signal (dB)
+	{1, 1},  // Antenna noise (dB)
+	{2, 2},  // RX flags
+	{2, 2},  // TX flags
+	{1, 1},  // RTS retries
+	{1, 1},  // Data retries
+	{4, 8},  // XChannel
+	{1, 3},  // MCS
+	{4, 8},  // A-MPDU status
+	{2, 12}, // VHT
+	{8, 12}, // Timestamp
+	{2, 12}, // HE
+	{2, 12}, // HE-MU
+	{2, 6},  // HE-MU-other-user
+	{1, 1},  // 0-length PSDU
+	{2, 4},  // L-SIG
+}
+
+// RadiotapFlags is the flags field of a Radiotap header.
+type RadiotapFlags uint8
+
+func (f RadiotapFlags) ShortPreamble() bool { return f&0x02 != 0 }
+func (f RadiotapFlags) WEP() bool           { return f&0x04 != 0 }
+func (f RadiotapFlags) Fragmented() bool    { return f&0x08 != 0 }
+func (f RadiotapFlags) FCS() bool           { return f&0x10 != 0 }
+func (f RadiotapFlags) BadFCS() bool        { return f&0x40 != 0 }
+
+// Radiotap is a Radiotap header, which contains the radio information of
+// captured 802.11 frames. Only the fields of the default namespace which are
+// flagged in the first present bitmap are decoded.
+type Radiotap struct {
+	Version uint8
+	Length  uint16
+	Present RadiotapPresent
+	TSFT    uint64
+	Flags   RadiotapFlags
+	// Rate is the data rate in units of 500 kbps.
+	Rate             uint8
+	ChannelFrequency uint16
+	ChannelFlags     uint16
+	// AntennaSignal and AntennaNoise are in dBm.
+	AntennaSignal int8
+	AntennaNoise  int8
+	Antenna       uint8
+	RXFlags       uint16
+	MCSKnown      uint8
+	MCSFlags      uint8
+	MCSIndex      uint8
+	PacketBytes
+}
+
+func (r *Radiotap) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("radiotap header too small: %w", ErrTruncated)
+	}
+	*r = Radiotap{}
+	r.Version = data[0]
+	if r.Version != 0 {
+		return fmt.Errorf("unsupported radiotap version %d: %w", r.Version, ErrUnsupported)
+	}
+	r.Length = binary.LittleEndian.Uint16(data[2:4])
+	if r.Length < 8 || int(r.Length) > len(data) {
+		return fmt.Errorf("invalid radiotap length: %w", ErrInvalid)
+	}
+	hdr := data[:r.Length]
+	r.Present = RadiotapPresent(binary.LittleEndian.Uint32(hdr[4:8]))
+	r.Contents = hdr
+	r.Payload = data[r.Length:]
+
+	// Skip extended bitmaps
+	off := 8
+	for p := r.Present; p&RadiotapPresentExt != 0; off += 4 {
+		if off+4 > len(hdr) {
+			return fmt.Errorf("radiotap present bitmap exceeds header: %w", ErrTruncated)
+		}
+		p = RadiotapPresent(binary.LittleEndian.Uint32(hdr[off:]))
+	}
+
+	for bit := 0; bit < 29; bit++ {
+		if r.Present&(1<<bit) == 0 {
+			continue
+		}
+		if bit >= len(radiotapFields) {
+			// The size of the field is unknown, so the remaining fields
+			// cannot be located
+			break
+		}
+		f := radiotapFields[bit]
+		off = (off + f.align - 1) &^ (f.align - 1)
+		if off+f.size > len(hdr) {
+			return fmt.Errorf("radiotap field exceeds header: %w", ErrTruncated)
+		}
+		b := hdr[off : off+f.size]
+		switch RadiotapPresent(1 << bit) {
+		case RadiotapPresentTSFT:
+			r.TSFT = binary.LittleEndian.Uint64(b)
+		case RadiotapPresentFlags:
+			r.Flags = RadiotapFlags(b[0])
+		case RadiotapPresentRate:
+			r.Rate = b[0]
+		case RadiotapPresentChannel:
+			r.ChannelFrequency = binary.LittleEndian.Uint16(b[0:2])
+			r.ChannelFlags = binary.LittleEndian.Uint16(b[2:4])
+		case RadiotapPresentAntennaSignal:
+			r.AntennaSignal = int8(b[0])
+		case RadiotapPresentAntennaNoise:
+			r.AntennaNoise = int8(b[0])
+		case RadiotapPresentAntenna:
+			r.Antenna = b[0]
+		case RadiotapPresentRXFlags:
+			r.RXFlags = binary.LittleEndian.Uint16(b)
+		case RadiotapPresentMCS:
+			r.MCSKnown, r.MCSFlags, r.MCSIndex = b[0], b[1], b[2]
+		}
+		off += f.size
+	}
+
+	// The frame check sequence is not part of the 802.11 frame
+	if r.Flags.FCS() {
+		if len(r.Payload) < 4 {
+			return fmt.Errorf("radiotap frame too small for fcs: %w", ErrTruncated)
+		}
+		r.Payload = r.Payload[:len(r.Payload)-4]
+	}
+	return nil
+}
+
+func (r Radiotap) Type() LayerType {
+	return LayerTypeRadiotap
+}
+
+func (r Radiotap) GetContents() []byte {
+	return r.Contents
+}
+
+func (r Radiotap) GetPayload() []byte {
+	return r.Payload
+}
+
+func (r Radiotap) MarshalJSON() ([]byte, error) {
+	type mcs struct {
+		Known uint8 `json:"known"`
+		Flags uint8 `json:"flags"`
+		Index uint8 `json:"index"`
+	}
+	v := struct {
+		Type             string  `json:"type"`
+		Version          uint8   `json:"version"`
+		Present          uint32  `json:"present"`
+		TSFT             *uint64 `json:"tsft,omitempty"`
+		Flags            *uint8  `json:"flags,omitempty"`
+		Rate             *uint8  `json:"rate,omitempty"`
+		ChannelFrequency *uint16 `json:"channel_frequency,omitempty"`
+		ChannelFlags     *uint16 `json:"channel_flags,omitempty"`
+		AntennaSignal    *int8   `json:"antenna_signal,omitempty"`
+		AntennaNoise     *int8   `json:"antenna_noise,omitempty"`
+		Antenna          *uint8  `json:"antenna,omitempty"`
+		RXFlags          *uint16 `json:"rx_flags,omitempty"`
+		MCS              *mcs    `json:"mcs,omitempty"`
+		Length           int     `json:"length"`
+	}{
+		Type:    r.Type().String(),
+		Version: r.Version,
+		Present: uint32(r.Present),
+		Length:  len(r.Contents),
+	}
+	has := func(f RadiotapPresent) bool { return r.Present&f != 0 }
+	if has(RadiotapPresentTSFT) {
+		v.TSFT = &r.TSFT
+	}
+	if has(RadiotapPresentFlags) {
+		flags := uint8(r.Flags)
+		v.Flags = &flags
+	}
+	if has(RadiotapPresentRate) {
+		v.Rate = &r.Rate
+	}
+	if has(RadiotapPresentChannel) {
+		v.ChannelFrequency, v.ChannelFlags = &r.ChannelFrequency, &r.ChannelFlags
+	}
+	if has(RadiotapPresentAntennaSignal) {
+		v.AntennaSignal = &r.AntennaSignal
+	}
+	if has(RadiotapPresentAntennaNoise) {
+		v.AntennaNoise = &r.AntennaNoise
+	}
+	if has(RadiotapPresentAntenna) {
+		v.Antenna = &r.Antenna
+	}
+	if has(RadiotapPresentRXFlags) {
+		v.RXFlags = &r.RXFlags
+	}
+	if has(RadiotapPresentMCS) {
+		v.MCS = &mcs{Known: r.MCSKnown, Flags: r.MCSFlags, Index: r.MCSIndex}
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,358 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(RTCP)
+	_ json.Marshaler = new(RTCP)
+)
+
+type RTCPPacketType uint8
+
+const (
+	RTCPPacketTypeSR    RTCPPacketType = 200
+	RTCPPacketTypeRR    RTCPPacketType = 201
+	RTCPPacketTypeSDES  RTCPPacketType = 202
+	RTCPPacketTypeBYE   RTCPPacketType = 203
+	RTCPPacketTypeAPP   RTCPPacketType = 204
+	RTCPPacketTypeRTPFB RTCPPacketType = 205
+	RTCPPacketTypePSFB  RTCPPacketType = 206
+	RTCPPacketTypeXR    RTCPPacketType = 207
+)
+
+type RTCPSDESType uint8
+
+const (
+	RTCPSDESTypeEnd   RTCPSDESType = 0
+	RTCPSDESTypeCNAME RTCPSDESType = 1
+	RTCPSDESTypeName  RTCPSDESType = 2
+	RTCPSDESTypeEmail RTCPSDESType = 3
+	RTCPSDESTypePhone RTCPSDESType = 4
+	RTCPSDESTypeLoc   RTCPSDESType = 5
+	RTCPSDESTypeTool  RTCPSDESType = 6
+	RTCPSDESTypeNote  RTCPSDESType = 7
+	RTCPSDESTypePriv  RTCPSDESType = 8
+)
+
+// RTCPSenderInfo is the sender information of a sender report.
+type RTCPSenderInfo struct {
+	NTPTime     NTPTimestamp
+	RTPTime     uint32
+	PacketCount uint32
+	OctetCount  uint32
+}
+
+// RTCPReceptionReport is a reception report block of a sender or receiver
+// report.
+type RTCPReceptionReport struct {
+	SSRC         uint32
+	FractionLost uint8
+	// CumulativeLost is a signed 24-bit value, which is negative if
+	// duplicates were received.
+	CumulativeLost  int32
+	HighestSequence uint32
+	Jitter          uint32
+	// LastSR contains the middle 32 bits of the NTP timestamp of the last
+	// sender report, and DelaySinceLastSR is in units of 1/65536 seconds.
+	LastSR           uint32
+	DelaySinceLastSR uint32
+}
+
+// RTCPSDESItem is an item of a source description chunk.
+type RTCPSDESItem struct {
+	Type RTCPSDESType
+	Text string
+}
+
+// RTCPSDESChunk is the source description of a source.
+type RTCPSDESChunk struct {
+	Source uint32
+	Items  []RTCPSDESItem
+}
+
+// RTCPPacket is a packet of a compound RTCP packet. The fields which are set
+// depend on the packet type: SSRC is the sender of SR, RR, APP and feedback
+// packets, Sources are the sources leaving for BYE packets. Packets of other
+// types, and the profile-specific extensions of reports, are kept in Data.
+type RTCPPacket struct {
+	Padding bool
+	// Count is the number of reports, chunks or sources, or the subtype of
+	// APP and feedback packets.
+	Count      uint8
+	Type       RTCPPacketType
+	SSRC       uint32
+	SenderInfo *RTCPSenderInfo
+	Reports    []RTCPReceptionReport
+	Chunks     []RTCPSDESChunk
+	Sources    []uint32
+	Reason     string
+	// Name is the ASCII name of APP packets.
+	Name [4]byte
+	Data []byte
+}
+
+// RTCP is a compound RTCP packet (RFC 3550, section 6).
+type RTCP struct {
+	Packets []RTCPPacket
+	PacketBytes
+}
+
+func (r *RTCP) Unmarshal(data []byte) error {
+	if len(data) < 4 {
+		return fmt.Errorf("rtcp packet too small: %w", ErrTruncated)
+	}
+	*r = RTCP{Packets: r.Packets[:0]}
+	r.Contents = data
+	for b := data; len(b) > 0; {
+		if len(b) < 4 {
+			return fmt.Errorf("rtcp packet too small: %w", ErrTruncated)
+		}
+		if ver := b[0] >> 6; ver != 2 {
+			return fmt.Errorf("rtcp packets must be v2, was %v: %w", ver, ErrUnsupported)
+		}
+		n := 4 + 4*int(binary.BigEndian.Uint16(b[2:4]))
+		if n > len(b) {
+			return fmt.Errorf("rtcp packet length exceeds compound packet: %w", ErrTruncated)
+		}
+		p := RTCPPacket{
+			Padding: b[0]&0x20 != 0,
+			Count:   b[0] & 0x1F,
+			Type:    RTCPPacketType(b[1]),
+		}
+		body := b[4:n]
+		if p.Padding {
+			// Only the last packet of a compound packet may be padded
+			pad := int(b[n-1])
+			if pad == 0 || pad > len(body) {
+				return fmt.Errorf("invalid rtcp padding length %d: %w", pad, ErrInvalid)
+			}
+			body = body[:len(body)-pad]
+		}
+		if err := p.unmarshalBody(body); err != nil {
+			return fmt.Errorf("invalid rtcp %v packet: %w", p.Type, err)
+		}
+		r.Packets = append(r.Packets, p)
+		b = b[n:]
+	}
+	return nil
+}
+
+func (p *RTCPPacket) unmarshalBody(b []byte) error {
+	switch p.Type {
+	case RTCPPacketTypeSR, RTCPPacketTypeRR:
+		if len(b) < 4 {
+			return fmt.Errorf("report too small: %w", ErrTruncated)
+		}
+		p.SSRC = binary.BigEndian.Uint32(b[0:4])
+		b = b[4:]
+		if p.Type == RTCPPacketTypeSR {
+			if len(b) < 20 {
+				return fmt.Errorf("sender info too small: %w", ErrTruncated)
+			}
+			p.SenderInfo = &RTCPSenderInfo{
+				NTPTime:     NTPTimestamp(binary.BigEndian.Uint64(b[0:8])),
+				RTPTime:     binary.BigEndian.Uint32(b[8:12]),
+				PacketCount: binary.BigEndian.Uint32(b[12:16]),
+				OctetCount:  binary.BigEndian.Uint32(b[16:20]),
+			}
+			b = b[20:]
+		}
+		if 24*int(p.Count) > len(b) {
+			return fmt.Errorf("report blocks exceed packet: %w", ErrTruncated)
+		}
+		for i := 0; i < int(p.Count); i++ {
+			rb := b[24*i : 24*(i+1)]
+			// Sign extend the 24-bit cumulative loss
+			lost := int32(binary.BigEndian.Uint32(rb[4:8])<<8) >> 8
+			p.Reports = append(p.Reports, RTCPReceptionReport{
+				SSRC:             binary.BigEndian.Uint32(rb[0:4]),
+				FractionLost:     rb[4],
+				CumulativeLost:   lost,
+				HighestSequence:  binary.BigEndian.Uint32(rb[8:12]),
+				Jitter:           binary.BigEndian.Uint32(rb[12:16]),
+				LastSR:           binary.BigEndian.Uint32(rb[16:20]),
+				DelaySinceLastSR: binary.BigEndian.Uint32(rb[20:24]),
+			})
+		}
+		p.Data = b[24*int(p.Count):]
+	case RTCPPacketTypeSDES:
+		for i := 0; i < int(p.Count); i++ {
+			if len(b) < 4 {
+				return fmt.Errorf("sdes chunk too small: %w", ErrTruncated)
+			}
+			c := RTCPSDESChunk{Source: binary.BigEndian.Uint32(b[0:4])}
+			j := 4
+			for {
+				if j >= len(b) {
+					return fmt.Errorf("sdes chunk is not terminated: %w", ErrTruncated)
+				}
+				typ := RTCPSDESType(b[j])
+				if typ == RTCPSDESTypeEnd {
+					break
+				}
+				if j+2 > len(b) || j+2+int(b[j+1]) > len(b) {
+					return fmt.Errorf("sdes item exceeds chunk: %w", ErrTruncated)
+				}
+				c.Items = append(c.Items, RTCPSDESItem{Type: typ, Text: string(b[j+2 : j+2+int(b[j+1])])})
+				j += 2 + int(b[j+1])
+			}
+			// Chunks are terminated by null octets up to a 32-bit boundary
+			j = (j + 4) &^ 3
+			if j > len(b) {
+				return fmt.Errorf("sdes chunk is not terminated: %w", ErrTruncated)
+			}
+			p.Chunks = append(p.Chunks, c)
+			b = b[j:]
+		}
+	case RTCPPacketTypeBYE:
+		if 4*int(p.Count) > len(b) {
+			return fmt.Errorf("sources exceed packet: %w", ErrTruncated)
+		}
+		for i := 0; i < int(p.Count); i++ {
+			p.Sources = append(p.Sources, binary.BigEndian.Uint32(b[4*i:4*i+4]))
+		}
+		b = b[4*int(p.Count):]
+		if len(b) > 0 {
+			if 1+int(b[0]) > len(b) {
+				return fmt.Errorf("reason exceeds packet: %w", ErrTruncated)
+			}
+			p.Reason = string(b[1 : 1+int(b[0])])
+		}
+	case RTCPPacketTypeAPP:
+		if len(b) < 8 {
+			return fmt.Errorf("app packet too small: %w", ErrTruncated)
+		}
+		p.SSRC = binary.BigEndian.Uint32(b[0:4])
+		copy(p.Name[:], b[4:8])
+		p.Data = b[8:]
+	case RTCPPacketTypeRTPFB, RTCPPacketTypePSFB:
+		// The sender SSRC, followed by the media source SSRC and the
+		// feedback control information (RFC 4585)
+		if len(b) < 8 {
+			return fmt.Errorf("feedback packet too small: %w", ErrTruncated)
+		}
+		p.SSRC = binary.BigEndian.Uint32(b[0:4])
+		p.Data = b[4:]
+	default:
+		p.Data = b
+	}
+	return nil
+}
+
+// SDES returns the first item of type t of the source descriptions of the
+// compound packet, such as the CNAME of a source.
+func (r RTCP) SDES(source uint32, t RTCPSDESType) (string, bool) {
+	for _, p := range r.Packets {
+		for _, c := range p.Chunks {
+			if c.Source != source {
+				continue
+			}
+			for _, it := range c.Items {
+				if it.Type == t {
+					return it.Text, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+func (r RTCP) Type() LayerType {
+	return LayerTypeRTCP
+}
+
+func (r RTCP) GetContents() []byte {
+	return r.Contents
+}
+
+func (r RTCP) GetPayload() []byte {
+	return r.Payload
+}
+
+func (r RTCP) MarshalJSON() ([]byte, error) {
+	type senderInfo struct {
+		NTPTime     string `json:"ntp_time"`
+		RTPTime     uint32 `json:"rtp_time"`
+		PacketCount uint32 `json:"packet_count"`
+		OctetCount  uint32 `json:"octet_count"`
+	}
+	type report struct {
+		SSRC             uint32 `json:"ssrc"`
+		FractionLost     uint8  `json:"fraction_lost"`
+		CumulativeLost   int32  `json:"cumulative_lost"`
+		HighestSequence  uint32 `json:"highest_sequence"`
+		Jitter           uint32 `json:"jitter"`
+		LastSR           uint32 `json:"last_sr"`
+		DelaySinceLastSR uint32 `json:"delay_since_last_sr"`
+	}
+	type item struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	type chunk struct {
+		Source uint32 `json:"source"`
+		Items  []item `json:"items"`
+	}
+	type rtcpPacket struct {
+		Padding    bool        `json:"padding"`
+		Count      uint8       `json:"count"`
+		Type       string      `json:"type"`
+		SSRC       uint32      `json:"ssrc,omitempty"`
+		SenderInfo *senderInfo `json:"sender_info,omitempty"`
+		Reports    []report    `json:"reports,omitempty"`
+		Chunks     []chunk     `json:"chunks,omitempty"`
+		Sources    []uint32    `json:"sources,omitempty"`
+		Reason     string      `json:"reason,omitempty"`
+		Name       string      `json:"name,omitempty"`
+		Data       string      `json:"data,omitempty"`
+	}
+	v := struct {
+		Type    string       `json:"type"`
+		Packets []rtcpPacket `json:"packets"`
+		Length  int          `json:"length"`
+	}{
+		Type:    r.Type().String(),
+		Packets: make([]rtcpPacket, len(r.Packets)),
+		Length:  len(r.Contents),
+	}
+	for i, p := range r.Packets {
+		vp := rtcpPacket{
+			Padding: p.Padding,
+			Count:   p.Count,
+			Type:    p.Type.String(),
+			SSRC:    p.SSRC,
+			Sources: p.Sources,
+			Reason:  p.Reason,
+			Data:    hex.EncodeToString(p.Data),
+		}
+		if p.Type == RTCPPacketTypeAPP {
+			vp.Name = string(p.Name[:])
+		}
+		if s := p.SenderInfo; s != nil {
+			vp.SenderInfo = &senderInfo{
+				NTPTime:     ntpTimeJSON(s.NTPTime),
+				RTPTime:     s.RTPTime,
+				PacketCount: s.PacketCount,
+				OctetCount:  s.OctetCount,
+			}
+		}
+		for _, rb := range p.Reports {
+			vp.Reports = append(vp.Reports, report(rb))
+		}
+		for _, c := range p.Chunks {
+			vc := chunk{Source: c.Source, Items: make([]item, len(c.Items))}
+			for j, it := range c.Items {
+				vc.Items[j] = item{Type: it.Type.String(), Text: it.Text}
+			}
+			vp.Chunks = append(vp.Chunks, vc)
+		}
+		v.Packets[i] = vp
+	}
+	return json.Marshal(v)
+}
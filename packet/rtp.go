@@ -0,0 +1,216 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(RTP)
+	_ json.Marshaler = new(RTP)
+)
+
+// RTPExtensionElement is an element of a one-byte or two-byte header
+// extension (RFC 8285).
+type RTPExtensionElement struct {
+	ID   uint8
+	Data []byte
+}
+
+// RTP is a Real-time Transport Protocol packet (RFC 3550). The payload
+// excludes any padding.
+type RTP struct {
+	Version     uint8
+	Padding     bool
+	Extension   bool
+	Marker      bool
+	PayloadType uint8
+	Sequence    uint16
+	Timestamp   uint32
+	SSRC        uint32
+	CSRCs       []uint32
+	// ExtensionProfile and ExtensionData contain the header extension, if
+	// present. ExtensionData excludes the profile and length fields.
+	ExtensionProfile uint16
+	ExtensionData    []byte
+	// ExtensionElements contains the elements of the header extension, for
+	// the one-byte (0xBEDE) and two-byte (0x100X) profiles of RFC 8285.
+	ExtensionElements []RTPExtensionElement
+	// PaddingLen is the number of padding octets at the end of the packet.
+	PaddingLen uint8
+	PacketBytes
+}
+
+func (r *RTP) Unmarshal(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("rtp packet too small: %w", ErrTruncated)
+	}
+	if ver := data[0] >> 6; ver != 2 {
+		return fmt.Errorf("rtp packets must be v2, was %v: %w", ver, ErrUnsupported)
+	}
+	*r = RTP{CSRCs: r.CSRCs[:0], ExtensionElements: r.ExtensionElements[:0]}
+	r.Version = 2
+	r.Padding = data[0]&0x20 != 0
+	r.Extension = data[0]&0x10 != 0
+	r.Marker = data[1]&0x80 != 0
+	r.PayloadType = data[1] & 0x7F
+	r.Sequence = binary.BigEndian.Uint16(data[2:4])
+	r.Timestamp = binary.BigEndian.Uint32(data[4:8])
+	r.SSRC = binary.BigEndian.Uint32(data[8:12])
+	n := 12 + 4*int(data[0]&0x0F)
+	if n > len(data) {
+		return fmt.Errorf("rtp csrc list exceeds packet: %w", ErrTruncated)
+	}
+	for i := 12; i < n; i += 4 {
+		r.CSRCs = append(r.CSRCs, binary.BigEndian.Uint32(data[i:i+4]))
+	}
+	if r.Extension {
+		if n+4 > len(data) {
+			return fmt.Errorf("rtp header extension too small: %w", ErrTruncated)
+		}
+		r.ExtensionProfile = binary.BigEndian.Uint16(data[n : n+2])
+		end := n + 4 + 4*int(binary.BigEndian.Uint16(data[n+2:n+4]))
+		if end > len(data) {
+			return fmt.Errorf("rtp header extension exceeds packet: %w", ErrTruncated)
+		}
+		r.ExtensionData = data[n+4 : end]
+		if err := r.unmarshalElements(); err != nil {
+			return err
+		}
+		n = end
+	}
+	end := len(data)
+	if r.Padding {
+		r.PaddingLen = data[end-1]
+		if r.PaddingLen == 0 || n+int(r.PaddingLen) > end {
+			return fmt.Errorf("invalid rtp padding length %d: %w", r.PaddingLen, ErrInvalid)
+		}
+		end -= int(r.PaddingLen)
+	}
+	r.Contents = data
+	r.Payload = data[n:end]
+	return nil
+}
+
+// unmarshalElements decodes the elements of one-byte and two-byte header
+// extensions. Padding octets between elements have an ID of zero.
+func (r *RTP) unmarshalElements() error {
+	b := r.ExtensionData
+	switch {
+	case r.ExtensionProfile == 0xBEDE:
+		for len(b) > 0 {
+			id := b[0] >> 4
+			if id == 0 {
+				b = b[1:]
+				continue
+			}
+			if id == 15 {
+				// Reserved, stops processing of the extension
+				return nil
+			}
+			l := int(b[0]&0x0F) + 1
+			if 1+l > len(b) {
+				return fmt.Errorf("rtp extension element %d exceeds extension: %w", id, ErrTruncated)
+			}
+			r.ExtensionElements = append(r.ExtensionElements, RTPExtensionElement{ID: id, Data: b[1 : 1+l]})
+			b = b[1+l:]
+		}
+	case r.ExtensionProfile&0xFFF0 == 0x1000:
+		for len(b) > 0 {
+			if b[0] == 0 {
+				b = b[1:]
+				continue
+			}
+			if len(b) < 2 || 2+int(b[1]) > len(b) {
+				return fmt.Errorf("rtp extension element %d exceeds extension: %w", b[0], ErrTruncated)
+			}
+			l := int(b[1])
+			r.ExtensionElements = append(r.ExtensionElements, RTPExtensionElement{ID: b[0], Data: b[2 : 2+l]})
+			b = b[2+l:]
+		}
+	}
+	return nil
+}
+
+func (r RTP) Type() LayerType {
+	return LayerTypeRTP
+}
+
+func (r RTP) GetContents() []byte {
+	return r.Contents
+}
+
+func (r RTP) GetPayload() []byte {
+	return r.Payload
+}
+
+func (r RTP) MarshalJSON() ([]byte, error) {
+	type element struct {
+		ID   uint8  `json:"id"`
+		Data string `json:"data"`
+	}
+	v := struct {
+		Type              string    `json:"type"`
+		Version           uint8     `json:"version"`
+		Padding           bool      `json:"padding"`
+		Extension         bool      `json:"extension"`
+		Marker            bool      `json:"marker"`
+		PayloadType       uint8     `json:"payload_type"`
+		Sequence          uint16    `json:"sequence"`
+		Timestamp         uint32    `json:"timestamp"`
+		SSRC              uint32    `json:"ssrc"`
+		CSRCs             []uint32  `json:"csrcs"`
+		ExtensionProfile  uint16    `json:"extension_profile,omitempty"`
+		ExtensionData     string    `json:"extension_data,omitempty"`
+		ExtensionElements []element `json:"extension_elements,omitempty"`
+		Length            int       `json:"length"`
+	}{
+		Type:             r.Type().String(),
+		Version:          r.Version,
+		Padding:          r.Padding,
+		Extension:        r.Extension,
+		Marker:           r.Marker,
+		PayloadType:      r.PayloadType,
+		Sequence:         r.Sequence,
+		Timestamp:        r.Timestamp,
+		SSRC:             r.SSRC,
+		CSRCs:            append([]uint32{}, r.CSRCs...),
+		ExtensionProfile: r.ExtensionProfile,
+		ExtensionData:    hex.EncodeToString(r.ExtensionData),
+		Length:           len(r.Contents),
+	}
+	for _, e := range r.ExtensionElements {
+		v.ExtensionElements = append(v.ExtensionElements, element{ID: e.ID, Data: hex.EncodeToString(e.Data)})
+	}
+	return json.Marshal(v)
+}
+
+// DecodeRTP decodes the UDP payload of the packet as RTP or RTCP, and sets
+// the application layer. RTP and RTCP use dynamic ports, which are usually
+// negotiated by SDP, so they are only decoded on request. RTCP packet types
+// are the RTP payload types 72-79 with the marker bit set, which RTP avoids.
+func (p *Packet) DecodeRTP() error {
+	udp, ok := p.Transport.(*UDP)
+	if !ok {
+		return errors.New("rtp requires a udp transport layer")
+	}
+	b := udp.Payload
+	if len(b) >= 2 && b[1] >= 200 && b[1] <= 207 {
+		rtcp := new(RTCP)
+		if err := rtcp.Unmarshal(b); err != nil {
+			return err
+		}
+		p.Application = rtcp
+		return nil
+	}
+	rtp := new(RTP)
+	if err := rtp.Unmarshal(b); err != nil {
+		return err
+	}
+	p.Application = rtp
+	return nil
+}
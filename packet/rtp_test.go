@@ -0,0 +1,118 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func decodeUDP(t *testing.T, sport, dport uint16, payload []byte) packet.Packet {
+	udp := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(udp[0:2], sport)
+	binary.BigEndian.PutUint16(udp[2:4], dport)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(payload)))
+	udp = append(udp, payload...)
+	return decodeIPv4(t, ipv4Packet(netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"), 17, udp, 6))
+}
+
+func TestRTP(t *testing.T) {
+	// Padding, extension and two CSRCs, with a marker and payload type 111
+	rtp := []byte{0xB2, 0x80 | 111, 0x12, 0x34, 0, 0, 0x03, 0xE8, 0xCA, 0xFE, 0xBA, 0xBE}
+	rtp = append(rtp, 0, 0, 0, 1, 0, 0, 0, 2)
+	// One-byte header extension with an audio level and a padded element
+	rtp = append(rtp, 0xBE, 0xDE, 0, 2, 0x10, 0x85, 0, 0x31, 1, 2, 0, 0)
+	rtp = append(rtp, []byte("opus")...)
+	rtp = append(rtp, 0, 0, 3)
+
+	// RTP is only decoded on request
+	p := decodeUDP(t, 40000, 40002, rtp)
+	if p.Application != nil {
+		t.Fatalf("expected no application layer, got %T", p.Application)
+	}
+	if err := p.DecodeRTP(); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := p.Application.(*packet.RTP)
+	if !ok {
+		t.Fatalf("expected rtp, got %T", p.Application)
+	}
+	if !r.Marker || r.PayloadType != 111 || r.Sequence != 0x1234 || r.Timestamp != 1000 || r.SSRC != 0xCAFEBABE {
+		t.Errorf("unexpected header %+v", r)
+	}
+	if len(r.CSRCs) != 2 || r.CSRCs[1] != 2 {
+		t.Errorf("unexpected csrcs %v", r.CSRCs)
+	}
+	if r.ExtensionProfile != 0xBEDE || len(r.ExtensionElements) != 2 ||
+		r.ExtensionElements[0].ID != 1 || r.ExtensionElements[0].Data[0] != 0x85 ||
+		r.ExtensionElements[1].ID != 3 || len(r.ExtensionElements[1].Data) != 2 {
+		t.Errorf("unexpected extension elements %+v", r.ExtensionElements)
+	}
+	if string(r.Payload) != "opus" || r.PaddingLen != 3 {
+		t.Errorf("unexpected payload %q with %d padding octets", r.Payload, r.PaddingLen)
+	}
+}
+
+func TestRTCPCompound(t *testing.T) {
+	// Sender report with one reception report block
+	sr := []byte{0x81, 200, 0, 12, 0, 0, 0, 1}
+	sr = append(sr, 0xE9, 0x3C, 0x7F, 0x00, 0x80, 0, 0, 0) // 2024-01-01T00:00:00.5Z
+	sr = append(sr, 0, 0, 0x03, 0xE8, 0, 0, 0, 50, 0, 0, 0x1F, 0x40)
+	sr = append(sr, 0, 0, 0, 2, 0x40, 0xFF, 0xFF, 0xFE, 0, 1, 0, 10, 0, 0, 0, 20, 0, 0, 0, 0, 0, 0, 0, 0)
+	// Source description with a CNAME, padded to 32 bits
+	sdes := []byte{0x81, 202, 0, 3, 0, 0, 0, 1, 1, 5}
+	sdes = append(sdes, []byte("alice")...)
+	sdes = append(sdes, 0)
+	bye := []byte{0x81, 203, 0, 3, 0, 0, 0, 1, 4}
+	bye = append(bye, []byte("done")...)
+	bye = append(bye, 0, 0, 0)
+	compound := append(append(sr, sdes...), bye...)
+
+	p := decodeUDP(t, 40001, 40003, compound)
+	if err := p.DecodeRTP(); err != nil {
+		t.Fatal(err)
+	}
+	r, ok := p.Application.(*packet.RTCP)
+	if !ok {
+		t.Fatalf("expected rtcp, got %T", p.Application)
+	}
+	if len(r.Packets) != 3 {
+		t.Fatalf("got %d packets, want 3", len(r.Packets))
+	}
+	s := r.Packets[0]
+	if s.Type != packet.RTCPPacketTypeSR || s.SSRC != 1 || s.SenderInfo == nil ||
+		s.SenderInfo.RTPTime != 1000 || s.SenderInfo.PacketCount != 50 || s.SenderInfo.OctetCount != 8000 {
+		t.Errorf("unexpected sender report %+v", s)
+	}
+	if got := s.SenderInfo.NTPTime.Time().Format("2006-01-02T15:04:05.0"); got != "2024-01-01T00:00:00.5" {
+		t.Errorf("unexpected ntp time %v", got)
+	}
+	if len(s.Reports) != 1 || s.Reports[0].SSRC != 2 || s.Reports[0].FractionLost != 0x40 ||
+		s.Reports[0].CumulativeLost != -2 || s.Reports[0].HighestSequence != 0x1000A || s.Reports[0].Jitter != 20 {
+		t.Errorf("unexpected reception reports %+v", s.Reports)
+	}
+	if cname, ok := r.SDES(1, packet.RTCPSDESTypeCNAME); !ok || cname != "alice" {
+		t.Errorf("unexpected cname %q", cname)
+	}
+	if b := r.Packets[2]; b.Type != packet.RTCPPacketTypeBYE || len(b.Sources) != 1 || b.Reason != "done" {
+		t.Errorf("unexpected bye %+v", b)
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"type":"RTCPSDESTypeCNAME","text":"alice"`) {
+		t.Errorf("unexpected json %s", data)
+	}
+
+	// A truncated compound packet is not decoded
+	p = decodeUDP(t, 40001, 40003, compound[:len(compound)-4])
+	if err := p.DecodeRTP(); err == nil || p.Application != nil {
+		t.Errorf("expected no application layer, got %T", p.Application)
+	}
+}
@@ -0,0 +1,393 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(SCTP)
+	_ json.Marshaler = new(SCTP)
+)
+
+type SCTPChunkType uint8
+
+const (
+	SCTPChunkTypeData             SCTPChunkType = 0
+	SCTPChunkTypeInit             SCTPChunkType = 1
+	SCTPChunkTypeInitAck          SCTPChunkType = 2
+	SCTPChunkTypeSACK             SCTPChunkType = 3
+	SCTPChunkTypeHeartbeat        SCTPChunkType = 4
+	SCTPChunkTypeHeartbeatAck     SCTPChunkType = 5
+	SCTPChunkTypeAbort            SCTPChunkType = 6
+	SCTPChunkTypeShutdown         SCTPChunkType = 7
+	SCTPChunkTypeShutdownAck      SCTPChunkType = 8
+	SCTPChunkTypeError            SCTPChunkType = 9
+	SCTPChunkTypeCookieEcho       SCTPChunkType = 10
+	SCTPChunkTypeCookieAck        SCTPChunkType = 11
+	SCTPChunkTypeECNE             SCTPChunkType = 12
+	SCTPChunkTypeCWR              SCTPChunkType = 13
+	SCTPChunkTypeShutdownComplete SCTPChunkType = 14
+	SCTPChunkTypeAuth             SCTPChunkType = 15
+	SCTPChunkTypeIData            SCTPChunkType = 64
+	SCTPChunkTypeASCONFAck        SCTPChunkType = 128
+	SCTPChunkTypeReConfig         SCTPChunkType = 130
+	SCTPChunkTypePad              SCTPChunkType = 132
+	SCTPChunkTypeForwardTSN       SCTPChunkType = 192
+	SCTPChunkTypeASCONF           SCTPChunkType = 193
+)
+
+// SCTPParamStateCookie is the parameter type of the State Cookie in INIT ACK
+// chunks.
+const SCTPParamStateCookie = 7
+
+// SCTPParameter is a type-length-value parameter of a chunk. It is also used
+// for error causes, in which case Type holds the cause code.
+type SCTPParameter struct {
+	Type  uint16
+	Value []byte
+}
+
+// SCTPData contains the fields of a DATA or I-DATA chunk.
+type SCTPData struct {
+	Unordered bool
+	Beginning bool
+	Ending    bool
+	Immediate bool
+	TSN       uint32
+	StreamID  uint16
+	StreamSeq uint16
+	// MessageID is the Message Identifier of I-DATA chunks (RFC 8260).
+	MessageID uint32
+	// PPID is the Payload Protocol Identifier. For I-DATA chunks which are not
+	// the first fragment of a message, it holds the Fragment Sequence Number.
+	PPID     uint32
+	UserData []byte
+}
+
+// SCTPInit contains the fixed fields of INIT and INIT ACK chunks.
+type SCTPInit struct {
+	InitiateTag     uint32
+	AdvertisedRwnd  uint32
+	OutboundStreams uint16
+	InboundStreams  uint16
+	InitialTSN      uint32
+}
+
+// SCTPGapAckBlock is a range of received TSNs, given as offsets from the
+// cumulative TSN ack.
+type SCTPGapAckBlock struct {
+	Start, End uint16
+}
+
+// SCTPSACK contains the fields of a SACK chunk.
+type SCTPSACK struct {
+	CumulativeTSNAck uint32
+	AdvertisedRwnd   uint32
+	GapAckBlocks     []SCTPGapAckBlock
+	DuplicateTSNs    []uint32
+}
+
+// SCTPShutdown contains the fields of a SHUTDOWN chunk.
+type SCTPShutdown struct {
+	CumulativeTSNAck uint32
+}
+
+// SCTPChunk is a chunk of an SCTP packet. Value holds the chunk value without
+// its header and padding. Depending on the chunk type, the value is also
+// decoded into one of the typed fields.
+type SCTPChunk struct {
+	ChunkType SCTPChunkType
+	Flags     uint8
+	Length    uint16
+	Value     []byte
+
+	// Data is set for DATA chunks.
+	Data *SCTPData
+	// Init is set for INIT and INIT ACK chunks.
+	Init *SCTPInit
+	// SACK is set for SACK chunks.
+	SACK *SCTPSACK
+	// Shutdown is set for SHUTDOWN chunks.
+	Shutdown *SCTPShutdown
+	// Params contains the parameters of INIT, INIT ACK, HEARTBEAT and
+	// HEARTBEAT ACK chunks, and the error causes of ABORT and ERROR chunks.
+	Params []SCTPParameter
+}
+
+// SCTP is an SCTP packet (RFC 9260), consisting of a common header followed by
+// a list of chunks.
+type SCTP struct {
+	SourcePort      uint16
+	DestinationPort uint16
+	VerificationTag uint32
+	// Checksum contains the CRC32c checksum of the packet.
+	Checksum uint32
+	Chunks   []SCTPChunk
+	PacketBytes
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+func (s *SCTP) Unmarshal(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("sctp packet too small: %w", ErrTruncated)
+	}
+	s.SourcePort = binary.BigEndian.Uint16(data[0:2])
+	s.DestinationPort = binary.BigEndian.Uint16(data[2:4])
+	s.VerificationTag = binary.BigEndian.Uint32(data[4:8])
+	// The CRC32c is transmitted in reflected, i.e. little-endian, bit order
+	s.Checksum = binary.LittleEndian.Uint32(data[8:12])
+	s.Chunks = s.Chunks[:0]
+	s.Contents = data
+	s.Payload = nil
+
+	for rest := data[12:]; len(rest) > 0; {
+		if len(rest) < 4 {
+			return fmt.Errorf("sctp chunk too small: %w", ErrTruncated)
+		}
+		var c SCTPChunk
+		c.ChunkType = SCTPChunkType(rest[0])
+		c.Flags = rest[1]
+		c.Length = binary.BigEndian.Uint16(rest[2:4])
+		if c.Length < 4 || int(c.Length) > len(rest) {
+			return fmt.Errorf("invalid length of sctp chunk %v: %w", c.ChunkType, ErrInvalid)
+		}
+		c.Value = rest[4:c.Length]
+		if err := c.decodeValue(); err != nil {
+			return err
+		}
+		s.Chunks = append(s.Chunks, c)
+		padded := (int(c.Length) + 3) &^ 3
+		if padded > len(rest) {
+			padded = len(rest)
+		}
+		rest = rest[padded:]
+	}
+	return nil
+}
+
+func (c *SCTPChunk) decodeValue() error {
+	v := c.Value
+	tooSmall := fmt.Errorf("sctp %v chunk too small: %w", c.ChunkType, ErrTruncated)
+	switch c.ChunkType {
+	case SCTPChunkTypeData, SCTPChunkTypeIData:
+		d := &SCTPData{
+			Ending:    c.Flags&0x01 != 0,
+			Beginning: c.Flags&0x02 != 0,
+			Unordered: c.Flags&0x04 != 0,
+			Immediate: c.Flags&0x08 != 0,
+		}
+		if c.ChunkType == SCTPChunkTypeIData {
+			if len(v) < 16 {
+				return tooSmall
+			}
+			d.TSN = binary.BigEndian.Uint32(v[0:4])
+			d.StreamID = binary.BigEndian.Uint16(v[4:6])
+			d.MessageID = binary.BigEndian.Uint32(v[8:12])
+			d.PPID = binary.BigEndian.Uint32(v[12:16])
+			d.UserData = v[16:]
+		} else {
+			if len(v) < 12 {
+				return tooSmall
+			}
+			d.TSN = binary.BigEndian.Uint32(v[0:4])
+			d.StreamID = binary.BigEndian.Uint16(v[4:6])
+			d.StreamSeq = binary.BigEndian.Uint16(v[6:8])
+			d.PPID = binary.BigEndian.Uint32(v[8:12])
+			d.UserData = v[12:]
+		}
+		c.Data = d
+	case SCTPChunkTypeInit, SCTPChunkTypeInitAck:
+		if len(v) < 16 {
+			return tooSmall
+		}
+		c.Init = &SCTPInit{
+			InitiateTag:     binary.BigEndian.Uint32(v[0:4]),
+			AdvertisedRwnd:  binary.BigEndian.Uint32(v[4:8]),
+			OutboundStreams: binary.BigEndian.Uint16(v[8:10]),
+			InboundStreams:  binary.BigEndian.Uint16(v[10:12]),
+			InitialTSN:      binary.BigEndian.Uint32(v[12:16]),
+		}
+		return c.decodeParams(v[16:])
+	case SCTPChunkTypeSACK:
+		if len(v) < 12 {
+			return tooSmall
+		}
+		sack := &SCTPSACK{
+			CumulativeTSNAck: binary.BigEndian.Uint32(v[0:4]),
+			AdvertisedRwnd:   binary.BigEndian.Uint32(v[4:8]),
+		}
+		nGaps := int(binary.BigEndian.Uint16(v[8:10]))
+		nDups := int(binary.BigEndian.Uint16(v[10:12]))
+		if len(v) < 12+4*nGaps+4*nDups {
+			return tooSmall
+		}
+		v = v[12:]
+		for i := 0; i < nGaps; i++ {
+			sack.GapAckBlocks = append(sack.GapAckBlocks, SCTPGapAckBlock{
+				Start: binary.BigEndian.Uint16(v[0:2]),
+				End:   binary.BigEndian.Uint16(v[2:4]),
+			})
+			v = v[4:]
+		}
+		for i := 0; i < nDups; i++ {
+			sack.DuplicateTSNs = append(sack.DuplicateTSNs, binary.BigEndian.Uint32(v[0:4]))
+			v = v[4:]
+		}
+		c.SACK = sack
+	case SCTPChunkTypeShutdown:
+		if len(v) < 4 {
+			return tooSmall
+		}
+		c.Shutdown = &SCTPShutdown{CumulativeTSNAck: binary.BigEndian.Uint32(v[0:4])}
+	case SCTPChunkTypeHeartbeat, SCTPChunkTypeHeartbeatAck, SCTPChunkTypeAbort,
+		SCTPChunkTypeError:
+		return c.decodeParams(v)
+	}
+	return nil
+}
+
+func (c *SCTPChunk) decodeParams(v []byte) error {
+	for len(v) > 0 {
+		if len(v) < 4 {
+			return fmt.Errorf("sctp %v parameter too small: %w", c.ChunkType, ErrTruncated)
+		}
+		typ := binary.BigEndian.Uint16(v[0:2])
+		n := int(binary.BigEndian.Uint16(v[2:4]))
+		if n < 4 || n > len(v) {
+			return fmt.Errorf("invalid length of sctp %v parameter %d: %w", c.ChunkType, typ, ErrInvalid)
+		}
+		c.Params = append(c.Params, SCTPParameter{Type: typ, Value: v[4:n]})
+		n = (n + 3) &^ 3
+		if n > len(v) {
+			n = len(v)
+		}
+		v = v[n:]
+	}
+	return nil
+}
+
+// VerifyChecksum reports whether the CRC32c checksum of the packet is valid.
+// Note that packets captured on the sending host may carry an invalid checksum
+// when checksum offloading is enabled.
+func (s SCTP) VerifyChecksum() bool {
+	if len(s.Contents) < 12 {
+		return false
+	}
+	var zero [4]byte
+	crc := crc32.Update(0, castagnoli, s.Contents[:8])
+	crc = crc32.Update(crc, castagnoli, zero[:])
+	crc = crc32.Update(crc, castagnoli, s.Contents[12:])
+	return crc == s.Checksum
+}
+
+func (s SCTP) Type() LayerType {
+	return LayerTypeSCTP
+}
+
+func (s SCTP) GetContents() []byte {
+	return s.Contents
+}
+
+func (s SCTP) GetPayload() []byte {
+	return s.Payload
+}
+
+func (s SCTP) MarshalJSON() ([]byte, error) {
+	type param struct {
+		Type  uint16 `json:"type"`
+		Value string `json:"value"`
+	}
+	type data struct {
+		Unordered bool   `json:"unordered"`
+		Beginning bool   `json:"beginning"`
+		Ending    bool   `json:"ending"`
+		Immediate bool   `json:"immediate"`
+		TSN       uint32 `json:"tsn"`
+		StreamID  uint16 `json:"stream_id"`
+		StreamSeq uint16 `json:"stream_seq"`
+		MessageID uint32 `json:"message_id"`
+		PPID      uint32 `json:"ppid"`
+		Length    int    `json:"length"`
+	}
+	type initChunk struct {
+		InitiateTag     uint32 `json:"initiate_tag"`
+		AdvertisedRwnd  uint32 `json:"advertised_rwnd"`
+		OutboundStreams uint16 `json:"outbound_streams"`
+		InboundStreams  uint16 `json:"inbound_streams"`
+		InitialTSN      uint32 `json:"initial_tsn"`
+	}
+	type gap struct {
+		Start uint16 `json:"start"`
+		End   uint16 `json:"end"`
+	}
+	type sack struct {
+		CumulativeTSNAck uint32   `json:"cumulative_tsn_ack"`
+		AdvertisedRwnd   uint32   `json:"advertised_rwnd"`
+		GapAckBlocks     []gap    `json:"gap_ack_blocks"`
+		DuplicateTSNs    []uint32 `json:"duplicate_tsns"`
+	}
+	type shutdown struct {
+		CumulativeTSNAck uint32 `json:"cumulative_tsn_ack"`
+	}
+	type chunk struct {
+		ChunkType string     `json:"chunk_type"`
+		Flags     uint8      `json:"flags"`
+		Length    uint16     `json:"length"`
+		Data      *data      `json:"data,omitempty"`
+		Init      *initChunk `json:"init,omitempty"`
+		SACK      *sack      `json:"sack,omitempty"`
+		Shutdown  *shutdown  `json:"shutdown,omitempty"`
+		Params    []param    `json:"params,omitempty"`
+	}
+	chunks := make([]chunk, len(s.Chunks))
+	for i, c := range s.Chunks {
+		jc := chunk{ChunkType: c.ChunkType.String(), Flags: c.Flags, Length: c.Length}
+		if d := c.Data; d != nil {
+			jc.Data = &data{d.Unordered, d.Beginning, d.Ending, d.Immediate, d.TSN,
+				d.StreamID, d.StreamSeq, d.MessageID, d.PPID, len(d.UserData)}
+		}
+		if in := c.Init; in != nil {
+			jc.Init = &initChunk{in.InitiateTag, in.AdvertisedRwnd, in.OutboundStreams,
+				in.InboundStreams, in.InitialTSN}
+		}
+		if sa := c.SACK; sa != nil {
+			jc.SACK = &sack{sa.CumulativeTSNAck, sa.AdvertisedRwnd, []gap{}, []uint32{}}
+			for _, g := range sa.GapAckBlocks {
+				jc.SACK.GapAckBlocks = append(jc.SACK.GapAckBlocks, gap{g.Start, g.End})
+			}
+			jc.SACK.DuplicateTSNs = append(jc.SACK.DuplicateTSNs, sa.DuplicateTSNs...)
+		}
+		if sh := c.Shutdown; sh != nil {
+			jc.Shutdown = &shutdown{sh.CumulativeTSNAck}
+		}
+		for _, p := range c.Params {
+			jc.Params = append(jc.Params, param{p.Type, hex.EncodeToString(p.Value)})
+		}
+		chunks[i] = jc
+	}
+	return json.Marshal(struct {
+		Type            string  `json:"type"`
+		SourcePort      uint16  `json:"source_port"`
+		DestinationPort uint16  `json:"destination_port"`
+		VerificationTag uint32  `json:"verification_tag"`
+		Checksum        uint32  `json:"checksum"`
+		ChecksumValid   bool    `json:"checksum_valid"`
+		Chunks          []chunk `json:"chunks"`
+		Length          int     `json:"length"`
+	}{
+		Type:            s.Type().String(),
+		SourcePort:      s.SourcePort,
+		DestinationPort: s.DestinationPort,
+		VerificationTag: s.VerificationTag,
+		Checksum:        s.Checksum,
+		ChecksumValid:   s.VerifyChecksum(),
+		Chunks:          chunks,
+		Length:          len(s.Contents),
+	})
+}
@@ -0,0 +1,96 @@
+package packet_test
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+func TestSCTP(t *testing.T) {
+	sctp := []byte{
+		0x0b, 0x59, 0x0b, 0x59, 0xde, 0xad, 0xbe, 0xef, 0, 0, 0, 0,
+		// DATA chunk with 5 bytes of user data, padded to 4 bytes
+		0x00, 0x03, 0x00, 0x15,
+		0x00, 0x00, 0x00, 0x2a, 0x00, 0x01, 0x00, 0x07, 0x00, 0x00, 0x00, 0x2e,
+		'h', 'e', 'l', 'l', 'o', 0, 0, 0,
+		// SACK chunk with one gap ack block
+		0x03, 0x00, 0x00, 0x14,
+		0x00, 0x00, 0x00, 0x29, 0x00, 0x01, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00,
+		0x00, 0x02, 0x00, 0x03,
+	}
+	crc := crc32.Checksum(sctp, crc32.MakeTable(crc32.Castagnoli))
+	binary.LittleEndian.PutUint32(sctp[8:12], crc)
+
+	ip := []byte{
+		0x45, 0, 0, 0, 0, 0, 0, 0, 64, 132, 0, 0,
+		10, 0, 0, 1, 10, 0, 0, 2,
+	}
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(ip)+len(sctp)))
+	frame := append([]byte{
+		0x02, 0, 0, 0, 0, 0x0b, 0x02, 0, 0, 0, 0, 0x0a, 0x08, 0x00,
+	}, append(ip, sctp...)...)
+
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	got, ok := p.Transport.(*packet.SCTP)
+	if !ok {
+		t.Fatalf("expected sctp layer, got %T", p.Transport)
+	}
+	if !got.VerifyChecksum() {
+		t.Errorf("expected valid checksum")
+	}
+	if got.VerificationTag != 0xdeadbeef {
+		t.Errorf("verification tag = %#x, want 0xdeadbeef", got.VerificationTag)
+	}
+	if len(got.Chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(got.Chunks))
+	}
+	data := got.Chunks[0].Data
+	if data == nil || !data.Beginning || !data.Ending || data.TSN != 42 ||
+		data.StreamSeq != 7 || data.PPID != 46 || string(data.UserData) != "hello" {
+		t.Errorf("unexpected data chunk %+v", data)
+	}
+	sack := got.Chunks[1].SACK
+	if sack == nil || sack.CumulativeTSNAck != 41 || len(sack.GapAckBlocks) != 1 ||
+		sack.GapAckBlocks[0] != (packet.SCTPGapAckBlock{Start: 2, End: 3}) {
+		t.Errorf("unexpected sack chunk %+v", sack)
+	}
+
+	got.Contents[len(got.Contents)-1] ^= 0xff
+	if got.VerifyChecksum() {
+		t.Errorf("expected invalid checksum after corruption")
+	}
+}
+
+func TestSCTPCapturedInit(t *testing.T) {
+	// An INIT sent by a WebRTC data channel in Chrome, as captured with its
+	// checksum
+	sctp := []byte{
+		0x13, 0x88, 0x13, 0x88, 0x00, 0x00, 0x00, 0x00, 0xbc, 0xb3, 0x45, 0xa2, 0x01, 0x00, 0x00, 0x56, 0xce, 0x15,
+		0x79, 0xa2, 0x00, 0x02, 0x00, 0x00, 0x04, 0x00, 0x08, 0x00, 0x94, 0x57, 0x95, 0xc0, 0xc0, 0x00, 0x00, 0x04,
+		0x80, 0x08, 0x00, 0x09, 0xc0, 0x0f, 0xc1, 0x80, 0x82, 0x00, 0x00, 0x00, 0x80, 0x02, 0x00, 0x24, 0xff, 0x5c,
+		0x49, 0x19, 0x4a, 0x94, 0xe8, 0x2a, 0xec, 0x58, 0x55, 0x62, 0x29, 0x1f, 0x8e, 0x23, 0xcd, 0x7c, 0xe8, 0x46,
+		0xba, 0x58, 0x1b, 0x3d, 0xab, 0xd7, 0x7e, 0x50, 0xf2, 0x41, 0xb1, 0x2e, 0x80, 0x04, 0x00, 0x06, 0x00, 0x01,
+		0x00, 0x00, 0x80, 0x03, 0x00, 0x06, 0x80, 0xc1, 0x00, 0x00,
+	}
+	var got packet.SCTP
+	if err := got.Unmarshal(sctp); err != nil {
+		t.Fatal(err)
+	}
+	if got.Checksum != 0xa245b3bc || !got.VerifyChecksum() {
+		t.Errorf("checksum %#08x was not verified", got.Checksum)
+	}
+	if len(got.Chunks) != 1 || got.Chunks[0].Init == nil || got.Chunks[0].Init.InitiateTag != 0xce1579a2 ||
+		got.Chunks[0].Init.InitialTSN != 0x945795c0 {
+		t.Errorf("unexpected chunks %+v", got.Chunks)
+	}
+
+	sctp[4] = 1
+	if got.VerifyChecksum() {
+		t.Errorf("expected invalid checksum after corruption")
+	}
+}
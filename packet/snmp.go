@@ -0,0 +1,486 @@
+package packet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+
+	"github.com/sebnyberg/net/ber"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(SNMP)
+	_ json.Marshaler = new(SNMP)
+)
+
+type SNMPVersion uint8
+
+const (
+	SNMPVersion1  SNMPVersion = 0
+	SNMPVersion2c SNMPVersion = 1
+	SNMPVersion3  SNMPVersion = 3
+)
+
+// SNMPPDUType is the context-specific tag of a PDU.
+type SNMPPDUType uint8
+
+const (
+	SNMPPDUTypeGetRequest     SNMPPDUType = 0
+	SNMPPDUTypeGetNextRequest SNMPPDUType = 1
+	SNMPPDUTypeResponse       SNMPPDUType = 2
+	SNMPPDUTypeSetRequest     SNMPPDUType = 3
+	SNMPPDUTypeTrap           SNMPPDUType = 4
+	SNMPPDUTypeGetBulkRequest SNMPPDUType = 5
+	SNMPPDUTypeInformRequest  SNMPPDUType = 6
+	SNMPPDUTypeTrapV2         SNMPPDUType = 7
+	SNMPPDUTypeReport         SNMPPDUType = 8
+)
+
+type SNMPErrorStatus uint8
+
+const (
+	SNMPErrorStatusNoError             SNMPErrorStatus = 0
+	SNMPErrorStatusTooBig              SNMPErrorStatus = 1
+	SNMPErrorStatusNoSuchName          SNMPErrorStatus = 2
+	SNMPErrorStatusBadValue            SNMPErrorStatus = 3
+	SNMPErrorStatusReadOnly            SNMPErrorStatus = 4
+	SNMPErrorStatusGenErr              SNMPErrorStatus = 5
+	SNMPErrorStatusNoAccess            SNMPErrorStatus = 6
+	SNMPErrorStatusWrongType           SNMPErrorStatus = 7
+	SNMPErrorStatusWrongLength         SNMPErrorStatus = 8
+	SNMPErrorStatusWrongEncoding       SNMPErrorStatus = 9
+	SNMPErrorStatusWrongValue          SNMPErrorStatus = 10
+	SNMPErrorStatusNoCreation          SNMPErrorStatus = 11
+	SNMPErrorStatusInconsistentValue   SNMPErrorStatus = 12
+	SNMPErrorStatusResourceUnavailable SNMPErrorStatus = 13
+	SNMPErrorStatusCommitFailed        SNMPErrorStatus = 14
+	SNMPErrorStatusUndoFailed          SNMPErrorStatus = 15
+	SNMPErrorStatusAuthorizationError  SNMPErrorStatus = 16
+	SNMPErrorStatusNotWritable         SNMPErrorStatus = 17
+	SNMPErrorStatusInconsistentName    SNMPErrorStatus = 18
+)
+
+// SNMPValueType is the type of a variable binding value, which is the
+// identifier octet of its encoding.
+type SNMPValueType uint8
+
+const (
+	SNMPValueTypeInteger        SNMPValueType = 0x02
+	SNMPValueTypeOctetString    SNMPValueType = 0x04
+	SNMPValueTypeNull           SNMPValueType = 0x05
+	SNMPValueTypeOID            SNMPValueType = 0x06
+	SNMPValueTypeIPAddress      SNMPValueType = 0x40
+	SNMPValueTypeCounter32      SNMPValueType = 0x41
+	SNMPValueTypeGauge32        SNMPValueType = 0x42
+	SNMPValueTypeTimeTicks      SNMPValueType = 0x43
+	SNMPValueTypeOpaque         SNMPValueType = 0x44
+	SNMPValueTypeCounter64      SNMPValueType = 0x46
+	SNMPValueTypeNoSuchObject   SNMPValueType = 0x80
+	SNMPValueTypeNoSuchInstance SNMPValueType = 0x81
+	SNMPValueTypeEndOfMibView   SNMPValueType = 0x82
+)
+
+// SNMPVarBind is a variable binding. The Go type of Value depends on Type:
+// int64 for Integer, []byte for OctetString and Opaque, ber.OID for OID,
+// netip.Addr for IPAddress, uint64 for the counters, gauges and time ticks,
+// and nil for Null and the exceptions of SNMPv2.
+type SNMPVarBind struct {
+	Name  ber.OID
+	Type  SNMPValueType
+	Value any
+}
+
+// SNMPPDU is a protocol data unit. For GetBulkRequest PDUs, the error status
+// and index fields contain the non-repeaters and max-repetitions. The trap
+// fields are only set for SNMPv1 traps.
+type SNMPPDU struct {
+	Type        SNMPPDUType
+	RequestID   int32
+	ErrorStatus SNMPErrorStatus
+	ErrorIndex  int32
+	VarBinds    []SNMPVarBind
+
+	Enterprise   ber.OID
+	AgentAddress netip.Addr
+	GenericTrap  int32
+	SpecificTrap int32
+	TimeStamp    uint32
+}
+
+// NonRepeaters returns the non-repeaters field of a GetBulkRequest PDU.
+func (p SNMPPDU) NonRepeaters() int32 {
+	return int32(p.ErrorStatus)
+}
+
+// MaxRepetitions returns the max-repetitions field of a GetBulkRequest PDU.
+func (p SNMPPDU) MaxRepetitions() int32 {
+	return p.ErrorIndex
+}
+
+// SNMPUSM contains the security parameters of the User-based Security Model
+// of SNMPv3 (RFC 3414).
+type SNMPUSM struct {
+	EngineID    []byte
+	EngineBoots int32
+	EngineTime  int32
+	UserName    string
+	AuthParams  []byte
+	PrivParams  []byte
+}
+
+// SNMPv3 message flags.
+const (
+	SNMPFlagAuth       = 0x01
+	SNMPFlagPriv       = 0x02
+	SNMPFlagReportable = 0x04
+)
+
+// SNMP is an SNMP message (RFC 1157, RFC 3416, RFC 3412). Community is set
+// for v1 and v2c, and the header and security fields for v3. PDU is not set
+// for v3 messages whose scoped PDU is encrypted, which is then kept in
+// EncryptedPDU.
+type SNMP struct {
+	Version   SNMPVersion
+	Community string
+
+	MessageID       int32
+	MaxSize         int32
+	Flags           uint8
+	SecurityModel   int32
+	USM             *SNMPUSM
+	ContextEngineID []byte
+	ContextName     string
+	EncryptedPDU    []byte
+
+	PDU *SNMPPDU
+	PacketBytes
+}
+
+func (s *SNMP) Unmarshal(data []byte) error {
+	*s = SNMP{}
+	msg, _, err := ber.Parse(data)
+	if err != nil {
+		return err
+	}
+	if !msg.Is(ber.ClassUniversal, ber.TagSequence) {
+		return fmt.Errorf("snmp message is not a sequence: %w", ErrInvalid)
+	}
+	s.Contents = msg.Raw
+	elems, err := msg.Elements()
+	if err != nil {
+		return err
+	}
+	if len(elems) < 3 {
+		return fmt.Errorf("snmp message too small: %w", ErrTruncated)
+	}
+	version, err := elems[0].Int64()
+	if err != nil {
+		return fmt.Errorf("invalid snmp version: %w", err)
+	}
+	s.Version = SNMPVersion(version)
+	switch s.Version {
+	case SNMPVersion1, SNMPVersion2c:
+		if !elems[1].Is(ber.ClassUniversal, ber.TagOctetString) {
+			return fmt.Errorf("snmp community is not an octet string: %w", ErrInvalid)
+		}
+		s.Community = string(elems[1].Value)
+		s.PDU, err = unmarshalSNMPPDU(elems[2])
+		return err
+	case SNMPVersion3:
+		if len(elems) < 4 {
+			return fmt.Errorf("snmpv3 message too small: %w", ErrTruncated)
+		}
+		if err := s.unmarshalHeader(elems[1]); err != nil {
+			return err
+		}
+		if s.SecurityModel == 3 {
+			if err := s.unmarshalUSM(elems[2]); err != nil {
+				return err
+			}
+		}
+		if elems[3].Is(ber.ClassUniversal, ber.TagOctetString) {
+			s.EncryptedPDU = elems[3].Value
+			return nil
+		}
+		return s.unmarshalScopedPDU(elems[3])
+	}
+	return fmt.Errorf("unsupported snmp version %d: %w", version, ErrUnsupported)
+}
+
+// snmpInts decodes the leading integers of a sequence.
+func snmpInts(elems []ber.Element, n int) ([]int64, error) {
+	if len(elems) < n {
+		return nil, fmt.Errorf("snmp sequence too small: %w", ErrTruncated)
+	}
+	v := make([]int64, n)
+	for i := range v {
+		var err error
+		if v[i], err = elems[i].Int64(); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+func (s *SNMP) unmarshalHeader(e ber.Element) error {
+	elems, err := e.Elements()
+	if err != nil {
+		return fmt.Errorf("invalid snmpv3 header: %w", err)
+	}
+	v, err := snmpInts(elems, 2)
+	if err != nil || len(elems) < 4 || len(elems[2].Value) != 1 {
+		return fmt.Errorf("invalid snmpv3 header: %w", ErrInvalid)
+	}
+	s.MessageID, s.MaxSize = int32(v[0]), int32(v[1])
+	s.Flags = elems[2].Value[0]
+	model, err := elems[3].Int64()
+	if err != nil {
+		return fmt.Errorf("invalid snmpv3 security model: %w", err)
+	}
+	s.SecurityModel = int32(model)
+	return nil
+}
+
+func (s *SNMP) unmarshalUSM(e ber.Element) error {
+	// The security parameters are an octet string containing the encoded
+	// UsmSecurityParameters sequence
+	params, _, err := ber.Parse(e.Value)
+	if err != nil {
+		return fmt.Errorf("invalid snmpv3 usm parameters: %w", err)
+	}
+	elems, err := params.Elements()
+	if err != nil || len(elems) < 6 {
+		return fmt.Errorf("invalid snmpv3 usm parameters: %w", ErrInvalid)
+	}
+	boots, err1 := elems[1].Int64()
+	t, err2 := elems[2].Int64()
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("invalid snmpv3 usm engine boots or time: %w", ErrInvalid)
+	}
+	s.USM = &SNMPUSM{
+		EngineID:    elems[0].Value,
+		EngineBoots: int32(boots),
+		EngineTime:  int32(t),
+		UserName:    string(elems[3].Value),
+		AuthParams:  elems[4].Value,
+		PrivParams:  elems[5].Value,
+	}
+	return nil
+}
+
+func (s *SNMP) unmarshalScopedPDU(e ber.Element) error {
+	elems, err := e.Elements()
+	if err != nil || len(elems) < 3 {
+		return fmt.Errorf("invalid snmpv3 scoped pdu: %w", ErrInvalid)
+	}
+	s.ContextEngineID = elems[0].Value
+	s.ContextName = string(elems[1].Value)
+	s.PDU, err = unmarshalSNMPPDU(elems[2])
+	return err
+}
+
+// DecodeScopedPDU decodes the plaintext of an encrypted scoped PDU, and sets
+// the context and PDU of the message.
+func (s *SNMP) DecodeScopedPDU(plaintext []byte) error {
+	e, _, err := ber.Parse(plaintext)
+	if err != nil {
+		return err
+	}
+	return s.unmarshalScopedPDU(e)
+}
+
+func unmarshalSNMPPDU(e ber.Element) (*SNMPPDU, error) {
+	if e.Class != ber.ClassContext || !e.Constructed || e.Tag > int(SNMPPDUTypeReport) {
+		return nil, fmt.Errorf("invalid snmp pdu tag %d: %w", e.Tag, ErrInvalid)
+	}
+	p := &SNMPPDU{Type: SNMPPDUType(e.Tag)}
+	elems, err := e.Elements()
+	if err != nil {
+		return nil, err
+	}
+	var binds ber.Element
+	if p.Type == SNMPPDUTypeTrap {
+		if len(elems) < 6 {
+			return nil, fmt.Errorf("snmp trap pdu too small: %w", ErrTruncated)
+		}
+		if p.Enterprise, err = elems[0].OID(); err != nil {
+			return nil, fmt.Errorf("invalid snmp trap enterprise: %w", err)
+		}
+		if len(elems[1].Value) == 4 {
+			p.AgentAddress = netip.AddrFrom4(*(*[4]byte)(elems[1].Value))
+		}
+		v, err := snmpInts(elems[2:], 2)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snmp trap type: %w", err)
+		}
+		p.GenericTrap, p.SpecificTrap = int32(v[0]), int32(v[1])
+		ts, err := elems[4].Uint64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid snmp trap time stamp: %w", err)
+		}
+		p.TimeStamp = uint32(ts)
+		binds = elems[5]
+	} else {
+		v, err := snmpInts(elems, 3)
+		if err != nil || len(elems) < 4 {
+			return nil, fmt.Errorf("invalid snmp pdu header: %w", ErrInvalid)
+		}
+		p.RequestID, p.ErrorStatus, p.ErrorIndex = int32(v[0]), SNMPErrorStatus(v[1]), int32(v[2])
+		binds = elems[3]
+	}
+
+	list, err := binds.Elements()
+	if err != nil {
+		return nil, fmt.Errorf("invalid snmp variable bindings: %w", err)
+	}
+	for _, b := range list {
+		vb, err := unmarshalVarBind(b)
+		if err != nil {
+			return nil, err
+		}
+		p.VarBinds = append(p.VarBinds, vb)
+	}
+	return p, nil
+}
+
+func unmarshalVarBind(e ber.Element) (SNMPVarBind, error) {
+	var vb SNMPVarBind
+	elems, err := e.Elements()
+	if err != nil || len(elems) != 2 {
+		return vb, fmt.Errorf("invalid snmp variable binding: %w", ErrInvalid)
+	}
+	if vb.Name, err = elems[0].OID(); err != nil {
+		return vb, fmt.Errorf("invalid snmp variable name: %w", err)
+	}
+	v := elems[1]
+	vb.Type = SNMPValueType(v.Raw[0])
+	switch vb.Type {
+	case SNMPValueTypeInteger:
+		vb.Value, err = v.Int64()
+	case SNMPValueTypeOctetString, SNMPValueTypeOpaque:
+		vb.Value = v.Value
+	case SNMPValueTypeOID:
+		vb.Value, err = v.OID()
+	case SNMPValueTypeIPAddress:
+		if len(v.Value) != 4 {
+			return vb, fmt.Errorf("invalid snmp ip address length: %w", ErrInvalid)
+		}
+		vb.Value = netip.AddrFrom4(*(*[4]byte)(v.Value))
+	case SNMPValueTypeCounter32, SNMPValueTypeGauge32, SNMPValueTypeTimeTicks, SNMPValueTypeCounter64:
+		vb.Value, err = v.Uint64()
+	case SNMPValueTypeNull, SNMPValueTypeNoSuchObject, SNMPValueTypeNoSuchInstance, SNMPValueTypeEndOfMibView:
+	default:
+		// Unknown types keep their encoded value
+		vb.Value = v.Value
+	}
+	if err != nil {
+		return vb, fmt.Errorf("invalid snmp %v value of %v: %w", vb.Type, vb.Name, err)
+	}
+	return vb, nil
+}
+
+func (s SNMP) Type() LayerType {
+	return LayerTypeSNMP
+}
+
+func (s SNMP) GetContents() []byte {
+	return s.Contents
+}
+
+func (s SNMP) GetPayload() []byte {
+	return s.Payload
+}
+
+func (s SNMP) MarshalJSON() ([]byte, error) {
+	type varBind struct {
+		Name  ber.OID `json:"name"`
+		Type  string  `json:"type"`
+		Value any     `json:"value"`
+	}
+	type pdu struct {
+		Type         string    `json:"type"`
+		RequestID    int32     `json:"request_id"`
+		ErrorStatus  string    `json:"error_status"`
+		ErrorIndex   int32     `json:"error_index"`
+		VarBinds     []varBind `json:"varbinds"`
+		Enterprise   ber.OID   `json:"enterprise,omitempty"`
+		AgentAddress string    `json:"agent_address,omitempty"`
+		GenericTrap  int32     `json:"generic_trap,omitempty"`
+		SpecificTrap int32     `json:"specific_trap,omitempty"`
+		TimeStamp    uint32    `json:"time_stamp,omitempty"`
+	}
+	type usm struct {
+		EngineID    string `json:"engine_id"`
+		EngineBoots int32  `json:"engine_boots"`
+		EngineTime  int32  `json:"engine_time"`
+		UserName    string `json:"user_name"`
+		AuthParams  string `json:"auth_params"`
+		PrivParams  string `json:"priv_params"`
+	}
+	v := struct {
+		Type            string `json:"type"`
+		Version         string `json:"version"`
+		Community       string `json:"community,omitempty"`
+		MessageID       int32  `json:"message_id,omitempty"`
+		MaxSize         int32  `json:"max_size,omitempty"`
+		Flags           uint8  `json:"flags,omitempty"`
+		SecurityModel   int32  `json:"security_model,omitempty"`
+		USM             *usm   `json:"usm,omitempty"`
+		ContextEngineID string `json:"context_engine_id,omitempty"`
+		ContextName     string `json:"context_name,omitempty"`
+		EncryptedPDU    string `json:"encrypted_pdu,omitempty"`
+		PDU             *pdu   `json:"pdu,omitempty"`
+		Length          int    `json:"length"`
+	}{
+		Type:            s.Type().String(),
+		Version:         s.Version.String(),
+		Community:       s.Community,
+		MessageID:       s.MessageID,
+		MaxSize:         s.MaxSize,
+		Flags:           s.Flags,
+		SecurityModel:   s.SecurityModel,
+		ContextEngineID: hex.EncodeToString(s.ContextEngineID),
+		ContextName:     s.ContextName,
+		EncryptedPDU:    hex.EncodeToString(s.EncryptedPDU),
+		Length:          len(s.Contents),
+	}
+	if u := s.USM; u != nil {
+		v.USM = &usm{
+			EngineID:    hex.EncodeToString(u.EngineID),
+			EngineBoots: u.EngineBoots,
+			EngineTime:  u.EngineTime,
+			UserName:    u.UserName,
+			AuthParams:  hex.EncodeToString(u.AuthParams),
+			PrivParams:  hex.EncodeToString(u.PrivParams),
+		}
+	}
+	if p := s.PDU; p != nil {
+		v.PDU = &pdu{
+			Type:         p.Type.String(),
+			RequestID:    p.RequestID,
+			ErrorStatus:  p.ErrorStatus.String(),
+			ErrorIndex:   p.ErrorIndex,
+			VarBinds:     make([]varBind, len(p.VarBinds)),
+			Enterprise:   p.Enterprise,
+			GenericTrap:  p.GenericTrap,
+			SpecificTrap: p.SpecificTrap,
+			TimeStamp:    p.TimeStamp,
+		}
+		if p.AgentAddress.IsValid() {
+			v.PDU.AgentAddress = p.AgentAddress.String()
+		}
+		for i, b := range p.VarBinds {
+			vb := varBind{Name: b.Name, Type: b.Type.String(), Value: b.Value}
+			switch val := b.Value.(type) {
+			case []byte:
+				vb.Value = hex.EncodeToString(val)
+			case netip.Addr:
+				vb.Value = val.String()
+			}
+			v.PDU.VarBinds[i] = vb
+		}
+	}
+	return json.Marshal(v)
+}
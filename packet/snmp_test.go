@@ -0,0 +1,155 @@
+package packet_test
+
+import (
+	"encoding/json"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// tlv encodes a BER element with a definite length.
+func tlv(tag byte, parts ...[]byte) []byte {
+	var v []byte
+	for _, p := range parts {
+		v = append(v, p...)
+	}
+	b := []byte{tag}
+	switch {
+	case len(v) < 0x80:
+		b = append(b, byte(len(v)))
+	case len(v) < 0x100:
+		b = append(b, 0x81, byte(len(v)))
+	default:
+		b = append(b, 0x82, byte(len(v)>>8), byte(len(v)))
+	}
+	return append(b, v...)
+}
+
+var (
+	sysUpTime   = tlv(0x06, []byte{0x2B, 6, 1, 2, 1, 1, 3, 0})
+	sysName     = tlv(0x06, []byte{0x2B, 6, 1, 2, 1, 1, 5, 0})
+	ifInOctets  = tlv(0x06, []byte{0x2B, 6, 1, 2, 1, 2, 2, 1, 10, 1})
+	snmpInteger = func(v byte) []byte { return tlv(0x02, []byte{v}) }
+)
+
+func TestSNMPv2cResponse(t *testing.T) {
+	binds := tlv(0x30,
+		tlv(0x30, sysUpTime, tlv(0x43, []byte{0x01, 0x00, 0x00})),
+		tlv(0x30, sysName, tlv(0x04, []byte("router1"))),
+		tlv(0x30, ifInOctets, tlv(0x41, []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF})),
+		tlv(0x30, tlv(0x06, []byte{0x2B, 6, 1, 2, 1, 4, 20, 1, 1}), tlv(0x40, []byte{192, 0, 2, 1})),
+		tlv(0x30, tlv(0x06, []byte{0x2B, 6, 1, 2, 1, 1, 9, 0}), tlv(0x81, nil)),
+	)
+	msg := tlv(0x30, snmpInteger(1), tlv(0x04, []byte("public")),
+		tlv(0xA2, snmpInteger(42), snmpInteger(0), snmpInteger(0), binds))
+
+	p := decodeUDP(t, 161, 50000, msg)
+	s, ok := p.Application.(*packet.SNMP)
+	if !ok {
+		t.Fatalf("expected snmp, got %T", p.Application)
+	}
+	if s.Version != packet.SNMPVersion2c || s.Community != "public" || s.PDU == nil {
+		t.Fatalf("unexpected message %+v", s)
+	}
+	pdu := s.PDU
+	if pdu.Type != packet.SNMPPDUTypeResponse || pdu.RequestID != 42 || pdu.ErrorStatus != packet.SNMPErrorStatusNoError {
+		t.Errorf("unexpected pdu %+v", pdu)
+	}
+	if len(pdu.VarBinds) != 5 {
+		t.Fatalf("got %d varbinds, want 5", len(pdu.VarBinds))
+	}
+	want := []struct {
+		name  string
+		typ   packet.SNMPValueType
+		value any
+	}{
+		{"1.3.6.1.2.1.1.3.0", packet.SNMPValueTypeTimeTicks, uint64(65536)},
+		{"1.3.6.1.2.1.1.5.0", packet.SNMPValueTypeOctetString, "router1"},
+		{"1.3.6.1.2.1.2.2.1.10.1", packet.SNMPValueTypeCounter32, uint64(1<<32 - 1)},
+		{"1.3.6.1.2.1.4.20.1.1", packet.SNMPValueTypeIPAddress, netip.MustParseAddr("192.0.2.1")},
+		{"1.3.6.1.2.1.1.9.0", packet.SNMPValueTypeNoSuchInstance, nil},
+	}
+	for i, w := range want {
+		vb := pdu.VarBinds[i]
+		value := vb.Value
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		if vb.Name.String() != w.name || vb.Type != w.typ || value != w.value {
+			t.Errorf("varbind %d is %v %v %v, want %v %v %v", i, vb.Name, vb.Type, vb.Value, w.name, w.typ, w.value)
+		}
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `{"name":"1.3.6.1.2.1.4.20.1.1","type":"SNMPValueTypeIPAddress","value":"192.0.2.1"}`) {
+		t.Errorf("unexpected json %s", data)
+	}
+}
+
+func TestSNMPv1Trap(t *testing.T) {
+	trap := tlv(0xA4,
+		tlv(0x06, []byte{0x2B, 6, 1, 4, 1, 9}),
+		tlv(0x40, []byte{192, 0, 2, 9}),
+		snmpInteger(2), snmpInteger(0),
+		tlv(0x43, []byte{0x12, 0x34}),
+		tlv(0x30, tlv(0x30, ifInOctets, tlv(0x02, []byte{0xFF}))),
+	)
+	msg := tlv(0x30, snmpInteger(0), tlv(0x04, []byte("traps")), trap)
+
+	p := decodeUDP(t, 50000, 162, msg)
+	s, ok := p.Application.(*packet.SNMP)
+	if !ok {
+		t.Fatalf("expected snmp, got %T", p.Application)
+	}
+	pdu := s.PDU
+	if s.Version != packet.SNMPVersion1 || pdu.Type != packet.SNMPPDUTypeTrap ||
+		pdu.Enterprise.String() != "1.3.6.1.4.1.9" || pdu.AgentAddress != netip.MustParseAddr("192.0.2.9") ||
+		pdu.GenericTrap != 2 || pdu.TimeStamp != 0x1234 {
+		t.Errorf("unexpected trap %+v", pdu)
+	}
+	if len(pdu.VarBinds) != 1 || pdu.VarBinds[0].Value != int64(-1) {
+		t.Errorf("unexpected varbinds %+v", pdu.VarBinds)
+	}
+}
+
+func TestSNMPv3(t *testing.T) {
+	header := tlv(0x30, snmpInteger(7), tlv(0x02, []byte{0x05, 0xDC}), tlv(0x04, []byte{0x05}), snmpInteger(3))
+	usm := tlv(0x04, tlv(0x30,
+		tlv(0x04, []byte{0x80, 0, 0, 9, 3}), snmpInteger(4), tlv(0x02, []byte{0x01, 0x00}),
+		tlv(0x04, []byte("admin")), tlv(0x04, make([]byte, 12)), tlv(0x04, nil)))
+	scoped := tlv(0x30, tlv(0x04, []byte{0x80, 0, 0, 9, 3}), tlv(0x04, nil),
+		tlv(0xA5, snmpInteger(9), snmpInteger(0), snmpInteger(10), tlv(0x30, tlv(0x30, sysName, tlv(0x05, nil)))))
+
+	msg := tlv(0x30, snmpInteger(3), header, usm, scoped)
+	p := decodeUDP(t, 50000, 161, msg)
+	s, ok := p.Application.(*packet.SNMP)
+	if !ok {
+		t.Fatalf("expected snmp, got %T", p.Application)
+	}
+	if s.Version != packet.SNMPVersion3 || s.MessageID != 7 || s.MaxSize != 1500 ||
+		s.Flags != packet.SNMPFlagAuth|packet.SNMPFlagReportable || s.SecurityModel != 3 {
+		t.Errorf("unexpected header %+v", s)
+	}
+	if s.USM == nil || s.USM.UserName != "admin" || s.USM.EngineBoots != 4 || s.USM.EngineTime != 256 || len(s.USM.AuthParams) != 12 {
+		t.Errorf("unexpected usm %+v", s.USM)
+	}
+	if s.PDU == nil || s.PDU.Type != packet.SNMPPDUTypeGetBulkRequest || s.PDU.MaxRepetitions() != 10 ||
+		len(s.PDU.VarBinds) != 1 || s.PDU.VarBinds[0].Type != packet.SNMPValueTypeNull {
+		t.Errorf("unexpected pdu %+v", s.PDU)
+	}
+
+	// Encrypted scoped PDUs are kept, and can be decoded once decrypted
+	msg = tlv(0x30, snmpInteger(3), header, usm, tlv(0x04, []byte{1, 2, 3, 4}))
+	s = decodeUDP(t, 50000, 161, msg).Application.(*packet.SNMP)
+	if s.PDU != nil || len(s.EncryptedPDU) != 4 {
+		t.Fatalf("unexpected encrypted message %+v", s)
+	}
+	if err := s.DecodeScopedPDU(scoped); err != nil || s.PDU == nil || s.PDU.RequestID != 9 {
+		t.Errorf("decoding scoped pdu failed, %v", err)
+	}
+}
@@ -1,9 +1,124 @@
 package packet
 
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(TCP)
+	_ json.Marshaler = new(TCP)
+)
+
+// TCPFlags contains the control bits of a TCP segment.
+type TCPFlags uint16
+
+const (
+	TCPFlagFIN TCPFlags = 1 << 0
+	TCPFlagSYN TCPFlags = 1 << 1
+	TCPFlagRST TCPFlags = 1 << 2
+	TCPFlagPSH TCPFlags = 1 << 3
+	TCPFlagACK TCPFlags = 1 << 4
+	TCPFlagURG TCPFlags = 1 << 5
+	TCPFlagECE TCPFlags = 1 << 6
+	TCPFlagCWR TCPFlags = 1 << 7
+	TCPFlagNS  TCPFlags = 1 << 8
+)
+
+var tcpFlagNames = []string{"FIN", "SYN", "RST", "PSH", "ACK", "URG", "ECE", "CWR", "NS"}
+
+// Names returns the names of the set flags, e.g. ["SYN", "ACK"].
+func (f TCPFlags) Names() []string {
+	names := []string{}
+	for i, name := range tcpFlagNames {
+		if f&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (f TCPFlags) String() string {
+	return strings.Join(f.Names(), "|")
+}
+
 type TCP struct {
-	Payload []byte
+	SourcePort      uint16
+	DestinationPort uint16
+	Seq             uint32
+	Ack             uint32
+	DataOffset      uint8
+	Flags           TCPFlags
+	Window          uint16
+	Checksum        uint16
+	Urgent          uint16
+	// Options contains the raw, undecoded options of the segment.
+	Options []byte
+	PacketBytes
 }
 
-func (e *TCP) Unmarshal(data []byte) error {
+func (t *TCP) Unmarshal(data []byte) error {
+	if len(data) < 20 {
+		return fmt.Errorf("tcp segment too small: %w", ErrTruncated)
+	}
+	t.SourcePort = binary.BigEndian.Uint16(data[0:2])
+	t.DestinationPort = binary.BigEndian.Uint16(data[2:4])
+	t.Seq = binary.BigEndian.Uint32(data[4:8])
+	t.Ack = binary.BigEndian.Uint32(data[8:12])
+	t.DataOffset = data[12] >> 4
+	t.Flags = TCPFlags(binary.BigEndian.Uint16(data[12:14]) & 0x01FF)
+	t.Window = binary.BigEndian.Uint16(data[14:16])
+	t.Checksum = binary.BigEndian.Uint16(data[16:18])
+	t.Urgent = binary.BigEndian.Uint16(data[18:20])
+	hdrLen := int(t.DataOffset) * 4
+	if hdrLen < 20 || hdrLen > len(data) {
+		return fmt.Errorf("invalid tcp data offset: %w", ErrInvalid)
+	}
+	t.Options = data[20:hdrLen]
+	t.Contents = data
+	t.Payload = data[hdrLen:]
 	return nil
 }
+
+func (t TCP) Type() LayerType {
+	return LayerTypeTCP
+}
+
+func (t TCP) GetContents() []byte {
+	return t.Contents
+}
+
+func (t TCP) GetPayload() []byte {
+	return t.Payload
+}
+
+func (t TCP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type            string   `json:"type"`
+		SourcePort      uint16   `json:"source_port"`
+		DestinationPort uint16   `json:"destination_port"`
+		Seq             uint32   `json:"seq"`
+		Ack             uint32   `json:"ack"`
+		DataOffset      uint8    `json:"data_offset"`
+		Flags           []string `json:"flags"`
+		Window          uint16   `json:"window"`
+		Checksum        uint16   `json:"checksum"`
+		Urgent          uint16   `json:"urgent"`
+		Length          int      `json:"length"`
+	}{
+		Type:            t.Type().String(),
+		SourcePort:      t.SourcePort,
+		DestinationPort: t.DestinationPort,
+		Seq:             t.Seq,
+		Ack:             t.Ack,
+		DataOffset:      t.DataOffset,
+		Flags:           t.Flags.Names(),
+		Window:          t.Window,
+		Checksum:        t.Checksum,
+		Urgent:          t.Urgent,
+		Length:          len(t.Contents),
+	})
+}
@@ -0,0 +1,759 @@
+package packet
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(TLS)
+	_ json.Marshaler = new(TLS)
+)
+
+type TLSContentType uint8
+
+const (
+	TLSContentTypeChangeCipherSpec TLSContentType = 20
+	TLSContentTypeAlert            TLSContentType = 21
+	TLSContentTypeHandshake        TLSContentType = 22
+	TLSContentTypeApplicationData  TLSContentType = 23
+	TLSContentTypeHeartbeat        TLSContentType = 24
+)
+
+type TLSHandshakeType uint8
+
+const (
+	TLSHandshakeTypeHelloRequest        TLSHandshakeType = 0
+	TLSHandshakeTypeClientHello         TLSHandshakeType = 1
+	TLSHandshakeTypeServerHello         TLSHandshakeType = 2
+	TLSHandshakeTypeNewSessionTicket    TLSHandshakeType = 4
+	TLSHandshakeTypeEndOfEarlyData      TLSHandshakeType = 5
+	TLSHandshakeTypeEncryptedExtensions TLSHandshakeType = 8
+	TLSHandshakeTypeCertificate         TLSHandshakeType = 11
+	TLSHandshakeTypeServerKeyExchange   TLSHandshakeType = 12
+	TLSHandshakeTypeCertificateRequest  TLSHandshakeType = 13
+	TLSHandshakeTypeServerHelloDone     TLSHandshakeType = 14
+	TLSHandshakeTypeCertificateVerify   TLSHandshakeType = 15
+	TLSHandshakeTypeClientKeyExchange   TLSHandshakeType = 16
+	TLSHandshakeTypeFinished            TLSHandshakeType = 20
+	TLSHandshakeTypeKeyUpdate           TLSHandshakeType = 24
+)
+
+type TLSExtensionType uint16
+
+const (
+	TLSExtensionTypeServerName              TLSExtensionType = 0
+	TLSExtensionTypeMaxFragmentLength       TLSExtensionType = 1
+	TLSExtensionTypeStatusRequest           TLSExtensionType = 5
+	TLSExtensionTypeSupportedGroups         TLSExtensionType = 10
+	TLSExtensionTypeECPointFormats          TLSExtensionType = 11
+	TLSExtensionTypeSignatureAlgorithms     TLSExtensionType = 13
+	TLSExtensionTypeUseSRTP                 TLSExtensionType = 14
+	TLSExtensionTypeHeartbeat               TLSExtensionType = 15
+	TLSExtensionTypeALPN                    TLSExtensionType = 16
+	TLSExtensionTypeSignedCertTimestamp     TLSExtensionType = 18
+	TLSExtensionTypePadding                 TLSExtensionType = 21
+	TLSExtensionTypeEncryptThenMAC          TLSExtensionType = 22
+	TLSExtensionTypeExtendedMasterSecret    TLSExtensionType = 23
+	TLSExtensionTypeCompressCertificate     TLSExtensionType = 27
+	TLSExtensionTypeRecordSizeLimit         TLSExtensionType = 28
+	TLSExtensionTypeSessionTicket           TLSExtensionType = 35
+	TLSExtensionTypePreSharedKey            TLSExtensionType = 41
+	TLSExtensionTypeEarlyData               TLSExtensionType = 42
+	TLSExtensionTypeSupportedVersions       TLSExtensionType = 43
+	TLSExtensionTypeCookie                  TLSExtensionType = 44
+	TLSExtensionTypePSKKeyExchangeModes     TLSExtensionType = 45
+	TLSExtensionTypeCertificateAuthorities  TLSExtensionType = 47
+	TLSExtensionTypePostHandshakeAuth       TLSExtensionType = 49
+	TLSExtensionTypeSignatureAlgorithmsCert TLSExtensionType = 50
+	TLSExtensionTypeKeyShare                TLSExtensionType = 51
+	TLSExtensionTypeQUICTransportParameters TLSExtensionType = 57
+	TLSExtensionTypeApplicationSettings     TLSExtensionType = 17513
+	TLSExtensionTypeEncryptedClientHello    TLSExtensionType = 0xfe0d
+	TLSExtensionTypeRenegotiationInfo       TLSExtensionType = 0xff01
+)
+
+// TLSVersion is a TLS protocol version as it appears on the wire.
+type TLSVersion uint16
+
+const (
+	TLSVersionSSL30 TLSVersion = 0x0300
+	TLSVersionTLS10 TLSVersion = 0x0301
+	TLSVersionTLS11 TLSVersion = 0x0302
+	TLSVersionTLS12 TLSVersion = 0x0303
+	TLSVersionTLS13 TLSVersion = 0x0304
+)
+
+func (v TLSVersion) String() string {
+	switch v {
+	case TLSVersionSSL30:
+		return "SSL 3.0"
+	case TLSVersionTLS10:
+		return "TLS 1.0"
+	case TLSVersionTLS11:
+		return "TLS 1.1"
+	case TLSVersionTLS12:
+		return "TLS 1.2"
+	case TLSVersionTLS13:
+		return "TLS 1.3"
+	}
+	if isGREASE(uint16(v)) {
+		return "GREASE"
+	}
+	return fmt.Sprintf("0x%04x", uint16(v))
+}
+
+// isGREASE reports whether v is one of the reserved values of RFC 8701, which
+// clients send to keep servers tolerant of unknown values.
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// TLSRecord is a record of the TLS record layer.
+type TLSRecord struct {
+	ContentType TLSContentType
+	Version     TLSVersion
+	Fragment    []byte
+}
+
+// TLSHandshake is a handshake message. Body excludes the 4 byte message
+// header.
+type TLSHandshake struct {
+	HandshakeType TLSHandshakeType
+	Body          []byte
+}
+
+// TLSExtension is a hello extension in its raw form.
+type TLSExtension struct {
+	Type TLSExtensionType
+	Data []byte
+}
+
+// TLSClientHello is a decoded ClientHello message. Commonly used extensions
+// are decoded into their own fields, all extensions are kept in Extensions in
+// the order in which they were sent.
+type TLSClientHello struct {
+	Version            TLSVersion
+	Random             [32]byte
+	SessionID          []byte
+	CipherSuites       []uint16
+	CompressionMethods []uint8
+	Extensions         []TLSExtension
+
+	ServerName          string
+	ALPN                []string
+	SupportedVersions   []TLSVersion
+	SupportedGroups     []uint16
+	ECPointFormats      []uint8
+	SignatureAlgorithms []uint16
+}
+
+// TLSServerHello is a decoded ServerHello message.
+type TLSServerHello struct {
+	Version           TLSVersion
+	Random            [32]byte
+	SessionID         []byte
+	CipherSuite       uint16
+	CompressionMethod uint8
+	Extensions        []TLSExtension
+
+	// SupportedVersion contains the negotiated version of TLS 1.3 servers.
+	SupportedVersion TLSVersion
+	ALPN             string
+}
+
+// TLS contains the records of a TLS stream. Handshake messages which are sent
+// in plaintext are reassembled across records and stored in Handshakes, and
+// the first ClientHello and ServerHello are decoded.
+//
+// Unmarshal expects complete records, i.e. a reassembled stream or a segment
+// which happens to contain only whole records.
+type TLS struct {
+	Records     []TLSRecord
+	Handshakes  []TLSHandshake
+	ClientHello *TLSClientHello
+	ServerHello *TLSServerHello
+	PacketBytes
+}
+
+func (t *TLS) Unmarshal(data []byte) error {
+	t.Records = t.Records[:0]
+	t.Handshakes = t.Handshakes[:0]
+	t.ClientHello = nil
+	t.ServerHello = nil
+	t.Contents = data
+	t.Payload = nil
+
+	var (
+		handshake []byte
+		nfrag     int
+		encrypted bool
+	)
+	for rest := data; len(rest) > 0; {
+		if len(rest) < 5 {
+			return fmt.Errorf("tls record header too small: %w", ErrTruncated)
+		}
+		n := int(binary.BigEndian.Uint16(rest[3:5]))
+		if len(rest) < 5+n {
+			return fmt.Errorf("tls record truncated: %w", ErrTruncated)
+		}
+		r := TLSRecord{
+			ContentType: TLSContentType(rest[0]),
+			Version:     TLSVersion(binary.BigEndian.Uint16(rest[1:3])),
+			Fragment:    rest[5 : 5+n],
+		}
+		t.Records = append(t.Records, r)
+		rest = rest[5+n:]
+
+		switch {
+		case r.ContentType == TLSContentTypeChangeCipherSpec:
+			// Subsequent handshake records are encrypted (TLS 1.2)
+			encrypted = true
+		case r.ContentType == TLSContentTypeHandshake && !encrypted:
+			// Avoid copying unless a message spans multiple records
+			if nfrag == 0 {
+				handshake = r.Fragment
+			} else {
+				if nfrag == 1 {
+					handshake = append([]byte{}, handshake...)
+				}
+				handshake = append(handshake, r.Fragment...)
+			}
+			nfrag++
+		}
+	}
+	if len(t.Records) == 0 {
+		return fmt.Errorf("tls stream is empty: %w", ErrTruncated)
+	}
+
+	// A trailing partial message is ignored, its remainder may be in the next
+	// segment.
+	t.Handshakes = parseTLSHandshakes(t.Handshakes, handshake)
+	for _, h := range t.Handshakes {
+		switch {
+		case h.HandshakeType == TLSHandshakeTypeClientHello && t.ClientHello == nil:
+			t.ClientHello = new(TLSClientHello)
+			if err := t.ClientHello.Unmarshal(h.Body); err != nil {
+				return err
+			}
+		case h.HandshakeType == TLSHandshakeTypeServerHello && t.ServerHello == nil:
+			t.ServerHello = new(TLSServerHello)
+			if err := t.ServerHello.Unmarshal(h.Body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseTLSHandshakes appends the complete handshake messages in data to hs.
+func parseTLSHandshakes(hs []TLSHandshake, data []byte) []TLSHandshake {
+	for len(data) >= 4 {
+		n := int(data[1])<<16 | int(binary.BigEndian.Uint16(data[2:4]))
+		if len(data) < 4+n {
+			break
+		}
+		hs = append(hs, TLSHandshake{
+			HandshakeType: TLSHandshakeType(data[0]),
+			Body:          data[4 : 4+n],
+		})
+		data = data[4+n:]
+	}
+	return hs
+}
+
+// tlsVector splits a vector with a length prefix of size bytes off the start
+// of data.
+func tlsVector(data []byte, size int) (vec, rest []byte, ok bool) {
+	if len(data) < size {
+		return nil, nil, false
+	}
+	var n int
+	for _, b := range data[:size] {
+		n = n<<8 | int(b)
+	}
+	if len(data) < size+n {
+		return nil, nil, false
+	}
+	return data[size : size+n], data[size+n:], true
+}
+
+func tlsUint16s(data []byte) []uint16 {
+	res := make([]uint16, len(data)/2)
+	for i := range res {
+		res[i] = binary.BigEndian.Uint16(data[2*i:])
+	}
+	return res
+}
+
+func parseTLSExtensions(data []byte) ([]TLSExtension, error) {
+	var exts []TLSExtension
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, fmt.Errorf("tls extension too small: %w", ErrTruncated)
+		}
+		typ := TLSExtensionType(binary.BigEndian.Uint16(data[0:2]))
+		v, rest, ok := tlsVector(data[2:], 2)
+		if !ok {
+			return nil, fmt.Errorf("tls extension %v truncated: %w", typ, ErrTruncated)
+		}
+		exts = append(exts, TLSExtension{Type: typ, Data: v})
+		data = rest
+	}
+	return exts, nil
+}
+
+// parseHelloPrefix parses the fields which are common to ClientHello and
+// ServerHello messages, returning the remaining data.
+func parseHelloPrefix(data []byte, version *TLSVersion, random *[32]byte,
+	sessionID *[]byte) ([]byte, error) {
+	if len(data) < 35 {
+		return nil, fmt.Errorf("tls hello too small: %w", ErrTruncated)
+	}
+	*version = TLSVersion(binary.BigEndian.Uint16(data[0:2]))
+	copy(random[:], data[2:34])
+	sid, rest, ok := tlsVector(data[34:], 1)
+	if !ok {
+		return nil, fmt.Errorf("tls hello session id truncated: %w", ErrTruncated)
+	}
+	*sessionID = sid
+	return rest, nil
+}
+
+// Unmarshal decodes the body of a ClientHello handshake message.
+func (h *TLSClientHello) Unmarshal(data []byte) error {
+	*h = TLSClientHello{}
+	rest, err := parseHelloPrefix(data, &h.Version, &h.Random, &h.SessionID)
+	if err != nil {
+		return err
+	}
+	ciphers, rest, ok := tlsVector(rest, 2)
+	if !ok || len(ciphers)%2 != 0 {
+		return fmt.Errorf("invalid tls client hello cipher suites: %w", ErrInvalid)
+	}
+	h.CipherSuites = tlsUint16s(ciphers)
+	if h.CompressionMethods, rest, ok = tlsVector(rest, 1); !ok {
+		return fmt.Errorf("invalid tls client hello compression methods: %w", ErrInvalid)
+	}
+	if len(rest) == 0 {
+		return nil
+	}
+	exts, _, ok := tlsVector(rest, 2)
+	if !ok {
+		return fmt.Errorf("tls client hello extensions truncated: %w", ErrTruncated)
+	}
+	if h.Extensions, err = parseTLSExtensions(exts); err != nil {
+		return err
+	}
+
+	for _, ext := range h.Extensions {
+		switch ext.Type {
+		case TLSExtensionTypeServerName:
+			names, _, _ := tlsVector(ext.Data, 2)
+			for len(names) >= 1 {
+				typ := names[0]
+				var name []byte
+				if name, names, ok = tlsVector(names[1:], 2); !ok {
+					return fmt.Errorf("invalid tls server name extension: %w", ErrInvalid)
+				}
+				// Only host names are defined
+				if typ == 0 && h.ServerName == "" {
+					h.ServerName = string(name)
+				}
+			}
+		case TLSExtensionTypeALPN:
+			h.ALPN = parseALPN(ext.Data)
+		case TLSExtensionTypeSupportedVersions:
+			versions, _, _ := tlsVector(ext.Data, 1)
+			for _, v := range tlsUint16s(versions) {
+				h.SupportedVersions = append(h.SupportedVersions, TLSVersion(v))
+			}
+		case TLSExtensionTypeSupportedGroups:
+			groups, _, _ := tlsVector(ext.Data, 2)
+			h.SupportedGroups = tlsUint16s(groups)
+		case TLSExtensionTypeECPointFormats:
+			h.ECPointFormats, _, _ = tlsVector(ext.Data, 1)
+		case TLSExtensionTypeSignatureAlgorithms:
+			algs, _, _ := tlsVector(ext.Data, 2)
+			h.SignatureAlgorithms = tlsUint16s(algs)
+		}
+	}
+	return nil
+}
+
+func parseALPN(data []byte) []string {
+	var protos []string
+	list, _, _ := tlsVector(data, 2)
+	for len(list) > 0 {
+		proto, rest, ok := tlsVector(list, 1)
+		if !ok {
+			break
+		}
+		protos = append(protos, string(proto))
+		list = rest
+	}
+	return protos
+}
+
+// Unmarshal decodes the body of a ServerHello handshake message.
+func (h *TLSServerHello) Unmarshal(data []byte) error {
+	*h = TLSServerHello{}
+	rest, err := parseHelloPrefix(data, &h.Version, &h.Random, &h.SessionID)
+	if err != nil {
+		return err
+	}
+	if len(rest) < 3 {
+		return fmt.Errorf("tls server hello too small: %w", ErrTruncated)
+	}
+	h.CipherSuite = binary.BigEndian.Uint16(rest[0:2])
+	h.CompressionMethod = rest[2]
+	if len(rest) == 3 {
+		return nil
+	}
+	exts, _, ok := tlsVector(rest[3:], 2)
+	if !ok {
+		return fmt.Errorf("tls server hello extensions truncated: %w", ErrTruncated)
+	}
+	if h.Extensions, err = parseTLSExtensions(exts); err != nil {
+		return err
+	}
+
+	for _, ext := range h.Extensions {
+		switch ext.Type {
+		case TLSExtensionTypeSupportedVersions:
+			if len(ext.Data) >= 2 {
+				h.SupportedVersion = TLSVersion(binary.BigEndian.Uint16(ext.Data))
+			}
+		case TLSExtensionTypeALPN:
+			if protos := parseALPN(ext.Data); len(protos) > 0 {
+				h.ALPN = protos[0]
+			}
+		}
+	}
+	return nil
+}
+
+func joinDecimal(vs []uint16) string {
+	var sb strings.Builder
+	for _, v := range vs {
+		if isGREASE(v) {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteString(strconv.Itoa(int(v)))
+	}
+	return sb.String()
+}
+
+func (h TLSClientHello) extensionTypes() []uint16 {
+	types := make([]uint16, len(h.Extensions))
+	for i, ext := range h.Extensions {
+		types[i] = uint16(ext.Type)
+	}
+	return types
+}
+
+// JA3 returns the JA3 fingerprint string of the ClientHello, i.e.
+// "Version,Ciphers,Extensions,Groups,PointFormats" with GREASE values removed.
+func (h TLSClientHello) JA3() string {
+	formats := make([]uint16, len(h.ECPointFormats))
+	for i, f := range h.ECPointFormats {
+		formats[i] = uint16(f)
+	}
+	return strings.Join([]string{
+		strconv.Itoa(int(h.Version)),
+		joinDecimal(h.CipherSuites),
+		joinDecimal(h.extensionTypes()),
+		joinDecimal(h.SupportedGroups),
+		joinDecimal(formats),
+	}, ",")
+}
+
+// JA3Hash returns the hex encoded MD5 hash of the JA3 string.
+func (h TLSClientHello) JA3Hash() string {
+	sum := md5.Sum([]byte(h.JA3()))
+	return hex.EncodeToString(sum[:])
+}
+
+// JA4 returns the JA4 fingerprint of a ClientHello sent over TCP.
+func (h TLSClientHello) JA4() string {
+	return h.ja4('t')
+}
+
+// ja4 returns the JA4 fingerprint with the given transport protocol
+// character, 't' for TCP or 'q' for QUIC.
+func (h TLSClientHello) ja4(proto byte) string {
+	version := h.Version
+	var highest TLSVersion
+	for _, v := range h.SupportedVersions {
+		if !isGREASE(uint16(v)) && v > highest {
+			highest = v
+		}
+	}
+	if highest != 0 {
+		version = highest
+	}
+	var ver string
+	switch version {
+	case TLSVersionTLS13:
+		ver = "13"
+	case TLSVersionTLS12:
+		ver = "12"
+	case TLSVersionTLS11:
+		ver = "11"
+	case TLSVersionTLS10:
+		ver = "10"
+	case TLSVersionSSL30:
+		ver = "s3"
+	default:
+		ver = "00"
+	}
+	sni := byte('i')
+	var ciphers, exts []string
+	for _, c := range h.CipherSuites {
+		if !isGREASE(c) {
+			ciphers = append(ciphers, fmt.Sprintf("%04x", c))
+		}
+	}
+	var nexts int
+	for _, ext := range h.Extensions {
+		if isGREASE(uint16(ext.Type)) {
+			continue
+		}
+		nexts++
+		switch ext.Type {
+		case TLSExtensionTypeServerName:
+			sni = 'd'
+		case TLSExtensionTypeALPN:
+		default:
+			exts = append(exts, fmt.Sprintf("%04x", uint16(ext.Type)))
+		}
+	}
+	alpn := "00"
+	if len(h.ALPN) > 0 && h.ALPN[0] != "" {
+		first := h.ALPN[0]
+		if !isAlnum(first[0]) || !isAlnum(first[len(first)-1]) {
+			first = hex.EncodeToString([]byte(first))
+		}
+		alpn = first[:1] + first[len(first)-1:]
+	}
+
+	sort.Strings(ciphers)
+	sort.Strings(exts)
+	extStr := strings.Join(exts, ",")
+	if len(h.SignatureAlgorithms) > 0 {
+		algs := make([]string, len(h.SignatureAlgorithms))
+		for i, alg := range h.SignatureAlgorithms {
+			algs[i] = fmt.Sprintf("%04x", alg)
+		}
+		extStr += "_" + strings.Join(algs, ",")
+	}
+	return fmt.Sprintf("%c%s%c%02d%02d%s_%s_%s", proto, ver, sni,
+		min99(len(ciphers)), min99(nexts), alpn,
+		ja4Hash(strings.Join(ciphers, ","), len(ciphers) == 0),
+		ja4Hash(extStr, len(exts) == 0))
+}
+
+func isAlnum(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func min99(n int) int {
+	if n > 99 {
+		return 99
+	}
+	return n
+}
+
+func ja4Hash(s string, empty bool) string {
+	if empty {
+		return "000000000000"
+	}
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:6])
+}
+
+// JA3S returns the JA3S fingerprint string of the ServerHello, i.e.
+// "Version,Cipher,Extensions".
+func (h TLSServerHello) JA3S() string {
+	exts := make([]uint16, len(h.Extensions))
+	for i, ext := range h.Extensions {
+		exts[i] = uint16(ext.Type)
+	}
+	return fmt.Sprintf("%d,%d,%s", h.Version, h.CipherSuite, joinDecimal(exts))
+}
+
+// JA3SHash returns the hex encoded MD5 hash of the JA3S string.
+func (h TLSServerHello) JA3SHash() string {
+	sum := md5.Sum([]byte(h.JA3S()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t TLS) Type() LayerType {
+	return LayerTypeTLS
+}
+
+func (t TLS) GetContents() []byte {
+	return t.Contents
+}
+
+func (t TLS) GetPayload() []byte {
+	return t.Payload
+}
+
+type tlsExtensionJSON struct {
+	Type   string `json:"type"`
+	Length int    `json:"length"`
+}
+
+func tlsExtensionsJSON(exts []TLSExtension) []tlsExtensionJSON {
+	res := make([]tlsExtensionJSON, len(exts))
+	for i, ext := range exts {
+		res[i] = tlsExtensionJSON{ext.Type.String(), len(ext.Data)}
+	}
+	return res
+}
+
+func tlsCipherSuiteNames(ids []uint16) []string {
+	res := make([]string, len(ids))
+	for i, id := range ids {
+		res[i] = tls.CipherSuiteName(id)
+	}
+	return res
+}
+
+func tlsVersionNames(vs []TLSVersion) []string {
+	res := make([]string, len(vs))
+	for i, v := range vs {
+		res[i] = v.String()
+	}
+	return res
+}
+
+func nonNilUint16s(vs []uint16) []uint16 {
+	if vs == nil {
+		return []uint16{}
+	}
+	return vs
+}
+
+func nonNilStrings(vs []string) []string {
+	if vs == nil {
+		return []string{}
+	}
+	return vs
+}
+
+func (h TLSClientHello) MarshalJSON() ([]byte, error) {
+	formats := make([]uint16, len(h.ECPointFormats))
+	for i, f := range h.ECPointFormats {
+		formats[i] = uint16(f)
+	}
+	return json.Marshal(struct {
+		Version             string             `json:"version"`
+		Random              string             `json:"random"`
+		SessionID           string             `json:"session_id"`
+		CipherSuites        []string           `json:"cipher_suites"`
+		Extensions          []tlsExtensionJSON `json:"extensions"`
+		ServerName          string             `json:"server_name"`
+		ALPN                []string           `json:"alpn"`
+		SupportedVersions   []string           `json:"supported_versions"`
+		SupportedGroups     []uint16           `json:"supported_groups"`
+		ECPointFormats      []uint16           `json:"ec_point_formats"`
+		SignatureAlgorithms []uint16           `json:"signature_algorithms"`
+		JA3                 string             `json:"ja3"`
+		JA3Hash             string             `json:"ja3_hash"`
+		JA4                 string             `json:"ja4"`
+	}{
+		Version:             h.Version.String(),
+		Random:              hex.EncodeToString(h.Random[:]),
+		SessionID:           hex.EncodeToString(h.SessionID),
+		CipherSuites:        tlsCipherSuiteNames(h.CipherSuites),
+		Extensions:          tlsExtensionsJSON(h.Extensions),
+		ServerName:          h.ServerName,
+		ALPN:                nonNilStrings(h.ALPN),
+		SupportedVersions:   tlsVersionNames(h.SupportedVersions),
+		SupportedGroups:     nonNilUint16s(h.SupportedGroups),
+		ECPointFormats:      formats,
+		SignatureAlgorithms: nonNilUint16s(h.SignatureAlgorithms),
+		JA3:                 h.JA3(),
+		JA3Hash:             h.JA3Hash(),
+		JA4:                 h.JA4(),
+	})
+}
+
+func (h TLSServerHello) MarshalJSON() ([]byte, error) {
+	var supported string
+	if h.SupportedVersion != 0 {
+		supported = h.SupportedVersion.String()
+	}
+	return json.Marshal(struct {
+		Version           string             `json:"version"`
+		Random            string             `json:"random"`
+		SessionID         string             `json:"session_id"`
+		CipherSuite       string             `json:"cipher_suite"`
+		CompressionMethod uint8              `json:"compression_method"`
+		Extensions        []tlsExtensionJSON `json:"extensions"`
+		SupportedVersion  string             `json:"supported_version"`
+		ALPN              string             `json:"alpn"`
+		JA3S              string             `json:"ja3s"`
+		JA3SHash          string             `json:"ja3s_hash"`
+	}{
+		Version:           h.Version.String(),
+		Random:            hex.EncodeToString(h.Random[:]),
+		SessionID:         hex.EncodeToString(h.SessionID),
+		CipherSuite:       tls.CipherSuiteName(h.CipherSuite),
+		CompressionMethod: h.CompressionMethod,
+		Extensions:        tlsExtensionsJSON(h.Extensions),
+		SupportedVersion:  supported,
+		ALPN:              h.ALPN,
+		JA3S:              h.JA3S(),
+		JA3SHash:          h.JA3SHash(),
+	})
+}
+
+func (t TLS) MarshalJSON() ([]byte, error) {
+	type record struct {
+		ContentType string `json:"content_type"`
+		Version     string `json:"version"`
+		Length      int    `json:"length"`
+	}
+	type handshake struct {
+		HandshakeType string `json:"handshake_type"`
+		Length        int    `json:"length"`
+	}
+	records := make([]record, len(t.Records))
+	for i, r := range t.Records {
+		records[i] = record{r.ContentType.String(), r.Version.String(), len(r.Fragment)}
+	}
+	handshakes := make([]handshake, len(t.Handshakes))
+	for i, h := range t.Handshakes {
+		handshakes[i] = handshake{h.HandshakeType.String(), len(h.Body)}
+	}
+	return json.Marshal(struct {
+		Type        string          `json:"type"`
+		Records     []record        `json:"records"`
+		Handshakes  []handshake     `json:"handshakes"`
+		ClientHello *TLSClientHello `json:"client_hello,omitempty"`
+		ServerHello *TLSServerHello `json:"server_hello,omitempty"`
+		Length      int             `json:"length"`
+	}{
+		Type:        t.Type().String(),
+		Records:     records,
+		Handshakes:  handshakes,
+		ClientHello: t.ClientHello,
+		ServerHello: t.ServerHello,
+		Length:      len(t.Contents),
+	})
+}
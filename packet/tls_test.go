@@ -0,0 +1,206 @@
+package packet_test
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// clientHello returns the first flight of a crypto/tls client.
+func clientHello(t *testing.T, config *tls.Config) []byte {
+	client, server := net.Pipe()
+	defer server.Close()
+	go func() {
+		tls.Client(client, config).Handshake()
+		client.Close()
+	}()
+	hdr := make([]byte, 5)
+	if _, err := server.Read(hdr); err != nil {
+		t.Fatal(err)
+	}
+	rec := make([]byte, 5+int(binary.BigEndian.Uint16(hdr[3:5])))
+	copy(rec, hdr)
+	for n := 5; n < len(rec); {
+		m, err := server.Read(rec[n:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		n += m
+	}
+	return rec
+}
+
+func TestTLSClientHello(t *testing.T) {
+	rec := clientHello(t, &tls.Config{
+		ServerName: "example.com",
+		NextProtos: []string{"h2", "http/1.1"},
+		MinVersion: tls.VersionTLS12,
+	})
+
+	tcp := make([]byte, 20)
+	binary.BigEndian.PutUint16(tcp[0:2], 50000)
+	binary.BigEndian.PutUint16(tcp[2:4], 443)
+	tcp[12] = 5 << 4
+	ip := []byte{
+		0x45, 0, 0, 0, 0, 0, 0, 0, 64, 6, 0, 0,
+		10, 0, 0, 1, 10, 0, 0, 2,
+	}
+	binary.BigEndian.PutUint16(ip[2:4], uint16(len(ip)+len(tcp)+len(rec)))
+	frame := append([]byte{
+		0x02, 0, 0, 0, 0, 0x0b, 0x02, 0, 0, 0, 0, 0x0a, 0x08, 0x00,
+	}, append(ip, append(tcp, rec...)...)...)
+
+	p, err := packet.Decode(frame)
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	tlsLayer, ok := p.Application.(*packet.TLS)
+	if !ok {
+		t.Fatalf("expected tls layer, got %T", p.Application)
+	}
+	hello := tlsLayer.ClientHello
+	if hello == nil {
+		t.Fatal("expected client hello")
+	}
+	if hello.ServerName != "example.com" {
+		t.Errorf("server name = %q, want example.com", hello.ServerName)
+	}
+	if strings.Join(hello.ALPN, ",") != "h2,http/1.1" {
+		t.Errorf("alpn = %v, want [h2 http/1.1]", hello.ALPN)
+	}
+	if hello.Version != packet.TLSVersionTLS12 {
+		t.Errorf("version = %v, want TLS 1.2", hello.Version)
+	}
+	var has13 bool
+	for _, v := range hello.SupportedVersions {
+		has13 = has13 || v == packet.TLSVersionTLS13
+	}
+	if !has13 {
+		t.Errorf("supported versions %v lack TLS 1.3", hello.SupportedVersions)
+	}
+
+	ja3 := strings.Split(hello.JA3(), ",")
+	if len(ja3) != 5 || ja3[0] != "771" {
+		t.Errorf("unexpected ja3 %q", hello.JA3())
+	}
+	if n := len(strings.Split(ja3[2], "-")); n != len(hello.Extensions) {
+		t.Errorf("ja3 has %d extensions, want %d", n, len(hello.Extensions))
+	}
+	if len(hello.JA3Hash()) != 32 {
+		t.Errorf("unexpected ja3 hash %q", hello.JA3Hash())
+	}
+	ja4 := hello.JA4()
+	if !strings.HasPrefix(ja4, "t13d") || !strings.Contains(ja4, "h2_") {
+		t.Errorf("unexpected ja4 %q", ja4)
+	}
+	if parts := strings.Split(ja4, "_"); len(parts) != 3 || len(parts[1]) != 12 ||
+		len(parts[2]) != 12 {
+		t.Errorf("unexpected ja4 %q", ja4)
+	}
+}
+
+func TestTLSFragmentedHandshake(t *testing.T) {
+	rec := clientHello(t, &tls.Config{ServerName: "example.com"})
+
+	// Split the handshake message over two records
+	body := rec[5:]
+	var stream []byte
+	for _, frag := range [][]byte{body[:10], body[10:]} {
+		stream = append(stream, 22, 3, 1, byte(len(frag)>>8), byte(len(frag)))
+		stream = append(stream, frag...)
+	}
+
+	var tlsLayer packet.TLS
+	if err := tlsLayer.Unmarshal(stream); err != nil {
+		t.Fatal(err)
+	}
+	if len(tlsLayer.Records) != 2 || len(tlsLayer.Handshakes) != 1 {
+		t.Fatalf("got %d records and %d handshakes, want 2 and 1",
+			len(tlsLayer.Records), len(tlsLayer.Handshakes))
+	}
+	if tlsLayer.ClientHello == nil || tlsLayer.ClientHello.ServerName != "example.com" {
+		t.Errorf("expected client hello for example.com")
+	}
+
+	if err := tlsLayer.Unmarshal(stream[:len(stream)-1]); err == nil {
+		t.Errorf("expected error for truncated record")
+	}
+}
+
+// tlsExt encodes a TLS extension.
+func tlsExt(typ uint16, data ...byte) []byte {
+	return append([]byte{byte(typ >> 8), byte(typ), byte(len(data) >> 8), byte(len(data))}, data...)
+}
+
+// clientHelloBody encodes the body of a ClientHello message without a
+// session ID.
+func clientHelloBody(version uint16, ciphers []uint16, exts ...[]byte) []byte {
+	b := []byte{byte(version >> 8), byte(version)}
+	b = append(b, make([]byte, 32)...)
+	b = append(b, 0, byte(len(ciphers)*2>>8), byte(len(ciphers)*2))
+	for _, c := range ciphers {
+		b = append(b, byte(c>>8), byte(c))
+	}
+	b = append(b, 1, 0)
+	var ext []byte
+	for _, e := range exts {
+		ext = append(ext, e...)
+	}
+	return append(append(b, byte(len(ext)>>8), byte(len(ext))), ext...)
+}
+
+func TestJA3KnownAnswer(t *testing.T) {
+	// The example of the JA3 README
+	body := clientHelloBody(769,
+		[]uint16{47, 53, 5, 10, 49161, 49162, 49171, 49172, 50, 56, 19, 4},
+		tlsExt(0, 0, 14, 0, 0, 11, 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm'),
+		tlsExt(10, 0, 6, 0, 23, 0, 24, 0, 25),
+		tlsExt(11, 1, 0))
+	var h packet.TLSClientHello
+	if err := h.Unmarshal(body); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.JA3(), "769,47-53-5-10-49161-49162-49171-49172-50-56-19-4,0-10-11,23-24-25,0"; got != want {
+		t.Errorf("ja3 = %q, want %q", got, want)
+	}
+	if got, want := h.JA3Hash(), "ada70206e40642a3e4461f35503241d5"; got != want {
+		t.Errorf("ja3 hash = %q, want %q", got, want)
+	}
+}
+
+func TestJA4KnownAnswer(t *testing.T) {
+	// A Chrome ClientHello with GREASE values, of which the JA4 technical
+	// details publish the fingerprint
+	body := clientHelloBody(0x0303,
+		[]uint16{0x4A4A, 0x1301, 0x1302, 0x1303, 0xC02B, 0xC02F, 0xC02C, 0xC030, 0xCCA9, 0xCCA8,
+			0xC013, 0xC014, 0x009C, 0x009D, 0x002F, 0x0035},
+		tlsExt(0x1A1A),
+		tlsExt(0x0000, 0, 14, 0, 0, 11, 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm'),
+		tlsExt(0x0017),
+		tlsExt(0xFF01, 0),
+		tlsExt(0x000A, 0, 8, 0x2A, 0x2A, 0, 0x1D, 0, 0x17, 0, 0x18),
+		tlsExt(0x000B, 1, 0),
+		tlsExt(0x0023),
+		tlsExt(0x0010, 0, 12, 2, 'h', '2', 8, 'h', 't', 't', 'p', '/', '1', '.', '1'),
+		tlsExt(0x0005, 1, 0, 0, 0, 0),
+		tlsExt(0x000D, 0, 16, 0x04, 0x03, 0x08, 0x04, 0x04, 0x01, 0x05, 0x03,
+			0x08, 0x05, 0x05, 0x01, 0x08, 0x06, 0x06, 0x01),
+		tlsExt(0x0012),
+		tlsExt(0x0033),
+		tlsExt(0x002D, 1, 1),
+		tlsExt(0x002B, 6, 0x6A, 0x6A, 0x03, 0x04, 0x03, 0x03),
+		tlsExt(0x001B, 2, 0, 2),
+		tlsExt(0x4469, 0, 3, 2, 'h', '2'),
+		tlsExt(0x0015, make([]byte, 16)...))
+	var h packet.TLSClientHello
+	if err := h.Unmarshal(body); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.JA4(), "t13d1516h2_8daaf6152771_e5627efa2ab1"; got != want {
+		t.Errorf("ja4 = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,75 @@
+package packet
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+)
+
+// Interface guards
+var (
+	_ Layer          = new(UDP)
+	_ json.Marshaler = new(UDP)
+)
+
+type UDP struct {
+	SourcePort      uint16
+	DestinationPort uint16
+	Length          uint16
+	Checksum        uint16
+	// Truncated is set if the datagram was captured without the end of its
+	// payload, in which case Contents and Payload end with the captured data.
+	Truncated bool
+	PacketBytes
+}
+
+func (u *UDP) Unmarshal(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("udp datagram too small: %w", ErrTruncated)
+	}
+	u.SourcePort = binary.BigEndian.Uint16(data[0:2])
+	u.DestinationPort = binary.BigEndian.Uint16(data[2:4])
+	u.Length = binary.BigEndian.Uint16(data[4:6])
+	u.Checksum = binary.BigEndian.Uint16(data[6:8])
+	if u.Length < 8 {
+		return fmt.Errorf("invalid udp length: %w", ErrInvalid)
+	}
+	end := int(u.Length)
+	u.Truncated = end > len(data)
+	if u.Truncated {
+		end = len(data)
+	}
+	u.Contents = data[:end]
+	u.Payload = data[8:end]
+	return nil
+}
+
+func (u UDP) Type() LayerType {
+	return LayerTypeUDP
+}
+
+func (u UDP) GetContents() []byte {
+	return u.Contents
+}
+
+func (u UDP) GetPayload() []byte {
+	return u.Payload
+}
+
+func (u UDP) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type            string `json:"type"`
+		SourcePort      uint16 `json:"source_port"`
+		DestinationPort uint16 `json:"destination_port"`
+		Length          uint16 `json:"length"`
+		Checksum        uint16 `json:"checksum"`
+		Truncated       bool   `json:"truncated,omitempty"`
+	}{
+		Type:            u.Type().String(),
+		SourcePort:      u.SourcePort,
+		DestinationPort: u.DestinationPort,
+		Length:          u.Length,
+		Checksum:        u.Checksum,
+		Truncated:       u.Truncated,
+	})
+}
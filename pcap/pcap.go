@@ -0,0 +1,170 @@
+// Package pcap reads and writes capture files in the classic libpcap format.
+package pcap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// LinkType is the link-layer header type of the packets in a capture file.
+type LinkType uint32
+
+const (
+	LinkTypeNull              LinkType = 0
+	LinkTypeEthernet          LinkType = 1
+	LinkTypeRaw               LinkType = 101
+	LinkTypeIEEE80211         LinkType = 105
+	LinkTypeLinuxSLL          LinkType = 113
+	LinkTypeIEEE80211Radiotap LinkType = 127
+)
+
+const (
+	magicMicros = 0xa1b2c3d4
+	magicNanos  = 0xa1b23c4d
+
+	fileHeaderLen   = 24
+	recordHeaderLen = 16
+
+	// DefaultSnapLen is the snapshot length used by tcpdump.
+	DefaultSnapLen = 262144
+
+	// maxCaptureLength bounds the buffer allocated for a packet, regardless
+	// of the snapshot length in the file header, which may be corrupt.
+	maxCaptureLength = 262144
+)
+
+// CaptureInfo contains the metadata of a captured packet.
+type CaptureInfo struct {
+	Timestamp time.Time
+
+	// CaptureLength is the number of bytes stored in the file.
+	CaptureLength int
+
+	// Length is the length of the packet on the wire.
+	Length int
+}
+
+// Reader reads packets from a capture file.
+type Reader struct {
+	r        io.Reader
+	order    binary.ByteOrder
+	nanos    bool
+	snapLen  uint32
+	linkType LinkType
+	hdr      [recordHeaderLen]byte
+}
+
+// NewReader reads the file header from r and returns a reader for its packets.
+func NewReader(r io.Reader) (*Reader, error) {
+	var hdr [fileHeaderLen]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("pcap: failed to read file header, %w", err)
+	}
+	pr := &Reader{r: r}
+	switch magic := binary.LittleEndian.Uint32(hdr[0:4]); magic {
+	case magicMicros, magicNanos:
+		pr.order = binary.LittleEndian
+		pr.nanos = magic == magicNanos
+	default:
+		switch magic := binary.BigEndian.Uint32(hdr[0:4]); magic {
+		case magicMicros, magicNanos:
+			pr.order = binary.BigEndian
+			pr.nanos = magic == magicNanos
+		default:
+			return nil, fmt.Errorf("pcap: unknown magic %#x", magic)
+		}
+	}
+	if major := pr.order.Uint16(hdr[4:6]); major != 2 {
+		return nil, fmt.Errorf("pcap: unsupported version %d", major)
+	}
+	pr.snapLen = pr.order.Uint32(hdr[16:20])
+	pr.linkType = LinkType(pr.order.Uint32(hdr[20:24]) & 0x0FFFFFFF)
+	return pr, nil
+}
+
+// LinkType returns the link-layer type of the packets in the file.
+func (r *Reader) LinkType() LinkType {
+	return r.linkType
+}
+
+// SnapLen returns the maximum number of bytes stored per packet.
+func (r *Reader) SnapLen() uint32 {
+	return r.snapLen
+}
+
+// ReadPacket reads the next packet from the file. It returns io.EOF when there
+// are no more packets.
+func (r *Reader) ReadPacket() ([]byte, CaptureInfo, error) {
+	var ci CaptureInfo
+	if _, err := io.ReadFull(r.r, r.hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ci, fmt.Errorf("pcap: truncated record header, %w", err)
+		}
+		return nil, ci, err
+	}
+	sec := int64(r.order.Uint32(r.hdr[0:4]))
+	frac := int64(r.order.Uint32(r.hdr[4:8]))
+	if !r.nanos {
+		frac *= 1000
+	}
+	ci.Timestamp = time.Unix(sec, frac).UTC()
+	ci.CaptureLength = int(r.order.Uint32(r.hdr[8:12]))
+	ci.Length = int(r.order.Uint32(r.hdr[12:16]))
+	if ci.CaptureLength > maxCaptureLength {
+		return nil, ci, fmt.Errorf("pcap: invalid capture length %d", ci.CaptureLength)
+	}
+	data := make([]byte, ci.CaptureLength)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return nil, ci, fmt.Errorf("pcap: truncated packet, %w", err)
+	}
+	return data, ci, nil
+}
+
+// Writer writes packets to a capture file.
+type Writer struct {
+	w       io.Writer
+	snapLen uint32
+	hdr     [recordHeaderLen]byte
+}
+
+// NewWriter writes a file header with nanosecond timestamps to w and returns a
+// writer for adding packets.
+func NewWriter(w io.Writer, linkType LinkType, snapLen uint32) (*Writer, error) {
+	if snapLen == 0 {
+		snapLen = DefaultSnapLen
+	}
+	var hdr [fileHeaderLen]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], magicNanos)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2)
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], snapLen)
+	binary.LittleEndian.PutUint32(hdr[20:24], uint32(linkType))
+	if _, err := w.Write(hdr[:]); err != nil {
+		return nil, err
+	}
+	return &Writer{w: w, snapLen: snapLen}, nil
+}
+
+// WritePacket writes a packet to the file. Packets longer than the snapshot
+// length are truncated. If ci.Length is zero, the length of data is used.
+func (w *Writer) WritePacket(ci CaptureInfo, data []byte) error {
+	if ci.Length == 0 {
+		ci.Length = len(data)
+	}
+	if len(data) > int(w.snapLen) {
+		data = data[:w.snapLen]
+	}
+	ts := ci.Timestamp.UnixNano()
+	binary.LittleEndian.PutUint32(w.hdr[0:4], uint32(ts/1e9))
+	binary.LittleEndian.PutUint32(w.hdr[4:8], uint32(ts%1e9))
+	binary.LittleEndian.PutUint32(w.hdr[8:12], uint32(len(data)))
+	binary.LittleEndian.PutUint32(w.hdr[12:16], uint32(ci.Length))
+	if _, err := w.w.Write(w.hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.w.Write(data)
+	return err
+}
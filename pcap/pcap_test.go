@@ -0,0 +1,141 @@
+package pcap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/pcap"
+)
+
+// bigEndian returns the capture file b, written by a Writer, with all header
+// fields in big endian byte order.
+func bigEndian(b []byte) []byte {
+	b = append([]byte{}, b...)
+	swap32 := func(b []byte) {
+		binary.BigEndian.PutUint32(b, binary.LittleEndian.Uint32(b))
+	}
+	swap32(b[0:4])
+	binary.BigEndian.PutUint16(b[4:6], binary.LittleEndian.Uint16(b[4:6]))
+	binary.BigEndian.PutUint16(b[6:8], binary.LittleEndian.Uint16(b[6:8]))
+	for off := 8; off < 24; off += 4 {
+		swap32(b[off : off+4])
+	}
+	for rec := b[24:]; len(rec) > 0; {
+		n := int(binary.LittleEndian.Uint32(rec[8:12]))
+		for off := 0; off < 16; off += 4 {
+			swap32(rec[off : off+4])
+		}
+		rec = rec[16+n:]
+	}
+	return b
+}
+
+func TestRoundTrip(t *testing.T) {
+	pkts := []struct {
+		ci   pcap.CaptureInfo
+		data []byte
+	}{
+		{pcap.CaptureInfo{Timestamp: time.Unix(1700000000, 123456789)}, []byte{1, 2, 3}},
+		{pcap.CaptureInfo{Timestamp: time.Unix(1700000001, 1), Length: 1500}, bytes.Repeat([]byte{0xAB}, 100)},
+	}
+	var buf bytes.Buffer
+	w, err := pcap.NewWriter(&buf, pcap.LinkTypeRaw, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range pkts {
+		if err := w.WritePacket(p.ci, p.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for name, file := range map[string][]byte{
+		"little endian": buf.Bytes(),
+		"big endian":    bigEndian(buf.Bytes()),
+	} {
+		r, err := pcap.NewReader(bytes.NewReader(file))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if r.LinkType() != pcap.LinkTypeRaw || r.SnapLen() != 64 {
+			t.Errorf("%s: unexpected link type %v and snap length %d", name, r.LinkType(), r.SnapLen())
+		}
+		for i, p := range pkts {
+			data, ci, err := r.ReadPacket()
+			if err != nil {
+				t.Fatalf("%s: packet %d: %v", name, i, err)
+			}
+			want := p.data
+			if len(want) > 64 {
+				want = want[:64]
+			}
+			length := p.ci.Length
+			if length == 0 {
+				length = len(p.data)
+			}
+			// Timestamps keep their nanoseconds
+			if !ci.Timestamp.Equal(p.ci.Timestamp) || ci.CaptureLength != len(want) || ci.Length != length {
+				t.Errorf("%s: packet %d: unexpected capture info %+v", name, i, ci)
+			}
+			if !bytes.Equal(data, want) {
+				t.Errorf("%s: packet %d: got %x, want %x", name, i, data, want)
+			}
+		}
+		if _, _, err := r.ReadPacket(); !errors.Is(err, io.EOF) {
+			t.Errorf("%s: got %v, want EOF", name, err)
+		}
+	}
+}
+
+// file returns a capture file with microsecond timestamps, in big endian byte
+// order, with a single record header which claims caplen bytes.
+func file(snapLen, caplen uint32, data []byte) []byte {
+	b := make([]byte, 24+16)
+	binary.BigEndian.PutUint32(b[0:4], 0xa1b2c3d4)
+	binary.BigEndian.PutUint16(b[4:6], 2)
+	binary.BigEndian.PutUint16(b[6:8], 4)
+	binary.BigEndian.PutUint32(b[16:20], snapLen)
+	binary.BigEndian.PutUint32(b[20:24], uint32(pcap.LinkTypeEthernet))
+	binary.BigEndian.PutUint32(b[24:28], 1)
+	binary.BigEndian.PutUint32(b[28:32], 5)
+	binary.BigEndian.PutUint32(b[32:36], caplen)
+	binary.BigEndian.PutUint32(b[36:40], caplen)
+	return append(b, data...)
+}
+
+func TestMicroseconds(t *testing.T) {
+	r, err := pcap.NewReader(bytes.NewReader(file(65535, 2, []byte{1, 2})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ci, err := r.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ci.Timestamp.Equal(time.Unix(1, 5000)) || !bytes.Equal(data, []byte{1, 2}) {
+		t.Errorf("unexpected packet %x at %v", data, ci.Timestamp)
+	}
+}
+
+func TestCaptureLengthLimit(t *testing.T) {
+	// The capture length is bounded regardless of the snap length
+	r, err := pcap.NewReader(bytes.NewReader(file(0xFFFFFFFF, 262145, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.ReadPacket(); err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("got %v, want an invalid capture length error", err)
+	}
+
+	r, err = pcap.NewReader(bytes.NewReader(file(0xFFFFFFFF, 262144, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := r.ReadPacket(); !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want a truncated packet error", err)
+	}
+}
@@ -0,0 +1,223 @@
+// Package reassembly reassembles the byte streams of TCP connections from
+// decoded packets.
+//
+// Each direction of a connection is a separate stream, created by a
+// StreamFactory when the first segment of the direction is seen. Segments
+// which arrive out of order are buffered until the missing data arrives, or
+// until the buffer limit is reached, in which case the missing bytes are
+// reported as a gap.
+package reassembly
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// Stream receives the reassembled bytes of one direction of a connection.
+type Stream interface {
+	// Data is called with the next bytes of the stream, and the capture time
+	// of the segment which carried them. Data is only valid during the call.
+	Data(data []byte, ts time.Time)
+
+	// Gap is called when n bytes of the stream were lost before the data of
+	// the next call to Data.
+	Gap(n int)
+
+	// End is called once when the stream is closed by FIN or RST, or when it
+	// is flushed. No other calls follow.
+	End()
+}
+
+// StreamFactory creates streams for new connection directions.
+type StreamFactory interface {
+	New(flow packet.Flow) Stream
+}
+
+// DefaultMaxBuffered is the default limit of out-of-order bytes which are
+// buffered per stream.
+const DefaultMaxBuffered = 1 << 20
+
+// Assembler reassembles TCP streams. It is not safe for concurrent use.
+type Assembler struct {
+	// MaxBuffered limits the number of out-of-order bytes buffered per stream.
+	// When exceeded, the data up to the first buffered segment is skipped.
+	MaxBuffered int
+
+	factory StreamFactory
+	streams map[packet.Flow]*stream
+}
+
+// NewAssembler returns an assembler which creates streams using f.
+func NewAssembler(f StreamFactory) *Assembler {
+	return &Assembler{
+		MaxBuffered: DefaultMaxBuffered,
+		factory:     f,
+		streams:     make(map[packet.Flow]*stream),
+	}
+}
+
+type segment struct {
+	seq  uint32
+	data []byte
+	fin  bool
+	ts   time.Time
+}
+
+type stream struct {
+	s        Stream
+	started  bool
+	next     uint32
+	pending  []segment
+	buffered int
+	lastSeen time.Time
+}
+
+// seqDiff returns a-b, accounting for wrap-around.
+func seqDiff(a, b uint32) int {
+	return int(int32(a - b))
+}
+
+// Assemble adds a packet captured at ts to its stream. Packets without a TCP
+// transport layer are ignored.
+func (a *Assembler) Assemble(p *packet.Packet, ts time.Time) {
+	tcp, ok := p.Transport.(*packet.TCP)
+	if !ok {
+		return
+	}
+	flow, ok := p.Flow()
+	if !ok {
+		return
+	}
+	fin := tcp.Flags&packet.TCPFlagFIN != 0
+	st := a.streams[flow]
+	if st == nil {
+		// Resets and pure ACKs do not start streams, such as the final ACK
+		// of a direction which was closed by FIN
+		if tcp.Flags&packet.TCPFlagRST != 0 ||
+			len(tcp.Payload) == 0 && !fin && tcp.Flags&packet.TCPFlagSYN == 0 {
+			return
+		}
+		st = &stream{s: a.factory.New(flow)}
+		a.streams[flow] = st
+	}
+	st.lastSeen = ts
+
+	if tcp.Flags&packet.TCPFlagRST != 0 {
+		a.close(flow, st)
+		return
+	}
+
+	seq := tcp.Seq
+	if tcp.Flags&packet.TCPFlagSYN != 0 {
+		// The SYN consumes one sequence number
+		st.started = true
+		st.next = seq + 1
+		seq++
+	} else if !st.started {
+		// The capture started mid-stream
+		st.started = true
+		st.next = seq
+	}
+	if len(tcp.Payload) == 0 && !fin {
+		return
+	}
+
+	seg := segment{seq: seq, data: tcp.Payload, fin: fin, ts: ts}
+	if seqDiff(seq, st.next) > 0 {
+		seg.data = append([]byte{}, seg.data...)
+		st.insert(seg)
+		if st.buffered > a.MaxBuffered {
+			st.s.Gap(seqDiff(st.pending[0].seq, st.next))
+			st.next = st.pending[0].seq
+		}
+	} else if st.deliver(seg) {
+		a.close(flow, st)
+		return
+	}
+	if st.drain() {
+		a.close(flow, st)
+	}
+}
+
+// insert adds an out-of-order segment to the pending segments, which are
+// kept sorted by sequence number.
+func (st *stream) insert(seg segment) {
+	i := sort.Search(len(st.pending), func(i int) bool {
+		return seqDiff(st.pending[i].seq, seg.seq) > 0
+	})
+	st.pending = append(st.pending, segment{})
+	copy(st.pending[i+1:], st.pending[i:])
+	st.pending[i] = seg
+	st.buffered += len(seg.data)
+}
+
+// deliver passes the part of seg which has not been delivered yet to the
+// stream. The segment must not start after the next expected byte. It reports
+// whether the stream has ended.
+func (st *stream) deliver(seg segment) bool {
+	if skip := seqDiff(st.next, seg.seq); skip < len(seg.data) {
+		data := seg.data[skip:]
+		st.s.Data(data, seg.ts)
+		st.next += uint32(len(data))
+	}
+	return seg.fin && seqDiff(seg.seq+uint32(len(seg.data)), st.next) <= 0
+}
+
+// drain delivers pending segments which have become contiguous with the
+// stream. It reports whether the stream has ended.
+func (st *stream) drain() bool {
+	for len(st.pending) > 0 && seqDiff(st.pending[0].seq, st.next) <= 0 {
+		seg := st.pending[0]
+		st.pending = st.pending[1:]
+		st.buffered -= len(seg.data)
+		if st.deliver(seg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Assembler) close(flow packet.Flow, st *stream) {
+	delete(a.streams, flow)
+	st.s.End()
+}
+
+// FlushOlderThan closes the streams which have not seen any packets since t.
+// Buffered data is delivered first, with the missing bytes reported as gaps.
+// It returns the number of closed streams.
+func (a *Assembler) FlushOlderThan(t time.Time) int {
+	var n int
+	for flow, st := range a.streams {
+		if !st.lastSeen.Before(t) {
+			continue
+		}
+		st.flush()
+		a.close(flow, st)
+		n++
+	}
+	return n
+}
+
+// FlushAll closes all streams, e.g. at the end of a capture file.
+func (a *Assembler) FlushAll() int {
+	n := len(a.streams)
+	for flow, st := range a.streams {
+		st.flush()
+		a.close(flow, st)
+	}
+	return n
+}
+
+func (st *stream) flush() {
+	for len(st.pending) > 0 {
+		if gap := seqDiff(st.pending[0].seq, st.next); gap > 0 {
+			st.s.Gap(gap)
+			st.next = st.pending[0].seq
+		}
+		if st.drain() {
+			return
+		}
+	}
+}
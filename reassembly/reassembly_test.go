@@ -0,0 +1,158 @@
+package reassembly_test
+
+import (
+	"net/netip"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/reassembly"
+)
+
+var (
+	client = netip.MustParseAddr("192.0.2.1")
+	server = netip.MustParseAddr("192.0.2.2")
+)
+
+// recorder records the calls to a stream as a string, with gaps as "[n]" and
+// the end as "|".
+type recorder struct {
+	sb strings.Builder
+}
+
+func (r *recorder) Data(data []byte, ts time.Time) { r.sb.Write(data) }
+func (r *recorder) Gap(n int)                      { r.sb.WriteString("[" + strconv.Itoa(n) + "]") }
+func (r *recorder) End()                           { r.sb.WriteString("|") }
+
+type factory struct {
+	streams map[packet.Flow]*recorder
+	created int
+}
+
+func (f *factory) New(flow packet.Flow) reassembly.Stream {
+	r := new(recorder)
+	f.streams[flow] = r
+	f.created++
+	return r
+}
+
+func newAssembler() (*reassembly.Assembler, *factory) {
+	f := &factory{streams: make(map[packet.Flow]*recorder)}
+	return reassembly.NewAssembler(f), f
+}
+
+// segment returns a packet from client to server.
+func segment(seq uint32, flags packet.TCPFlags, data string) *packet.Packet {
+	tcp := &packet.TCP{SourcePort: 40000, DestinationPort: 80, Seq: seq, Flags: flags | packet.TCPFlagACK}
+	tcp.Payload = []byte(data)
+	return &packet.Packet{
+		Network:   &packet.IPv4{Source: client, Destination: server},
+		Transport: tcp,
+	}
+}
+
+func (f *factory) output(t *testing.T) string {
+	t.Helper()
+	p := segment(0, 0, "")
+	flow, _ := p.Flow()
+	r := f.streams[flow]
+	if r == nil {
+		t.Fatal("stream was not created")
+	}
+	return r.sb.String()
+}
+
+func TestOutOfOrder(t *testing.T) {
+	a, f := newAssembler()
+	ts := time.Unix(1, 0)
+	a.Assemble(segment(99, packet.TCPFlagSYN, ""), ts)
+	a.Assemble(segment(106, 0, "world"), ts)
+	a.Assemble(segment(111, packet.TCPFlagFIN, "!"), ts)
+	a.Assemble(segment(100, 0, "hello "), ts)
+	if got := f.output(t); got != "hello world!|" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	a, f := newAssembler()
+	ts := time.Unix(1, 0)
+	a.Assemble(segment(1000, 0, "abcd"), ts)
+	// Retransmission with new data, and a buffered segment which overlaps
+	// the delivered data
+	a.Assemble(segment(1006, 0, "ghij"), ts)
+	a.Assemble(segment(1002, 0, "cdef"), ts)
+	a.Assemble(segment(1000, 0, "abcd"), ts)
+	a.FlushAll()
+	if got := f.output(t); got != "abcdefghij|" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestMaxBuffered(t *testing.T) {
+	a, f := newAssembler()
+	a.MaxBuffered = 8
+	ts := time.Unix(1, 0)
+	a.Assemble(segment(0, 0, "ab"), ts)
+	a.Assemble(segment(10, 0, "klmno"), ts)
+	// Exceeding the limit skips the missing bytes up to the first buffered
+	// segment
+	a.Assemble(segment(20, 0, "uvwx"), ts)
+	if got := f.output(t); got != "ab[8]klmno" {
+		t.Errorf("got %q", got)
+	}
+	a.FlushAll()
+	if got := f.output(t); got != "ab[8]klmno[5]uvwx|" {
+		t.Errorf("got %q after flush", got)
+	}
+}
+
+func TestSequenceWraparound(t *testing.T) {
+	a, f := newAssembler()
+	ts := time.Unix(1, 0)
+	a.Assemble(segment(1<<32-4, packet.TCPFlagSYN, ""), ts)
+	a.Assemble(segment(0, 0, "defg"), ts)
+	a.Assemble(segment(1<<32-3, 0, "abc"), ts)
+	a.Assemble(segment(4, packet.TCPFlagFIN, ""), ts)
+	if got := f.output(t); got != "abcdefg|" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestClose(t *testing.T) {
+	a, f := newAssembler()
+	ts := time.Unix(1, 0)
+	a.Assemble(segment(0, 0, "data"), ts)
+	a.Assemble(segment(4, packet.TCPFlagFIN, ""), ts)
+	// The final ACK of the closed direction does not start a new stream
+	a.Assemble(segment(5, 0, ""), ts)
+	if got := f.output(t); got != "data|" || f.created != 1 {
+		t.Errorf("got %q from %d streams", got, f.created)
+	}
+	if n := a.FlushAll(); n != 0 {
+		t.Errorf("flushed %d streams, want 0", n)
+	}
+
+	// Resets end the stream without delivering buffered data
+	a, f = newAssembler()
+	a.Assemble(segment(0, 0, "ab"), ts)
+	a.Assemble(segment(4, 0, "ef"), ts)
+	a.Assemble(segment(2, packet.TCPFlagRST, ""), ts)
+	a.Assemble(segment(6, packet.TCPFlagRST, ""), ts)
+	if got := f.output(t); got != "ab|" || f.created != 1 {
+		t.Errorf("got %q from %d streams after reset", got, f.created)
+	}
+}
+
+func TestFlushOlderThan(t *testing.T) {
+	a, f := newAssembler()
+	a.Assemble(segment(0, 0, "ab"), time.Unix(1, 0))
+	if n := a.FlushOlderThan(time.Unix(1, 0)); n != 0 {
+		t.Errorf("flushed %d streams, want 0", n)
+	}
+	if n := a.FlushOlderThan(time.Unix(2, 0)); n != 1 || f.output(t) != "ab|" {
+		t.Errorf("flushed %d streams with %q", n, f.output(t))
+	}
+}
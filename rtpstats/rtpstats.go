@@ -0,0 +1,297 @@
+// Package rtpstats computes the quality metrics of RTP streams from captured
+// packets.
+//
+// RTP uses dynamic ports, which are usually negotiated by SDP, so the
+// analyzer decodes the UDP datagrams of the ports it is given as RTP.
+//
+// An Analyzer tracks each stream by its flow and SSRC, following the
+// algorithms of RFC 3550 appendix A: sequence numbers are extended across
+// wraparounds, large jumps are counted as sequence errors and resynchronize
+// the stream, and the interarrival jitter is estimated from the capture times
+// and RTP timestamps of the packets.
+package rtpstats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+)
+
+// Thresholds of the sequence number validation of RFC 3550 appendix A.1.
+const (
+	maxDropout  = 3000
+	maxMisorder = 100
+)
+
+// clockRates contains the clock rates of the static payload types of RFC
+// 3551.
+var clockRates = map[uint8]int{
+	0:  8000,  // PCMU
+	3:  8000,  // GSM
+	4:  8000,  // G723
+	5:  8000,  // DVI4
+	6:  16000, // DVI4
+	7:  8000,  // LPC
+	8:  8000,  // PCMA
+	9:  8000,  // G722
+	10: 44100, // L16 stereo
+	11: 44100, // L16 mono
+	12: 8000,  // QCELP
+	13: 8000,  // CN
+	14: 90000, // MPA
+	15: 8000,  // G728
+	18: 8000,  // G729
+	25: 90000, // CelB
+	26: 90000, // JPEG
+	28: 90000, // nv
+	31: 90000, // H261
+	32: 90000, // MPV
+	33: 90000, // MP2T
+	34: 90000, // H263
+}
+
+// StreamKey identifies a stream by its flow and synchronization source.
+type StreamKey struct {
+	Flow packet.Flow
+	SSRC uint32
+}
+
+// Stream contains the metrics of an RTP stream.
+type Stream struct {
+	StreamKey
+	PayloadType uint8
+	First       time.Time
+	Last        time.Time
+	Packets     uint64
+	// Octets is the number of payload octets.
+	Octets uint64
+	// Expected is the number of packets expected from the extended sequence
+	// numbers, and Lost is the difference to the number of packets received,
+	// which is negative if duplicates were received.
+	Expected uint64
+	Lost     int64
+	// OutOfOrder counts packets which were older than the highest sequence
+	// number, including duplicates.
+	OutOfOrder uint64
+	// SequenceErrors counts jumps of the sequence number beyond the dropout
+	// and misorder thresholds.
+	SequenceErrors uint64
+	// Jitter is the interarrival jitter, or zero if the clock rate of the
+	// payload type is unknown.
+	Jitter time.Duration
+	// MaxJitter is the largest jitter of the stream.
+	MaxJitter time.Duration
+}
+
+// LossRate returns the fraction of the expected packets which were lost.
+func (s Stream) LossRate() float64 {
+	if s.Expected == 0 || s.Lost <= 0 {
+		return 0
+	}
+	return float64(s.Lost) / float64(s.Expected)
+}
+
+type stream struct {
+	Stream
+	// Sequence state of RFC 3550 appendix A.1
+	baseSeq  uint32
+	maxSeq   uint16
+	cycles   uint32
+	badSeq   uint32
+	received uint64
+	// Expected and received packets before the last resynchronization
+	priorExpected uint64
+	priorReceived uint64
+	// Jitter state of RFC 3550 appendix A.8, in units of the RTP clock
+	rate       int
+	transit    uint32
+	hasTransit bool
+	jitter     float64
+}
+
+// Analyzer computes the metrics of the RTP streams of packets. It is safe for
+// concurrent use.
+type Analyzer struct {
+	mu      sync.Mutex
+	ports   map[uint16]bool
+	rates   map[uint8]int
+	streams map[StreamKey]*stream
+}
+
+// NewAnalyzer returns an analyzer of the RTP streams of the UDP ports, which
+// knows the clock rates of the static payload types.
+func NewAnalyzer(ports ...uint16) *Analyzer {
+	a := &Analyzer{
+		ports:   make(map[uint16]bool),
+		rates:   make(map[uint8]int),
+		streams: make(map[StreamKey]*stream),
+	}
+	for _, port := range ports {
+		a.ports[port] = true
+	}
+	for pt, hz := range clockRates {
+		a.rates[pt] = hz
+	}
+	return a
+}
+
+// AddPort adds a UDP port whose datagrams are decoded as RTP, such as a media
+// port learned from SDP.
+func (a *Analyzer) AddPort(port uint16) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.ports[port] = true
+}
+
+// SetClockRate sets the clock rate of a payload type, which is needed to
+// compute the jitter of dynamic payload types, as negotiated by SDP.
+func (a *Analyzer) SetClockRate(pt uint8, hz int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.rates[pt] = hz
+}
+
+// Add adds a packet captured at ts. Packets whose source or destination port
+// was given to the analyzer are decoded as RTP, unless they already contain
+// an RTP layer. It reports false if the packet is not an RTP packet.
+func (a *Analyzer) Add(p packet.Packet, ts time.Time) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if udp, ok := p.Transport.(*packet.UDP); ok && p.Application == nil &&
+		(a.ports[udp.SourcePort] || a.ports[udp.DestinationPort]) {
+		_ = p.DecodeRTP()
+	}
+	rtp, ok := p.Application.(*packet.RTP)
+	if !ok {
+		return false
+	}
+	f, _ := p.Flow()
+	key := StreamKey{Flow: f, SSRC: rtp.SSRC}
+
+	s := a.streams[key]
+	if s == nil {
+		s = &stream{Stream: Stream{StreamKey: key, PayloadType: rtp.PayloadType, First: ts}}
+		s.rate = a.rates[rtp.PayloadType]
+		s.init(rtp.Sequence)
+		a.streams[key] = s
+	}
+	if rtp.PayloadType != s.PayloadType {
+		// The clock rate may change with the payload type
+		s.PayloadType = rtp.PayloadType
+		s.rate = a.rates[rtp.PayloadType]
+		s.hasTransit = false
+	}
+	s.Packets++
+	s.Octets += uint64(len(rtp.Payload))
+	s.Last = ts
+	if s.updateSeq(rtp.Sequence) {
+		s.updateJitter(rtp.Timestamp, ts)
+	}
+	s.Expected = s.priorExpected + s.expected()
+	s.Lost = int64(s.Expected) - int64(s.priorReceived+s.received)
+	return true
+}
+
+// init resets the sequence state to seq.
+func (s *stream) init(seq uint16) {
+	s.baseSeq = uint32(seq)
+	s.maxSeq = seq
+	s.badSeq = 1<<16 + 1
+	s.cycles = 0
+	s.received = 0
+}
+
+// updateSeq updates the sequence state, and reports whether the packet is in
+// sequence, so that it can be used for the jitter estimate.
+func (s *stream) updateSeq(seq uint16) bool {
+	delta := seq - s.maxSeq
+	inOrder := true
+	switch {
+	case s.received == 0:
+	case delta < maxDropout:
+		if seq < s.maxSeq {
+			s.cycles += 1 << 16
+		}
+		s.maxSeq = seq
+	case delta <= 1<<16-maxMisorder:
+		// A large jump. Two sequential packets after a jump are taken as a
+		// restart of the source.
+		if uint32(seq) != s.badSeq {
+			s.badSeq = uint32(seq+1) & 0xFFFF
+			s.SequenceErrors++
+			return false
+		}
+		s.priorExpected += s.expected()
+		s.priorReceived += s.received
+		s.init(seq)
+		s.hasTransit = false
+	default:
+		// Duplicate or reordered packet
+		s.OutOfOrder++
+		inOrder = false
+	}
+	s.received++
+	return inOrder
+}
+
+// expected returns the number of packets expected since the last
+// resynchronization.
+func (s *stream) expected() uint64 {
+	if s.received == 0 {
+		return 0
+	}
+	return uint64(s.cycles) + uint64(s.maxSeq) - uint64(s.baseSeq) + 1
+}
+
+// updateJitter updates the interarrival jitter from the difference of the
+// transit times of consecutive packets.
+func (s *stream) updateJitter(rtpTime uint32, ts time.Time) {
+	if s.rate == 0 {
+		return
+	}
+	rate := int64(s.rate)
+	arrival := uint32(ts.Unix()*rate + int64(ts.Nanosecond())*rate/int64(time.Second))
+	transit := arrival - rtpTime
+	if s.hasTransit {
+		d := float64(int32(transit - s.transit))
+		if d < 0 {
+			d = -d
+		}
+		s.jitter += (d - s.jitter) / 16
+		s.Jitter = time.Duration(s.jitter * float64(time.Second) / float64(s.rate))
+		if s.Jitter > s.MaxJitter {
+			s.MaxJitter = s.Jitter
+		}
+	}
+	s.transit, s.hasTransit = transit, true
+}
+
+// Stream returns the metrics of a stream.
+func (a *Analyzer) Stream(key StreamKey) (Stream, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.streams[key]
+	if !ok {
+		return Stream{}, false
+	}
+	return s.Stream, true
+}
+
+// Streams returns the metrics of all streams, ordered by their first packet.
+func (a *Analyzer) Streams() []Stream {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	streams := make([]Stream, 0, len(a.streams))
+	for _, s := range a.streams {
+		streams = append(streams, s.Stream)
+	}
+	sort.Slice(streams, func(i, j int) bool {
+		if !streams[i].First.Equal(streams[j].First) {
+			return streams[i].First.Before(streams[j].First)
+		}
+		return streams[i].SSRC < streams[j].SSRC
+	})
+	return streams
+}
@@ -0,0 +1,145 @@
+package rtpstats_test
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/rtpstats"
+)
+
+// rtpPort is the destination port of the packets returned by rtp.
+const rtpPort = 16386
+
+// rtp returns a packet of a PCMU stream.
+func rtp(t *testing.T, ssrc uint32, seq uint16, ts uint32) packet.Packet {
+	return rtpType(t, 0, ssrc, seq, ts)
+}
+
+// rtpType returns a packet of a stream with the payload type.
+func rtpType(t *testing.T, pt uint8, ssrc uint32, seq uint16, ts uint32) packet.Packet {
+	payload := make([]byte, 12+160)
+	payload[0] = 0x80
+	payload[1] = pt
+	binary.BigEndian.PutUint16(payload[2:4], seq)
+	binary.BigEndian.PutUint32(payload[4:8], ts)
+	binary.BigEndian.PutUint32(payload[8:12], ssrc)
+
+	udp := make([]byte, 8)
+	binary.BigEndian.PutUint16(udp[0:2], 16384)
+	binary.BigEndian.PutUint16(udp[2:4], rtpPort)
+	binary.BigEndian.PutUint16(udp[4:6], uint16(8+len(payload)))
+	udp = append(udp, payload...)
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(udp)))
+	ip[8] = 64
+	ip[9] = 17
+	copy(ip[12:16], []byte{10, 0, 0, 1})
+	copy(ip[16:20], []byte{10, 0, 0, 2})
+
+	frame := append([]byte{2, 0, 0, 0, 0, 2, 2, 0, 0, 0, 0, 1, 0x08, 0x00}, ip...)
+	p, err := packet.Decode(append(frame, udp...))
+	if err != nil {
+		t.Fatalf("decode failed, %v", err)
+	}
+	return p
+}
+
+func TestLossAndReordering(t *testing.T) {
+	a := rtpstats.NewAnalyzer(rtpPort)
+	start := time.Unix(1700000000, 0)
+	// Sequence numbers wrap, 65534 is lost and 1 arrives late
+	for i, seq := range []uint16{65530, 65531, 65532, 65533, 65535, 0, 2, 1, 3} {
+		if !a.Add(rtp(t, 42, seq, uint32(i)*160), start.Add(time.Duration(i)*20*time.Millisecond)) {
+			t.Fatal("packet was not added")
+		}
+	}
+	streams := a.Streams()
+	if len(streams) != 1 {
+		t.Fatalf("got %d streams, want 1", len(streams))
+	}
+	s := streams[0]
+	if s.SSRC != 42 || s.Packets != 9 || s.Expected != 10 || s.Lost != 1 || s.OutOfOrder != 1 {
+		t.Errorf("unexpected stream %+v", s)
+	}
+	if s.LossRate() != 0.1 {
+		t.Errorf("loss rate is %v, want 0.1", s.LossRate())
+	}
+	if s.Octets != 9*160 {
+		t.Errorf("got %d octets, want %d", s.Octets, 9*160)
+	}
+}
+
+func TestSequenceError(t *testing.T) {
+	a := rtpstats.NewAnalyzer(rtpPort)
+	ts := time.Unix(1700000000, 0)
+	add := func(seq uint16) {
+		ts = ts.Add(20 * time.Millisecond)
+		a.Add(rtp(t, 7, seq, 0), ts)
+	}
+	add(100)
+	add(101)
+	// A single stray packet is a sequence error, while two sequential
+	// packets restart the stream, without counting the first of them
+	add(20000)
+	add(102)
+	add(30000)
+	add(30001)
+	add(30002)
+	s := a.Streams()[0]
+	if s.SequenceErrors != 2 || s.Expected != 5 || s.Lost != 0 {
+		t.Errorf("unexpected stream %+v", s)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	a := rtpstats.NewAnalyzer(rtpPort)
+	start := time.Unix(1700000000, 0)
+	// Packets are sent every 20ms, and arrive alternately 0 and 10ms late
+	for i := 0; i < 200; i++ {
+		arrival := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		if i%2 == 1 {
+			arrival = arrival.Add(10 * time.Millisecond)
+		}
+		a.Add(rtp(t, 1, uint16(i), uint32(i)*160), arrival)
+	}
+	// The jitter converges to the mean deviation of 10ms
+	s := a.Streams()[0]
+	if s.Jitter < 9*time.Millisecond || s.Jitter > 10*time.Millisecond || s.MaxJitter < s.Jitter {
+		t.Errorf("unexpected jitter %v, max %v", s.Jitter, s.MaxJitter)
+	}
+
+	// Dynamic payload types have no jitter unless their clock rate is set
+	a = rtpstats.NewAnalyzer(rtpPort)
+	for i := 0; i < 10; i++ {
+		a.Add(rtpType(t, 111, 1, uint16(i), uint32(i)*960), start.Add(time.Duration(i)*30*time.Millisecond))
+	}
+	if s := a.Streams()[0]; s.Jitter != 0 || s.PayloadType != 111 {
+		t.Errorf("unexpected stream %+v", s)
+	}
+}
+
+func TestPorts(t *testing.T) {
+	// Datagrams of other ports are not decoded as RTP
+	a := rtpstats.NewAnalyzer(5004)
+	start := time.Unix(1700000000, 0)
+	if a.Add(rtp(t, 1, 1, 0), start) || len(a.Streams()) != 0 {
+		t.Fatal("packet of another port was added")
+	}
+	a.AddPort(rtpPort)
+	if !a.Add(rtp(t, 1, 2, 160), start) {
+		t.Fatal("packet was not added")
+	}
+
+	// Packets which were decoded by the caller are added regardless of port
+	p := rtp(t, 2, 1, 0)
+	if err := p.DecodeRTP(); err != nil {
+		t.Fatal(err)
+	}
+	if !rtpstats.NewAnalyzer().Add(p, start) {
+		t.Error("decoded packet was not added")
+	}
+}
@@ -0,0 +1,409 @@
+// Package stats aggregates statistics over streams of decoded packets.
+//
+// A Stats value counts packets incrementally, either from a live capture such
+// as an afpacket.Conn, from a capture file, or from packets which were decoded
+// elsewhere. Reports can be taken at any time, also while a capture is
+// running. They contain the protocol hierarchy, the top talkers,
+// conversations, a histogram of frame sizes and decode errors by reason.
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/pcap"
+)
+
+// sizeBounds are the upper bounds of the frame size buckets, as used by
+// Wireshark. The last bucket has no upper bound.
+var sizeBounds = []int{19, 39, 79, 159, 319, 639, 1279, 2559, 5119}
+
+// Stats aggregates packet statistics. It is safe for concurrent use.
+type Stats struct {
+	mu      sync.Mutex
+	packets uint64
+	bytes   uint64
+	first   time.Time
+	last    time.Time
+	root    *node
+	talkers map[netip.Addr]*Talker
+	convs   map[packet.Flow]*Conversation
+	sizes   []uint64
+	errs    map[string]uint64
+}
+
+type node struct {
+	packets  uint64
+	bytes    uint64
+	children map[packet.LayerType]*node
+}
+
+func (n *node) child(t packet.LayerType) *node {
+	c, ok := n.children[t]
+	if !ok {
+		c = &node{children: make(map[packet.LayerType]*node)}
+		n.children[t] = c
+	}
+	return c
+}
+
+// New returns an empty aggregator.
+func New() *Stats {
+	return &Stats{
+		root:    &node{children: make(map[packet.LayerType]*node)},
+		talkers: make(map[netip.Addr]*Talker),
+		convs:   make(map[packet.Flow]*Conversation),
+		sizes:   make([]uint64, len(sizeBounds)+1),
+		errs:    make(map[string]uint64),
+	}
+}
+
+// Add counts a packet captured at ts, along with the error returned when it
+// was decoded. The layers which were decoded before the error are counted.
+// The size of the packet is the length of its outermost layer.
+func (s *Stats) Add(p packet.Packet, err error, ts time.Time) {
+	var n int
+	if l := p.Layers(); len(l) > 0 {
+		n = len(l[0].GetContents())
+	}
+	s.add(p, err, n, ts)
+}
+
+// AddFrame decodes and counts an Ethernet frame captured at ts.
+func (s *Stats) AddFrame(data []byte, ts time.Time) {
+	p, err := packet.Decode(data)
+	s.add(p, err, len(data), ts)
+}
+
+// Capture counts the frames read from conn, such as an afpacket.Conn, until
+// reading fails. The error of the failed read is returned.
+func (s *Stats) Capture(conn net.PacketConn) error {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		s.AddFrame(buf[:n], time.Now())
+	}
+}
+
+// ReadPcap counts the packets of a capture file, which may have any link type
+// supported by packet.DecodeLinkType. The size of each packet is its length
+// on the wire, which may be larger than the captured length.
+func (s *Stats) ReadPcap(r io.Reader) error {
+	pr, err := pcap.NewReader(r)
+	if err != nil {
+		return err
+	}
+	switch pr.LinkType() {
+	case pcap.LinkTypeEthernet, pcap.LinkTypeIEEE80211, pcap.LinkTypeIEEE80211Radiotap:
+	default:
+		return fmt.Errorf("unsupported link type %d", pr.LinkType())
+	}
+	for {
+		data, ci, err := pr.ReadPacket()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p, err := packet.DecodeLinkType(pr.LinkType(), data)
+		s.add(p, err, ci.Length, ci.Timestamp)
+	}
+}
+
+func (s *Stats) add(p packet.Packet, err error, size int, ts time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.packets++
+	s.bytes += uint64(size)
+	if s.first.IsZero() || ts.Before(s.first) {
+		s.first = ts
+	}
+	if ts.After(s.last) {
+		s.last = ts
+	}
+	if err != nil {
+		s.errs[errReason(p, err)]++
+	}
+	i := sort.SearchInts(sizeBounds, size)
+	s.sizes[i]++
+
+	// Protocol hierarchy
+	n := s.root
+	n.packets++
+	n.bytes += uint64(size)
+	for _, l := range p.Layers() {
+		n = n.child(l.Type())
+		n.packets++
+		n.bytes += uint64(size)
+	}
+
+	// Endpoints
+	var src, dst netip.Addr
+	switch ip := p.Network.(type) {
+	case *packet.IPv4:
+		src, dst = ip.Source, ip.Destination
+	case *packet.IPv6:
+		src, dst = ip.Source, ip.Destination
+	default:
+		return
+	}
+	t := s.talker(src)
+	t.TxPackets++
+	t.TxBytes += uint64(size)
+	t = s.talker(dst)
+	t.RxPackets++
+	t.RxBytes += uint64(size)
+
+	// Conversations
+	f, ok := p.Flow()
+	if !ok {
+		return
+	}
+	key := f.Canonical()
+	c, ok := s.convs[key]
+	if !ok {
+		c = &Conversation{Flow: key, First: ts}
+		s.convs[key] = c
+	}
+	if f == key {
+		c.Packets++
+		c.Bytes += uint64(size)
+	} else {
+		c.ReversePackets++
+		c.ReverseBytes += uint64(size)
+	}
+	if ts.Before(c.First) {
+		c.First = ts
+	}
+	if ts.After(c.Last) {
+		c.Last = ts
+	}
+}
+
+// errReasons are the reasons under which decode errors are counted, by the
+// error they wrap.
+var errReasons = []struct {
+	err    error
+	reason string
+}{
+	{packet.ErrTruncated, "truncated"},
+	{packet.ErrInvalid, "invalid"},
+	{packet.ErrUnsupported, "unsupported"},
+	{packet.ErrAuthFailed, "authentication failed"},
+}
+
+// errReason returns the reason under which a decode error is counted. Error
+// messages may contain values of the packet, such as lengths, so errors are
+// counted by the payload of the last layer which was decoded and the kind of
+// error instead.
+func errReason(p packet.Packet, err error) string {
+	where := "frame"
+	if l := p.Layers(); len(l) > 0 {
+		where = l[len(l)-1].Type().String() + " payload"
+	}
+	for _, r := range errReasons {
+		if errors.Is(err, r.err) {
+			return where + ": " + r.reason
+		}
+	}
+	return where + ": other"
+}
+
+func (s *Stats) talker(addr netip.Addr) *Talker {
+	t, ok := s.talkers[addr]
+	if !ok {
+		t = &Talker{Addr: addr}
+		s.talkers[addr] = t
+	}
+	return t
+}
+
+// Node is a protocol in the protocol hierarchy, which counts the packets
+// which contain the protocols on the path from the root to the node.
+type Node struct {
+	Layer    packet.LayerType
+	Packets  uint64
+	Bytes    uint64
+	Children []Node
+}
+
+func (n Node) MarshalJSON() ([]byte, error) {
+	children := n.Children
+	if children == nil {
+		children = []Node{}
+	}
+	return json.Marshal(struct {
+		Layer    string `json:"layer"`
+		Packets  uint64 `json:"packets"`
+		Bytes    uint64 `json:"bytes"`
+		Children []Node `json:"children"`
+	}{
+		Layer:    n.Layer.String(),
+		Packets:  n.Packets,
+		Bytes:    n.Bytes,
+		Children: children,
+	})
+}
+
+// Talker contains the traffic sent and received by an IP address.
+type Talker struct {
+	Addr      netip.Addr `json:"addr"`
+	TxPackets uint64     `json:"tx_packets"`
+	TxBytes   uint64     `json:"tx_bytes"`
+	RxPackets uint64     `json:"rx_packets"`
+	RxBytes   uint64     `json:"rx_bytes"`
+}
+
+// Conversation contains the traffic of both directions of a flow. Flow is the
+// canonical flow, and the reverse counters count packets of its reverse.
+type Conversation struct {
+	Flow           packet.Flow
+	Packets        uint64
+	Bytes          uint64
+	ReversePackets uint64
+	ReverseBytes   uint64
+	First          time.Time
+	Last           time.Time
+}
+
+func (c Conversation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Proto          string         `json:"proto"`
+		Src            netip.AddrPort `json:"src"`
+		Dst            netip.AddrPort `json:"dst"`
+		Packets        uint64         `json:"packets"`
+		Bytes          uint64         `json:"bytes"`
+		ReversePackets uint64         `json:"reverse_packets"`
+		ReverseBytes   uint64         `json:"reverse_bytes"`
+		First          time.Time      `json:"first"`
+		Last           time.Time      `json:"last"`
+	}{
+		Proto:          c.Flow.Proto.String(),
+		Src:            c.Flow.Src,
+		Dst:            c.Flow.Dst,
+		Packets:        c.Packets,
+		Bytes:          c.Bytes,
+		ReversePackets: c.ReversePackets,
+		ReverseBytes:   c.ReverseBytes,
+		First:          c.First,
+		Last:           c.Last,
+	})
+}
+
+// SizeBucket counts the frames with a size in [Min, Max]. Max is -1 for the
+// last bucket.
+type SizeBucket struct {
+	Min     int    `json:"min"`
+	Max     int    `json:"max"`
+	Packets uint64 `json:"packets"`
+}
+
+// Report is a snapshot of the statistics. Talkers are sorted by the total
+// number of bytes, and conversations by their number of bytes in both
+// directions, largest first. Errors counts the decode errors by the payload
+// which failed to decode and the kind of error, such as
+// "LayerTypeIPv4 payload: truncated", or "frame: truncated" if no layer was
+// decoded.
+type Report struct {
+	Packets       uint64            `json:"packets"`
+	Bytes         uint64            `json:"bytes"`
+	First         time.Time         `json:"first"`
+	Last          time.Time         `json:"last"`
+	Hierarchy     []Node            `json:"hierarchy"`
+	Talkers       []Talker          `json:"talkers"`
+	Conversations []Conversation    `json:"conversations"`
+	Sizes         []SizeBucket      `json:"sizes"`
+	Errors        map[string]uint64 `json:"errors"`
+}
+
+// Report returns the current statistics. At most n talkers and conversations
+// are included, or all of them if n is zero.
+func (s *Stats) Report(n int) Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := Report{
+		Packets:       s.packets,
+		Bytes:         s.bytes,
+		First:         s.first,
+		Last:          s.last,
+		Hierarchy:     s.root.report().Children,
+		Talkers:       make([]Talker, 0, len(s.talkers)),
+		Conversations: make([]Conversation, 0, len(s.convs)),
+		Sizes:         make([]SizeBucket, len(s.sizes)),
+		Errors:        make(map[string]uint64, len(s.errs)),
+	}
+	if r.Hierarchy == nil {
+		r.Hierarchy = []Node{}
+	}
+	for _, t := range s.talkers {
+		r.Talkers = append(r.Talkers, *t)
+	}
+	sort.Slice(r.Talkers, func(i, j int) bool {
+		a, b := r.Talkers[i], r.Talkers[j]
+		if a.TxBytes+a.RxBytes != b.TxBytes+b.RxBytes {
+			return a.TxBytes+a.RxBytes > b.TxBytes+b.RxBytes
+		}
+		return a.Addr.Less(b.Addr)
+	})
+	for _, c := range s.convs {
+		r.Conversations = append(r.Conversations, *c)
+	}
+	sort.Slice(r.Conversations, func(i, j int) bool {
+		a, b := r.Conversations[i], r.Conversations[j]
+		if a.Bytes+a.ReverseBytes != b.Bytes+b.ReverseBytes {
+			return a.Bytes+a.ReverseBytes > b.Bytes+b.ReverseBytes
+		}
+		return a.Flow.String() < b.Flow.String()
+	})
+	if n > 0 && len(r.Talkers) > n {
+		r.Talkers = r.Talkers[:n]
+	}
+	if n > 0 && len(r.Conversations) > n {
+		r.Conversations = r.Conversations[:n]
+	}
+	lo := 0
+	for i, count := range s.sizes {
+		hi := -1
+		if i < len(sizeBounds) {
+			hi = sizeBounds[i]
+		}
+		r.Sizes[i] = SizeBucket{Min: lo, Max: hi, Packets: count}
+		lo = hi + 1
+	}
+	for reason, count := range s.errs {
+		r.Errors[reason] = count
+	}
+	return r
+}
+
+// report returns the subtree of n, with children sorted by bytes, largest
+// first.
+func (n *node) report() Node {
+	var res Node
+	res.Packets, res.Bytes = n.packets, n.bytes
+	for t, c := range n.children {
+		child := c.report()
+		child.Layer = t
+		res.Children = append(res.Children, child)
+	}
+	sort.Slice(res.Children, func(i, j int) bool {
+		a, b := res.Children[i], res.Children[j]
+		if a.Bytes != b.Bytes {
+			return a.Bytes > b.Bytes
+		}
+		return a.Layer < b.Layer
+	})
+	return res
+}
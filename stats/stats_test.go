@@ -0,0 +1,103 @@
+package stats_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/sebnyberg/net/internal/packettest"
+	"github.com/sebnyberg/net/packet"
+	"github.com/sebnyberg/net/pcap"
+	"github.com/sebnyberg/net/stats"
+)
+
+func TestReadPcap(t *testing.T) {
+	var (
+		client = netip.MustParseAddrPort("10.0.0.2:40000")
+		server = netip.MustParseAddrPort("10.0.0.1:53")
+	)
+	var buf bytes.Buffer
+	w, err := pcap.NewWriter(&buf, pcap.LinkTypeEthernet, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(100, 0)
+	for i, frame := range [][]byte{
+		packettest.UDPFrame(client, server, make([]byte, 30)),
+		packettest.UDPFrame(server, client, make([]byte, 100)),
+		packettest.UDPFrame(client, server, make([]byte, 30)),
+		make([]byte, 10),
+	} {
+		ci := pcap.CaptureInfo{Timestamp: start.Add(time.Duration(i) * time.Second)}
+		if err := w.WritePacket(ci, frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := stats.New()
+	if err := s.ReadPcap(&buf); err != nil {
+		t.Fatal(err)
+	}
+	r := s.Report(0)
+	if r.Packets != 4 || r.Bytes != 72+142+72+10 {
+		t.Errorf("unexpected totals %d packets, %d bytes", r.Packets, r.Bytes)
+	}
+	if len(r.Errors) != 1 || r.Errors["frame: truncated"] != 1 {
+		t.Errorf("unexpected errors %v", r.Errors)
+	}
+
+	// Ethernet -> IPv4 -> UDP
+	h := r.Hierarchy
+	for _, want := range []packet.LayerType{packet.LayerTypeEthernet, packet.LayerTypeIPv4, packet.LayerTypeUDP} {
+		if len(h) != 1 || h[0].Layer != want || h[0].Packets != 3 {
+			t.Fatalf("unexpected hierarchy at %v: %+v", want, h)
+		}
+		h = h[0].Children
+	}
+
+	if len(r.Talkers) != 2 || r.Talkers[0].Addr != server.Addr() ||
+		r.Talkers[0].TxBytes != 142 || r.Talkers[0].RxPackets != 2 {
+		t.Errorf("unexpected talkers %+v", r.Talkers)
+	}
+	if len(r.Conversations) != 1 {
+		t.Fatalf("expected one conversation, got %d", len(r.Conversations))
+	}
+	c := r.Conversations[0]
+	if c.Flow.Src != server || c.Packets != 1 || c.ReversePackets != 2 ||
+		!c.First.Equal(start) || !c.Last.Equal(start.Add(2*time.Second)) {
+		t.Errorf("unexpected conversation %+v", c)
+	}
+	// 10 bytes, 72 and 72 bytes, and 142 bytes
+	for i, want := range []uint64{1, 0, 2, 1, 0, 0, 0, 0, 0, 0} {
+		if r.Sizes[i].Packets != want {
+			t.Errorf("bucket %d-%d: got %d packets, want %d", r.Sizes[i].Min, r.Sizes[i].Max, r.Sizes[i].Packets, want)
+		}
+	}
+	if _, err := json.Marshal(r); err != nil {
+		t.Errorf("marshal failed, %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s := stats.New()
+	frame := packettest.UDPFrame(netip.MustParseAddrPort("10.0.0.2:40000"), netip.MustParseAddrPort("10.0.0.1:53"), make([]byte, 30))
+	for _, n := range []int{10, 14 + 10, 14 + 16} {
+		s.AddFrame(frame[:n], time.Unix(100, 0))
+	}
+	binary.BigEndian.PutUint16(frame[14+20+4:], 4)
+	s.AddFrame(frame, time.Unix(100, 0))
+	frame[14] = 0x55
+	s.AddFrame(frame, time.Unix(100, 0))
+	// Errors are counted by the payload which failed to decode and the kind of
+	// error, regardless of the lengths in their messages
+	r := s.Report(0)
+	if len(r.Errors) != 4 || r.Errors["frame: truncated"] != 1 ||
+		r.Errors["LayerTypeEthernet payload: truncated"] != 2 ||
+		r.Errors["LayerTypeEthernet payload: unsupported"] != 1 ||
+		r.Errors["LayerTypeIPv4 payload: invalid"] != 1 {
+		t.Errorf("unexpected errors %v", r.Errors)
+	}
+}